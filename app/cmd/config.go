@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"graphql-comment-system/app/pkg/config"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// loadConfig - функция загрузки конфигурации приложения.
+// Переменные из файла .env загружаются в окружение заранее; ошибка их загрузки не критична
+// и возвращается отдельно, чтобы записать ее в лог после настройки логгера.
+func loadConfig(name string, args []string, extra ...func(*flag.FlagSet)) (cfg config.Config, envErr error, err error) {
+	// Загрузка переменных окружения из файла .env, если он существует.
+	// В противном случае используются системные переменные окружения.
+	envErr = godotenv.Load()
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	for _, register := range extra {
+		register(flags)
+	}
+
+	cfg, err = config.Load(flags, args, os.Getenv)
+	return cfg, envErr, err
+}
+
+// runConfigCommand - обработчик команды `config`.
+// `app config print [-output yaml|toml] [флаги]` выводит итоговую конфигурацию со скрытыми секретами,
+// с учетом файла, переменных окружения и флагов - так же, как ее увидит сервер.
+func runConfigCommand(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("usage: app config print [-output yaml|toml] [-config file] [flags]")
+	}
+
+	var output *string
+	cfg, _, err := loadConfig("config print", args[1:], func(flags *flag.FlagSet) {
+		output = flags.String("output", "yaml", "output format: yaml or toml")
+	})
+	if err != nil {
+		return err
+	}
+
+	raw, err := config.Marshal(cfg.Redacted(), *output)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(raw)
+	return err
+}
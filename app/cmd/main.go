@@ -2,97 +2,307 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"graphql-comment-system/app/graph"
+	"graphql-comment-system/app/pkg/auth"
+	"graphql-comment-system/app/pkg/clock"
+	"graphql-comment-system/app/pkg/config"
 	"graphql-comment-system/app/pkg/data"
 	inmemory "graphql-comment-system/app/pkg/data/in-memory"
 	"graphql-comment-system/app/pkg/data/postgres"
-	"log"
+	"graphql-comment-system/app/pkg/data/sqlite"
+	"graphql-comment-system/app/pkg/health"
+	"graphql-comment-system/app/pkg/ids"
+	"graphql-comment-system/app/pkg/logging"
+	"graphql-comment-system/app/pkg/metrics"
+	"graphql-comment-system/app/pkg/persisted"
+	"graphql-comment-system/app/pkg/retention"
+	"graphql-comment-system/app/pkg/server"
+	"graphql-comment-system/app/pkg/tracing"
+	"log/slog"
 	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/handler/lru"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
-	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/vektah/gqlparser/v2/ast"
 )
 
-const defaultPort = "50051" // Порт по умолчанию для запуска сервера.
+// version - версия приложения, отдаваемая на /status. Подставляется при сборке:
+// go build -ldflags "-X main.version=1.2.3".
+var version = "dev"
 
 func main() {
-	// Загрузка переменных окружения из файла .env, если он существует.
-	// В противном случае используются системные переменные окружения.
-	err := godotenv.Load()
+	// Обработка вспомогательных команд: `app manifest ...` строит манифест persisted queries.
+	if len(os.Args) > 1 && os.Args[1] == "manifest" {
+		if err := runManifestCommand(os.Args[2:]); err != nil {
+			fatal("manifest command failed", err)
+		}
+		return
+	}
+	// `app config print` выводит итоговую конфигурацию со скрытыми секретами.
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfigCommand(os.Args[2:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+			fatal("config command failed", err)
+		}
+		return
+	}
+
+	// Контекст сервера отменяется по SIGINT/SIGTERM, после чего начинается корректная остановка.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Вся работа вынесена в run, чтобы отложенные вызовы (закрытие пула, отправка спанов)
+	// выполнялись до выхода из процесса.
+	if err := run(ctx, os.Args[1:]); err != nil {
+		stop()
+		if errors.Is(err, flag.ErrHelp) {
+			return // Справка по флагам уже выведена.
+		}
+		fatal("server failed", err)
+	}
+}
+
+// run - функция запуска сервера. Возвращается после остановки сервера по отмене ctx.
+// args - флаги командной строки, переопределяющие параметры конфигурации.
+func run(ctx context.Context, args []string) error {
+	// Конфигурация собирается из файла, переменных окружения (в том числе из .env) и флагов
+	// и проверяется целиком до начала работы.
+	cfg, envErr, err := loadConfig("app", args)
 	if err != nil {
-		log.Println("Error loading .env file, using system environment variables: ", err)
+		return err
 	}
 
-	log.Println("Starting server") // Информационное сообщение о старте сервера.
+	// Настройка структурированного логгера: уровень и формат задаются параметрами logging.level и logging.format.
+	logger, err := logging.New(os.Stderr, cfg.Logging.Level, cfg.Logging.Format)
+	if err != nil {
+		return fmt.Errorf("invalid logging configuration: %w", err)
+	}
+	slog.SetDefault(logger) // Логгер по умолчанию используется и пакетами хранилищ.
 
-	// Определение порта для сервера.
-	// Приоритет отдается переменной окружения PORT, если она задана.
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = defaultPort // Использование порта по умолчанию, если PORT не задана.
+	if envErr != nil {
+		slog.Info("error loading .env file, using system environment variables", slog.Any("error", envErr))
 	}
 
-	// Определение типа хранилища данных из переменной окружения STORAGE_TYPE.
-	storageType := os.Getenv("STORAGE_TYPE")
+	slog.Info("starting server", slog.String("version", version)) // Информационное сообщение о старте сервера.
 
-	var postStore data.PostStore   // Интерфейс для хранилища постов.
-	var commentStore data.CommentStore // Интерфейс для хранилища комментариев.
+	// Настройка трейсинга OpenTelemetry. Экспортер выбирается параметром tracing.exporter (none, otlp, stdout, file).
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		FilePath:    cfg.Tracing.File,
+		ServiceName: "graphql-comment-system",
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("error configuring tracing: %w", err)
+	}
+	// Отправка накопленных спанов при завершении; выполняется последней, после остановки сервера и закрытия пула.
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Warn("error shutting down tracing", slog.Any("error", err))
+		}
+	}()
+
+	var postStore data.PostStore                 // Интерфейс для хранилища постов.
+	var commentStore data.CommentStore           // Интерфейс для хранилища комментариев.
+	var unitOfWork data.UnitOfWork               // Транзакции над хранилищами того же бэкенда.
+	var purger data.Purger                       // Окончательное удаление записей по истечении срока хранения.
+	var searcher data.Searcher                   // Полнотекстовый поиск по постам и комментариям.
+	var notificationStore data.NotificationStore // Уведомления о новых комментариях.
+
+	appMetrics := metrics.New() // Метрики Prometheus, отдаваемые на /metrics.
+
+	storageInfo := health.Storage{Type: "inmemory"} // Сведения о хранилище для /readyz и /status.
 
-	// Выбор реализации хранилища данных в зависимости от STORAGE_TYPE.
-	switch storageType {
-	case "postgres":
-		// Конфигурация и инициализация PostgreSQL.
-		dbPortStr := os.Getenv("DB_PORT")
-		dbPort, err := strconv.Atoi(dbPortStr)
+	// Выбор реализации хранилища данных; допустимость storage.type уже проверена при загрузке конфигурации.
+	switch cfg.Storage.Type {
+	case config.StoragePostgres:
+		// Инициализация PostgreSQL.
+		pool, err := postgres.New(postgres.Config{
+			URL:      cfg.Database.URL,
+			Host:     cfg.Database.Host,
+			Port:     cfg.Database.Port,
+			Username: cfg.Database.User,
+			Password: cfg.Database.Password,
+			Database: cfg.Database.Name,
+			SSLMode:  cfg.Database.SSLMode,
+		})
 		if err != nil {
-			log.Fatalf("Error converting DB_PORT '%s' to int: %v", dbPortStr, err)
+			return fmt.Errorf("error connecting to database: %w", err)
 		}
+		// Пул закрывается после остановки HTTP-сервера, когда активных запросов уже не осталось.
+		defer func() {
+			pool.Close()
+			slog.Info("database pool closed")
+		}()
 
-		config := postgres.Config{
-			Host:     os.Getenv("DB_HOST"),
-			Port:     dbPort,
-			Username: os.Getenv("DB_USER"),
-			Password: os.Getenv("DB_PASSWORD"),
-			Database: os.Getenv("DB_NAME"),
+		// Схема базы данных приводится к актуальной версии до начала обслуживания запросов.
+		migrationVersion, err := postgres.Migrate(ctx, pool)
+		if err != nil {
+			return fmt.Errorf("error migrating database: %w", err)
 		}
+		slog.Info("database schema is up to date", slog.Int("migration_version", migrationVersion))
 
-		conn, err := postgres.New(config)
-		if err != nil {
-			log.Fatalf("Error connecting to database: %v", err)
+		storageInfo = health.Storage{
+			Type: "postgres",
+			Ping: pool.Ping,
+			MigrationVersion: func(ctx context.Context) (int, error) {
+				return postgres.MigrationVersion(ctx, pool)
+			},
+			PoolStats: func() *health.PoolStats {
+				stat := pool.Stat()
+				return &health.PoolStats{
+					TotalConns:    stat.TotalConns(),
+					IdleConns:     stat.IdleConns(),
+					AcquiredConns: stat.AcquiredConns(),
+					MaxConns:      stat.MaxConns(),
+				}
+			},
+		}
+
+		// Статистика пула соединений публикуется вместе с остальными метриками.
+		if err := appMetrics.Register(metrics.NewPoolCollector(pool)); err != nil {
+			return fmt.Errorf("error registering pool metrics: %w", err)
 		}
-		defer conn.Close(context.Background()) // Гарантированное закрытие соединения после завершения main.
 
 		// Инициализация хранилищ с использованием PostgreSQL.
-		postStore = postgres.NewPostStore(conn)
-		commentStore = postgres.NewCommentStore(conn)
-		log.Println("Using PostgreSQL storage")
-
-	case "inmemory":
-		// Инициализация In-Memory хранилища.
-		log.Println("Using In-Memory storage")
-		inmemory.InitializeData() // Заполнение In-Memory данными по умолчанию.
-		postStore = inmemory.NewPostStore()
-		commentStore = inmemory.NewCommentStore()
-
-	default:
-		// Default case: In-Memory хранилище, если STORAGE_TYPE не задан или не распознан.
-		log.Println("STORAGE_TYPE not set or invalid, using default In-Memory storage")
-		inmemory.InitializeData() // Заполнение In-Memory данными по умолчанию.
-		postStore = inmemory.NewPostStore()
-		commentStore = inmemory.NewCommentStore()
+		postStore = postgres.NewPostStore(pool)
+		commentStore = postgres.NewCommentStore(pool)
+		unitOfWork = postgres.NewUnitOfWork(pool)
+		purger = postgres.NewPurger(pool)
+		searcher = postgres.NewSearcher(pool)
+		notificationStore = postgres.NewNotificationStore(pool)
+		slog.Info("using PostgreSQL storage")
+
+	case config.StorageSQLite:
+		// Инициализация SQLite: база в одном файле, без отдельного сервера.
+		db, err := sqlite.Open(ctx, cfg.Storage.SQLitePath)
+		if err != nil {
+			return fmt.Errorf("error opening sqlite database: %w", err)
+		}
+		// База закрывается после остановки HTTP-сервера, когда активных запросов уже не осталось.
+		defer func() {
+			db.Close()
+			slog.Info("sqlite database closed")
+		}()
+
+		migrationVersion, err := sqlite.Migrate(ctx, db)
+		if err != nil {
+			return fmt.Errorf("error migrating database: %w", err)
+		}
+		slog.Info("database schema is up to date", slog.Int("migration_version", migrationVersion))
+
+		storageInfo = health.Storage{
+			Type: "sqlite",
+			Ping: db.PingContext,
+			MigrationVersion: func(ctx context.Context) (int, error) {
+				return sqlite.MigrationVersion(ctx, db)
+			},
+			PoolStats: func() *health.PoolStats {
+				stat := db.Stats()
+				return &health.PoolStats{
+					TotalConns:    int32(stat.OpenConnections),
+					IdleConns:     int32(stat.Idle),
+					AcquiredConns: int32(stat.InUse),
+					MaxConns:      int32(stat.MaxOpenConnections),
+				}
+			},
+		}
+
+		if err := appMetrics.Register(collectors.NewDBStatsCollector(db, "sqlite")); err != nil {
+			return fmt.Errorf("error registering database metrics: %w", err)
+		}
+
+		postStore = sqlite.NewPostStore(db)
+		commentStore = sqlite.NewCommentStore(db)
+		unitOfWork = sqlite.NewUnitOfWork(db)
+		purger = sqlite.NewPurger(db)
+		searcher = sqlite.NewSearcher(db)
+		notificationStore = sqlite.NewNotificationStore(db)
+		slog.Info("using SQLite storage", slog.String("path", cfg.Storage.SQLitePath))
+
+	case config.StorageInMemory:
+		// Инициализация In-Memory хранилища: каждый экземпляр владеет своими данными.
+		slog.Info("using In-Memory storage")
+		posts, comments := inmemory.NewStores()
+
+		if cfg.Storage.DataDir != "" {
+			// Режим с сохранением на диск: данные восстанавливаются из снимка и журнала.
+			journal, err := inmemory.OpenJournal(cfg.Storage.DataDir, posts, comments)
+			if err != nil {
+				return fmt.Errorf("error opening in-memory journal: %w", err)
+			}
+			// Итоговый снимок пишется после остановки сервера, когда изменений больше не будет.
+			defer func() {
+				if err := journal.Close(); err != nil {
+					slog.Error("error closing in-memory journal", slog.Any("error", err))
+				}
+			}()
+			go journal.Run(ctx, time.Duration(cfg.Storage.SnapshotInterval))
+		}
+
+		// Демонстрационные данные добавляются только в пустое хранилище (при первом запуске).
+		if cfg.Storage.SeedFixtures && posts.Len() == 0 && comments.Len() == 0 {
+			if err := inmemory.LoadFixtures(ctx, posts, comments, clock.System{}); err != nil {
+				return fmt.Errorf("error loading fixtures: %w", err)
+			}
+		}
+
+		postStore = posts
+		commentStore = comments
+		unitOfWork = inmemory.NewUnitOfWork(posts, comments)
+		purger = inmemory.NewPurger(posts, comments)
+		searcher = inmemory.NewSearcher(posts, comments)
+		notificationStore = comments.Notifications()
+	}
+
+	// Хранилища оборачиваются декораторами трейсинга и метрик, поэтому их получают все бэкенды.
+	postStore = metrics.InstrumentPostStore(tracing.TracePostStore(postStore), appMetrics)
+	commentStore = metrics.InstrumentCommentStore(tracing.TraceCommentStore(commentStore), appMetrics)
+	unitOfWork = metrics.InstrumentUnitOfWork(tracing.TraceUnitOfWork(unitOfWork), appMetrics)
+	purger = metrics.InstrumentPurger(tracing.TracePurger(purger), appMetrics)
+	searcher = metrics.InstrumentSearcher(tracing.TraceSearcher(searcher), appMetrics)
+	notificationStore = metrics.InstrumentNotificationStore(tracing.TraceNotificationStore(notificationStore), appMetrics)
+
+	// Фоновая очистка удаленных записей. Останавливается до закрытия пула и журнала,
+	// так как отложенные вызовы выполняются в обратном порядке.
+	purgeCtx, stopPurge := context.WithCancel(ctx)
+	purgeDone := make(chan struct{})
+	go func() {
+		defer close(purgeDone)
+		retention.New(purger, time.Duration(cfg.Moderation.Retention), time.Duration(cfg.Moderation.PurgeInterval)).Run(purgeCtx)
+	}()
+	defer func() {
+		stopPurge()
+		<-purgeDone
+	}()
+
+	// Генератор ID новых постов и комментариев (формат уже проверен при загрузке конфигурации).
+	idGenerator, err := ids.New(cfg.Storage.IDFormat)
+	if err != nil {
+		return fmt.Errorf("error configuring ID generator: %w", err)
 	}
 
 	// Создание GraphQL-сервера на основе сгенерированной схемы и resolvers.
-	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver(postStore, commentStore)}))
+	resolver := graph.NewResolver(postStore, commentStore, unitOfWork, searcher, notificationStore)
+	resolver.Retention = time.Duration(cfg.Moderation.Retention) // Восстановить удаленную запись можно, пока она не очищена.
+	resolver.IDs = idGenerator
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+	srv.SetErrorPresenter(graph.ErrorPresenter) // Коды ошибок (NOT_FOUND, BAD_USER_INPUT...) в extensions.code.
 
 	// Добавление транспортов для поддержки различных HTTP-методов и WebSocket.
+	srv.AddTransport(transport.Websocket{KeepAlivePingInterval: 10 * time.Second})
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
@@ -100,17 +310,64 @@ func main() {
 	// Настройка кэширования запросов для повышения производительности.
 	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
 
+	// Манифест persisted queries загружается до подключения расширений: метрики используют имена его операций.
+	var manifest *persisted.Manifest
+	tracer := metrics.Tracer{Metrics: appMetrics}
+	manifestPath := cfg.PersistedQueries.Manifest
+	if manifestPath != "" {
+		if manifest, err = persisted.LoadManifest(manifestPath); err != nil {
+			return fmt.Errorf("error loading persisted queries manifest: %w", err)
+		}
+		tracer.KnownOperations = manifest.HasOperation // В метках метрик - только имена операций манифеста.
+	}
+
 	// Добавление расширений, таких как интроспекция и Automatic Persisted Queries.
-	srv.Use(extension.Introspection{}) // Включение интроспекции GraphQL API.
-	srv.Use(extension.AutomaticPersistedQuery{ // Поддержка Automatic Persisted Queries для оптимизации запросов.
-		Cache: lru.New[string](100),
-	})
+	srv.Use(extension.Introspection{})                // Включение интроспекции GraphQL API.
+	srv.Use(&logging.OperationLogger{Logger: logger}) // Запись в лог каждой операции: имя, длительность, ошибки, сложность.
+	srv.Use(tracer)                                   // Метрики операций и resolvers полей.
+	srv.Use(tracing.Tracer{})                         // Спаны операций и resolvers полей.
+
+	// Если задан манифест persisted queries, сервер работает в строгом режиме и принимает только операции из него.
+	// Иначе включаются Automatic Persisted Queries, где клиент может зарегистрировать любой запрос.
+	if manifest != nil {
+		srv.Use(persisted.Allowlist{Manifest: manifest})
+		slog.Info("persisted queries allowlist enabled", slog.Int("operations", manifest.Len()), slog.String("manifest", manifestPath))
+	} else {
+		srv.Use(extension.AutomaticPersistedQuery{ // Поддержка Automatic Persisted Queries для оптимизации запросов.
+			Cache: lru.New[string](100),
+		})
+	}
+
+	// Запросы с токеном модератора (Authorization: Bearer <token>) могут править и удалять чужие записи,
+	// восстанавливать удаленные и видеть историю правок.
+	moderators := auth.NewModerators(cfg.Moderation.Tokens)
+	// Запросы с токеном автора выполняются от его имени: только так можно читать и отмечать его уведомления.
+	authors := auth.NewAuthors(cfg.Authors.TokenMap())
 
 	// Регистрация HTTP-обработчиков: Playground и основной GraphQL endpoint.
-	http.Handle("/", playground.Handler("GraphQL playground", "/query")) // GraphQL Playground для разработки.
-	http.Handle("/query", srv)                                         // Основной GraphQL endpoint.
+	mux := http.NewServeMux()
+	mux.Handle("/", playground.Handler("GraphQL playground", "/query"))  // GraphQL Playground для разработки.
+	mux.Handle("/query", moderators.Middleware(authors.Middleware(srv))) // Основной GraphQL endpoint.
+	mux.Handle("/metrics", appMetrics.Handler())                         // Метрики в формате Prometheus.
+
+	// Проверки состояния для оркестратора: жив ли процесс, готов ли принимать трафик, подробный статус.
+	checks := health.New(version, storageInfo)
+	mux.Handle("/healthz", checks.Liveness())
+	mux.Handle("/readyz", checks.Readiness())
+	mux.Handle("/status", checks.StatusHandler())
+
+	// Все запросы проходят через middleware, назначающее X-Request-ID и извлекающее traceparent.
+	httpServer := server.New(cfg.Server.ServerConfig(), logging.Middleware(logger, tracing.Middleware(mux)), logger)
+	httpServer.OnShutdown(checks.SetShuttingDown) // С начала остановки /readyz отвечает 503.
 
 	// Запуск HTTP-сервера и вывод информации в лог.
-	log.Printf("Connect to http://localhost:%s/ for GraphQL playground", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil)) // Запуск сервера на заданном порту.
-}
\ No newline at end of file
+	// Run возвращается после сигнала остановки, когда запросы и подписки завершены.
+	slog.Info("connect to http://localhost" + cfg.Server.Addr() + "/ for GraphQL playground")
+	return httpServer.Run(ctx)
+}
+
+// fatal - вспомогательная функция, записывающая критическую ошибку в лог и завершающая процесс.
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"graphql-comment-system/app/pkg/persisted"
+	"os"
+)
+
+// runManifestCommand - обработчик команды `manifest`, которая строит манифест
+// разрешенных операций из .graphql файлов.
+//
+// Пример: app manifest -out manifest.json -format apollo ./queries
+func runManifestCommand(args []string) error {
+	flags := flag.NewFlagSet("manifest", flag.ContinueOnError)
+	out := flags.String("out", "", "path to write the manifest to (stdout if empty)")
+	format := flags.String("format", persisted.FormatApollo, "manifest format: apollo or relay")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: app manifest [-out file] [-format apollo|relay] <file or dir>...")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("no .graphql files or directories given")
+	}
+
+	// Сбор файлов с операциями и построение манифеста.
+	files, err := persisted.CollectFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+	manifest, err := persisted.BuildManifestFromFiles(files)
+	if err != nil {
+		return err
+	}
+
+	raw, err := manifest.Marshal(*format)
+	if err != nil {
+		return err
+	}
+	raw = append(raw, '\n')
+
+	if *out == "" {
+		_, err = os.Stdout.Write(raw)
+		return err
+	}
+	if err := os.WriteFile(*out, raw, 0o644); err != nil {
+		return fmt.Errorf("error writing manifest: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %d operations from %d files to %s\n", manifest.Len(), len(files), *out)
+	return nil
+}
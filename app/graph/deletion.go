@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/pkg/auth"
+	"graphql-comment-system/app/pkg/data"
+	"time"
+)
+
+// checkVisible - вспомогательная функция проверки, что клиент может видеть запись kind с ID id.
+// Удаленные записи видят только модераторы; для остальных они не существуют.
+func checkVisible(ctx context.Context, kind, id string, deletedAt *time.Time) error {
+	if deletedAt != nil && !auth.IsModerator(ctx) {
+		return fmt.Errorf("%s with id %s: %w", kind, id, data.ErrNotFound)
+	}
+	return nil
+}
+
+// checkNotDeleted - вспомогательная функция проверки, что запись kind не удалена: удаленную запись
+// нельзя править или удалить повторно, пока модератор ее не восстановит.
+func checkNotDeleted(ctx context.Context, kind, id string, deletedAt *time.Time) error {
+	if err := checkVisible(ctx, kind, id, deletedAt); err != nil {
+		return err
+	}
+	if deletedAt != nil {
+		return fmt.Errorf("%s with id %s is deleted: %w", kind, id, data.ErrConflict)
+	}
+	return nil
+}
+
+// checkRestorable - вспомогательный метод проверки права на восстановление записи kind, удаленной в deletedAt.
+// Восстанавливать записи может только модератор и только в течение срока хранения.
+func (r *Resolver) checkRestorable(ctx context.Context, kind, id string, deletedAt *time.Time) error {
+	if !auth.IsModerator(ctx) {
+		return fmt.Errorf("only a moderator can restore %s %s: %w", kind, id, errForbidden)
+	}
+	if deletedAt == nil || r.Retention <= 0 {
+		return nil // Неудаленную запись хранилище отклонит с ErrConflict.
+	}
+
+	if r.now().Sub(*deletedAt) > r.Retention {
+		return fmt.Errorf("%s with id %s was deleted more than %s ago and can no longer be restored: %w",
+			kind, id, r.Retention, data.ErrConflict)
+	}
+	return nil
+}
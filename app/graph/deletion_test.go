@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"context"
+	"graphql-comment-system/app/pkg/auth"
+	"graphql-comment-system/app/pkg/clock"
+	"testing"
+	"time"
+)
+
+func TestCheckNotDeleted(t *testing.T) {
+	// TestCheckNotDeleted проверяет, что удаленная запись не существует для обычного клиента,
+	// а модератор получает конфликт.
+	ctx := context.Background()
+	deletedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	if err := checkNotDeleted(ctx, "post", "p1", nil); err != nil {
+		t.Errorf("Expected a visible post to pass, got %v", err)
+	}
+	if err := checkNotDeleted(ctx, "post", "p1", &deletedAt); errorCode(err) != CodeNotFound {
+		t.Errorf("Expected %s for a regular client, got %v", CodeNotFound, err)
+	}
+	if err := checkNotDeleted(auth.WithModerator(ctx), "post", "p1", &deletedAt); errorCode(err) != CodeConflict {
+		t.Errorf("Expected %s for a moderator, got %v", CodeConflict, err)
+	}
+}
+
+func TestCheckRestorable(t *testing.T) {
+	// TestCheckRestorable проверяет, что восстановить запись может только модератор и только в течение срока хранения.
+	now := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	r := &Resolver{Retention: 24 * time.Hour, Clock: clock.NewFixed(now)}
+	moderator := auth.WithModerator(context.Background())
+	recent := now.Add(-time.Hour)
+	expired := now.Add(-48 * time.Hour)
+
+	if err := r.checkRestorable(context.Background(), "comment", "c1", &recent); errorCode(err) != CodeForbidden {
+		t.Errorf("Expected %s for a regular client, got %v", CodeForbidden, err)
+	}
+	if err := r.checkRestorable(moderator, "comment", "c1", &recent); err != nil {
+		t.Errorf("Expected a recently deleted comment to be restorable, got %v", err)
+	}
+	if err := r.checkRestorable(moderator, "comment", "c1", &expired); errorCode(err) != CodeConflict {
+		t.Errorf("Expected %s after the retention period, got %v", CodeConflict, err)
+	}
+
+	r.Retention = 0
+	if err := r.checkRestorable(moderator, "comment", "c1", &expired); err != nil {
+		t.Errorf("Expected no limit without a retention period, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/validator"
+	"log/slog"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Коды ошибок, передаваемые клиенту в extensions.code.
+const (
+	CodeNotFound       = "NOT_FOUND"             // CodeNotFound - запрошенной записи нет.
+	CodeConflict       = "CONFLICT"              // CodeConflict - запись с таким ID уже существует.
+	CodeBadUserInput   = "BAD_USER_INPUT"        // CodeBadUserInput - некорректные аргументы: ошибки валидации, поврежденный курсор.
+	CodeForbidden      = "FORBIDDEN"             // CodeForbidden - операция не разрешена (например, правка чужой записи).
+	CodeInternalServer = "INTERNAL_SERVER_ERROR" // CodeInternalServer - внутренняя ошибка; подробности только в логе.
+)
+
+// errForbidden - ошибка запрета операции; клиент получает код CodeForbidden.
+var errForbidden = errors.New("forbidden")
+
+// internalErrorMessage - сообщение, которое клиент получает вместо текста внутренней ошибки.
+const internalErrorMessage = "internal server error"
+
+// ErrorPresenter - функция представления ошибок resolvers клиенту (handler.Server.SetErrorPresenter).
+// Ошибки хранилищ и валидации получают код в extensions.code; сообщения остальных ошибок не меняются.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if code := errorCode(err); code != "" {
+		errcode.Set(gqlErr, code)
+	}
+	return gqlErr
+}
+
+// errorCode - вспомогательная функция определения кода ошибки по ошибкам пакета data и валидатора.
+// Пустая строка означает, что вид ошибки неизвестен.
+func errorCode(err error) string {
+	var validationErr *validator.ValidationError
+	switch {
+	case errors.Is(err, data.ErrNotFound):
+		return CodeNotFound
+	case errors.Is(err, data.ErrConflict):
+		return CodeConflict
+	case errors.Is(err, errForbidden):
+		return CodeForbidden
+	case errors.Is(err, data.ErrInvalidCursor), errors.Is(err, data.ErrInvalidInput), errors.As(err, &validationErr):
+		return CodeBadUserInput
+	}
+	return ""
+}
+
+// storeError - вспомогательная функция обработки ошибки хранилища в resolver.
+// Ошибки известного вида (не найдено, конфликт, неверный курсор) возвращаются клиенту с контекстом msg.
+// Остальные ошибки - внутренние: они записываются в лог, а клиент получает только код и общее сообщение,
+// чтобы тексты SQL-ошибок и детали инфраструктуры не попадали в ответ.
+func storeError(ctx context.Context, msg string, err error) error {
+	if errorCode(err) != "" {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+
+	slog.ErrorContext(ctx, msg, slog.Any("error", err))
+	gqlErr := gqlerror.Errorf(internalErrorMessage)
+	errcode.Set(gqlErr, CodeInternalServer)
+	return gqlErr
+}
+
+// txError - вспомогательная функция обработки ошибки единицы работы в resolver.
+// Ошибки, которые fn уже подготовила для клиента (inputError, storeError), возвращаются как есть;
+// остальные - ошибки начала или фиксации транзакции - обрабатываются storeError.
+func txError(ctx context.Context, msg string, err error) error {
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) || errorCode(err) != "" {
+		return err
+	}
+	return storeError(ctx, msg, err)
+}
+
+// validationErrors - ошибки валидации входных данных мутации; сообщение перечисляет их все.
+type validationErrors []error
+
+// Error - реализация интерфейса error: "validation errors: <ошибка>; <ошибка>".
+func (e validationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "validation errors: " + strings.Join(messages, "; ")
+}
+
+// Unwrap - метод, позволяющий errors.As найти отдельные *validator.ValidationError.
+func (e validationErrors) Unwrap() []error {
+	return e
+}
+
+// inputError - вспомогательная функция превращения результата валидации в ошибку resolver.
+// Если валидатор не смог проверить данные (например, хранилище недоступно), это внутренняя ошибка,
+// а не ошибка валидации.
+func inputError(ctx context.Context, msg string, errs []error) error {
+	for _, err := range errs {
+		var validationErr *validator.ValidationError
+		if !errors.As(err, &validationErr) {
+			return storeError(ctx, msg, err)
+		}
+	}
+	return validationErrors(errs)
+}
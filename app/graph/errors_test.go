@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/validator"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+func TestErrorPresenterCodes(t *testing.T) {
+	// TestErrorPresenterCodes проверяет коды extensions.code для ошибок хранилищ и валидации.
+	ctx := graphql.WithResponseContext(context.Background(), graphql.DefaultErrorPresenter, graphql.DefaultRecover)
+
+	for _, tc := range []struct {
+		err  error
+		code any
+	}{
+		{fmt.Errorf("get post: %w", data.ErrNotFound), CodeNotFound},
+		{fmt.Errorf("create post: %w", data.ErrConflict), CodeConflict},
+		{fmt.Errorf("get posts: %w", data.ErrInvalidCursor), CodeBadUserInput},
+		{fmt.Errorf("update post: %w", errForbidden), CodeForbidden},
+		{validationErrors{&validator.ValidationError{Field: "title", Message: "title cannot be empty"}}, CodeBadUserInput},
+		{errors.New("unclassified"), nil},
+	} {
+		got := ErrorPresenter(ctx, tc.err)
+		if got.Extensions["code"] != tc.code {
+			t.Errorf("%v: expected code %v, got %v", tc.err, tc.code, got.Extensions["code"])
+		}
+	}
+}
+
+func TestStoreErrorHidesInternalErrors(t *testing.T) {
+	// TestStoreErrorHidesInternalErrors проверяет, что текст внутренней ошибки не попадает к клиенту.
+	ctx := graphql.WithResponseContext(context.Background(), graphql.DefaultErrorPresenter, graphql.DefaultRecover)
+
+	err := storeError(ctx, "get posts", errors.New(`ERROR: relation "posts" does not exist (SQLSTATE 42P01)`))
+	presented := ErrorPresenter(ctx, err)
+	if presented.Message != internalErrorMessage || presented.Extensions["code"] != CodeInternalServer {
+		t.Errorf("Expected generic internal error, got %q (%v)", presented.Message, presented.Extensions)
+	}
+
+	err = storeError(ctx, "get post by id", fmt.Errorf("post with id 1: %w", data.ErrNotFound))
+	if !errors.Is(err, data.ErrNotFound) || !strings.Contains(err.Error(), "post with id 1") {
+		t.Errorf("Expected not found error to be passed through, got %v", err)
+	}
+}
+
+func TestInputError(t *testing.T) {
+	// TestInputError проверяет, что сбой хранилища во время валидации не выдается за ошибку валидации.
+	ctx := context.Background()
+
+	err := inputError(ctx, "validate", []error{
+		&validator.ValidationError{Field: "author", Message: "author cannot be empty"},
+		&validator.ValidationError{Field: "content", Message: "content cannot be empty"},
+	})
+	if errorCode(err) != CodeBadUserInput || !strings.HasPrefix(err.Error(), "validation errors: ") || !strings.Contains(err.Error(), "; ") {
+		t.Errorf("Expected joined validation errors, got %v", err)
+	}
+
+	err = inputError(ctx, "validate", []error{errors.New("connection refused")})
+	if strings.Contains(err.Error(), "connection refused") || errorCode(err) != "" {
+		t.Errorf("Expected internal error to be hidden, got %v", err)
+	}
+}
+
+func TestTxError(t *testing.T) {
+	// TestTxError проверяет, что ошибки, подготовленные внутри единицы работы, не оборачиваются повторно.
+	ctx := context.Background()
+
+	prepared := storeError(ctx, "create comment", fmt.Errorf("comment with id 1: %w", data.ErrConflict))
+	if err := txError(ctx, "create comment", prepared); err != prepared {
+		t.Errorf("Expected prepared error unchanged, got %v", err)
+	}
+
+	internal := storeError(ctx, "create comment", errors.New("connection reset"))
+	if err := txError(ctx, "create comment", internal); err != internal {
+		t.Errorf("Expected internal error unchanged, got %v", err)
+	}
+
+	err := txError(ctx, "create comment", errors.New("commit unexpectedly resulted in rollback"))
+	if strings.Contains(err.Error(), "rollback") || errorCode(err) != "" {
+		t.Errorf("Expected commit error to be hidden, got %v", err)
+	}
+}
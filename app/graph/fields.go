@@ -0,0 +1,22 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// fieldRequested - вспомогательная функция проверки, выбрано ли в запросе поле name у результата текущего resolver
+// (например, totalCount у connection), с учетом фрагментов. Позволяет не выполнять подсчеты, которые клиент не запросил.
+// Вне выполнения GraphQL-запроса (например, при прямом вызове resolver в тестах) возвращает false.
+func fieldRequested(ctx context.Context, name string) bool {
+	if !graphql.HasOperationContext(ctx) || graphql.GetFieldContext(ctx) == nil {
+		return false
+	}
+	for _, field := range graphql.CollectFieldsCtx(ctx, nil) {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	inmemory "graphql-comment-system/app/pkg/data/in-memory"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+// countingPostStore - хранилище постов, запоминающее число вызовов CountPosts.
+type countingPostStore struct {
+	data.PostStore
+	counts int
+}
+
+// CountPosts - метод подсчета постов с учетом вызова.
+func (s *countingPostStore) CountPosts(ctx context.Context, filter model.PostFilter) (int, error) {
+	s.counts++
+	return s.PostStore.CountPosts(ctx, filter)
+}
+
+func TestTotalCountIsLazy(t *testing.T) {
+	// TestTotalCountIsLazy проверяет, что totalCount считается только тогда, когда поле запрошено,
+	// в том числе через фрагмент.
+	ctx := context.Background()
+	posts, comments := inmemory.NewStores()
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, id := range []string{"p1", "p2", "p3"} {
+		if err := posts.AddPost(ctx, &model.Post{ID: id, Author: "Автор", Title: "Пост", Content: "Текст", CreatedAt: createdAt, AllowComments: true}); err != nil {
+			t.Fatalf("AddPost failed: %v", err)
+		}
+	}
+	for _, id := range []string{"c1", "c2"} {
+		if err := comments.AddComment(ctx, &model.Comment{ID: id, PostID: "p1", Author: "Комментатор", Content: "Текст", CreatedAt: createdAt}); err != nil {
+			t.Fatalf("AddComment failed: %v", err)
+		}
+	}
+
+	counting := &countingPostStore{PostStore: posts}
+	resolver := NewResolver(counting, comments, inmemory.NewUnitOfWork(posts, comments), inmemory.NewSearcher(posts, comments), comments.Notifications())
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+	srv.AddTransport(transport.POST{})
+	c := client.New(srv)
+
+	var withoutCount struct {
+		Posts struct{ Edges []struct{ Cursor string } }
+	}
+	c.MustPost(`{ posts(first: 1) { edges { cursor } } }`, &withoutCount)
+	if counting.counts != 0 || len(withoutCount.Posts.Edges) != 1 {
+		t.Errorf("Expected no count without totalCount, got %d calls", counting.counts)
+	}
+
+	var withCount struct {
+		Posts struct{ TotalCount int }
+		Post  struct {
+			Comments struct{ TotalCount int }
+		}
+	}
+	c.MustPost(`query($id: ID!) {
+		posts(first: 1) { ...total }
+		post(id: $id) { comments(first: 1) { totalCount } }
+	}
+	fragment total on PostConnection { totalCount }`, &withCount, client.Var("id", globalID(nodePost, "p1")))
+	if counting.counts != 1 {
+		t.Errorf("Expected one count for a requested totalCount, got %d calls", counting.counts)
+	}
+	if withCount.Posts.TotalCount != 3 || withCount.Post.Comments.TotalCount != 2 {
+		t.Errorf("Expected 3 posts and 2 comments, got %+v", withCount)
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// MarshalDateTime - функция вывода скаляра DateTime: дата в UTC в формате RFC3339 с долями секунды
+// (незначащие нули дробной части отбрасываются).
+func MarshalDateTime(t time.Time) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(t.UTC().Format(time.RFC3339Nano)))
+	})
+}
+
+// UnmarshalDateTime - функция разбора скаляра DateTime из строки RFC3339 (доли секунды и смещение часового пояса
+// необязательны).
+func UnmarshalDateTime(v any) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("DateTime must be a string, got %T", v)
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("DateTime must be an RFC3339 string such as 2024-05-01T12:30:00Z, got %q", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMarshalDateTime(t *testing.T) {
+	// TestMarshalDateTime проверяет вывод даты в UTC с долями секунды.
+	moscow := time.FixedZone("MSK", 3*60*60)
+	for _, tc := range []struct {
+		value time.Time
+		want  string
+	}{
+		{time.Date(2024, 5, 1, 15, 30, 0, 123456000, moscow), `"2024-05-01T12:30:00.123456Z"`},
+		{time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), `"2024-05-01T12:30:00Z"`},
+	} {
+		var buf bytes.Buffer
+		MarshalDateTime(tc.value).MarshalGQL(&buf)
+		if buf.String() != tc.want {
+			t.Errorf("MarshalDateTime(%v) = %s, want %s", tc.value, buf.String(), tc.want)
+		}
+	}
+}
+
+func TestUnmarshalDateTime(t *testing.T) {
+	// TestUnmarshalDateTime проверяет разбор RFC3339 с долями секунды и без них и ошибки для других значений.
+	got, err := UnmarshalDateTime("2024-05-01T15:30:00.5+03:00")
+	if err != nil || !got.Equal(time.Date(2024, 5, 1, 12, 30, 0, 500000000, time.UTC)) {
+		t.Errorf("Unexpected result: %v (%v)", got, err)
+	}
+	if _, err := UnmarshalDateTime("2024-05-01T12:30:00Z"); err != nil {
+		t.Errorf("Expected a date without fractions to be accepted, got %v", err)
+	}
+
+	for _, value := range []any{"вчера", "2024-05-01", 1714566600} {
+		if _, err := UnmarshalDateTime(value); err == nil {
+			t.Errorf("Expected an error for %v", value)
+		}
+	}
+}
@@ -2,20 +2,49 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type Node interface {
+	IsNode()
+	GetID() string
+}
+
+type SearchResult interface {
+	IsSearchResult()
+}
+
 type Comment struct {
-	ID        string             `json:"id"`
-	Author    string             `json:"author"`
-	Content   string             `json:"content"`
-	CreatedAt string             `json:"createdAt"`
-	PostID    string             `json:"postId"`
-	Post      *Post              `json:"post"`
-	ParentID  *string            `json:"parentId,omitempty"`
-	Replies   *CommentConnection `json:"replies"`
+	ID            string              `json:"id"`
+	Author        string              `json:"author"`
+	Content       string              `json:"content"`
+	ContentHTML   string              `json:"contentHtml"`
+	CreatedAt     time.Time           `json:"createdAt"`
+	PostID        string              `json:"postId"`
+	Post          *Post               `json:"post"`
+	ParentID      *string             `json:"parentId,omitempty"`
+	Replies       *CommentConnection  `json:"replies"`
+	EditedAt      *time.Time          `json:"editedAt,omitempty"`
+	RevisionCount int32               `json:"revisionCount"`
+	Revisions     *RevisionConnection `json:"revisions"`
+	DeletedAt     *time.Time          `json:"deletedAt,omitempty"`
+	DeletedBy     *string             `json:"deletedBy,omitempty"`
+	Mentions      []*Mention          `json:"mentions"`
 }
 
+func (Comment) IsNode()            {}
+func (this Comment) GetID() string { return this.ID }
+
+func (Comment) IsSearchResult() {}
+
 type CommentConnection struct {
-	Edges    []*CommentEdge `json:"edges"`
-	PageInfo *PageInfo      `json:"pageInfo"`
+	Edges      []*CommentEdge `json:"edges"`
+	PageInfo   *PageInfo      `json:"pageInfo"`
+	TotalCount int32          `json:"totalCount"`
 }
 
 type CommentEdge struct {
@@ -31,15 +60,54 @@ type CreateCommentInput struct {
 }
 
 type CreatePostInput struct {
-	Author        string `json:"author"`
-	Title         string `json:"title"`
-	Content       string `json:"content"`
-	AllowComments bool   `json:"allowComments"`
+	Author        string   `json:"author"`
+	Title         string   `json:"title"`
+	Content       string   `json:"content"`
+	AllowComments bool     `json:"allowComments"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+type DeleteCommentInput struct {
+	ID     string `json:"id"`
+	Author string `json:"author"`
+}
+
+type DeletePostInput struct {
+	ID     string `json:"id"`
+	Author string `json:"author"`
+}
+
+type Mention struct {
+	Handle string `json:"handle"`
+	Offset int32  `json:"offset"`
+	Length int32  `json:"length"`
 }
 
 type Mutation struct {
 }
 
+type Notification struct {
+	ID        string           `json:"id"`
+	Recipient string           `json:"recipient"`
+	Kind      NotificationKind `json:"kind"`
+	Actor     string           `json:"actor"`
+	PostID    string           `json:"postId"`
+	CommentID string           `json:"commentId"`
+	Comment   *Comment         `json:"comment,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+	ReadAt    *time.Time       `json:"readAt,omitempty"`
+}
+
+type NotificationConnection struct {
+	Edges    []*NotificationEdge `json:"edges"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+}
+
+type NotificationEdge struct {
+	Cursor string        `json:"cursor"`
+	Node   *Notification `json:"node"`
+}
+
 type PageInfo struct {
 	HasNextPage     bool    `json:"hasNextPage"`
 	HasPreviousPage bool    `json:"hasPreviousPage"`
@@ -48,18 +116,31 @@ type PageInfo struct {
 }
 
 type Post struct {
-	ID            string             `json:"id"`
-	Author        string             `json:"author"`
-	Title         string             `json:"title"`
-	Content       string             `json:"content"`
-	CreatedAt     string             `json:"createdAt"`
-	AllowComments bool               `json:"allowComments"`
-	Comments      *CommentConnection `json:"comments"`
+	ID            string              `json:"id"`
+	Author        string              `json:"author"`
+	Title         string              `json:"title"`
+	Content       string              `json:"content"`
+	ContentHTML   string              `json:"contentHtml"`
+	CreatedAt     time.Time           `json:"createdAt"`
+	AllowComments bool                `json:"allowComments"`
+	Comments      *CommentConnection  `json:"comments"`
+	EditedAt      *time.Time          `json:"editedAt,omitempty"`
+	RevisionCount int32               `json:"revisionCount"`
+	Revisions     *RevisionConnection `json:"revisions"`
+	DeletedAt     *time.Time          `json:"deletedAt,omitempty"`
+	DeletedBy     *string             `json:"deletedBy,omitempty"`
+	Tags          []string            `json:"tags"`
 }
 
+func (Post) IsNode()            {}
+func (this Post) GetID() string { return this.ID }
+
+func (Post) IsSearchResult() {}
+
 type PostConnection struct {
-	Edges    []*PostEdge `json:"edges"`
-	PageInfo *PageInfo   `json:"pageInfo"`
+	Edges      []*PostEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int32       `json:"totalCount"`
 }
 
 type PostEdge struct {
@@ -67,5 +148,150 @@ type PostEdge struct {
 	Cursor string `json:"cursor"`
 }
 
+type PostFilter struct {
+	Author        *string    `json:"author,omitempty"`
+	CreatedAfter  *time.Time `json:"createdAfter,omitempty"`
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+	AllowComments *bool      `json:"allowComments,omitempty"`
+	HasComments   *bool      `json:"hasComments,omitempty"`
+	Tag           *string    `json:"tag,omitempty"`
+}
+
 type Query struct {
 }
+
+type Revision struct {
+	Number    int32     `json:"number"`
+	Title     *string   `json:"title,omitempty"`
+	Content   *string   `json:"content,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type RevisionConnection struct {
+	Edges    []*RevisionEdge `json:"edges"`
+	PageInfo *PageInfo       `json:"pageInfo"`
+}
+
+type RevisionEdge struct {
+	Cursor string    `json:"cursor"`
+	Node   *Revision `json:"node"`
+}
+
+type SearchConnection struct {
+	Edges    []*SearchEdge `json:"edges"`
+	PageInfo *PageInfo     `json:"pageInfo"`
+}
+
+type SearchEdge struct {
+	Cursor  string       `json:"cursor"`
+	Node    SearchResult `json:"node"`
+	Snippet string       `json:"snippet"`
+	Rank    float64      `json:"rank"`
+}
+
+type Subscription struct {
+}
+
+type Tag struct {
+	Name      string `json:"name"`
+	PostCount int32  `json:"postCount"`
+}
+
+type UpdateCommentInput struct {
+	ID      string `json:"id"`
+	Author  string `json:"author"`
+	Content string `json:"content"`
+}
+
+type UpdatePostInput struct {
+	ID            string   `json:"id"`
+	Author        string   `json:"author"`
+	Title         *string  `json:"title,omitempty"`
+	Content       *string  `json:"content,omitempty"`
+	AllowComments *bool    `json:"allowComments,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+type NotificationKind string
+
+const (
+	NotificationKindComment NotificationKind = "COMMENT"
+	NotificationKindReply   NotificationKind = "REPLY"
+	NotificationKindMention NotificationKind = "MENTION"
+)
+
+var AllNotificationKind = []NotificationKind{
+	NotificationKindComment,
+	NotificationKindReply,
+	NotificationKindMention,
+}
+
+func (e NotificationKind) IsValid() bool {
+	switch e {
+	case NotificationKindComment, NotificationKindReply, NotificationKindMention:
+		return true
+	}
+	return false
+}
+
+func (e NotificationKind) String() string {
+	return string(e)
+}
+
+func (e *NotificationKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationKind", str)
+	}
+	return nil
+}
+
+func (e NotificationKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SearchType string
+
+const (
+	SearchTypePost    SearchType = "POST"
+	SearchTypeComment SearchType = "COMMENT"
+)
+
+var AllSearchType = []SearchType{
+	SearchTypePost,
+	SearchTypeComment,
+}
+
+func (e SearchType) IsValid() bool {
+	switch e {
+	case SearchTypePost, SearchTypeComment:
+		return true
+	}
+	return false
+}
+
+func (e SearchType) String() string {
+	return string(e)
+}
+
+func (e *SearchType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SearchType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SearchType", str)
+	}
+	return nil
+}
+
+func (e SearchType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"strings"
+)
+
+// Типы записей, реализующих интерфейс Node. Название типа GraphQL служит префиксом глобального ID.
+const (
+	nodePost    = "Post"    // nodePost - посты.
+	nodeComment = "Comment" // nodeComment - комментарии.
+)
+
+// maxNodes - наибольшее число ID в одном запросе nodes.
+const maxNodes = 100
+
+// globalID - функция кодирования глобального ID записи: base64url от "<тип>:<ID в хранилище>".
+// Глобальные ID уникальны среди записей всех типов и по ним можно определить, в каком хранилище искать запись.
+func globalID(typ, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(typ + ":" + id))
+}
+
+// parseGlobalID - функция разбора глобального ID на тип записи и ее ID в хранилище.
+// Тип не проверяется: это делают localID и fetchNode.
+func parseGlobalID(globalID string) (typ, id string, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(globalID)
+	if err == nil {
+		var found bool
+		if typ, id, found = strings.Cut(string(raw), ":"); found && typ != "" && id != "" {
+			return typ, id, nil
+		}
+	}
+	return "", "", fmt.Errorf("invalid ID %q: %w", globalID, data.ErrInvalidInput)
+}
+
+// localID - функция получения ID записи в хранилище из глобального ID аргумента.
+// ID другого типа - такая же ошибка клиента (BAD_USER_INPUT), как поврежденный.
+func localID(typ, globalID string) (string, error) {
+	idType, id, err := parseGlobalID(globalID)
+	if err != nil {
+		return "", err
+	}
+	if idType != typ {
+		return "", fmt.Errorf("invalid ID %q: expected a %s ID, got a %s ID: %w", globalID, typ, idType, data.ErrInvalidInput)
+	}
+	return id, nil
+}
+
+// optionalLocalID - функция получения ID записи в хранилище из необязательного глобального ID.
+func optionalLocalID(typ string, globalID *string) (*string, error) {
+	if globalID == nil {
+		return nil, nil
+	}
+	id, err := localID(typ, *globalID)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// optionalGlobalID - функция кодирования необязательного ID записи в хранилище.
+func optionalGlobalID(typ string, id *string) *string {
+	if id == nil {
+		return nil
+	}
+	encoded := globalID(typ, *id)
+	return &encoded
+}
+
+// fetchNode - метод получения записи по глобальному ID: по префиксу выбирается хранилище нужного типа.
+// Для записей неизвестного типа, отсутствующих и скрытых от клиента (удаленных) возвращается nil без ошибки,
+// как того требует спецификация Relay. Новый тип, реализующий Node, добавляется сюда.
+func (r *Resolver) fetchNode(ctx context.Context, id string) (model.Node, error) {
+	typ, id, err := parseGlobalID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var node model.Node
+	switch typ {
+	case nodePost:
+		var post *model.Post
+		if post, err = r.PostStore.GetPostByID(ctx, id); err == nil {
+			err = checkVisible(ctx, "post", post.ID, post.DeletedAt)
+			node = post
+		}
+	case nodeComment:
+		var comment *model.Comment
+		if comment, err = r.CommentStore.GetCommentByID(ctx, id); err == nil {
+			err = checkVisible(ctx, "comment", comment.ID, comment.DeletedAt)
+			node = comment
+		}
+	default:
+		return nil, nil
+	}
+
+	if errors.Is(err, data.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error getting node", err)
+	}
+	return node, nil
+}
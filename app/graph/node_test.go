@@ -0,0 +1,94 @@
+package graph
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/auth"
+	inmemory "graphql-comment-system/app/pkg/data/in-memory"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGlobalID(t *testing.T) {
+	// TestGlobalID проверяет кодирование и разбор глобальных ID.
+	id := globalID(nodePost, "0190a5c4-3c7e-7b1a-9f00-000000000001")
+	if strings.Contains(id, "Post") {
+		t.Errorf("Expected an opaque ID, got %s", id)
+	}
+	if got, err := localID(nodePost, id); err != nil || got != "0190a5c4-3c7e-7b1a-9f00-000000000001" {
+		t.Errorf("Expected the store ID back, got %q (%v)", got, err)
+	}
+
+	// ID другого типа, поврежденный или без префикса - ошибка клиента.
+	for _, bad := range []string{globalID(nodeComment, "1"), "1", "!!!", globalID("", "1"), globalID(nodePost, "")} {
+		if _, err := localID(nodePost, bad); errorCode(err) != CodeBadUserInput {
+			t.Errorf("Expected %s for %q, got %v", CodeBadUserInput, bad, err)
+		}
+	}
+
+	if id, err := optionalLocalID(nodeComment, nil); id != nil || err != nil {
+		t.Errorf("Expected nil for a missing ID, got %v (%v)", id, err)
+	}
+	if id := optionalGlobalID(nodeComment, nil); id != nil {
+		t.Errorf("Expected nil for a missing ID, got %v", *id)
+	}
+}
+
+func TestFetchNode(t *testing.T) {
+	// TestFetchNode проверяет, что node выбирает хранилище по типу глобального ID и скрывает удаленные записи.
+	ctx := context.Background()
+	posts, comments := inmemory.NewStores()
+	r := NewResolver(posts, comments, inmemory.NewUnitOfWork(posts, comments), inmemory.NewSearcher(posts, comments), comments.Notifications())
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := posts.AddPost(ctx, &model.Post{ID: "p1", Author: "Автор", Title: "Пост", Content: "Текст", CreatedAt: createdAt, AllowComments: true}); err != nil {
+		t.Fatalf("AddPost failed: %v", err)
+	}
+	if err := comments.AddComment(ctx, &model.Comment{ID: "c1", PostID: "p1", Author: "Комментатор", Content: "Текст", CreatedAt: createdAt}); err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+
+	node, err := r.Query().Node(ctx, globalID(nodePost, "p1"))
+	if post, ok := node.(*model.Post); err != nil || !ok || post.ID != "p1" {
+		t.Errorf("Expected post p1, got %#v (%v)", node, err)
+	}
+	node, err = r.Query().Node(ctx, globalID(nodeComment, "c1"))
+	if comment, ok := node.(*model.Comment); err != nil || !ok || comment.ID != "c1" {
+		t.Errorf("Expected comment c1, got %#v (%v)", node, err)
+	}
+
+	// ID поста в префиксе комментария - это другая, несуществующая запись.
+	for _, id := range []string{globalID(nodeComment, "p1"), globalID("Tag", "go"), globalID(nodePost, "missing")} {
+		if node, err := r.Query().Node(ctx, id); node != nil || err != nil {
+			t.Errorf("Expected null for %s, got %#v (%v)", id, node, err)
+		}
+	}
+	if _, err := r.Query().Node(ctx, "garbage"); errorCode(err) != CodeBadUserInput {
+		t.Errorf("Expected %s for a malformed ID, got %v", CodeBadUserInput, err)
+	}
+
+	// Удаленный пост виден только модератору.
+	if _, err := posts.DeletePost(ctx, "p1", "Автор", createdAt.Add(time.Hour)); err != nil {
+		t.Fatalf("DeletePost failed: %v", err)
+	}
+	if node, err := r.Query().Node(ctx, globalID(nodePost, "p1")); node != nil || err != nil {
+		t.Errorf("Expected a deleted post to be hidden, got %#v (%v)", node, err)
+	}
+	if node, err := r.Query().Node(auth.WithModerator(ctx), globalID(nodePost, "p1")); node == nil || err != nil {
+		t.Errorf("Expected a moderator to see the deleted post, got %#v (%v)", node, err)
+	}
+
+	// nodes сохраняет порядок и оставляет null на месте отсутствующих записей.
+	nodes, err := r.Query().Nodes(ctx, []string{globalID(nodeComment, "c1"), globalID(nodePost, "missing")})
+	if err != nil || len(nodes) != 2 || nodes[1] != nil {
+		t.Fatalf("Unexpected nodes: %#v (%v)", nodes, err)
+	}
+	if comment, ok := nodes[0].(*model.Comment); !ok || comment.ID != "c1" {
+		t.Errorf("Expected comment c1 first, got %#v", nodes[0])
+	}
+
+	if _, err := r.Query().Nodes(ctx, make([]string, maxNodes+1)); errorCode(err) != CodeBadUserInput {
+		t.Errorf("Expected a limit error, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/auth"
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/mentions"
+	"time"
+)
+
+// nodeNotification - префикс глобальных ID уведомлений. Уведомления не реализуют Node:
+// они доступны только получателю через notifications, а не любому клиенту через node.
+const nodeNotification = "Notification"
+
+// maxNotificationIDs - наибольшее число ID в одном запросе markNotificationsRead.
+const maxNotificationIDs = 100
+
+// notifyComment - метод создания уведомлений о новом комментарии внутри единицы работы, в которой он добавлен:
+// уведомления фиксируются вместе с комментарием или не появляются вовсе. Ответ адресуется автору
+// родительского комментария, корневой комментарий - автору поста; упомянутые в тексте авторы получают
+// уведомление MENTION (см. notifyMentions). О собственных комментариях авторы не уведомляются.
+// Возвращает созданные уведомления для рассылки подписчикам после фиксации (см. publish).
+func (r *Resolver) notifyComment(ctx context.Context, tx data.Tx, comment *model.Comment) ([]*model.Notification, error) {
+	recipient, kind, err := commentTarget(ctx, tx, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []*model.Notification
+	if recipient != comment.Author {
+		notification, err := r.addNotification(ctx, tx, recipient, kind, comment, comment.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, notification)
+	}
+
+	mentioned, err := r.notifyMentions(ctx, tx, comment, map[string]bool{recipient: true}, comment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return append(created, mentioned...), nil
+}
+
+// notifyCommentEdit - метод создания уведомлений об упоминаниях, появившихся при правке комментария old
+// (новая версия - comment). Уведомление получают только упомянутые впервые: адресат комментария
+// и упомянутые до правки уже знают о нем.
+func (r *Resolver) notifyCommentEdit(ctx context.Context, tx data.Tx, old, comment *model.Comment, editedAt time.Time) ([]*model.Notification, error) {
+	recipient, _, err := commentTarget(ctx, tx, comment)
+	if err != nil {
+		return nil, err
+	}
+	skip := map[string]bool{recipient: true}
+	for _, handle := range mentions.Handles(old.Mentions) {
+		skip[handle] = true
+	}
+	return r.notifyMentions(ctx, tx, comment, skip, editedAt)
+}
+
+// notifyMentions - метод создания уведомлений MENTION авторам, упомянутым в комментарии (comment.Mentions).
+// Кроме автора комментария пропускаются имена из skip: у них уже есть уведомление об этом комментарии
+// (о новом комментарии или об упоминании до правки), поэтому каждое имя получает не больше одного уведомления.
+func (r *Resolver) notifyMentions(ctx context.Context, tx data.Tx, comment *model.Comment, skip map[string]bool, createdAt time.Time) ([]*model.Notification, error) {
+	var created []*model.Notification
+	for _, handle := range mentions.Handles(comment.Mentions) {
+		if handle == comment.Author || skip[handle] {
+			continue
+		}
+		notification, err := r.addNotification(ctx, tx, handle, model.NotificationKindMention, comment, createdAt)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, notification)
+	}
+	return created, nil
+}
+
+// addNotification - вспомогательный метод записи уведомления получателю recipient о комментарии comment.
+func (r *Resolver) addNotification(ctx context.Context, tx data.Tx, recipient string, kind model.NotificationKind, comment *model.Comment, createdAt time.Time) (*model.Notification, error) {
+	notification := &model.Notification{
+		ID:        r.IDs.NewID(),
+		Recipient: recipient,
+		Kind:      kind,
+		Actor:     comment.Author,
+		PostID:    comment.PostID,
+		CommentID: comment.ID,
+		CreatedAt: createdAt,
+	}
+	if err := tx.Notifications().AddNotification(ctx, notification); err != nil {
+		return nil, storeError(ctx, "error creating notification", err)
+	}
+	return notification, nil
+}
+
+// commentTarget - функция выбора адресата уведомления о новом комментарии: автора родительского комментария
+// для ответа (REPLY) или автора поста для корневого комментария (COMMENT).
+func commentTarget(ctx context.Context, tx data.Tx, comment *model.Comment) (string, model.NotificationKind, error) {
+	if comment.ParentID != nil {
+		parent, err := tx.Comments().GetCommentByID(ctx, *comment.ParentID)
+		if err != nil {
+			return "", "", storeError(ctx, "error getting parent comment", err)
+		}
+		return parent.Author, model.NotificationKindReply, nil
+	}
+	post, err := tx.Posts().GetPostByID(ctx, comment.PostID)
+	if err != nil {
+		return "", "", storeError(ctx, "error getting post", err)
+	}
+	return post.Author, model.NotificationKindComment, nil
+}
+
+// commentMentions - функция поиска упоминаний в тексте комментария: остаются упоминания только известных
+// авторов (data.NotificationStore.KnownRecipients), остальные остаются обычным текстом.
+func commentMentions(ctx context.Context, tx data.Tx, content string) ([]*model.Mention, error) {
+	found := mentions.Find(content)
+	if len(found) == 0 {
+		return []*model.Mention{}, nil
+	}
+	known, err := tx.Notifications().KnownRecipients(ctx, mentions.Candidates(found))
+	if err != nil {
+		return nil, storeError(ctx, "error checking mentions", err)
+	}
+	return mentions.Filter(found, known), nil
+}
+
+// publish - метод рассылки зафиксированных уведомлений подпискам notificationAdded их получателей.
+// Вызывается после завершения единицы работы, поэтому подписчик не узнает о комментарии, который откатился.
+func (r *Resolver) publish(notifications []*model.Notification) {
+	for _, notification := range notifications {
+		r.Subscribers.Publish(notification.Recipient, notification)
+	}
+}
+
+// currentRecipient - функция получения получателя уведомлений для запроса: имя автора, подтвержденное
+// токеном автора (auth.Author). Анонимный запрос получает FORBIDDEN: имя получателя не передается аргументом,
+// поэтому прочитать, отметить или слушать чужие уведомления нельзя.
+func currentRecipient(ctx context.Context) (string, error) {
+	recipient, ok := auth.Author(ctx)
+	if !ok {
+		return "", fmt.Errorf("notifications require an author token: %w", errForbidden)
+	}
+	return recipient, nil
+}
+
+// notificationIDs - функция получения ID уведомлений в хранилище из глобальных ID аргумента markNotificationsRead.
+// nil (аргумент не передан) остается nil - это все уведомления получателя.
+func notificationIDs(globalIDs []string) ([]string, error) {
+	if globalIDs == nil {
+		return nil, nil
+	}
+	if len(globalIDs) > maxNotificationIDs {
+		return nil, fmt.Errorf("too many IDs: %d (at most %d): %w", len(globalIDs), maxNotificationIDs, data.ErrInvalidInput)
+	}
+
+	ids := make([]string, len(globalIDs))
+	for i, globalID := range globalIDs {
+		id, err := localID(nodeNotification, globalID)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
@@ -0,0 +1,288 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/auth"
+	inmemory "graphql-comment-system/app/pkg/data/in-memory"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newNotificationResolver - вспомогательная функция создания Resolver над пустыми in-memory хранилищами.
+func newNotificationResolver() *Resolver {
+	posts, comments := inmemory.NewStores()
+	return NewResolver(posts, comments, inmemory.NewUnitOfWork(posts, comments), inmemory.NewSearcher(posts, comments), comments.Notifications())
+}
+
+// notificationsOf - вспомогательная функция получения всех уведомлений получателя через query notifications
+// от его имени.
+func notificationsOf(t *testing.T, r *Resolver, recipient string, unreadOnly bool) []*model.Notification {
+	t.Helper()
+	first := int32(100)
+	connection, err := r.Query().Notifications(auth.WithAuthor(context.Background(), recipient), &first, nil, &unreadOnly)
+	if err != nil {
+		t.Fatalf("Notifications failed: %v", err)
+	}
+	result := make([]*model.Notification, len(connection.Edges))
+	for i, edge := range connection.Edges {
+		result[i] = edge.Node
+	}
+	return result
+}
+
+func TestCreateCommentNotifies(t *testing.T) {
+	// TestCreateCommentNotifies проверяет адресатов уведомлений: корневой комментарий - автору поста,
+	// ответ - автору родительского комментария, о собственных комментариях уведомлений нет.
+	ctx := context.Background()
+	r := newNotificationResolver()
+	mutation := r.Mutation()
+
+	post, err := mutation.CreatePost(ctx, model.CreatePostInput{Title: "Пост", Author: "Автор", Content: "Текст", AllowComments: true})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+	postID := globalID(nodePost, post.ID)
+	comment, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: postID, Author: "Анна", Content: "Комментарий"})
+	if err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	parentID := globalID(nodeComment, comment.ID)
+	reply, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: postID, ParentID: &parentID, Author: "Борис", Content: "Ответ"})
+	if err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	// Автор отвечает сам себе и комментирует свой пост - уведомлений нет.
+	if _, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: postID, ParentID: &parentID, Author: "Анна", Content: "Дополнение"}); err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	if _, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: postID, Author: "Автор", Content: "Спасибо"}); err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+
+	toAuthor := notificationsOf(t, r, "Автор", false)
+	if len(toAuthor) != 1 || toAuthor[0].Kind != model.NotificationKindComment || toAuthor[0].Actor != "Анна" || toAuthor[0].CommentID != comment.ID {
+		t.Errorf("Expected one COMMENT notification from Анна, got %+v", toAuthor)
+	}
+	toAnna := notificationsOf(t, r, "Анна", false)
+	if len(toAnna) != 1 || toAnna[0].Kind != model.NotificationKindReply || toAnna[0].Actor != "Борис" || toAnna[0].CommentID != reply.ID {
+		t.Errorf("Expected one REPLY notification from Борис, got %+v", toAnna)
+	}
+	if toBoris := notificationsOf(t, r, "Борис", false); len(toBoris) != 0 {
+		t.Errorf("Expected no notifications for Борис, got %+v", toBoris)
+	}
+
+	// Поля уведомления отдаются с глобальными ID, а comment - это сам ответ.
+	notification := r.Notification()
+	if id, _ := notification.CommentID(ctx, toAnna[0]); id != globalID(nodeComment, reply.ID) {
+		t.Errorf("Expected the global ID of the reply, got %s", id)
+	}
+	if got, err := notification.Comment(ctx, toAnna[0]); err != nil || got == nil || got.ID != reply.ID {
+		t.Errorf("Expected the reply, got %+v (%v)", got, err)
+	}
+}
+
+func TestNotificationAddedSubscription(t *testing.T) {
+	// TestNotificationAddedSubscription проверяет, что подписчик получает уведомление своего получателя,
+	// а после отмены контекста подписка завершается.
+	r := newNotificationResolver()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := r.Subscription().NotificationAdded(auth.WithAuthor(ctx, "Автор"))
+	if err != nil {
+		t.Fatalf("NotificationAdded failed: %v", err)
+	}
+	if _, err := r.Subscription().NotificationAdded(ctx); errorCode(err) != CodeForbidden {
+		t.Errorf("Expected %s for an anonymous subscription, got %v", CodeForbidden, err)
+	}
+
+	post, err := r.Mutation().CreatePost(context.Background(), model.CreatePostInput{Title: "Пост", Author: "Автор", Content: "Текст", AllowComments: true})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+	comment, err := r.Mutation().CreateComment(context.Background(), model.CreateCommentInput{PostID: globalID(nodePost, post.ID), Author: "Анна", Content: "Комментарий"})
+	if err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+
+	select {
+	case notification := <-events:
+		if notification.CommentID != comment.ID || notification.Recipient != "Автор" {
+			t.Errorf("Expected a notification about %s, got %+v", comment.ID, notification)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Notification was not delivered")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected the subscription to end")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Subscription did not end after cancel")
+	}
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	// TestMarkNotificationsRead проверяет отметку прочтения по глобальным ID и всех уведомлений сразу.
+	ctx := context.Background()
+	r := newNotificationResolver()
+	mutation := r.Mutation()
+
+	post, err := mutation.CreatePost(ctx, model.CreatePostInput{Title: "Пост", Author: "Автор", Content: "Текст", AllowComments: true})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+	for _, author := range []string{"Анна", "Борис", "Вера"} {
+		if _, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: globalID(nodePost, post.ID), Author: author, Content: "Комментарий"}); err != nil {
+			t.Fatalf("CreateComment failed: %v", err)
+		}
+	}
+	unread := notificationsOf(t, r, "Автор", true)
+	if len(unread) != 3 {
+		t.Fatalf("Expected 3 unread notifications, got %d", len(unread))
+	}
+
+	// Уведомление читает только его получатель.
+	id := globalID(nodeNotification, unread[0].ID)
+	author := auth.WithAuthor(ctx, "Автор")
+	if marked, err := mutation.MarkNotificationsRead(auth.WithAuthor(ctx, "Анна"), []string{id}); err != nil || marked != 0 {
+		t.Errorf("Expected nothing marked for another recipient, got %d (%v)", marked, err)
+	}
+	if marked, err := mutation.MarkNotificationsRead(author, []string{id}); err != nil || marked != 1 {
+		t.Errorf("Expected 1 notification marked, got %d (%v)", marked, err)
+	}
+	if unread := notificationsOf(t, r, "Автор", true); len(unread) != 2 {
+		t.Errorf("Expected 2 unread notifications, got %d", len(unread))
+	}
+	if marked, err := mutation.MarkNotificationsRead(author, nil); err != nil || marked != 2 {
+		t.Errorf("Expected the remaining 2 notifications marked, got %d (%v)", marked, err)
+	}
+
+	// ID другого типа и слишком длинный список - ошибки клиента.
+	if _, err := mutation.MarkNotificationsRead(author, []string{globalID(nodeComment, unread[0].CommentID)}); errorCode(err) != CodeBadUserInput {
+		t.Errorf("Expected %s for a comment ID, got %v", CodeBadUserInput, err)
+	}
+	tooMany := slices.Repeat([]string{id}, maxNotificationIDs+1)
+	if _, err := mutation.MarkNotificationsRead(author, tooMany); errorCode(err) != CodeBadUserInput {
+		t.Errorf("Expected %s for %d IDs, got %v", CodeBadUserInput, len(tooMany), err)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	// TestMentions проверяет разбор упоминаний при создании и правке комментария: неизвестные имена и адреса
+	// остаются текстом, каждое имя получает не больше одного уведомления о комментарии.
+	ctx := context.Background()
+	r := newNotificationResolver()
+	mutation := r.Mutation()
+
+	post, err := mutation.CreatePost(ctx, model.CreatePostInput{Title: "Пост", Author: "Автор", Content: "Текст", AllowComments: true})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+	postID := globalID(nodePost, post.ID)
+	for _, author := range []string{"Анна", "Вера"} {
+		if _, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: postID, Author: author, Content: "Комментарий"}); err != nil {
+			t.Fatalf("CreateComment failed: %v", err)
+		}
+	}
+
+	comment, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: postID, Author: "Борис",
+		Content: "@Анна, @Незнакомец, пишите на boris@почта.рф. @Автор, @Борис"})
+	if err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	if got := fmt.Sprint(mentionHandles(comment.Mentions)); got != "[Анна@0 Автор@46]" {
+		t.Errorf("Unexpected mentions %s", got)
+	}
+
+	// Автор поста уже получил уведомление о комментарии, поэтому упоминание его не дублирует.
+	if got := notificationsOf(t, r, "Анна", false); len(got) != 1 || got[0].Kind != model.NotificationKindMention || got[0].Actor != "Борис" {
+		t.Errorf("Expected a MENTION notification for Анна, got %+v", got)
+	}
+	if got := notificationsOf(t, r, "Автор", false); len(got) != 3 || got[0].Kind != model.NotificationKindComment {
+		t.Errorf("Expected only COMMENT notifications for Автор, got %+v", got)
+	}
+	for _, handle := range []string{"Незнакомец", "Борис"} {
+		if got := notificationsOf(t, r, handle, false); len(got) != 0 {
+			t.Errorf("Expected no notifications for %s, got %+v", handle, got)
+		}
+	}
+
+	// При правке уведомляются только упомянутые впервые.
+	edited, err := mutation.UpdateComment(auth.WithAuthor(ctx, "Борис"), model.UpdateCommentInput{ID: globalID(nodeComment, comment.ID), Author: "Борис", Content: "@Вера и снова @Анна"})
+	if err != nil {
+		t.Fatalf("UpdateComment failed: %v", err)
+	}
+	if got := fmt.Sprint(mentionHandles(edited.Mentions)); got != "[Вера@0 Анна@14]" {
+		t.Errorf("Unexpected mentions after edit %s", got)
+	}
+	if got := notificationsOf(t, r, "Вера", false); len(got) != 1 || got[0].Kind != model.NotificationKindMention || !got[0].CreatedAt.Equal(*edited.EditedAt) {
+		t.Errorf("Expected a MENTION notification for Вера at the edit time, got %+v", got)
+	}
+	if got := notificationsOf(t, r, "Анна", false); len(got) != 1 {
+		t.Errorf("Expected no new notifications for Анна, got %+v", got)
+	}
+}
+
+func TestNotificationsRequireIdentity(t *testing.T) {
+	// TestNotificationsRequireIdentity проверяет, что уведомления доступны только их получателю: анонимный запрос
+	// получает FORBIDDEN, а другой автор не видит, не отмечает прочитанными и не слушает чужие уведомления.
+	ctx := context.Background()
+	r := newNotificationResolver()
+	mutation := r.Mutation()
+	author, anna := auth.WithAuthor(ctx, "Автор"), auth.WithAuthor(ctx, "Анна")
+
+	subscription, cancel := context.WithCancel(anna)
+	defer cancel()
+	annaEvents, err := r.Subscription().NotificationAdded(subscription)
+	if err != nil {
+		t.Fatalf("NotificationAdded failed: %v", err)
+	}
+	post, err := mutation.CreatePost(ctx, model.CreatePostInput{Title: "Пост", Author: "Автор", Content: "Текст", AllowComments: true})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+	if _, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: globalID(nodePost, post.ID), Author: "Борис", Content: "Комментарий"}); err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+
+	first := int32(10)
+	if _, err := r.Query().Notifications(ctx, &first, nil, nil); errorCode(err) != CodeForbidden {
+		t.Errorf("Expected %s for anonymous notifications, got %v", CodeForbidden, err)
+	}
+	if _, err := mutation.MarkNotificationsRead(ctx, nil); errorCode(err) != CodeForbidden {
+		t.Errorf("Expected %s for anonymous markNotificationsRead, got %v", CodeForbidden, err)
+	}
+	if got := notificationsOf(t, r, "Анна", false); len(got) != 0 {
+		t.Errorf("Expected Анна to see no notifications, got %+v", got)
+	}
+
+	// Анна отмечает "все свои" уведомления - уведомление автора поста остается непрочитанным.
+	if marked, err := mutation.MarkNotificationsRead(anna, nil); err != nil || marked != 0 {
+		t.Errorf("Expected nothing marked for Анна, got %d (%v)", marked, err)
+	}
+	unread, err := r.Query().Notifications(author, &first, nil, nil)
+	if err != nil || len(unread.Edges) != 1 || unread.Edges[0].Node.ReadAt != nil {
+		t.Errorf("Expected one unread notification for Автор, got %+v (%v)", unread, err)
+	}
+
+	select {
+	case notification := <-annaEvents:
+		t.Errorf("Expected no events for Анна, got %+v", notification)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// mentionHandles - вспомогательная функция записи упоминаний в виде "имя@позиция".
+func mentionHandles(mentions []*model.Mention) []string {
+	result := make([]string, len(mentions))
+	for i, mention := range mentions {
+		result[i] = fmt.Sprintf("%s@%d", mention.Handle, mention.Offset)
+	}
+	return result
+}
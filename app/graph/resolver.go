@@ -1,7 +1,13 @@
 package graph
 
 import (
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/clock"
 	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/ids"
+	"graphql-comment-system/app/pkg/markdown"
+	"graphql-comment-system/app/pkg/pubsub"
+	"time"
 )
 
 // Resolver - структура для хранения зависимостей, необходимых для resolvers GraphQL.
@@ -9,11 +15,39 @@ import (
 type Resolver struct {
 	PostStore    data.PostStore    // Интерфейс для доступа к данным постов.
 	CommentStore data.CommentStore // Интерфейс для доступа к данным комментариев.
+	UnitOfWork   data.UnitOfWork   // Транзакции для мутаций, где проверка и запись должны быть атомарными.
+	Searcher     data.Searcher     // Полнотекстовый поиск по постам и комментариям того же хранилища.
+	Retention    time.Duration     // Срок, в течение которого удаленные записи можно восстановить; 0 - без ограничения.
+	Clock        clock.Clock       // Источник времени для дат создания, правки и удаления.
+	IDs          ids.Generator     // Генератор ID новых постов, комментариев и уведомлений.
+
+	Notifications data.NotificationStore              // Уведомления о новых комментариях.
+	Subscribers   *pubsub.Broker[*model.Notification] // Рассылка новых уведомлений подпискам notificationAdded по получателю.
+
+	Markdown *markdown.Renderer // Отрисовка текста постов и комментариев в HTML (поле contentHtml) с кэшем.
 }
 
 // NewResolver - конструктор для создания экземпляра Resolver.
-// Принимает реализации интерфейсов PostStore и CommentStore и возвращает Resolver,
-// готовый к использованию в resolvers GraphQL.
-func NewResolver(postStore data.PostStore, commentStore data.CommentStore) *Resolver {
-	return &Resolver{PostStore: postStore, CommentStore: commentStore}
+// Принимает реализации интерфейсов PostStore, CommentStore, UnitOfWork, Searcher и NotificationStore того же хранилища
+// и возвращает Resolver, готовый к использованию в resolvers GraphQL.
+// Время берется из системных часов, ID - случайные UUIDv4; и то и другое можно заменить полями Clock и IDs.
+// Текст отрисовывается в HTML с кэшем на markdown.DefaultCacheSize текстов.
+func NewResolver(postStore data.PostStore, commentStore data.CommentStore, uow data.UnitOfWork, searcher data.Searcher, notifications data.NotificationStore) *Resolver {
+	return &Resolver{
+		PostStore:     postStore,
+		CommentStore:  commentStore,
+		UnitOfWork:    uow,
+		Searcher:      searcher,
+		Clock:         clock.System{},
+		IDs:           ids.UUIDv4{},
+		Markdown:      markdown.NewRenderer(markdown.DefaultCacheSize),
+		Notifications: notifications,
+		Subscribers:   pubsub.New[*model.Notification](),
+	}
+}
+
+// now - вспомогательный метод текущего времени для дат создания, правки и удаления:
+// время сразу приводится к точности хранилищ, поэтому ответ мутации совпадает с тем, что потом вернет запрос.
+func (r *Resolver) now() time.Time {
+	return data.Timestamp(r.Clock.Now())
 }
@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/auth"
+	"graphql-comment-system/app/pkg/clock"
+	inmemory "graphql-comment-system/app/pkg/data/in-memory"
+	"graphql-comment-system/app/pkg/ids"
+	"testing"
+	"time"
+)
+
+func TestMutationsUseClockAndIDs(t *testing.T) {
+	// TestMutationsUseClockAndIDs проверяет, что мутации берут ID и даты из зависимостей Resolver,
+	// поэтому с фиксированными часами и генератором ответ полностью предсказуем.
+	ctx := context.Background()
+	posts, comments := inmemory.NewStores()
+	r := NewResolver(posts, comments, inmemory.NewUnitOfWork(posts, comments), inmemory.NewSearcher(posts, comments), comments.Notifications())
+
+	start := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.FixedZone("MSK", 3*60*60))
+	fixed := clock.NewFixed(start)
+	r.Clock = fixed
+	next := 0
+	r.IDs = ids.Func(func() string {
+		next++
+		return fmt.Sprintf("id-%d", next)
+	})
+	mutation := r.Mutation()
+
+	post, err := mutation.CreatePost(ctx, model.CreatePostInput{Title: "Пост", Author: "Автор", Content: "Текст", AllowComments: true})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+	// Время приводится к UTC и точности хранилищ.
+	if want := time.Date(2024, 5, 1, 9, 30, 0, 123456000, time.UTC); post.ID != "id-1" || post.CreatedAt != want {
+		t.Errorf("Expected post id-1 created at %v, got %s at %v", want, post.ID, post.CreatedAt)
+	}
+
+	fixed.Advance(time.Minute)
+	comment, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: globalID(nodePost, post.ID), Author: "Комментатор", Content: "Комментарий"})
+	if err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	if want := post.CreatedAt.Add(time.Minute); comment.ID != "id-2" || !comment.CreatedAt.Equal(want) {
+		t.Errorf("Expected comment id-2 created at %v, got %s at %v", want, comment.ID, comment.CreatedAt)
+	}
+
+	fixed.Advance(time.Minute)
+	edited, err := mutation.UpdateComment(auth.WithAuthor(ctx, "Комментатор"), model.UpdateCommentInput{ID: globalID(nodeComment, comment.ID), Author: "Комментатор", Content: "Исправленный комментарий"})
+	if err != nil {
+		t.Fatalf("UpdateComment failed: %v", err)
+	}
+	if want := post.CreatedAt.Add(2 * time.Minute); edited.EditedAt == nil || !edited.EditedAt.Equal(want) {
+		t.Errorf("Expected comment edited at %v, got %v", want, edited.EditedAt)
+	}
+}
+
+func TestContentHTML(t *testing.T) {
+	// TestContentHTML проверяет, что contentHtml отрисовывается из исходного текста, который остается в content,
+	// а текст с неподдерживаемой разметкой отклоняется при создании и правке.
+	ctx := context.Background()
+	r := newNotificationResolver()
+	mutation := r.Mutation()
+
+	post, err := mutation.CreatePost(ctx, model.CreatePostInput{Title: "Пост", Author: "Автор", Content: "**Важно:** см. https://example.com", AllowComments: true})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+	want := `<p><strong>Важно:</strong> см. <a href="https://example.com" rel="nofollow ugc">https://example.com</a></p>` + "\n"
+	if html, err := r.Post().ContentHTML(ctx, post); err != nil || html != want || post.Content != "**Важно:** см. https://example.com" {
+		t.Errorf("Expected %q, got %q (%v), content %q", want, html, err, post.Content)
+	}
+
+	comment, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: globalID(nodePost, post.ID), Author: "Анна", Content: "> цитата\n\n`код`"})
+	if err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	if html, err := r.Comment().ContentHTML(ctx, comment); err != nil || html != "<blockquote>\n<p>цитата</p>\n</blockquote>\n<p><code>код</code></p>\n" {
+		t.Errorf("Unexpected comment HTML %q (%v)", html, err)
+	}
+
+	if _, err := mutation.CreatePost(ctx, model.CreatePostInput{Title: "Пост", Author: "Автор", Content: "# Заголовок", AllowComments: true}); errorCode(err) != CodeBadUserInput {
+		t.Errorf("Expected %s for a heading, got %v", CodeBadUserInput, err)
+	}
+	if _, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: globalID(nodePost, post.ID), Author: "Анна", Content: "<script>alert(1)</script>"}); errorCode(err) != CodeBadUserInput {
+		t.Errorf("Expected %s for HTML, got %v", CodeBadUserInput, err)
+	}
+	if _, err := mutation.UpdateComment(ctx, model.UpdateCommentInput{ID: globalID(nodeComment, comment.ID), Author: "Анна", Content: "- пункт"}); errorCode(err) != CodeBadUserInput {
+		t.Errorf("Expected %s for a list, got %v", CodeBadUserInput, err)
+	}
+}
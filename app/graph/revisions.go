@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/auth"
+)
+
+// checkAuthor - вспомогательная функция проверки права на правку записи kind с ID id и автором stored.
+// Править запись может модератор или ее автор, предъявивший свой токен (см. auth.Authors): имя автора
+// берется из токена, а не из запроса, который может прислать кто угодно.
+func checkAuthor(ctx context.Context, kind, id, stored string) error {
+	if auth.IsModerator(ctx) {
+		return nil
+	}
+	author, ok := auth.Author(ctx)
+	if !ok {
+		return fmt.Errorf("editing %s %s requires an author token: %w", kind, id, errForbidden)
+	}
+	if author != stored {
+		return fmt.Errorf("only the author or a moderator can edit %s %s: %w", kind, id, errForbidden)
+	}
+	return nil
+}
+
+// actingAuthor - вспомогательная функция имени того, кто удаляет запись (deletedBy): автор из токена,
+// а для модератора, у которого токена автора нет, - имя из запроса.
+func actingAuthor(ctx context.Context, claimed string) string {
+	if author, ok := auth.Author(ctx); ok {
+		return author
+	}
+	return claimed
+}
+
+// revisionConnection - вспомогательная функция подготовки страницы ревизий для клиента:
+// заполняет курсоры начала и конца страницы и скрывает текст прежних версий от всех, кроме модераторов.
+func revisionConnection(ctx context.Context, result *model.RevisionConnection) *model.RevisionConnection {
+	moderator := auth.IsModerator(ctx)
+
+	edges := make([]*model.RevisionEdge, 0, len(result.Edges))
+	for _, edge := range result.Edges {
+		node := edge.Node
+		if !moderator {
+			node = &model.Revision{Number: node.Number, CreatedAt: node.CreatedAt}
+		}
+		edges = append(edges, &model.RevisionEdge{Cursor: edge.Cursor, Node: node})
+	}
+
+	pageInfo := &model.PageInfo{HasNextPage: result.PageInfo.HasNextPage}
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	return &model.RevisionConnection{Edges: edges, PageInfo: pageInfo}
+}
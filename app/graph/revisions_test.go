@@ -0,0 +1,105 @@
+package graph
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/auth"
+	"testing"
+	"time"
+)
+
+func TestCheckAuthor(t *testing.T) {
+	// TestCheckAuthor проверяет, что запись может править только автор с токеном или модератор.
+	ctx := context.Background()
+
+	if err := checkAuthor(auth.WithAuthor(ctx, "Автор"), "post", "p1", "Автор"); err != nil {
+		t.Errorf("Expected the author to be allowed, got %v", err)
+	}
+	if err := checkAuthor(auth.WithAuthor(ctx, "Другой"), "post", "p1", "Автор"); errorCode(err) != CodeForbidden {
+		t.Errorf("Expected %s for another author, got %v", CodeForbidden, err)
+	}
+	if err := checkAuthor(ctx, "post", "p1", "Автор"); errorCode(err) != CodeForbidden {
+		t.Errorf("Expected %s without an author token, got %v", CodeForbidden, err)
+	}
+	if err := checkAuthor(auth.WithModerator(ctx), "post", "p1", "Автор"); err != nil {
+		t.Errorf("Expected a moderator to be allowed, got %v", err)
+	}
+}
+
+func TestRevisionConnection(t *testing.T) {
+	// TestRevisionConnection проверяет, что текст прежних версий видят только модераторы.
+	title, content := "Заголовок", "Текст"
+	page := &model.RevisionConnection{
+		Edges: []*model.RevisionEdge{
+			{Cursor: "a", Node: &model.Revision{Number: 1, Title: &title, Content: &content, CreatedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)}},
+			{Cursor: "b", Node: &model.Revision{Number: 2, Title: &title, Content: &content, CreatedAt: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)}},
+		},
+		PageInfo: &model.PageInfo{HasNextPage: true},
+	}
+
+	got := revisionConnection(context.Background(), page)
+	for _, edge := range got.Edges {
+		if edge.Node.Title != nil || edge.Node.Content != nil || edge.Node.CreatedAt.IsZero() {
+			t.Errorf("Expected hidden text for a regular client, got %+v", edge.Node)
+		}
+	}
+	if page.Edges[0].Node.Content == nil {
+		t.Error("Expected the store page to stay unchanged")
+	}
+	if *got.PageInfo.StartCursor != "a" || *got.PageInfo.EndCursor != "b" || !got.PageInfo.HasNextPage {
+		t.Errorf("Unexpected page info: %+v", got.PageInfo)
+	}
+
+	got = revisionConnection(auth.WithModerator(context.Background()), page)
+	if got.Edges[1].Node.Content == nil || *got.Edges[1].Node.Content != content {
+		t.Errorf("Expected full revision for a moderator, got %+v", got.Edges[1].Node)
+	}
+}
+
+func TestMutationsRequireAuthorToken(t *testing.T) {
+	// TestMutationsRequireAuthorToken проверяет, что имя автора в запросе не дает права править и удалять запись:
+	// без токена и с токеном другого автора возвращается FORBIDDEN, а с токеном автора правка и удаление проходят.
+	ctx := context.Background()
+	r := newNotificationResolver()
+	mutation := r.Mutation()
+
+	post, err := mutation.CreatePost(ctx, model.CreatePostInput{Title: "Пост", Author: "Автор", Content: "Текст", AllowComments: true})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+	comment, err := mutation.CreateComment(ctx, model.CreateCommentInput{PostID: globalID(nodePost, post.ID), Author: "Анна", Content: "Комментарий"})
+	if err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	title := "Чужой заголовок"
+	updatePost := model.UpdatePostInput{ID: globalID(nodePost, post.ID), Author: "Автор", Title: &title}
+	updateComment := model.UpdateCommentInput{ID: globalID(nodeComment, comment.ID), Author: "Анна", Content: "Чужой текст"}
+
+	for name, ctx := range map[string]context.Context{"anonymous": ctx, "wrong token": auth.WithAuthor(ctx, "Борис")} {
+		if _, err := mutation.UpdatePost(ctx, updatePost); errorCode(err) != CodeForbidden {
+			t.Errorf("%s: expected %s for updatePost, got %v", name, CodeForbidden, err)
+		}
+		if _, err := mutation.UpdateComment(ctx, updateComment); errorCode(err) != CodeForbidden {
+			t.Errorf("%s: expected %s for updateComment, got %v", name, CodeForbidden, err)
+		}
+		if _, err := mutation.DeletePost(ctx, model.DeletePostInput{ID: updatePost.ID, Author: "Автор"}); errorCode(err) != CodeForbidden {
+			t.Errorf("%s: expected %s for deletePost, got %v", name, CodeForbidden, err)
+		}
+		if _, err := mutation.DeleteComment(ctx, model.DeleteCommentInput{ID: updateComment.ID, Author: "Анна"}); errorCode(err) != CodeForbidden {
+			t.Errorf("%s: expected %s for deleteComment, got %v", name, CodeForbidden, err)
+		}
+	}
+	if got, err := r.PostStore.GetPostByID(ctx, post.ID); err != nil || got.Title != "Пост" || got.DeletedAt != nil {
+		t.Errorf("Expected the post to stay unchanged, got %+v (%v)", got, err)
+	}
+
+	// С токеном автора правка проходит, а deletedBy берется из токена, а не из поля author.
+	anna := auth.WithAuthor(ctx, "Анна")
+	if _, err := mutation.UpdateComment(anna, updateComment); err != nil {
+		t.Errorf("Expected the author to edit the comment, got %v", err)
+	}
+	deleted, err := mutation.DeleteComment(anna, model.DeleteCommentInput{ID: updateComment.ID, Author: "Автор"})
+	if err != nil || deleted.DeletedBy == nil || *deleted.DeletedBy != "Анна" {
+		t.Errorf("Expected the comment deleted by Анна, got %+v (%v)", deleted, err)
+	}
+}
@@ -6,26 +6,52 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/tags"
 	"graphql-comment-system/app/pkg/validator"
-	"strings"
-	"time"
-
-	"github.com/google/uuid"
 )
 
+// ID - resolver для поля id типа Comment.
+// Возвращает глобальный ID комментария (см. globalID).
+func (r *commentResolver) ID(ctx context.Context, obj *model.Comment) (string, error) {
+	return globalID(nodeComment, obj.ID), nil
+}
+
+// ContentHTML - resolver для поля contentHtml типа Comment.
+// Возвращает текст комментария, отрисованный в очищенный HTML (см. markdown.Renderer); результат кэшируется.
+func (r *commentResolver) ContentHTML(ctx context.Context, obj *model.Comment) (string, error) {
+	return r.Markdown.Render(ctx, obj.Content)
+}
+
+// PostID - resolver для поля postId типа Comment.
+// Возвращает глобальный ID поста, к которому относится комментарий.
+func (r *commentResolver) PostID(ctx context.Context, obj *model.Comment) (string, error) {
+	return globalID(nodePost, obj.PostID), nil
+}
+
 // Post - resolver для поля post типа Comment.
 // Отвечает за получение поста, к которому относится комментарий.
 func (r *commentResolver) Post(ctx context.Context, obj *model.Comment) (*model.Post, error) {
 	post, err := r.Resolver.PostStore.GetPostByID(ctx, obj.PostID)
+	if err == nil {
+		err = checkVisible(ctx, "post", post.ID, post.DeletedAt) // Удаленный пост виден только модераторам.
+	}
 	if err != nil {
 		// В случае ошибки получения поста возвращаем ошибку с указанием ID поста.
-		return nil, fmt.Errorf("post with id %s not found: %w", obj.PostID, err)
+		return nil, storeError(ctx, "error getting post of comment", err)
 	}
 	return post, nil
 }
 
+// ParentID - resolver для поля parentId типа Comment.
+// Возвращает глобальный ID родительского комментария или null для корневого комментария.
+func (r *commentResolver) ParentID(ctx context.Context, obj *model.Comment) (*string, error) {
+	return optionalGlobalID(nodeComment, obj.ParentID), nil
+}
+
 // Replies - resolver для поля replies типа Comment.
 // Обеспечивает получение ответов на комментарий с пагинацией.
 func (r *commentResolver) Replies(ctx context.Context, obj *model.Comment, first *int32, after *string) (*model.CommentConnection, error) {
@@ -35,7 +61,14 @@ func (r *commentResolver) Replies(ctx context.Context, obj *model.Comment, first
 	}
 	result, err := r.Resolver.CommentStore.GetRepliesForComment(ctx, obj.ID, n, after)
 	if err != nil {
-		return nil, err // Возвращаем ошибку, если не удалось получить ответы.
+		return nil, storeError(ctx, "error getting replies", err) // Возвращаем ошибку, если не удалось получить ответы.
+	}
+
+	var totalCount int
+	if fieldRequested(ctx, "totalCount") { // Ответы считаются, только если клиент запросил totalCount.
+		if totalCount, err = r.Resolver.CommentStore.CountRepliesForComment(ctx, obj.ID); err != nil {
+			return nil, storeError(ctx, "error counting replies", err)
+		}
 	}
 
 	var edges []*model.CommentEdge
@@ -63,71 +96,359 @@ func (r *commentResolver) Replies(ctx context.Context, obj *model.Comment, first
 	}
 
 	return &model.CommentConnection{
-		Edges:    edges,
-		PageInfo: pageInfo,
+		Edges:      edges,
+		PageInfo:   pageInfo,
+		TotalCount: int32(totalCount),
 	}, nil
 }
 
+// Revisions - resolver для поля revisions типа Comment.
+// Возвращает прежние версии комментария; их текст видят только модераторы.
+func (r *commentResolver) Revisions(ctx context.Context, obj *model.Comment, first *int32, after *string) (*model.RevisionConnection, error) {
+	var n = int32(10) // Значение по умолчанию для количества возвращаемых версий.
+	if first != nil {
+		n = *first
+	}
+	result, err := r.Resolver.CommentStore.GetCommentRevisions(ctx, obj.ID, n, after)
+	if err != nil {
+		return nil, storeError(ctx, "error getting comment revisions", err)
+	}
+	return revisionConnection(ctx, result), nil
+}
+
 // CreatePost - resolver для мутации createPost.
 // Создает новый пост в системе, предварительно валидируя входные данные.
 func (r *mutationResolver) CreatePost(ctx context.Context, input model.CreatePostInput) (*model.Post, error) {
 	// Валидация входных данных для создания поста.
-	validationErrors := validator.ValidateCreatePostInput(ctx, input.Title, input.Author, input.Content)
-	if len(validationErrors) > 0 {
-		var errorMessages []string
-		for _, err := range validationErrors {
-			errorMessages = append(errorMessages, err.Error())
-		}
+	errs := validator.ValidateCreatePostInput(ctx, input.Title, input.Author, input.Content)
+	if errs = append(errs, validator.ValidateTags(ctx, input.Tags)...); len(errs) > 0 {
 		// Возвращаем ошибку, если валидация не пройдена, с перечислением ошибок.
-		return nil, fmt.Errorf("validation errors: %s", strings.Join(errorMessages, "; "))
+		return nil, inputError(ctx, "error validating post", errs)
 	}
 
 	post := &model.Post{
-		ID:            uuid.NewString(), // Генерация уникального ID для поста.
+		ID:            r.IDs.NewID(), // Генерация уникального ID для поста.
 		Author:        input.Author,
 		Title:         input.Title,
 		Content:       input.Content,
-		CreatedAt:     time.Now().Format(time.RFC3339), // Установка времени создания поста.
+		CreatedAt:     r.now(), // Установка времени создания поста.
 		AllowComments: input.AllowComments,
+		Tags:          tags.NormalizeAll(input.Tags), // Теги хранятся нормализованными.
 	}
 	err := r.Resolver.PostStore.AddPost(ctx, post)
 	if err != nil {
 		// Возвращаем ошибку, если не удалось создать пост в хранилище.
-		return nil, fmt.Errorf("error creating post: %w", err)
+		return nil, storeError(ctx, "error creating post", err)
 	}
 	return post, nil // Возвращаем созданный пост.
 }
 
 // CreateComment - resolver для мутации createComment.
 // Создает новый комментарий, включая валидацию входных данных и проверок связей (пост, родительский комментарий).
+// Проверка и вставка выполняются в одной транзакции, поэтому пост или родитель не могут исчезнуть между ними.
 func (r *mutationResolver) CreateComment(ctx context.Context, input model.CreateCommentInput) (*model.Comment, error) {
-	// Валидация входных данных для создания комментария.
-	validationErrors := validator.ValidateCreateCommentInput(r.PostStore, r.CommentStore, ctx, input.Author, input.Content, input.PostID, input.ParentID)
-	if len(validationErrors) > 0 {
-		var errorMessages []string
-		for _, err := range validationErrors {
-			errorMessages = append(errorMessages, err.Error())
-		}
-		// Возвращаем ошибку, если валидация не пройдена.
-		return nil, fmt.Errorf("validation errors: %s", strings.Join(errorMessages, "; "))
+	postID, err := localID(nodePost, input.PostID)
+	if err != nil {
+		return nil, err
+	}
+	parentID, err := optionalLocalID(nodeComment, input.ParentID)
+	if err != nil {
+		return nil, err
 	}
 
 	comment := &model.Comment{
-		ID:        uuid.NewString(), // Генерация уникального ID для комментария.
-		PostID:    input.PostID,
+		ID:        r.IDs.NewID(), // Генерация уникального ID для комментария.
+		PostID:    postID,
 		Author:    input.Author,
 		Content:   input.Content,
-		CreatedAt: time.Now().Format(time.RFC3339), // Установка времени создания комментария.
-		ParentID:  input.ParentID,
+		CreatedAt: r.now(), // Установка времени создания комментария.
+		ParentID:  parentID,
 	}
-	err := r.Resolver.CommentStore.AddComment(ctx, comment)
+
+	var notifications []*model.Notification // notifications - уведомления о комментарии, созданные в единице работы.
+	err = r.Resolver.UnitOfWork.Do(ctx, func(tx data.Tx) error {
+		// Валидация входных данных для создания комментария.
+		if errs := validator.ValidateCreateCommentInput(tx.Posts(), tx.Comments(), ctx, input.Author, input.Content, postID, parentID); len(errs) > 0 {
+			// Возвращаем ошибку, если валидация не пройдена или хранилище не смогло ее выполнить.
+			return inputError(ctx, "error validating comment", errs)
+		}
+		mentioned, err := commentMentions(ctx, tx, comment.Content)
+		if err != nil {
+			return err
+		}
+		comment.Mentions = mentioned
+		if err := tx.Comments().AddComment(ctx, comment); err != nil {
+			// Возвращаем ошибку, если не удалось создать комментарий.
+			return storeError(ctx, "error creating comment", err)
+		}
+		// Уведомления записываются в той же транзакции, что и комментарий.
+		created, err := r.Resolver.notifyComment(ctx, tx, comment)
+		notifications = created
+		return err
+	})
 	if err != nil {
-		// Возвращаем ошибку, если не удалось создать комментарий.
-		return nil, fmt.Errorf("error creating comment: %w", err)
+		return nil, txError(ctx, "error creating comment", err)
 	}
+	// Подписчики узнают о комментарии только после фиксации.
+	r.Resolver.publish(notifications)
 	return comment, nil // Возвращаем созданный комментарий.
 }
 
+// UpdatePost - resolver для мутации updatePost.
+// Править пост может его автор или модератор; прежняя версия сохраняется в истории правок.
+func (r *mutationResolver) UpdatePost(ctx context.Context, input model.UpdatePostInput) (*model.Post, error) {
+	errs := validator.ValidateUpdatePostInput(ctx, input.Author, input.Title, input.Content)
+	if errs = append(errs, validator.ValidateTags(ctx, input.Tags)...); len(errs) > 0 {
+		return nil, inputError(ctx, "error validating post", errs)
+	}
+
+	id, err := localID(nodePost, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Автор поста не меняется при правке, поэтому проверку прав можно выполнить до нее.
+	// Пост, удаленный между проверкой и правкой, хранилище не изменит и вернет ErrConflict.
+	post, err := r.Resolver.PostStore.GetPostByID(ctx, id)
+	if err != nil {
+		return nil, storeError(ctx, "error getting post", err)
+	}
+	if err := checkNotDeleted(ctx, "post", post.ID, post.DeletedAt); err != nil {
+		return nil, err
+	}
+	if err := checkAuthor(ctx, "post", post.ID, post.Author); err != nil {
+		return nil, err
+	}
+
+	update := data.PostUpdate{
+		Title:         input.Title,
+		Content:       input.Content,
+		AllowComments: input.AllowComments,
+		EditedAt:      r.now(), // Время правки.
+	}
+	if input.Tags != nil {
+		update.Tags = tags.NormalizeAll(input.Tags) // Переданный список, даже пустой, заменяет теги целиком.
+	}
+	post, err = r.Resolver.PostStore.UpdatePost(ctx, id, update)
+	if err != nil {
+		return nil, storeError(ctx, "error updating post", err)
+	}
+	return post, nil
+}
+
+// UpdateComment - resolver для мутации updateComment.
+// Править комментарий может его автор или модератор; прежняя версия сохраняется в истории правок.
+func (r *mutationResolver) UpdateComment(ctx context.Context, input model.UpdateCommentInput) (*model.Comment, error) {
+	if errs := validator.ValidateUpdateCommentInput(ctx, input.Author, input.Content); len(errs) > 0 {
+		return nil, inputError(ctx, "error validating comment", errs)
+	}
+	id, err := localID(nodeComment, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var comment *model.Comment
+	var notifications []*model.Notification // notifications - уведомления о новых упоминаниях, созданные в единице работы.
+	err = r.Resolver.UnitOfWork.Do(ctx, func(tx data.Tx) error {
+		old, err := tx.Comments().GetCommentByID(ctx, id)
+		if err != nil {
+			return storeError(ctx, "error getting comment", err)
+		}
+		if err := checkNotDeleted(ctx, "comment", old.ID, old.DeletedAt); err != nil {
+			return err
+		}
+		if err := checkAuthor(ctx, "comment", old.ID, old.Author); err != nil {
+			return err
+		}
+
+		mentioned, err := commentMentions(ctx, tx, input.Content)
+		if err != nil {
+			return err
+		}
+		editedAt := r.now()
+		if comment, err = tx.Comments().UpdateComment(ctx, id, input.Content, mentioned, editedAt); err != nil {
+			return storeError(ctx, "error updating comment", err)
+		}
+		notifications, err = r.Resolver.notifyCommentEdit(ctx, tx, old, comment, editedAt)
+		return err
+	})
+	if err != nil {
+		return nil, txError(ctx, "error updating comment", err)
+	}
+	r.Resolver.publish(notifications)
+	return comment, nil
+}
+
+// DeletePost - resolver для мутации deletePost.
+// Удалить пост может его автор или модератор; пост скрывается, но до окончательного удаления его можно восстановить.
+func (r *mutationResolver) DeletePost(ctx context.Context, input model.DeletePostInput) (*model.Post, error) {
+	if errs := validator.ValidateDeleteInput(ctx, input.Author); len(errs) > 0 {
+		return nil, inputError(ctx, "error validating post", errs)
+	}
+	id, err := localID(nodePost, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := r.Resolver.PostStore.GetPostByID(ctx, id)
+	if err != nil {
+		return nil, storeError(ctx, "error getting post", err)
+	}
+	if err := checkNotDeleted(ctx, "post", post.ID, post.DeletedAt); err != nil {
+		return nil, err
+	}
+	if err := checkAuthor(ctx, "post", post.ID, post.Author); err != nil {
+		return nil, err
+	}
+
+	post, err = r.Resolver.PostStore.DeletePost(ctx, id, actingAuthor(ctx, input.Author), r.now())
+	if err != nil {
+		return nil, storeError(ctx, "error deleting post", err)
+	}
+	return post, nil
+}
+
+// DeleteComment - resolver для мутации deleteComment.
+// Удалить комментарий может его автор или модератор; ответы на удаленный комментарий остаются видимыми.
+func (r *mutationResolver) DeleteComment(ctx context.Context, input model.DeleteCommentInput) (*model.Comment, error) {
+	if errs := validator.ValidateDeleteInput(ctx, input.Author); len(errs) > 0 {
+		return nil, inputError(ctx, "error validating comment", errs)
+	}
+	id, err := localID(nodeComment, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := r.Resolver.CommentStore.GetCommentByID(ctx, id)
+	if err != nil {
+		return nil, storeError(ctx, "error getting comment", err)
+	}
+	if err := checkNotDeleted(ctx, "comment", comment.ID, comment.DeletedAt); err != nil {
+		return nil, err
+	}
+	if err := checkAuthor(ctx, "comment", comment.ID, comment.Author); err != nil {
+		return nil, err
+	}
+
+	comment, err = r.Resolver.CommentStore.DeleteComment(ctx, id, actingAuthor(ctx, input.Author), r.now())
+	if err != nil {
+		return nil, storeError(ctx, "error deleting comment", err)
+	}
+	return comment, nil
+}
+
+// RestorePost - resolver для мутации restorePost.
+// Восстановить пост может только модератор и только в течение срока хранения удаленных записей.
+func (r *mutationResolver) RestorePost(ctx context.Context, id string) (*model.Post, error) {
+	id, err := localID(nodePost, id)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := r.Resolver.PostStore.GetPostByID(ctx, id)
+	if err != nil {
+		return nil, storeError(ctx, "error getting post", err)
+	}
+	if err := r.Resolver.checkRestorable(ctx, "post", post.ID, post.DeletedAt); err != nil {
+		return nil, err
+	}
+
+	post, err = r.Resolver.PostStore.RestorePost(ctx, id)
+	if err != nil {
+		return nil, storeError(ctx, "error restoring post", err)
+	}
+	return post, nil
+}
+
+// RestoreComment - resolver для мутации restoreComment.
+// Восстановить комментарий может только модератор и только в течение срока хранения удаленных записей.
+func (r *mutationResolver) RestoreComment(ctx context.Context, id string) (*model.Comment, error) {
+	id, err := localID(nodeComment, id)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := r.Resolver.CommentStore.GetCommentByID(ctx, id)
+	if err != nil {
+		return nil, storeError(ctx, "error getting comment", err)
+	}
+	if err := r.Resolver.checkRestorable(ctx, "comment", comment.ID, comment.DeletedAt); err != nil {
+		return nil, err
+	}
+
+	comment, err = r.Resolver.CommentStore.RestoreComment(ctx, id)
+	if err != nil {
+		return nil, storeError(ctx, "error restoring comment", err)
+	}
+	return comment, nil
+}
+
+// MarkNotificationsRead - resolver для мутации markNotificationsRead.
+// Отмечает прочитанными уведомления автора запроса с переданными ID (без ids - все) и возвращает число отмеченных.
+// Уведомления других получателей не меняются, даже если их ID переданы.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, ids []string) (int32, error) {
+	recipient, err := currentRecipient(ctx)
+	if err != nil {
+		return 0, err
+	}
+	localIDs, err := notificationIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	marked, err := r.Resolver.Notifications.MarkNotificationsRead(ctx, recipient, localIDs, r.now())
+	if err != nil {
+		return 0, storeError(ctx, "error marking notifications read", err)
+	}
+	return int32(marked), nil
+}
+
+// ID - resolver для поля id типа Notification.
+// Возвращает глобальный ID уведомления (см. globalID).
+func (r *notificationResolver) ID(ctx context.Context, obj *model.Notification) (string, error) {
+	return globalID(nodeNotification, obj.ID), nil
+}
+
+// PostID - resolver для поля postId типа Notification.
+// Возвращает глобальный ID поста.
+func (r *notificationResolver) PostID(ctx context.Context, obj *model.Notification) (string, error) {
+	return globalID(nodePost, obj.PostID), nil
+}
+
+// CommentID - resolver для поля commentId типа Notification.
+// Возвращает глобальный ID нового комментария.
+func (r *notificationResolver) CommentID(ctx context.Context, obj *model.Notification) (string, error) {
+	return globalID(nodeComment, obj.CommentID), nil
+}
+
+// Comment - resolver для поля comment типа Notification.
+// Возвращает новый комментарий или null, если он удален (модераторы видят и удаленный).
+func (r *notificationResolver) Comment(ctx context.Context, obj *model.Notification) (*model.Comment, error) {
+	comment, err := r.Resolver.CommentStore.GetCommentByID(ctx, obj.CommentID)
+	if err == nil {
+		err = checkVisible(ctx, "comment", comment.ID, comment.DeletedAt)
+	}
+	if errors.Is(err, data.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error getting comment of notification", err)
+	}
+	return comment, nil
+}
+
+// ID - resolver для поля id типа Post.
+// Возвращает глобальный ID поста (см. globalID).
+func (r *postResolver) ID(ctx context.Context, obj *model.Post) (string, error) {
+	return globalID(nodePost, obj.ID), nil
+}
+
+// ContentHTML - resolver для поля contentHtml типа Post.
+// Возвращает текст поста, отрисованный в очищенный HTML (см. markdown.Renderer); результат кэшируется.
+func (r *postResolver) ContentHTML(ctx context.Context, obj *model.Post) (string, error) {
+	return r.Markdown.Render(ctx, obj.Content)
+}
+
 // Comments - resolver для поля comments типа Post.
 // Позволяет получить комментарии к посту с поддержкой пагинации.
 func (r *postResolver) Comments(ctx context.Context, obj *model.Post, first *int32, after *string) (*model.CommentConnection, error) {
@@ -137,7 +458,14 @@ func (r *postResolver) Comments(ctx context.Context, obj *model.Post, first *int
 	}
 	result, err := r.Resolver.CommentStore.GetCommentsForPost(ctx, obj.ID, n, after)
 	if err != nil {
-		return nil, err // Возвращаем ошибку, если не удалось получить комментарии.
+		return nil, storeError(ctx, "error getting comments", err) // Возвращаем ошибку, если не удалось получить комментарии.
+	}
+
+	var totalCount int
+	if fieldRequested(ctx, "totalCount") { // Комментарии считаются, только если клиент запросил totalCount.
+		if totalCount, err = r.Resolver.CommentStore.CountCommentsForPost(ctx, obj.ID); err != nil {
+			return nil, storeError(ctx, "error counting comments", err)
+		}
 	}
 
 	var edges []*model.CommentEdge
@@ -165,34 +493,73 @@ func (r *postResolver) Comments(ctx context.Context, obj *model.Post, first *int
 	}
 
 	return &model.CommentConnection{
-		Edges:    edges,
-		PageInfo: pageInfo,
+		Edges:      edges,
+		PageInfo:   pageInfo,
+		TotalCount: int32(totalCount),
 	}, nil
 }
 
+// Revisions - resolver для поля revisions типа Post.
+// Возвращает прежние версии поста; их заголовок и текст видят только модераторы.
+func (r *postResolver) Revisions(ctx context.Context, obj *model.Post, first *int32, after *string) (*model.RevisionConnection, error) {
+	var n = int32(10) // Значение по умолчанию для количества возвращаемых версий.
+	if first != nil {
+		n = *first
+	}
+	result, err := r.Resolver.PostStore.GetPostRevisions(ctx, obj.ID, n, after)
+	if err != nil {
+		return nil, storeError(ctx, "error getting post revisions", err)
+	}
+	return revisionConnection(ctx, result), nil
+}
+
 // Post - resolver для query post.
 // Возвращает один пост по его ID.
 func (r *queryResolver) Post(ctx context.Context, id string) (*model.Post, error) {
+	id, err := localID(nodePost, id)
+	if err != nil {
+		return nil, err
+	}
+
 	post, err := r.Resolver.PostStore.GetPostByID(ctx, id)
+	if err == nil {
+		err = checkVisible(ctx, "post", post.ID, post.DeletedAt) // Удаленный пост виден только модераторам.
+	}
 	if err != nil {
 		// В случае ошибки получения поста, возвращаем ошибку.
-		return nil, fmt.Errorf("get post by id: %w", err)
+		return nil, storeError(ctx, "get post by id", err)
 	}
 	return post, nil // Возвращаем найденный пост.
 }
 
 // Posts - resolver для query posts.
 // Возвращает список постов с поддержкой пагинации.
-func (r *queryResolver) Posts(ctx context.Context, first *int32, after *string) (*model.PostConnection, error) {
+func (r *queryResolver) Posts(ctx context.Context, first *int32, after *string, filter *model.PostFilter) (*model.PostConnection, error) {
 	var firstValue int32 = 10 // Значение по умолчанию для количества возвращаемых постов.
 	if first != nil {
 		firstValue = int32(*first) // Если в запросе указано 'first', используем его значение.
 	}
 
-	result, err := r.Resolver.PostStore.GetPosts(ctx, firstValue, after)
+	var postFilter model.PostFilter // Без фильтра выдаются все посты.
+	if filter != nil {
+		postFilter = *filter
+	}
+	if postFilter.Tag != nil {
+		tag := tags.Normalize(*postFilter.Tag) // Тег сравнивается с нормализованными тегами постов.
+		postFilter.Tag = &tag
+	}
+
+	result, err := r.Resolver.PostStore.GetPosts(ctx, firstValue, after, postFilter)
 	if err != nil {
 		// Возвращаем ошибку, если не удалось получить посты.
-		return nil, fmt.Errorf("get posts: %w", err)
+		return nil, storeError(ctx, "get posts", err)
+	}
+
+	var totalCount int
+	if fieldRequested(ctx, "totalCount") { // Посты считаются, только если клиент запросил totalCount.
+		if totalCount, err = r.Resolver.PostStore.CountPosts(ctx, postFilter); err != nil {
+			return nil, storeError(ctx, "count posts", err)
+		}
 	}
 
 	postEdges := make([]*model.PostEdge, len(result.Edges))
@@ -224,35 +591,157 @@ func (r *queryResolver) Posts(ctx context.Context, first *int32, after *string)
 	}
 
 	return &model.PostConnection{
-		Edges:    postEdges,
-		PageInfo: pageInfo,
+		Edges:      postEdges,
+		PageInfo:   pageInfo,
+		TotalCount: int32(totalCount),
 	}, nil
 }
 
 // Comment - resolver для query comment.
 // Возвращает один комментарий по его ID.
 func (r *queryResolver) Comment(ctx context.Context, id string) (*model.Comment, error) {
+	id, err := localID(nodeComment, id)
+	if err != nil {
+		return nil, err
+	}
+
 	comment, err := r.Resolver.CommentStore.GetCommentByID(ctx, id)
+	if err == nil {
+		err = checkVisible(ctx, "comment", comment.ID, comment.DeletedAt) // Удаленный комментарий виден только модераторам.
+	}
 	if err != nil {
 		// Возвращаем ошибку, если не удалось получить комментарий.
-		return nil, fmt.Errorf("get comment by id: %w", err)
+		return nil, storeError(ctx, "get comment by id", err)
 	}
 	return comment, nil // Возвращаем найденный комментарий.
 }
 
+// Search - resolver для query search.
+// Возвращает посты и комментарии, содержащие все значимые слова запроса, с подсвеченными фрагментами.
+func (r *queryResolver) Search(ctx context.Context, query string, typeArg []model.SearchType, first *int32, after *string) (*model.SearchConnection, error) {
+	if errs := validator.ValidateSearchInput(ctx, query); len(errs) > 0 {
+		return nil, inputError(ctx, "error validating search query", errs)
+	}
+
+	var firstValue int32 = 10 // Значение по умолчанию для количества результатов.
+	if first != nil {
+		firstValue = *first
+	}
+
+	result, err := r.Resolver.Searcher.Search(ctx, query, typeArg, firstValue, after)
+	if err != nil {
+		return nil, storeError(ctx, "search", err)
+	}
+
+	// Предыдущая страница есть, если указан курсор 'after'.
+	result.PageInfo.HasPreviousPage = after != nil
+	if len(result.Edges) > 0 {
+		result.PageInfo.StartCursor = &result.Edges[0].Cursor
+		result.PageInfo.EndCursor = &result.Edges[len(result.Edges)-1].Cursor
+	}
+	return result, nil
+}
+
+// Tags - resolver для query tags.
+// Возвращает теги неудаленных постов с числом постов, от самых популярных.
+func (r *queryResolver) Tags(ctx context.Context) ([]*model.Tag, error) {
+	result, err := r.Resolver.PostStore.GetTags(ctx)
+	if err != nil {
+		return nil, storeError(ctx, "get tags", err)
+	}
+	return result, nil
+}
+
+// Tag - resolver для query tag.
+// Возвращает посты с тегом так же, как posts с фильтром по тегу.
+func (r *queryResolver) Tag(ctx context.Context, name string, first *int32, after *string) (*model.PostConnection, error) {
+	if errs := validator.ValidateTags(ctx, []string{name}); len(errs) > 0 {
+		return nil, inputError(ctx, "error validating tag", errs)
+	}
+	return r.Posts(ctx, first, after, &model.PostFilter{Tag: &name})
+}
+
+// Node - resolver для query node.
+// Возвращает запись любого типа по глобальному ID или null, если записи нет.
+func (r *queryResolver) Node(ctx context.Context, id string) (model.Node, error) {
+	return r.Resolver.fetchNode(ctx, id)
+}
+
+// Nodes - resolver для query nodes.
+// Возвращает записи по списку глобальных ID в том же порядке; на месте отсутствующих - null.
+func (r *queryResolver) Nodes(ctx context.Context, ids []string) ([]model.Node, error) {
+	if len(ids) > maxNodes {
+		return nil, fmt.Errorf("too many IDs: %d (at most %d): %w", len(ids), maxNodes, data.ErrInvalidInput)
+	}
+	nodes := make([]model.Node, len(ids))
+	for i, id := range ids {
+		node, err := r.Resolver.fetchNode(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = node
+	}
+	return nodes, nil
+}
+
+// Notifications - resolver для query notifications.
+// Возвращает уведомления автора запроса от новых к старым с пагинацией.
+func (r *queryResolver) Notifications(ctx context.Context, first *int32, after *string, unreadOnly *bool) (*model.NotificationConnection, error) {
+	recipient, err := currentRecipient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var firstValue int32 = 10 // Значение по умолчанию для количества уведомлений.
+	if first != nil {
+		firstValue = *first
+	}
+
+	result, err := r.Resolver.Notifications.GetNotifications(ctx, recipient, firstValue, after, unreadOnly != nil && *unreadOnly)
+	if err != nil {
+		return nil, storeError(ctx, "get notifications", err)
+	}
+
+	// Предыдущая страница есть, если указан курсор 'after'.
+	result.PageInfo.HasPreviousPage = after != nil
+	if len(result.Edges) > 0 {
+		result.PageInfo.StartCursor = &result.Edges[0].Cursor
+		result.PageInfo.EndCursor = &result.Edges[len(result.Edges)-1].Cursor
+	}
+	return result, nil
+}
+
+// NotificationAdded - resolver для подписки notificationAdded.
+// Передает клиенту уведомления автора запроса, созданные после подписки, пока клиент не отключится.
+func (r *subscriptionResolver) NotificationAdded(ctx context.Context) (<-chan *model.Notification, error) {
+	recipient, err := currentRecipient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Resolver.Subscribers.Subscribe(ctx, recipient), nil
+}
+
 // Comment returns CommentResolver implementation.
 func (r *Resolver) Comment() CommentResolver { return &commentResolver{r} }
 
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// Notification returns NotificationResolver implementation.
+func (r *Resolver) Notification() NotificationResolver { return &notificationResolver{r} }
+
 // Post returns PostResolver implementation.
 func (r *Resolver) Post() PostResolver { return &postResolver{r} }
 
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
 type commentResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type notificationResolver struct{ *Resolver }
 type postResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
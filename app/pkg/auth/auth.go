@@ -0,0 +1,143 @@
+// Package auth - минимальная модель доступа: обычные клиенты, авторы и модераторы.
+// Модератор предъявляет один из настроенных токенов в заголовке Authorization (Bearer), автор - свой токен,
+// который связывает запрос с его именем (как в поле author); отдельной системы пользователей в сервисе нет.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// bearerPrefix - схема авторизации в заголовке Authorization.
+const bearerPrefix = "Bearer "
+
+// moderatorKey - ключ признака модератора в контексте.
+type moderatorKey struct{}
+
+// WithModerator - функция, возвращающая контекст запроса модератора.
+func WithModerator(ctx context.Context) context.Context {
+	return context.WithValue(ctx, moderatorKey{}, true)
+}
+
+// IsModerator - функция, сообщающая, что запрос выполняет модератор.
+func IsModerator(ctx context.Context) bool {
+	moderator, _ := ctx.Value(moderatorKey{}).(bool)
+	return moderator
+}
+
+// authorKey - ключ имени автора в контексте.
+type authorKey struct{}
+
+// WithAuthor - функция, возвращающая контекст запроса автора name.
+func WithAuthor(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, authorKey{}, name)
+}
+
+// Author - функция получения имени автора, от имени которого выполняется запрос.
+// Второе значение false, если запрос анонимный.
+func Author(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(authorKey{}).(string)
+	return name, ok && name != ""
+}
+
+// Moderators - набор токенов модераторов.
+// Хранятся только хеши токенов, а сравнение выполняется за постоянное время.
+type Moderators struct {
+	hashes [][sha256.Size]byte
+}
+
+// NewModerators - функция-конструктор набора токенов модераторов; пустые токены пропускаются.
+func NewModerators(tokens []string) *Moderators {
+	m := &Moderators{}
+	for _, token := range tokens {
+		if token != "" {
+			m.hashes = append(m.hashes, sha256.Sum256([]byte(token)))
+		}
+	}
+	return m
+}
+
+// Valid - метод проверки токена модератора.
+func (m *Moderators) Valid(token string) bool {
+	if token == "" {
+		return false
+	}
+	hash := sha256.Sum256([]byte(token))
+	valid := 0
+	for _, known := range m.hashes {
+		valid |= subtle.ConstantTimeCompare(hash[:], known[:])
+	}
+	return valid == 1
+}
+
+// Middleware - HTTP middleware, отмечающее в контексте запросы с токеном модератора.
+// Запрос без токена или с неизвестным токеном обрабатывается как запрос обычного клиента.
+func (m *Moderators) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if token, ok := strings.CutPrefix(header, bearerPrefix); ok && m.Valid(token) {
+			r = r.WithContext(WithModerator(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Authors - набор токенов авторов: каждый токен связан с именем автора.
+// Как и у Moderators, хранятся только хеши токенов, а поиск сравнивает токен со всеми за постоянное время.
+type Authors struct {
+	tokens []authorToken
+}
+
+// authorToken - хеш токена автора и имя, которое он подтверждает.
+type authorToken struct {
+	hash [sha256.Size]byte
+	name string
+}
+
+// NewAuthors - функция-конструктор набора токенов авторов по соответствию "токен - имя автора";
+// пустые токены и имена пропускаются.
+func NewAuthors(tokens map[string]string) *Authors {
+	a := &Authors{}
+	for token, name := range tokens {
+		if token != "" && name != "" {
+			a.tokens = append(a.tokens, authorToken{hash: sha256.Sum256([]byte(token)), name: name})
+		}
+	}
+	return a
+}
+
+// Lookup - метод поиска имени автора по токену. Второе значение false, если токен неизвестен.
+func (a *Authors) Lookup(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	hash := sha256.Sum256([]byte(token))
+	found := -1
+	for i, known := range a.tokens {
+		if subtle.ConstantTimeCompare(hash[:], known.hash[:]) == 1 {
+			found = i
+		}
+	}
+	if found < 0 {
+		return "", false
+	}
+	return a.tokens[found].name, true
+}
+
+// Middleware - HTTP middleware, записывающее в контекст имя автора, чей токен передан в заголовке Authorization.
+// Запрос без токена или с неизвестным токеном остается анонимным. Для подписок по WebSocket токен передается
+// в заголовке запроса на установку соединения.
+func (a *Authors) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if token, ok := strings.CutPrefix(header, bearerPrefix); ok {
+			if name, ok := a.Lookup(token); ok {
+				r = r.WithContext(WithAuthor(r.Context(), name))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	moderators := NewModerators([]string{"", "secret"})
+
+	for _, tc := range []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"Bearer secret", true},
+		{"Bearer other", false},
+		{"Bearer ", false},
+		{"secret", false},
+	} {
+		var got bool
+		handler := moderators.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = IsModerator(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/query", nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if got != tc.want {
+			t.Errorf("Authorization %q: expected moderator %v, got %v", tc.header, tc.want, got)
+		}
+	}
+}
+
+func TestAuthorsMiddleware(t *testing.T) {
+	// TestAuthorsMiddleware проверяет, что токен автора связывает запрос с его именем, а остальные запросы анонимны.
+	authors := NewAuthors(map[string]string{"anna-token": "Анна", "boris-token": "Борис", "": "Никто", "empty-name": ""})
+	for _, tc := range []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"Bearer anna-token", "Анна"},
+		{"Bearer boris-token", "Борис"},
+		{"Bearer other", ""},
+		{"Bearer ", ""},
+		{"Bearer empty-name", ""},
+		{"anna-token", ""},
+	} {
+		var got string
+		handler := authors.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, _ = Author(r.Context())
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/query", nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if got != tc.want {
+			t.Errorf("Authorization %q: expected author %q, got %q", tc.header, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package clock - источник текущего времени для кода, которому нужно "сейчас".
+//
+// Вместо прямого вызова time.Now() зависимость передается как Clock: в приложении это System,
+// а в тестах и демонстрационных данных - Fixed, чтобы даты были воспроизводимыми.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock - интерфейс источника текущего времени.
+type Clock interface {
+	Now() time.Time
+}
+
+// System - часы операционной системы.
+type System struct{}
+
+// Now - метод, возвращающий текущее время системы.
+func (System) Now() time.Time {
+	return time.Now()
+}
+
+// Fixed - часы, которые стоят на месте, пока их не переведут явно. Безопасны для конкурентного использования.
+type Fixed struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFixed - конструктор часов, показывающих время now.
+func NewFixed(now time.Time) *Fixed {
+	return &Fixed{now: now}
+}
+
+// Now - метод, возвращающий установленное время.
+func (f *Fixed) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Set - метод установки времени.
+func (f *Fixed) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Advance - метод, переводящий часы вперед на d (назад при отрицательном d).
+func (f *Fixed) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
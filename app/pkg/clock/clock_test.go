@@ -0,0 +1,39 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSystem(t *testing.T) {
+	// TestSystem проверяет, что системные часы показывают текущее время.
+	before := time.Now()
+	now := System{}.Now()
+	if now.Before(before) || now.After(time.Now()) {
+		t.Errorf("System clock returned %v outside of the call window", now)
+	}
+}
+
+func TestFixed(t *testing.T) {
+	// TestFixed проверяет, что фиксированные часы меняются только явным переводом.
+	start := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	c := NewFixed(start)
+
+	if got := c.Now(); !got.Equal(start) {
+		t.Fatalf("Expected %v, got %v", start, got)
+	}
+	if got := c.Now(); !got.Equal(start) {
+		t.Errorf("Expected fixed clock to stand still, got %v", got)
+	}
+
+	c.Advance(90 * time.Second)
+	if want := start.Add(90 * time.Second); !c.Now().Equal(want) {
+		t.Errorf("Expected %v after Advance, got %v", want, c.Now())
+	}
+
+	later := start.Add(24 * time.Hour)
+	c.Set(later)
+	if !c.Now().Equal(later) {
+		t.Errorf("Expected %v after Set, got %v", later, c.Now())
+	}
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// load - вспомогательная функция загрузки конфигурации с заданными переменными окружения.
+func load(t *testing.T, env map[string]string, args ...string) (Config, error) {
+	t.Helper()
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return Load(flags, args, func(key string) string { return env[key] })
+}
+
+// writeFile - вспомогательная функция создания файла конфигурации во временной директории.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	// TestLoadDefaults проверяет, что без источников используется конфигурация по умолчанию.
+	cfg, err := load(t, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("Expected default config, got %+v", cfg)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	// TestLoadPrecedence проверяет порядок источников: файл, затем окружение, затем флаги.
+	path := writeFile(t, "config.yaml", `
+server:
+  port: 8080
+  shutdown_timeout: 5s
+storage:
+  type: postgres
+database:
+  host: db
+  user: app
+  name: comments
+logging:
+  level: debug
+`)
+
+	env := map[string]string{FileEnv: path, "PORT": "9090", "LOG_LEVEL": "warn"}
+	cfg, err := load(t, env, "-logging.level", "error")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.Server.Port != 9090 {
+		t.Errorf("Expected env to override file port, got %d", cfg.Server.Port)
+	}
+	if time.Duration(cfg.Server.ShutdownTimeout) != 5*time.Second {
+		t.Errorf("Expected shutdown timeout from file, got %s", cfg.Server.ShutdownTimeout)
+	}
+	if cfg.Logging.Level != "error" {
+		t.Errorf("Expected flag to override env log level, got %q", cfg.Logging.Level)
+	}
+	if cfg.Database.Host != "db" || cfg.Database.Port != 5432 {
+		t.Errorf("Expected host from file and default port, got %s:%d", cfg.Database.Host, cfg.Database.Port)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	// TestLoadTOML проверяет чтение файла TOML с DSN базы данных.
+	path := writeFile(t, "config.toml", `
+[storage]
+type = "postgres"
+
+[database]
+url = "postgres://app:secret@db:5432/comments"
+
+[server]
+idle_timeout = "1m"
+`)
+
+	cfg, err := load(t, nil, "-config", path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Database.URL != "postgres://app:secret@db:5432/comments" {
+		t.Errorf("Unexpected database url %q", cfg.Database.URL)
+	}
+	if time.Duration(cfg.Server.IdleTimeout) != time.Minute {
+		t.Errorf("Expected idle timeout 1m, got %s", cfg.Server.IdleTimeout)
+	}
+}
+
+func TestLoadUnknownKey(t *testing.T) {
+	// TestLoadUnknownKey проверяет, что опечатка в ключе файла приводит к ошибке.
+	for name, content := range map[string]string{
+		"config.yaml": "storage:\n  typ: postgres\n",
+		"config.toml": "[storage]\ntyp = \"postgres\"\n",
+	} {
+		if _, err := load(t, nil, "-config", writeFile(t, name, content)); err == nil || !strings.Contains(err.Error(), "typ") {
+			t.Errorf("%s: expected unknown key error, got %v", name, err)
+		}
+	}
+}
+
+func TestLoadReportsAllErrors(t *testing.T) {
+	// TestLoadReportsAllErrors проверяет, что все ошибки окружения и проверки возвращаются вместе.
+	_, err := load(t, map[string]string{"STORAGE_TYPE": "mongo", "DB_PORT": "abc", "TRACING_SAMPLE_RATIO": "2"})
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	for _, want := range []string{`database.port (env DB_PORT): invalid integer "abc"`, `storage.type: unknown storage type "mongo"`, "tracing.sample_ratio"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestValidatePostgres(t *testing.T) {
+	// TestValidatePostgres проверяет обязательные параметры подключения для PostgreSQL.
+	cfg := Default()
+	cfg.Storage.Type = StoragePostgres
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "database.user") {
+		t.Errorf("Expected missing database.user error, got %v", err)
+	}
+
+	cfg.Database.URL = "mysql://db/comments"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "unsupported scheme") {
+		t.Errorf("Expected unsupported scheme error, got %v", err)
+	}
+
+	cfg.Database.URL = "postgres://app@db/comments"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestValidateSQLite(t *testing.T) {
+	// TestValidateSQLite проверяет, что для SQLite нужен только путь к файлу базы.
+	cfg := Default()
+	cfg.Storage.Type = StorageSQLite
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	cfg.Storage.SQLitePath = ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "storage.sqlite_path") {
+		t.Errorf("Expected missing storage.sqlite_path error, got %v", err)
+	}
+}
+
+func TestValidateIDFormat(t *testing.T) {
+	// TestValidateIDFormat проверяет, что допускаются только известные форматы ID.
+	cfg := Default()
+	for _, format := range []string{"uuidv4", "uuidv7"} {
+		cfg.Storage.IDFormat = format
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Unexpected error for %s: %v", format, err)
+		}
+	}
+
+	cfg.Storage.IDFormat = "ulid"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "storage.id_format") {
+		t.Errorf("Expected unknown storage.id_format error, got %v", err)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	// TestRedacted проверяет, что пароли скрыты и в отдельном поле, и в URL, а также токены модераторов и авторов.
+	cfg := Default()
+	cfg.Database.Password = "secret"
+	cfg.Database.URL = "postgres://app:secret@db:5432/comments?password=secret"
+	cfg.Moderation.Tokens = []string{"secret-token"}
+	cfg.Authors.Tokens = []string{"Анна:secret-author-token"}
+
+	raw, err := Marshal(cfg.Redacted(), "yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(string(raw), "secret") {
+		t.Errorf("Expected secrets to be redacted, got:\n%s", raw)
+	}
+	if !strings.Contains(string(raw), "Анна:"+redactedValue) {
+		t.Errorf("Expected the author name to be kept, got:\n%s", raw)
+	}
+	if cfg.Database.Password != "secret" {
+		t.Error("Expected Redacted to leave the original config untouched")
+	}
+}
+
+func TestLoadModeratorTokens(t *testing.T) {
+	// TestLoadModeratorTokens проверяет разбор списка токенов модераторов из переменной окружения.
+	cfg, err := load(t, map[string]string{"MODERATOR_TOKENS": " first, ,second "})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := strings.Join(cfg.Moderation.Tokens, "|"); got != "first|second" {
+		t.Errorf("Expected tokens first|second, got %q", got)
+	}
+}
+
+func TestLoadAuthorTokens(t *testing.T) {
+	// TestLoadAuthorTokens проверяет разбор токенов авторов и ошибку для записи без имени или токена.
+	cfg, err := load(t, map[string]string{"AUTHOR_TOKENS": "Анна:first, re:max:second"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := cfg.Authors.TokenMap(); len(got) != 2 || got["first"] != "Анна" || got["second"] != "re:max" {
+		t.Errorf("Unexpected author tokens %v", got)
+	}
+
+	_, err = load(t, map[string]string{"AUTHOR_TOKENS": "Анна:first,secret-without-name"})
+	if err == nil || !strings.Contains(err.Error(), "authors.tokens: entry 2") || strings.Contains(err.Error(), "secret-without-name") {
+		t.Errorf("Expected an authors.tokens error without the token, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv - переменная окружения с путем к файлу конфигурации (если не задан флаг -config).
+const FileEnv = "CONFIG_FILE"
+
+// option - описание одного параметра конфигурации: ключ в файле (он же имя флага)
+// и переменная окружения, переопределяющая значение из файла.
+type option struct {
+	key   string            // key - путь к параметру, например server.port; используется как имя флага.
+	env   string            // env - имя переменной окружения.
+	usage string            // usage - описание для справки по флагам.
+	field func(*Config) any // field - указатель на поле конфигурации.
+}
+
+// options - все параметры, которые можно задать через окружение и флаги.
+// Имена переменных окружения совпадают с теми, что использовались до появления файла конфигурации.
+var options = []option{
+	{"server.port", "PORT", "HTTP port to listen on", func(c *Config) any { return &c.Server.Port }},
+	{"server.read_timeout", "SERVER_READ_TIMEOUT", "max time to read a request", func(c *Config) any { return &c.Server.ReadTimeout }},
+	{"server.read_header_timeout", "SERVER_READ_HEADER_TIMEOUT", "max time to read request headers", func(c *Config) any { return &c.Server.ReadHeaderTimeout }},
+	{"server.write_timeout", "SERVER_WRITE_TIMEOUT", "max time to write a response", func(c *Config) any { return &c.Server.WriteTimeout }},
+	{"server.idle_timeout", "SERVER_IDLE_TIMEOUT", "keep-alive idle timeout", func(c *Config) any { return &c.Server.IdleTimeout }},
+	{"server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT", "time to finish requests on shutdown", func(c *Config) any { return &c.Server.ShutdownTimeout }},
+	{"server.drain_delay", "SERVER_DRAIN_DELAY", "delay between failing readiness and closing the listener", func(c *Config) any { return &c.Server.DrainDelay }},
+	{"storage.type", "STORAGE_TYPE", "storage backend: inmemory, postgres or sqlite", func(c *Config) any { return &c.Storage.Type }},
+	{"storage.data_dir", "DATA_DIR", "directory for the in-memory journal and snapshots (empty disables persistence)", func(c *Config) any { return &c.Storage.DataDir }},
+	{"storage.snapshot_interval", "SNAPSHOT_INTERVAL", "how often the in-memory store writes a snapshot", func(c *Config) any { return &c.Storage.SnapshotInterval }},
+	{"storage.seed_fixtures", "SEED_FIXTURES", "load demo posts and comments into an empty in-memory store", func(c *Config) any { return &c.Storage.SeedFixtures }},
+	{"storage.sqlite_path", "SQLITE_PATH", "path to the SQLite database file", func(c *Config) any { return &c.Storage.SQLitePath }},
+	{"storage.id_format", "ID_FORMAT", "ID format for new posts and comments: uuidv4 or uuidv7 (time-ordered)", func(c *Config) any { return &c.Storage.IDFormat }},
+	{"database.url", "DATABASE_URL", "PostgreSQL connection URL (overrides discrete fields)", func(c *Config) any { return &c.Database.URL }},
+	{"database.host", "DB_HOST", "PostgreSQL host", func(c *Config) any { return &c.Database.Host }},
+	{"database.port", "DB_PORT", "PostgreSQL port", func(c *Config) any { return &c.Database.Port }},
+	{"database.user", "DB_USER", "PostgreSQL user", func(c *Config) any { return &c.Database.User }},
+	{"database.password", "DB_PASSWORD", "PostgreSQL password", func(c *Config) any { return &c.Database.Password }},
+	{"database.name", "DB_NAME", "PostgreSQL database name", func(c *Config) any { return &c.Database.Name }},
+	{"database.sslmode", "DB_SSLMODE", "PostgreSQL sslmode", func(c *Config) any { return &c.Database.SSLMode }},
+	{"logging.level", "LOG_LEVEL", "log level: debug, info, warn or error", func(c *Config) any { return &c.Logging.Level }},
+	{"logging.format", "LOG_FORMAT", "log format: text or json", func(c *Config) any { return &c.Logging.Format }},
+	{"tracing.exporter", "TRACING_EXPORTER", "trace exporter: none, otlp, stdout or file", func(c *Config) any { return &c.Tracing.Exporter }},
+	{"tracing.file", "TRACING_FILE", "trace file for the file exporter", func(c *Config) any { return &c.Tracing.File }},
+	{"tracing.sample_ratio", "TRACING_SAMPLE_RATIO", "fraction of traces to sample, 0..1", func(c *Config) any { return &c.Tracing.SampleRatio }},
+	{"persisted_queries.manifest", "PERSISTED_QUERIES_MANIFEST", "persisted queries manifest; enables allowlist mode", func(c *Config) any { return &c.PersistedQueries.Manifest }},
+	{"moderation.tokens", "MODERATOR_TOKENS", "comma-separated moderator tokens", func(c *Config) any { return &c.Moderation.Tokens }},
+	{"moderation.retention", "DELETED_RETENTION", "how long deleted posts and comments can be restored", func(c *Config) any { return &c.Moderation.Retention }},
+	{"moderation.purge_interval", "PURGE_INTERVAL", "how often deleted posts and comments past retention are purged", func(c *Config) any { return &c.Moderation.PurgeInterval }},
+	{"authors.tokens", "AUTHOR_TOKENS", "comma-separated author tokens as name:token", func(c *Config) any { return &c.Authors.Tokens }},
+}
+
+// flagValue - значение флага командной строки, запомненное до применения.
+type flagValue struct {
+	option option
+	raw    string
+}
+
+// Load - функция загрузки конфигурации. Флаги параметров регистрируются в переданном FlagSet
+// (вызывающий код может заранее добавить в него свои флаги) и разбираются из args.
+// Порядок применения: значения по умолчанию, файл (-config или CONFIG_FILE), переменные окружения, флаги.
+// Ошибки разбора и проверки содержат имя параметра и источник значения.
+func Load(flags *flag.FlagSet, args []string, getenv func(string) string) (Config, error) {
+	configFile := flags.String("config", "", "path to a YAML or TOML config file (env "+FileEnv+")")
+
+	var fromFlags []flagValue
+	for _, opt := range options {
+		flags.Func(opt.key, fmt.Sprintf("%s (env %s)", opt.usage, opt.env), func(raw string) error {
+			fromFlags = append(fromFlags, flagValue{option: opt, raw: raw})
+			return nil
+		})
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+	if flags.NArg() > 0 {
+		return Config{}, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	config := Default()
+
+	path := *configFile
+	if path == "" {
+		path = getenv(FileEnv)
+	}
+	if path != "" {
+		if err := loadFile(path, &config); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var errs []error
+	for _, opt := range options {
+		if raw := getenv(opt.env); raw != "" {
+			if err := setValue(opt.field(&config), raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s (env %s): %w", opt.key, opt.env, err))
+			}
+		}
+	}
+	for _, value := range fromFlags {
+		if err := setValue(value.option.field(&config), value.raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s (flag -%s): %w", value.option.key, value.option.key, err))
+		}
+	}
+
+	// Проверка выполняется даже после ошибок разбора, чтобы сразу сообщить обо всех проблемах.
+	if err := config.Validate(); err != nil {
+		errs = append(errs, err.(interface{ Unwrap() []error }).Unwrap()...)
+	}
+	if len(errs) > 0 {
+		messages := make([]string, len(errs))
+		for i, err := range errs {
+			messages[i] = err.Error()
+		}
+		return Config{}, fmt.Errorf("invalid configuration: %s", strings.Join(messages, "; "))
+	}
+
+	return config, nil
+}
+
+// loadFile - вспомогательная функция чтения файла конфигурации. Формат определяется по расширению.
+// Неизвестные ключи считаются ошибкой, чтобы опечатки не проходили незамеченными.
+func loadFile(path string, config *Config) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(raw))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("error parsing config file %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(raw), config)
+		if err != nil {
+			return fmt.Errorf("error parsing config file %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, len(undecoded))
+			for i, key := range undecoded {
+				keys[i] = key.String()
+			}
+			return fmt.Errorf("error parsing config file %s: unknown keys: %s", path, strings.Join(keys, ", "))
+		}
+	default:
+		return fmt.Errorf("unsupported config file extension %q (expected .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+
+	return nil
+}
+
+// setValue - вспомогательная функция записи строкового значения в поле конфигурации нужного типа.
+func setValue(field any, raw string) error {
+	switch target := field.(type) {
+	case *string:
+		*target = raw
+	case *int:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		*target = value
+	case *float64:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		*target = value
+	case *bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		*target = value
+	case *Duration:
+		return target.UnmarshalText([]byte(raw))
+	case *[]string:
+		// Список задается через запятую; пустые элементы отбрасываются.
+		var values []string
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		*target = values
+	default:
+		return fmt.Errorf("unsupported field type %T", field)
+	}
+	return nil
+}
+
+// Marshal - функция вывода конфигурации в формате yaml или toml.
+func Marshal(config Config, format string) ([]byte, error) {
+	switch format {
+	case "yaml", "":
+		return yaml.Marshal(config)
+	case "toml":
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(config); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected yaml or toml)", format)
+	}
+}
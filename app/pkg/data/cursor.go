@@ -0,0 +1,175 @@
+package data
+
+import (
+	"encoding/base64"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cursorSeparator - разделитель даты создания и ID внутри курсора.
+const cursorSeparator = "|"
+
+// EncodeCursor - функция кодирования курсора keyset-пагинации.
+// Курсор содержит дату создания и ID записи, по которым хранилища продолжают выборку
+// без OFFSET. Для клиента курсор непрозрачен: это base64url-строка.
+func EncodeCursor(createdAt time.Time, id string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + cursorSeparator + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor - функция разбора курсора, созданного EncodeCursor.
+// Возвращает дату создания и ID записи, после которой продолжается выборка,
+// или ошибку, оборачивающую ErrInvalidCursor.
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), cursorSeparator)
+	if !ok || id == "" {
+		return time.Time{}, "", fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+
+	return t, id, nil
+}
+
+// revisionCursorPrefix - префикс курсора ревизий, отличающий его от курсоров постов и комментариев.
+const revisionCursorPrefix = "revision" + cursorSeparator
+
+// EncodeRevisionCursor - функция кодирования курсора по списку ревизий: номера ревизии достаточно,
+// потому что номера внутри поста или комментария уникальны и возрастают.
+func EncodeRevisionCursor(number int32) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(revisionCursorPrefix + strconv.Itoa(int(number))))
+}
+
+// DecodeRevisionCursor - функция разбора курсора, созданного EncodeRevisionCursor.
+// Возвращает номер ревизии, после которой продолжается выборка, или ошибку, оборачивающую ErrInvalidCursor.
+func DecodeRevisionCursor(cursor string) (int32, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+
+	number, ok := strings.CutPrefix(string(raw), revisionCursorPrefix)
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+	n, err := strconv.ParseInt(number, 10, 32)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+
+	return int32(n), nil
+}
+
+// searchCursorPrefix - префикс курсора результатов поиска.
+const searchCursorPrefix = "search" + cursorSeparator
+
+// EncodeSearchCursor - функция кодирования курсора по результатам поиска. Результаты упорядочены
+// по релевантности, а не по ключу записи, поэтому курсор хранит номер позиции в выдаче.
+func EncodeSearchCursor(position int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(searchCursorPrefix + strconv.Itoa(position)))
+}
+
+// DecodeSearchCursor - функция разбора курсора, созданного EncodeSearchCursor.
+// Возвращает число результатов до курсора включительно, с которого продолжается выдача,
+// или ошибку, оборачивающую ErrInvalidCursor.
+func DecodeSearchCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+
+	position, ok := strings.CutPrefix(string(raw), searchCursorPrefix)
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+	n, err := strconv.ParseInt(position, 10, 32)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+
+	return int(n), nil
+}
+
+// SearchOffset - общая для хранилищ функция разбора курсора поиска: возвращает число результатов,
+// которые нужно пропустить (0 без курсора).
+func SearchOffset(after *string) (int, error) {
+	if after == nil || *after == "" {
+		return 0, nil
+	}
+	return DecodeSearchCursor(*after)
+}
+
+// SearchPage - общая для хранилищ функция построения страницы результатов поиска: edges - результаты,
+// идущие после offset пропущенных, по убыванию релевантности; в страницу попадают первые first,
+// а оставшиеся означают, что есть следующая страница. Курсоры назначаются по позиции в выдаче.
+// `first` равное 0 возвращает пустую страницу.
+func SearchPage(edges []*model.SearchEdge, offset int, first int32) *model.SearchConnection {
+	if first <= 0 {
+		return &model.SearchConnection{Edges: []*model.SearchEdge{}, PageInfo: &model.PageInfo{}}
+	}
+
+	hasNextPage := len(edges) > int(first)
+	if hasNextPage {
+		edges = edges[:first]
+	}
+	if edges == nil {
+		edges = []*model.SearchEdge{}
+	}
+	for i, edge := range edges {
+		edge.Cursor = EncodeSearchCursor(offset + i + 1)
+	}
+
+	return &model.SearchConnection{
+		Edges:    edges,
+		PageInfo: &model.PageInfo{HasNextPage: hasNextPage},
+	}
+}
+
+// SearchTypes - общая для хранилищ функция разбора фильтра типов поиска: пустой список означает все типы.
+func SearchTypes(types []model.SearchType) (posts, comments bool) {
+	if len(types) == 0 {
+		return true, true
+	}
+	for _, t := range types {
+		switch t {
+		case model.SearchTypePost:
+			posts = true
+		case model.SearchTypeComment:
+			comments = true
+		}
+	}
+	return posts, comments
+}
+
+// RevisionPage - общая для хранилищ функция построения страницы из ревизий, идущих после курсора
+// по возрастанию номера: в страницу попадают первые first, а оставшиеся означают, что есть следующая страница.
+// `first` равное 0 возвращает пустую страницу.
+func RevisionPage(revisions []*model.Revision, first int32) *model.RevisionConnection {
+	if first <= 0 {
+		return &model.RevisionConnection{Edges: []*model.RevisionEdge{}, PageInfo: &model.PageInfo{}}
+	}
+
+	edges := make([]*model.RevisionEdge, 0, len(revisions))
+	for _, revision := range revisions {
+		if int32(len(edges)) == first {
+			break
+		}
+		edges = append(edges, &model.RevisionEdge{Node: revision, Cursor: EncodeRevisionCursor(revision.Number)})
+	}
+
+	return &model.RevisionConnection{
+		Edges:    edges,
+		PageInfo: &model.PageInfo{HasNextPage: len(revisions) > len(edges)},
+	}
+}
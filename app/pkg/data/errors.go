@@ -0,0 +1,18 @@
+package data
+
+import "errors"
+
+// Ошибки хранилищ. Реализации PostStore и CommentStore оборачивают их (fmt.Errorf с %w),
+// поэтому вызывающий код проверяет вид ошибки через errors.Is, не разбирая текст
+// и не завися от конкретной базы данных. Любая другая ошибка хранилища - внутренняя
+// (например, база недоступна), и ее текст не должен попадать к клиенту.
+var (
+	// ErrNotFound - запрошенной записи (или записи, на которую она ссылается) нет.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict - запись с таким ID уже существует.
+	ErrConflict = errors.New("already exists")
+	// ErrInvalidCursor - курсор пагинации поврежден или создан не хранилищем.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidInput - данные записи некорректны (например, ID записи в неверном формате).
+	ErrInvalidInput = errors.New("invalid input")
+)
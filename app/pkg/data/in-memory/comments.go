@@ -4,23 +4,65 @@ import (
 	"context"
 	"fmt"
 	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/search"
 	"sort"
 	"sync"
 	"time"
 )
 
 // CommentStore реализует интерфейс data.CommentStore для хранения комментариев в памяти.
-type CommentStore struct{}
+// Комментарии проиндексированы по посту и по родительскому комментарию, поэтому выборка
+// комментариев поста или ответов не просматривает все комментарии хранилища.
+type CommentStore struct {
+	mu       sync.RWMutex               // mu обеспечивает потокобезопасный доступ к данным хранилища.
+	comments map[string]*commentEntry   // comments - комментарии по ID.
+	byPost   map[string][]*commentEntry // byPost - комментарии поста по возрастанию даты создания.
+	byParent map[string][]*commentEntry // byParent - ответы на комментарий по возрастанию даты создания.
+	updateMu sync.Mutex                 // updateMu упорядочивает добавления и правки (см. PostStore.updateMu).
+	// searchIndex - инвертированный индекс текстов комментариев для полнотекстового поиска (см. Searcher).
+	searchIndex *search.Index
+	// visibleByPost и visibleByParent - число неудаленных комментариев поста и ответов на комментарий;
+	// поддерживаются при каждой записи, чтобы подсчет не просматривал индексы.
+	visibleByPost   map[string]int
+	visibleByParent map[string]int
+	// authors - число неудаленных комментариев по автору (см. NotificationStore.KnownRecipients).
+	authors map[string]int
+	// pairMu - блокировка записей пары связанных хранилищ (см. PostStore.pairMu).
+	pairMu *sync.RWMutex
+
+	notifications *NotificationStore // notifications - уведомления о комментариях этого хранилища.
+	journal       *Journal           // journal - журнал для сохранения на диск; nil - данные только в памяти.
+}
+
+// commentEntry - комментарий вместе с его прежними версиями.
+type commentEntry struct {
+	comment   *model.Comment
+	revisions []*model.Revision // revisions - прежние версии по возрастанию номера.
+}
 
-// NewCommentStore создает и возвращает новый экземпляр CommentStore.
+// NewCommentStore создает и возвращает новый пустой экземпляр CommentStore.
 func NewCommentStore() *CommentStore {
-	return &CommentStore{}
+	s := &CommentStore{
+		comments: make(map[string]*commentEntry),
+		byPost:   make(map[string][]*commentEntry),
+		byParent: make(map[string][]*commentEntry),
+
+		visibleByPost:   make(map[string]int),
+		visibleByParent: make(map[string]int),
+		authors:         make(map[string]int),
+
+		searchIndex: search.NewIndex(),
+		pairMu:      new(sync.RWMutex),
+	}
+	s.notifications = newNotificationStore(s)
+	return s
 }
 
-// comments хранит комментарии в памяти в виде map, где ключ - ID комментария.
-var comments map[string]*model.Comment
-// commentsMutex обеспечивает потокобезопасный доступ к map comments.
-var commentsMutex sync.RWMutex
+// Notifications возвращает хранилище уведомлений о комментариях этого хранилища.
+func (s *CommentStore) Notifications() *NotificationStore {
+	return s.notifications
+}
 
 // CommentsResult структура для возврата списка комментариев с информацией о пагинации (не используется в текущей реализации).
 type CommentsResult struct {
@@ -28,198 +70,425 @@ type CommentsResult struct {
 	HasNextPage bool
 }
 
-// InitializeComments инициализирует хранилище in-memory тестовыми данными комментариев.
-// Используется для демонстрации и локальной разработки.
-func InitializeComments() {
-	comments = make(map[string]*model.Comment)
-	ctx := context.Background()
-	store := NewCommentStore()
-
-	// Создание тестового комментария 1
-	comment1 := &model.Comment{
-		ID:        "1",
-		Author:    "Комментатор 1",
-		Content:   "Отличный первый пост!",
-		CreatedAt: time.Now().Add(-time.Hour * 2).Format(time.RFC3339),
-		PostID:    "1",
-	}
-	store.AddComment(ctx, comment1)
+// newCommentEntry - вспомогательная функция подготовки комментария к хранению:
+// дата создания приводится к точности хранения (data.Timestamp), а упоминания копируются (storedMentions);
+// комментарий вызывающего кода не меняется.
+func newCommentEntry(comment *model.Comment) *commentEntry {
+	stored := *comment
+	stored.CreatedAt = data.Timestamp(comment.CreatedAt)
+	stored.Mentions = storedMentions(comment.Mentions)
+	return &commentEntry{comment: &stored}
+}
 
-	// Создание тестового комментария 2
-	comment2 := &model.Comment{
-		ID:        "2",
-		Author:    "Комментатор 2",
-		Content:   "Согласен, очень интересно!",
-		CreatedAt: time.Now().Add(-time.Hour).Format(time.RFC3339),
-		PostID:    "1",
+// storedMentions - вспомогательная функция копирования упоминаний для хранения, чтобы изменения списка
+// вызывающим кодом не меняли хранилище; nil заменяется пустым списком, как в SQL-хранилищах.
+func storedMentions(mentions []*model.Mention) []*model.Mention {
+	result := make([]*model.Mention, len(mentions))
+	for i, mention := range mentions {
+		copied := *mention
+		result[i] = &copied
 	}
-	store.AddComment(ctx, comment2)
+	return result
+}
 
-	// Создание тестового комментария 3
-	comment3 := &model.Comment{
-		ID:        "3",
-		Author:    "Комментатор 3",
-		Content:   "Мне не очень...",
-		CreatedAt: time.Now().Format(time.RFC3339),
-		PostID:    "2",
+// before - функция порядка комментариев: по возрастанию даты создания, при равных датах - по ID.
+func (e *commentEntry) before(other *commentEntry) bool {
+	if !e.comment.CreatedAt.Equal(other.comment.CreatedAt) {
+		return e.comment.CreatedAt.Before(other.comment.CreatedAt)
 	}
-	store.AddComment(ctx, comment3)
+	return e.comment.ID < other.comment.ID
 }
 
 // GetCommentByID возвращает комментарий из in-memory хранилища по его ID.
 // Возвращает ошибку, если комментарий не найден.
-func (*CommentStore) GetCommentByID(ctx context.Context, id string) (*model.Comment, error) {
-	commentsMutex.RLock() // Блокировка на чтение для обеспечения конкурентного доступа.
-	defer commentsMutex.RUnlock()
+func (s *CommentStore) GetCommentByID(ctx context.Context, id string) (*model.Comment, error) {
+	s.mu.RLock() // Блокировка на чтение для обеспечения конкурентного доступа.
+	defer s.mu.RUnlock()
 
-	comment, ok := comments[id]
+	entry, ok := s.comments[id]
 	if !ok {
-		return nil, fmt.Errorf("comment with id %s not found", id) // Комментарий с указанным ID не найден.
+		return nil, fmt.Errorf("comment with id %s: %w", id, data.ErrNotFound) // Комментарий с указанным ID не найден.
 	}
 
-	return comment, nil
+	return entry.comment, nil
 }
 
 // GetCommentsForPost возвращает список комментариев для указанного поста с поддержкой пагинации.
 // `first` - количество комментариев для возврата, `afterCursor` - курсор для пагинации.
-func (*CommentStore) GetCommentsForPost(ctx context.Context, postID string, first int32, afterCursor *string) (*model.CommentConnection, error) {
-	commentsMutex.RLock() // Блокировка на чтение для обеспечения конкурентного доступа.
-	defer commentsMutex.RUnlock()
+func (s *CommentStore) GetCommentsForPost(ctx context.Context, postID string, first int32, afterCursor *string) (*model.CommentConnection, error) {
+	s.mu.RLock() // Блокировка на чтение для обеспечения конкурентного доступа.
+	defer s.mu.RUnlock()
 
-	filtered := make([]*model.Comment, 0)
+	return s.page(s.byPost[postID], first, afterCursor)
+}
 
-	// Фильтрация комментариев по PostID
-	for _, comment := range comments {
-		if comment.PostID == postID {
-			filtered = append(filtered, comment)
-		}
-	}
+// GetRepliesForComment возвращает ответы на комментарий (рекурсивные комментарии) с пагинацией.
+// `parentID` - ID родительского комментария, `first` - количество ответов, `afterCursor` - курсор.
+func (s *CommentStore) GetRepliesForComment(ctx context.Context, parentID string, first int32, afterCursor *string) (*model.CommentConnection, error) {
+	s.mu.RLock() // Блокировка на чтение для обеспечения конкурентного доступа.
+	defer s.mu.RUnlock()
 
-	// Сортировка комментариев по дате создания (CreatedAt) в порядке возрастания.
-	sort.Slice(filtered, func(i, j int) bool {
-		t1, err1 := time.Parse(time.RFC3339, filtered[i].CreatedAt)
-		t2, err2 := time.Parse(time.RFC3339, filtered[j].CreatedAt)
-		if err1 != nil || err2 != nil {
-			return false // В случае ошибки парсинга дат, порядок не меняем.
-		}
+	return s.page(s.byParent[parentID], first, afterCursor)
+}
 
-		return t1.Before(t2) // Сортировка по возрастанию CreatedAt.
-	})
+// CountCommentsForPost возвращает число неудаленных комментариев поста (см. data.CommentStore).
+func (s *CommentStore) CountCommentsForPost(ctx context.Context, postID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.visibleByPost[postID], nil
+}
 
-	// Проверка корректности дат (лишняя операция, можно убрать в production, если уверены в данных).
-	for _, c := range filtered {
-		_, err := time.Parse(time.RFC3339, c.CreatedAt)
+// CountRepliesForComment возвращает число неудаленных ответов на комментарий (см. data.CommentStore).
+func (s *CommentStore) CountRepliesForComment(ctx context.Context, commentID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.visibleByParent[commentID], nil
+}
+
+// page - вспомогательный метод нарезки страницы из упорядоченного индекса.
+// Вызывается под блокировкой на чтение.
+func (s *CommentStore) page(list []*commentEntry, first int32, afterCursor *string) (*model.CommentConnection, error) {
+	start := 0
+	// Если указан курсор `afterCursor`, выборка начинается с первого комментария, идущего после
+	// записанных в курсоре даты и ID.
+	if afterCursor != nil && *afterCursor != "" {
+		createdAt, id, err := data.DecodeCursor(*afterCursor)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing date: %w", err) // Ошибка парсинга даты.
+			return nil, err
 		}
+		key := &commentEntry{comment: &model.Comment{ID: id, CreatedAt: createdAt}}
+		start = sort.Search(len(list), func(i int) bool { return key.before(list[i]) })
+	}
+	if first <= 0 {
+		first = 0 // Пустая страница, как и в SQL-хранилищах.
 	}
 
-	start := 0
-	// Если указан курсор `afterCursor`, находим индекс, с которого начинать выборку.
-	if afterCursor != nil {
-		for i, c := range filtered {
-			if c.ID == *afterCursor {
-				start = i + 1 // Начинаем с элемента, следующего за курсором.
-				break
-			}
+	// Удаленные комментарии пропускаются.
+	edges := make([]*model.CommentEdge, 0, first)
+	hasNextPage := false
+	for _, entry := range list[start:] {
+		if entry.comment.DeletedAt != nil {
+			continue
+		}
+		if len(edges) == int(first) {
+			hasNextPage = first > 0
+			break
 		}
+		edges = append(edges, &model.CommentEdge{Node: entry.comment, Cursor: data.EncodeCursor(entry.comment.CreatedAt, entry.comment.ID)})
 	}
 
-	end := start + int(first) // Вычисление конечного индекса для нарезки слайса.
-	if end > len(filtered) {
-		end = len(filtered) // Если `end` выходит за границы слайса, обрезаем до конца.
+	return &model.CommentConnection{
+		Edges:    edges,
+		PageInfo: &model.PageInfo{HasNextPage: hasNextPage}, // Информация о пагинации.
+	}, nil
+}
+
+// AddComment добавляет новый комментарий в in-memory хранилище.
+// В режиме с сохранением на диск комментарий сначала записывается в журнал.
+// Проверка ID и добавление выполняются под updateMu (см. PostStore.AddPost).
+func (s *CommentStore) AddComment(ctx context.Context, comment *model.Comment) error {
+	s.pairMu.RLock()
+	defer s.pairMu.RUnlock()
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	entry := newCommentEntry(comment)
+	if s.exists(comment.ID) {
+		return fmt.Errorf("comment with id %s: %w", comment.ID, data.ErrConflict)
 	}
 
-	commentSlice := filtered[start:end] // Нарезка слайса комментариев для текущей страницы.
+	if s.journal != nil {
+		return s.journal.write(journalRecord{Op: opAddComment, Comment: entry.comment}, func() { s.insert(entry) })
+	}
 
-	hasNextPage := end < len(filtered) // Определение, есть ли следующая страница.
+	s.insert(entry)
+	return nil
+}
 
-	return &model.CommentConnection{
-		Edges:     convertToCommentEdges(commentSlice), // Преобразование слайса комментариев в слайс edges.
-		PageInfo: &model.PageInfo{HasNextPage: hasNextPage}, // Информация о пагинации.
-	}, nil
+// UpdateComment заменяет текст комментария и упоминания в нем (см. data.CommentStore).
+// Комментарий не меняется на месте, а заменяется новой версией.
+func (s *CommentStore) UpdateComment(ctx context.Context, id, content string, mentions []*model.Mention, editedAt time.Time) (*model.Comment, error) {
+	return s.change(ctx, id, func(old *model.Comment) (*model.Comment, *model.Revision, error) {
+		return applyCommentUpdate(old, content, mentions, editedAt)
+	})
+}
+
+// DeleteComment помечает комментарий удаленным (см. data.CommentStore).
+func (s *CommentStore) DeleteComment(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Comment, error) {
+	return s.change(ctx, id, func(old *model.Comment) (*model.Comment, *model.Revision, error) {
+		comment, err := deletedComment(old, deletedBy, deletedAt)
+		return comment, nil, err
+	})
 }
 
-// convertToCommentEdges преобразует слайс комментариев в слайс CommentEdge для GraphQL Connection.
-func convertToCommentEdges(comments []*model.Comment) []*model.CommentEdge {
-	edges := make([]*model.CommentEdge, len(comments))
+// RestoreComment снимает с комментария отметку об удалении (см. data.CommentStore).
+func (s *CommentStore) RestoreComment(ctx context.Context, id string) (*model.Comment, error) {
+	return s.change(ctx, id, func(old *model.Comment) (*model.Comment, *model.Revision, error) {
+		comment, err := restoredComment(old)
+		return comment, nil, err
+	})
+}
+
+// change - вспомогательный метод замены комментария версией, построенной функцией next из текущей
+// (вместе с ревизией или nil). В режиме с сохранением на диск новая версия сначала записывается в журнал.
+func (s *CommentStore) change(ctx context.Context, id string, next func(old *model.Comment) (*model.Comment, *model.Revision, error)) (*model.Comment, error) {
+	s.pairMu.RLock()
+	defer s.pairMu.RUnlock()
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	old, err := s.GetCommentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	comment, revision, err := next(old)
+	if err != nil {
+		return nil, err
+	}
 
-	// Для каждого комментария создается CommentEdge, содержащий курсор и ноду (комментарий).
-	for i, comment := range comments {
-		edges[i] = &model.CommentEdge{
-			Node: comment, // Установка ноды (комментария).
+	if s.journal != nil {
+		err = s.journal.write(journalRecord{Op: opUpdateComment, Comment: comment, Revision: revision}, func() { s.update(comment, revision) })
+		if err != nil {
+			return nil, err
 		}
+		return comment, nil
 	}
 
-	return edges
+	if err := s.update(comment, revision); err != nil {
+		return nil, err
+	}
+	return comment, nil
 }
 
-// AddComment добавляет новый комментарий в in-memory хранилище.
-func (*CommentStore) AddComment(ctx context.Context, comment *model.Comment) error {
-	commentsMutex.Lock() // Блокировка на запись для обеспечения эксклюзивного доступа к map.
-	defer commentsMutex.Unlock()
+// GetCommentRevisions возвращает прежние версии комментария с пагинацией (см. data.CommentStore).
+func (s *CommentStore) GetCommentRevisions(ctx context.Context, commentID string, first int32, afterCursor *string) (*model.RevisionConnection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var revisions []*model.Revision
+	if entry, ok := s.comments[commentID]; ok {
+		revisions = entry.revisions
+	}
+	return revisionPage(revisions, first, afterCursor)
+}
 
-	comments[comment.ID] = comment // Добавление комментария в map comments.
+// update - вспомогательный метод замены комментария новой версией в map и индексах и добавления ревизии.
+func (s *CommentStore) update(comment *model.Comment, revision *model.Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
+	old, ok := s.comments[comment.ID]
+	if !ok {
+		return fmt.Errorf("comment with id %s: %w", comment.ID, data.ErrNotFound)
+	}
+
+	entry := &commentEntry{comment: comment, revisions: appendRevision(old.revisions, revision)}
+	s.comments[comment.ID] = entry
+	s.count(old.comment, -1)
+	s.count(comment, 1)
+	replace(s.byPost[comment.PostID], old, entry)
+	if comment.ParentID != nil {
+		replace(s.byParent[*comment.ParentID], old, entry)
+	}
+	if comment.Content != old.comment.Content {
+		s.searchIndex.Add(comment.ID, commentFields(comment)...)
+	}
 	return nil
 }
 
-// GetRepliesForComment возвращает ответы на комментарий (рекурсивные комментарии) с пагинацией.
-// `parentID` - ID родительского комментария, `first` - количество ответов, `afterCursor` - курсор.
-func (*CommentStore) GetRepliesForComment(ctx context.Context, parentID string, first int32, afterCursor *string) (*model.CommentConnection, error) {
-	commentsMutex.RLock() // Блокировка на чтение для обеспечения конкурентного доступа.
-	defer commentsMutex.RUnlock()
+// revisions - вспомогательный метод получения ревизий всех комментариев, у которых они есть (для снимка журнала).
+func (s *CommentStore) revisions() map[string][]*model.Revision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	filtered := make([]*model.Comment, 0)
+	revisions := make(map[string][]*model.Revision)
+	for id, entry := range s.comments {
+		if len(entry.revisions) > 0 {
+			revisions[id] = entry.revisions
+		}
+	}
+	return revisions
+}
+
+// setRevisions - вспомогательный метод восстановления ревизий комментария из снимка.
+func (s *CommentStore) setRevisions(id string, revisions []*model.Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.comments[id]
+	if !ok {
+		return fmt.Errorf("revisions of comment with id %s: %w", id, data.ErrNotFound)
+	}
+	entry.revisions = revisions
+	return nil
+}
 
-	// Фильтрация комментариев для получения ответов на конкретный родительский комментарий.
-	for _, comment := range comments {
-		if comment.ParentID != nil && *comment.ParentID == parentID {
-			filtered = append(filtered, comment)
+// purge - вспомогательный метод окончательного удаления комментариев: всех комментариев постов removedPosts
+// и комментариев, помеченных удаленными раньше before, если на них нет ответов.
+// Уведомления об удаленных комментариях удаляются вместе с ними.
+// Возвращает число комментариев, удаленных не вместе с постами.
+func (s *CommentStore) purge(before time.Time, removedPosts []string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := make(map[string]bool) // removed - ID всех удаленных комментариев.
+	defer func() { s.notifications.removeComments(removed) }()
+
+	for _, postID := range removedPosts {
+		for _, entry := range s.byPost[postID] {
+			removed[entry.comment.ID] = true
+			if entry.comment.DeletedAt == nil {
+				adjust(s.authors, entry.comment.Author, -1) // Остальные счетчики удаляются вместе с постом.
+			}
+			delete(s.comments, entry.comment.ID)
+			delete(s.byParent, entry.comment.ID)
+			delete(s.visibleByParent, entry.comment.ID)
+			s.searchIndex.Remove(entry.comment.ID)
 		}
+		delete(s.byPost, postID)
+		delete(s.visibleByPost, postID)
 	}
 
-	// Сортировка ответов по дате создания (CreatedAt) в порядке возрастания.
-	sort.Slice(filtered, func(i, j int) bool {
-		t1, err1 := time.Parse(time.RFC3339, filtered[i].CreatedAt)
-		t2, err2 := time.Parse(time.RFC3339, filtered[j].CreatedAt)
-		if err1 != nil || err2 != nil {
-			return false // В случае ошибки парсинга дат, порядок не меняем.
+	// Кандидаты выбираются до удаления, поэтому удаленный комментарий и удаленный ответ на него
+	// удаляются за два прохода, как и в SQL-хранилищах.
+	var expired []*commentEntry
+	for _, entry := range s.comments {
+		if deletedBefore(entry.comment.DeletedAt, before) && len(s.byParent[entry.comment.ID]) == 0 {
+			expired = append(expired, entry)
 		}
+	}
+	for _, entry := range expired {
+		removed[entry.comment.ID] = true
+		delete(s.comments, entry.comment.ID)
+		delete(s.byParent, entry.comment.ID)
+		s.searchIndex.Remove(entry.comment.ID)
+		s.byPost[entry.comment.PostID] = remove(s.byPost[entry.comment.PostID], entry)
+		if entry.comment.ParentID != nil {
+			s.byParent[*entry.comment.ParentID] = remove(s.byParent[*entry.comment.ParentID], entry)
+		}
+	}
+	return len(expired)
+}
 
-		return t1.Before(t2) // Сортировка по возрастанию CreatedAt.
-	})
+// entry - вспомогательный метод получения записи хранилища для комментария.
+func (s *CommentStore) entry(id string) (*commentEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.comments[id]
+	return entry, ok
+}
 
-	// Проверка корректности дат (лишняя операция, можно убрать в production).
-	for _, c := range filtered {
-		_, err := time.Parse(time.RFC3339, c.CreatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing date: %w", err) // Ошибка парсинга даты.
+// hasVisible - вспомогательный метод проверки, есть ли у поста неудаленные комментарии.
+func (s *CommentStore) hasVisible(postID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.visibleByPost[postID] > 0
+}
+
+// hasAuthor - вспомогательный метод проверки, есть ли у автора неудаленные комментарии.
+func (s *CommentStore) hasAuthor(author string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.authors[author] > 0
+}
+
+// exists - вспомогательный метод проверки, есть ли в хранилище комментарий с указанным ID.
+func (s *CommentStore) exists(id string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.comments[id]
+	return ok
+}
+
+// insert - вспомогательный метод добавления комментария в map и индексы.
+// Комментарий с уже существующим ID заменяется.
+func (s *CommentStore) insert(entry *commentEntry) {
+	s.mu.Lock() // Блокировка на запись для обеспечения эксклюзивного доступа к данным.
+	defer s.mu.Unlock()
+
+	if old, ok := s.comments[entry.comment.ID]; ok {
+		s.byPost[old.comment.PostID] = remove(s.byPost[old.comment.PostID], old)
+		if old.comment.ParentID != nil {
+			s.byParent[*old.comment.ParentID] = remove(s.byParent[*old.comment.ParentID], old)
 		}
+		s.count(old.comment, -1)
 	}
 
-	start := 0
-	// Обработка курсора `afterCursor` для пагинации.
-	if afterCursor != nil {
-		for i, c := range filtered {
-			if c.ID == *afterCursor {
-				start = i + 1 // Начинаем с элемента, следующего за курсором.
-				break
-			}
-		}
+	s.comments[entry.comment.ID] = entry
+	s.count(entry.comment, 1)
+	s.searchIndex.Add(entry.comment.ID, commentFields(entry.comment)...)
+	s.byPost[entry.comment.PostID] = insertSorted(s.byPost[entry.comment.PostID], entry)
+	if entry.comment.ParentID != nil {
+		s.byParent[*entry.comment.ParentID] = insertSorted(s.byParent[*entry.comment.ParentID], entry)
 	}
+}
 
-	end := start + int(first) // Вычисление конечного индекса.
-	if end > len(filtered) {
-		end = len(filtered) // Обрезка до конца слайса, если `end` выходит за границы.
+// count - вспомогательный метод изменения счетчиков неудаленных комментариев на delta для комментария comment
+// (удаленный комментарий в счетчиках не участвует). Вызывается под блокировкой на запись.
+func (s *CommentStore) count(comment *model.Comment, delta int) {
+	if comment.DeletedAt != nil {
+		return
 	}
+	adjust(s.visibleByPost, comment.PostID, delta)
+	adjust(s.authors, comment.Author, delta)
+	if comment.ParentID != nil {
+		adjust(s.visibleByParent, *comment.ParentID, delta)
+	}
+}
 
-	commentSlice := filtered[start:end] // Нарезка слайса ответов.
+// adjust - вспомогательная функция изменения счетчика по ключу; нулевые счетчики удаляются из map.
+func adjust(counters map[string]int, key string, delta int) {
+	if counters[key] += delta; counters[key] == 0 {
+		delete(counters, key)
+	}
+}
 
-	hasNextPage := end < len(filtered) // Определение, есть ли следующая страница ответов.
+// all - вспомогательный метод получения всех комментариев (для снимка журнала).
+func (s *CommentStore) all() []*model.Comment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	return &model.CommentConnection{
-		Edges:     convertToCommentEdges(commentSlice), // Преобразование в CommentEdge.
-		PageInfo: &model.PageInfo{HasNextPage: hasNextPage}, // Информация о пагинации.
-	}, nil
-}
\ No newline at end of file
+	comments := make([]*model.Comment, 0, len(s.comments))
+	for _, entry := range s.comments {
+		comments = append(comments, entry.comment)
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+	return comments
+}
+
+// Len возвращает количество комментариев в хранилище.
+func (s *CommentStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.comments)
+}
+
+// position - вспомогательная функция поиска места комментария в упорядоченном индексе.
+func position(list []*commentEntry, entry *commentEntry) int {
+	return sort.Search(len(list), func(i int) bool { return !list[i].before(entry) })
+}
+
+// insertSorted - вспомогательная функция вставки комментария в упорядоченный индекс.
+func insertSorted(list []*commentEntry, entry *commentEntry) []*commentEntry {
+	index := position(list, entry)
+	list = append(list, nil)
+	copy(list[index+1:], list[index:])
+	list[index] = entry
+	return list
+}
+
+// replace - вспомогательная функция замены комментария в упорядоченном индексе записью с тем же ключом.
+func replace(list []*commentEntry, old, entry *commentEntry) {
+	if index := position(list, old); index < len(list) && list[index] == old {
+		list[index] = entry
+	}
+}
+
+// remove - вспомогательная функция удаления комментария из упорядоченного индекса.
+func remove(list []*commentEntry, entry *commentEntry) []*commentEntry {
+	index := position(list, entry)
+	if index < len(list) && list[index] == entry {
+		list = append(list[:index], list[index+1:]...)
+	}
+	return list
+}
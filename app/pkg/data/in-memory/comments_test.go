@@ -15,47 +15,8 @@ func TestNewCommentStore(t *testing.T) {
 	}
 }
 
-func setupTestEnvironment() {
-	// Функция для настройки тестового окружения перед каждым тестом.
-	// В данном случае, она инициализирует мапу comments для изоляции тестов.
-	comments = make(map[string]*model.Comment)
-}
-
-func TestInitializeComments(t *testing.T) {
-	// Тест функции InitializeComments, проверяющей начальное заполнение хранилища комментариями.
-	InitializeComments()
-
-	// Проверка, что после инициализации в хранилище должно быть ровно 3 комментария.
-	if len(comments) != 3 {
-		t.Errorf("Expected 3 comments, got %d", len(comments)) // Ожидалось 3 комментария, получено %d.
-	}
-
-	// Проверка существования и корректности данных первого комментария (ID "1").
-	comment1, exists := comments["1"]
-	if !exists {
-		t.Error("Comment with ID 1 not found") // Комментарий с ID 1 не найден.
-	}
-
-	// Проверка полей Author и PostID для комментария с ID "1".
-	if comment1.Author != "Комментатор 1" || comment1.PostID != "1" {
-		t.Errorf("Comment 1 has incorrect data: %+v", comment1) // Комментарий 1 содержит некорректные данные: %+v.
-	}
-
-	// Проверка существования и корректности данных третьего комментария (ID "3").
-	comment3, exists := comments["3"]
-	if !exists {
-		t.Error("Comment with ID 3 not found") // Комментарий с ID 3 не найден.
-	}
-
-	// Проверка полей Author и PostID для комментария с ID "3".
-	if comment3.Author != "Комментатор 3" || comment3.PostID != "2" {
-		t.Errorf("Comment 3 has incorrect data: %+v", comment3) // Комментарий 3 содержит некорректные данные: %+v.
-	}
-}
-
 func TestGetCommentByID(t *testing.T) {
-	setupTestEnvironment() // Настройка тестового окружения.
-	store := NewCommentStore() // Создание нового хранилища комментариев.
+	store := NewCommentStore()  // Создание нового хранилища комментариев; у каждого теста свои данные.
 	ctx := context.Background() // Создание контекста для тестов.
 
 	// Создание тестового комментария для добавления в хранилище.
@@ -63,7 +24,7 @@ func TestGetCommentByID(t *testing.T) {
 		ID:        "42",
 		Author:    "Тестовый автор",
 		Content:   "Тестовый контент",
-		CreatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
 		PostID:    "5",
 	}
 
@@ -93,8 +54,7 @@ func TestGetCommentByID(t *testing.T) {
 }
 
 func TestAddComment(t *testing.T) {
-	setupTestEnvironment() // Настройка тестового окружения.
-	store := NewCommentStore() // Создание нового хранилища комментариев.
+	store := NewCommentStore()  // Создание нового хранилища комментариев; у каждого теста свои данные.
 	ctx := context.Background() // Создание контекста для тестов.
 
 	// Создание тестового комментария для добавления.
@@ -102,7 +62,7 @@ func TestAddComment(t *testing.T) {
 		ID:        "123",
 		Author:    "Тестовый автор",
 		Content:   "Тестовый контент",
-		CreatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
 		PostID:    "5",
 	}
 
@@ -113,13 +73,11 @@ func TestAddComment(t *testing.T) {
 	}
 
 	// Проверка, что комментарий был успешно добавлен в хранилище.
-	commentsMutex.RLock()
-	comment, exists := comments["123"]
-	commentsMutex.RUnlock()
+	comment, err := store.GetCommentByID(ctx, "123")
 
-	// Проверка, что комментарий с ID "123" существует в мапе comments.
-	if !exists {
-		t.Error("Comment was not added to the store") // Комментарий не был добавлен в хранилище.
+	// Проверка, что комментарий с ID "123" существует в хранилище.
+	if err != nil {
+		t.Fatal("Comment was not added to the store") // Комментарий не был добавлен в хранилище.
 	}
 
 	// Проверка полей Author и Content добавленного комментария.
@@ -129,8 +87,7 @@ func TestAddComment(t *testing.T) {
 }
 
 func TestGetCommentsForPost(t *testing.T) {
-	setupTestEnvironment() // Настройка тестового окружения.
-	store := NewCommentStore() // Создание нового хранилища комментариев.
+	store := NewCommentStore()  // Создание нового хранилища комментариев; у каждого теста свои данные.
 	ctx := context.Background() // Создание контекста для тестов.
 
 	// Добавление нескольких комментариев для поста "post1".
@@ -139,8 +96,8 @@ func TestGetCommentsForPost(t *testing.T) {
 			ID:        string(rune('A' + i)), // ID комментария генерируется на основе буквы.
 			Author:    "Автор " + string(rune('A'+i)),
 			Content:   "Контент " + string(rune('A'+i)),
-			CreatedAt: time.Now().Add(time.Duration(i) * time.Hour).Format(time.RFC3339), // Время создания с разницей в час.
-			PostID:    "post1", // Все комментарии для поста "post1".
+			CreatedAt: time.Now().Add(time.Duration(i) * time.Hour), // Время создания с разницей в час.
+			PostID:    "post1",                                      // Все комментарии для поста "post1".
 		}
 		store.AddComment(ctx, comment) // Добавление комментария в хранилище.
 	}
@@ -150,7 +107,7 @@ func TestGetCommentsForPost(t *testing.T) {
 		ID:        "X",
 		Author:    "Другой автор",
 		Content:   "Другой контент",
-		CreatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
 		PostID:    "post2", // Комментарий для поста "post2".
 	}
 	store.AddComment(ctx, otherComment) // Добавление комментария в хранилище.
@@ -183,8 +140,9 @@ func TestGetCommentsForPost(t *testing.T) {
 	}
 
 	// Тест получения комментариев после курсора.
-	firstCommentID := connection.Edges[0].Node.ID // ID первого комментария из предыдущего запроса.
-	connection, err = store.GetCommentsForPost(ctx, "post1", 2, &firstCommentID) // Запрос комментариев после курсора.
+	firstCommentID := connection.Edges[0].Node.ID                             // ID первого комментария из предыдущего запроса.
+	firstCursor := connection.Edges[0].Cursor                                 // Курсор первого комментария.
+	connection, err = store.GetCommentsForPost(ctx, "post1", 2, &firstCursor) // Запрос комментариев после курсора.
 	if err != nil {
 		t.Fatalf("Failed to get comments after cursor: %v", err) // Не удалось получить комментарии после курсора: %v.
 	}
@@ -201,8 +159,7 @@ func TestGetCommentsForPost(t *testing.T) {
 }
 
 func TestGetRepliesForComment(t *testing.T) {
-	setupTestEnvironment() // Настройка тестового окружения.
-	store := NewCommentStore() // Создание нового хранилища комментариев.
+	store := NewCommentStore()  // Создание нового хранилища комментариев; у каждого теста свои данные.
 	ctx := context.Background() // Создание контекста для тестов.
 
 	// Создание родительского комментария.
@@ -210,7 +167,7 @@ func TestGetRepliesForComment(t *testing.T) {
 		ID:        "parent1",
 		Author:    "Родитель",
 		Content:   "Родительский комментарий",
-		CreatedAt: time.Now().Add(-time.Hour).Format(time.RFC3339),
+		CreatedAt: time.Now().Add(-time.Hour),
 		PostID:    "post1",
 	}
 	store.AddComment(ctx, parentComment) // Добавление родительского комментария.
@@ -222,9 +179,9 @@ func TestGetRepliesForComment(t *testing.T) {
 			ID:        "reply" + string(rune('1'+i)), // ID ответа генерируется на основе номера.
 			Author:    "Ответ " + string(rune('1'+i)),
 			Content:   "Содержание ответа " + string(rune('1'+i)),
-			CreatedAt: time.Now().Add(time.Duration(i) * time.Minute).Format(time.RFC3339), // Время создания с разницей в минуты.
-			PostID:    "post1", // Все ответы к посту "post1".
-			ParentID:  &parentID, // Установка ParentID.
+			CreatedAt: time.Now().Add(time.Duration(i) * time.Minute), // Время создания с разницей в минуты.
+			PostID:    "post1",                                        // Все ответы к посту "post1".
+			ParentID:  &parentID,                                      // Установка ParentID.
 		}
 		store.AddComment(ctx, reply) // Добавление ответа.
 	}
@@ -235,7 +192,7 @@ func TestGetRepliesForComment(t *testing.T) {
 		ID:        "otherReply",
 		Author:    "Другой ответ",
 		Content:   "Ответ на другой комментарий",
-		CreatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
 		PostID:    "post1",
 		ParentID:  &otherParentID, // ParentID = "parent2".
 	}
@@ -269,8 +226,8 @@ func TestGetRepliesForComment(t *testing.T) {
 	}
 
 	// Проверка курсора для ответов.
-	firstReplyID := connection.Edges[0].Node.ID // ID первого ответа из предыдущего запроса.
-	connection, err = store.GetRepliesForComment(ctx, "parent1", 2, &firstReplyID) // Запрос ответов после курсора.
+	firstReplyCursor := connection.Edges[0].Cursor                                     // Курсор первого ответа из предыдущего запроса.
+	connection, err = store.GetRepliesForComment(ctx, "parent1", 2, &firstReplyCursor) // Запрос ответов после курсора.
 	if err != nil {
 		t.Fatalf("Failed to get replies after cursor: %v", err) // Не удалось получить ответы после курсора: %v.
 	}
@@ -279,4 +236,4 @@ func TestGetRepliesForComment(t *testing.T) {
 	if len(connection.Edges) != 2 {
 		t.Errorf("Expected 2 replies after cursor, got %d", len(connection.Edges)) // Ожидалось 2 ответа после курсора, получено %d.
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,64 @@
+package inmemory
+
+import (
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/data/storetest"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	// TestConformance запускает общий набор тестов хранилищ для in-memory реализации.
+	storetest.Run(t, func(t *testing.T) (data.PostStore, data.CommentStore) {
+		return NewStores()
+	})
+}
+
+func TestConformanceWithJournal(t *testing.T) {
+	// TestConformanceWithJournal запускает тот же набор в режиме с сохранением на диск.
+	storetest.Run(t, func(t *testing.T) (data.PostStore, data.CommentStore) {
+		j, posts, comments := openTestJournal(t, t.TempDir())
+		t.Cleanup(func() { j.Close() })
+		return posts, comments
+	})
+}
+
+func TestUnitOfWorkConformance(t *testing.T) {
+	// TestUnitOfWorkConformance запускает общий набор тестов единицы работы для in-memory реализации.
+	storetest.RunUnitOfWork(t, func(t *testing.T) (data.PostStore, data.CommentStore, data.UnitOfWork) {
+		posts, comments := NewStores()
+		return posts, comments, NewUnitOfWork(posts, comments)
+	})
+}
+
+func TestPurgerConformance(t *testing.T) {
+	// TestPurgerConformance запускает общий набор тестов окончательного удаления для in-memory реализации.
+	storetest.RunPurger(t, func(t *testing.T) (data.PostStore, data.CommentStore, data.Purger) {
+		posts, comments := NewStores()
+		return posts, comments, NewPurger(posts, comments)
+	})
+}
+
+func TestSearcherConformance(t *testing.T) {
+	// TestSearcherConformance запускает общий набор тестов полнотекстового поиска для in-memory реализации.
+	storetest.RunSearcher(t, func(t *testing.T) (data.PostStore, data.CommentStore, data.Searcher) {
+		posts, comments := NewStores()
+		return posts, comments, NewSearcher(posts, comments)
+	})
+}
+
+func TestNotificationConformance(t *testing.T) {
+	// TestNotificationConformance запускает общий набор тестов уведомлений для in-memory реализации.
+	storetest.RunNotifications(t, func(t *testing.T) (data.PostStore, data.CommentStore, data.NotificationStore, data.Purger) {
+		posts, comments := NewStores()
+		return posts, comments, comments.Notifications(), NewPurger(posts, comments)
+	})
+}
+
+func TestNotificationConformanceWithJournal(t *testing.T) {
+	// TestNotificationConformanceWithJournal запускает тот же набор в режиме с сохранением на диск.
+	storetest.RunNotifications(t, func(t *testing.T) (data.PostStore, data.CommentStore, data.NotificationStore, data.Purger) {
+		j, posts, comments := openTestJournal(t, t.TempDir())
+		t.Cleanup(func() { j.Close() })
+		return posts, comments, comments.Notifications(), NewPurger(posts, comments)
+	})
+}
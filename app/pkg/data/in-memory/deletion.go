@@ -0,0 +1,102 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"time"
+)
+
+// deletedPost - вспомогательная функция построения версии поста с отметкой об удалении.
+// Исходный пост не меняется.
+func deletedPost(old *model.Post, deletedBy string, deletedAt time.Time) (*model.Post, error) {
+	if old.DeletedAt != nil {
+		return nil, fmt.Errorf("post with id %s is already deleted: %w", old.ID, data.ErrConflict)
+	}
+
+	post := *old
+	deletedAt = data.Timestamp(deletedAt)
+	post.DeletedAt, post.DeletedBy = &deletedAt, &deletedBy
+	return &post, nil
+}
+
+// restoredPost - вспомогательная функция построения версии поста без отметки об удалении.
+func restoredPost(old *model.Post) (*model.Post, error) {
+	if old.DeletedAt == nil {
+		return nil, fmt.Errorf("post with id %s is not deleted: %w", old.ID, data.ErrConflict)
+	}
+
+	post := *old
+	post.DeletedAt, post.DeletedBy = nil, nil
+	return &post, nil
+}
+
+// deletedComment - вспомогательная функция построения версии комментария с отметкой об удалении.
+func deletedComment(old *model.Comment, deletedBy string, deletedAt time.Time) (*model.Comment, error) {
+	if old.DeletedAt != nil {
+		return nil, fmt.Errorf("comment with id %s is already deleted: %w", old.ID, data.ErrConflict)
+	}
+
+	comment := *old
+	deletedAt = data.Timestamp(deletedAt)
+	comment.DeletedAt, comment.DeletedBy = &deletedAt, &deletedBy
+	return &comment, nil
+}
+
+// restoredComment - вспомогательная функция построения версии комментария без отметки об удалении.
+func restoredComment(old *model.Comment) (*model.Comment, error) {
+	if old.DeletedAt == nil {
+		return nil, fmt.Errorf("comment with id %s is not deleted: %w", old.ID, data.ErrConflict)
+	}
+
+	comment := *old
+	comment.DeletedAt, comment.DeletedBy = nil, nil
+	return &comment, nil
+}
+
+// deletedBefore - вспомогательная функция, сообщающая, что запись удалена раньше before.
+func deletedBefore(deletedAt *time.Time, before time.Time) bool {
+	return deletedAt != nil && deletedAt.Before(before)
+}
+
+// Purger реализует интерфейс data.Purger для пары in-memory хранилищ.
+// В режиме с сохранением на диск окончательное удаление записывается в журнал и повторяется при восстановлении.
+type Purger struct {
+	posts    *PostStore
+	comments *CommentStore
+}
+
+// NewPurger создает Purger для пары in-memory хранилищ.
+func NewPurger(posts *PostStore, comments *CommentStore) *Purger {
+	return &Purger{posts: posts, comments: comments}
+}
+
+// Purge безвозвратно удаляет записи, помеченные удаленными раньше deletedBefore (см. data.Purger).
+// Выполняется под блокировкой пары хранилищ на запись, поэтому не вклинивается между проверками
+// единицы работы и применением ее записей.
+func (p *Purger) Purge(ctx context.Context, before time.Time) (int, int, error) {
+	unlock := lockPair(p.posts, p.comments)
+	defer unlock()
+
+	var posts, comments int
+	apply := func() { posts, comments = purge(p.posts, p.comments, before) }
+
+	if p.posts.journal != nil {
+		if err := p.posts.journal.write(journalRecord{Op: opPurge, DeletedBefore: before}, apply); err != nil {
+			return 0, 0, err
+		}
+		return posts, comments, nil
+	}
+
+	apply()
+	return posts, comments, nil
+}
+
+// purge - вспомогательная функция окончательного удаления: сначала посты вместе с их комментариями,
+// затем удаленные комментарии без ответов. Возвращает число удаленных постов и комментариев
+// (комментарии, удаленные вместе с постами, не считаются).
+func purge(posts *PostStore, comments *CommentStore, before time.Time) (int, int) {
+	removed := posts.purge(before)
+	return len(removed), comments.purge(before, removed)
+}
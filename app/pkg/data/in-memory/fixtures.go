@@ -0,0 +1,72 @@
+package inmemory
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/clock"
+	"time"
+)
+
+// LoadFixtures заполняет хранилища тестовыми данными: двумя постами и тремя комментариями.
+// Данные используются для демонстрации и локальной разработки; загрузка выполняется
+// только явным вызовом и включается параметром storage.seed_fixtures.
+// Записи добавляются через AddPost/AddComment, поэтому в режиме с сохранением на диск попадают в журнал.
+// Даты отсчитываются от показаний clk; с фиксированными часами данные полностью воспроизводимы.
+func LoadFixtures(ctx context.Context, posts *PostStore, comments *CommentStore, clk clock.Clock) error {
+	now := clk.Now()
+
+	fixturePosts := []*model.Post{
+		{
+			ID:            "1",
+			Title:         "Первый пост",
+			Content:       "Содержание первого поста",
+			Author:        "Автор 1",
+			CreatedAt:     now.Add(time.Hour),
+			AllowComments: true, // Разрешены комментарии к посту.
+		},
+		{
+			ID:            "2",
+			Title:         "Второй пост",
+			Content:       "Содержание второго поста",
+			Author:        "Автор 2",
+			CreatedAt:     now,
+			AllowComments: false, // Комментарии к посту запрещены.
+		},
+	}
+	for _, post := range fixturePosts {
+		if err := posts.AddPost(ctx, post); err != nil {
+			return err
+		}
+	}
+
+	fixtureComments := []*model.Comment{
+		{
+			ID:        "1",
+			Author:    "Комментатор 1",
+			Content:   "Отличный первый пост!",
+			CreatedAt: now.Add(-time.Hour * 2),
+			PostID:    "1",
+		},
+		{
+			ID:        "2",
+			Author:    "Комментатор 2",
+			Content:   "Согласен, очень интересно!",
+			CreatedAt: now.Add(-time.Hour),
+			PostID:    "1",
+		},
+		{
+			ID:        "3",
+			Author:    "Комментатор 3",
+			Content:   "Мне не очень...",
+			CreatedAt: now,
+			PostID:    "2",
+		},
+	}
+	for _, comment := range fixtureComments {
+		if err := comments.AddComment(ctx, comment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package inmemory
+
+import (
+	"context"
+	"graphql-comment-system/app/pkg/clock"
+	"testing"
+	"time"
+)
+
+// fixturesTime - время фиксированных часов, от которого отсчитываются даты демонстрационных данных.
+var fixturesTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestLoadFixtures(t *testing.T) {
+	// TestLoadFixtures проверяет загрузку тестовых данных в пустые хранилища.
+	ctx := context.Background()
+	posts, comments := NewStores()
+
+	if err := LoadFixtures(ctx, posts, comments, clock.NewFixed(fixturesTime)); err != nil {
+		t.Fatalf("Failed to load fixtures: %v", err)
+	}
+
+	// После загрузки должно быть 2 поста и 3 комментария.
+	if posts.Len() != 2 || comments.Len() != 3 {
+		t.Errorf("Expected 2 posts and 3 comments, got %d and %d", posts.Len(), comments.Len())
+	}
+
+	// Проверка корректности данных постов.
+	post1, err := posts.GetPostByID(ctx, "1")
+	if err != nil || post1.Author != "Автор 1" || post1.Title != "Первый пост" || !post1.AllowComments {
+		t.Errorf("Post 1 has incorrect data: %+v (%v)", post1, err)
+	}
+	post2, err := posts.GetPostByID(ctx, "2")
+	if err != nil || post2.Author != "Автор 2" || post2.AllowComments {
+		t.Errorf("Post 2 has incorrect data: %+v (%v)", post2, err)
+	}
+
+	// Даты отсчитываются от фиксированных часов.
+	if post1 != nil && !post1.CreatedAt.Equal(fixturesTime.Add(time.Hour)) {
+		t.Errorf("Expected post 1 created at %v, got %v", fixturesTime.Add(time.Hour), post1.CreatedAt)
+	}
+	if post2 != nil && !post2.CreatedAt.Equal(fixturesTime) {
+		t.Errorf("Expected post 2 created at %v, got %v", fixturesTime, post2.CreatedAt)
+	}
+
+	// Комментарии поста "1" возвращаются по возрастанию даты создания.
+	connection, err := comments.GetCommentsForPost(ctx, "1", 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to get comments: %v", err)
+	}
+	if len(connection.Edges) != 2 || connection.Edges[0].Node.ID != "1" || connection.Edges[1].Node.ID != "2" {
+		t.Errorf("Unexpected comments for post 1: %+v", connection.Edges)
+	}
+}
+
+func TestStoresAreIndependent(t *testing.T) {
+	// TestStoresAreIndependent проверяет, что экземпляры хранилищ не разделяют данные.
+	ctx := context.Background()
+	first, second := NewPostStore(), NewPostStore()
+
+	if err := LoadFixtures(ctx, first, NewCommentStore(), clock.System{}); err != nil {
+		t.Fatalf("Failed to load fixtures: %v", err)
+	}
+
+	if second.Len() != 0 {
+		t.Errorf("Expected second store to stay empty, got %d posts", second.Len())
+	}
+	if _, err := second.GetPostByID(ctx, "1"); err == nil {
+		t.Error("Expected post from another store to be missing")
+	}
+}
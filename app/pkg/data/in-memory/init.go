@@ -1,7 +0,0 @@
-package inmemory
-
-//Инициализация тестовых данных
-func InitializeData() {
-	InitializePosts()
-	InitializeComments()
-}
@@ -0,0 +1,400 @@
+package inmemory
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Имена файлов в директории данных.
+const (
+	journalFileName  = "journal.log"   // journalFileName - журнал операций, по одной JSON-записи на строку.
+	snapshotFileName = "snapshot.json" // snapshotFileName - компактный снимок всех данных.
+)
+
+// Типы операций журнала.
+const (
+	opAddPost    = "add_post"    // opAddPost - добавление поста.
+	opAddComment = "add_comment" // opAddComment - добавление комментария.
+
+	opUpdatePost    = "update_post"    // opUpdatePost - новая версия поста (и ревизия с прежней, если она есть).
+	opUpdateComment = "update_comment" // opUpdateComment - новая версия комментария (и ревизия с прежней, если она есть).
+
+	opPurge = "purge" // opPurge - окончательное удаление записей, помеченных удаленными раньше DeletedBefore.
+
+	opAddNotification   = "add_notification"   // opAddNotification - добавление уведомления.
+	opReadNotifications = "read_notifications" // opReadNotifications - отметка уведомлений NotificationIDs прочитанными.
+
+	opBatch = "batch" // opBatch - записи Records одной единицы работы, применяемые целиком.
+)
+
+// journalRecord - одна запись журнала операций.
+type journalRecord struct {
+	Op      string         `json:"op"`
+	Post    *model.Post    `json:"post,omitempty"`
+	Comment *model.Comment `json:"comment,omitempty"`
+
+	Revision      *model.Revision `json:"revision,omitempty"`     // Revision - прежняя версия для операций правки.
+	DeletedBefore time.Time       `json:"deletedBefore,omitzero"` // DeletedBefore - граница срока хранения для opPurge.
+
+	Notification    *model.Notification `json:"notification,omitempty"`
+	NotificationIDs []string            `json:"notificationIds,omitempty"` // NotificationIDs - уведомления, отмеченные прочитанными.
+	ReadAt          time.Time           `json:"readAt,omitzero"`           // ReadAt - время прочтения для opReadNotifications.
+
+	Records []journalRecord `json:"records,omitempty"` // Records - записи единицы работы для opBatch.
+}
+
+// snapshot - содержимое файла снимка.
+type snapshot struct {
+	CreatedAt time.Time        `json:"createdAt"`
+	Posts     []*model.Post    `json:"posts"`
+	Comments  []*model.Comment `json:"comments"`
+
+	PostRevisions    map[string][]*model.Revision `json:"postRevisions,omitempty"`    // PostRevisions - прежние версии постов по ID.
+	CommentRevisions map[string][]*model.Revision `json:"commentRevisions,omitempty"` // CommentRevisions - прежние версии комментариев по ID.
+
+	Notifications []*model.Notification `json:"notifications,omitempty"`
+}
+
+// Journal - журнал предварительной записи (write-ahead log) и снимки для in-memory хранилища.
+// Каждое изменение (добавление, правка, удаление и восстановление записей, окончательное удаление,
+// уведомления и отметки об их прочтении; все записи единицы работы - одной записью журнала) сначала
+// дописывается в журнал и сбрасывается на диск, и только затем применяется к данным в памяти.
+// Периодически все данные записываются в снимок,
+// после чего журнал очищается. При запуске загружается снимок и поверх него повторяется журнал.
+type Journal struct {
+	dir      string
+	posts    *PostStore    // posts - хранилище постов, изменения которого записываются в журнал.
+	comments *CommentStore // comments - хранилище комментариев, изменения которого записываются в журнал.
+
+	mu      sync.Mutex // mu - упорядочивает запись в журнал, применение операций и создание снимков.
+	file    *os.File   // file - журнал, открытый на дозапись.
+	records int        // records - число записей в журнале с момента последнего снимка.
+}
+
+// OpenJournal - функция включения режима с сохранением на диск для пары хранилищ.
+// Создает директорию dir при необходимости, восстанавливает данные из снимка и журнала
+// в переданные (пустые) хранилища и начинает записывать в журнал все их последующие изменения.
+func OpenJournal(dir string, posts *PostStore, comments *CommentStore) (*Journal, error) {
+	if posts.Len() > 0 || comments.Len() > 0 {
+		return nil, errors.New("journal must be opened before any data is added to the stores")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("error creating data directory: %w", err)
+	}
+
+	j := &Journal{dir: dir, posts: posts, comments: comments}
+
+	if err := j.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := j.replay(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, journalFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("error opening journal: %w", err)
+	}
+	// Запись о только что созданном файле журнала должна попасть на диск до первой записи в него.
+	if err := syncDir(dir); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error syncing data directory: %w", err)
+	}
+	j.file = file
+
+	posts.journal = j
+	comments.journal = j
+
+	slog.Info("in-memory data restored",
+		slog.String("dir", dir), slog.Int("posts", posts.Len()), slog.Int("comments", comments.Len()),
+		slog.Int("journal_records", j.records))
+
+	return j, nil
+}
+
+// Empty - метод, сообщающий, что восстанавливать было нечего (например, при первом запуске).
+func (j *Journal) Empty() bool {
+	return j.posts.Len() == 0 && j.comments.Len() == 0
+}
+
+// loadSnapshot - вспомогательный метод загрузки снимка, если он есть.
+func (j *Journal) loadSnapshot() error {
+	raw, err := os.ReadFile(filepath.Join(j.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return fmt.Errorf("error parsing snapshot: %w", err)
+	}
+
+	for _, post := range snap.Posts {
+		if err := j.apply(journalRecord{Op: opAddPost, Post: post}); err != nil {
+			return fmt.Errorf("error loading snapshot: %w", err)
+		}
+	}
+	for _, comment := range snap.Comments {
+		if err := j.apply(journalRecord{Op: opAddComment, Comment: comment}); err != nil {
+			return fmt.Errorf("error loading snapshot: %w", err)
+		}
+	}
+	for id, revisions := range snap.PostRevisions {
+		if err := j.posts.setRevisions(id, revisions); err != nil {
+			return fmt.Errorf("error loading snapshot: %w", err)
+		}
+	}
+	for id, revisions := range snap.CommentRevisions {
+		if err := j.comments.setRevisions(id, revisions); err != nil {
+			return fmt.Errorf("error loading snapshot: %w", err)
+		}
+	}
+	for _, notification := range snap.Notifications {
+		if err := j.apply(journalRecord{Op: opAddNotification, Notification: notification}); err != nil {
+			return fmt.Errorf("error loading snapshot: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// replay - вспомогательный метод повторения операций из журнала поверх снимка.
+// Недописанная последняя строка (процесс был остановлен во время записи) отбрасывается,
+// а журнал обрезается до последней целой записи.
+func (j *Journal) replay() error {
+	path := filepath.Join(j.dir, journalFileName)
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error opening journal: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64 // offset - позиция конца последней целой записи.
+	for line := 1; ; line++ {
+		raw, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(raw)) > 0 {
+				slog.Warn("discarding incomplete journal record", slog.Int("line", line))
+				return os.Truncate(path, offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading journal: %w", err)
+		}
+
+		var record journalRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return fmt.Errorf("error parsing journal record at line %d: %w", line, err)
+		}
+		if err := j.apply(record); err != nil {
+			return fmt.Errorf("error replaying journal record at line %d: %w", line, err)
+		}
+
+		offset += int64(len(raw))
+		j.records++
+	}
+}
+
+// apply - вспомогательный метод применения записи журнала или снимка к хранилищам при восстановлении.
+func (j *Journal) apply(record journalRecord) error {
+	return applyRecord(j.posts, j.comments, record)
+}
+
+// applyRecord - вспомогательная функция применения записи журнала к данным в памяти пары хранилищ.
+// Используется при восстановлении и при завершении единицы работы (см. UnitOfWork.Do).
+func applyRecord(posts *PostStore, comments *CommentStore, record journalRecord) error {
+	switch {
+	case record.Op == opAddPost && record.Post != nil:
+		posts.insert(newPostEntry(record.Post))
+	case record.Op == opAddComment && record.Comment != nil:
+		comments.insert(newCommentEntry(record.Comment))
+	case record.Op == opUpdatePost && record.Post != nil:
+		return posts.update(record.Post, record.Revision)
+	case record.Op == opUpdateComment && record.Comment != nil:
+		record.Comment.Mentions = storedMentions(record.Comment.Mentions) // В записях до появления упоминаний их нет.
+		return comments.update(record.Comment, record.Revision)
+	case record.Op == opPurge:
+		purge(posts, comments, record.DeletedBefore)
+	case record.Op == opAddNotification && record.Notification != nil:
+		comments.notifications.insert(record.Notification)
+	case record.Op == opReadNotifications:
+		comments.notifications.markRead(record.NotificationIDs, record.ReadAt)
+	case record.Op == opBatch:
+		for _, nested := range record.Records {
+			if err := applyRecord(posts, comments, nested); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown journal operation %q", record.Op)
+	}
+	return nil
+}
+
+// write - метод записи операции: сначала в журнал с fsync, затем в память (функция apply).
+// Если запись на диск не удалась, данные в памяти не меняются.
+func (j *Journal) write(record journalRecord, apply func()) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error encoding journal record: %w", err)
+	}
+	raw = append(raw, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errors.New("journal is closed")
+	}
+	if _, err := j.file.Write(raw); err != nil {
+		return fmt.Errorf("error writing journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing journal: %w", err)
+	}
+	j.records++
+	apply()
+
+	return nil
+}
+
+// Snapshot - метод записи компактного снимка всех данных и очистки журнала.
+// Снимок пишется во временный файл и атомарно переименовывается, поэтому при сбое
+// на диске всегда остается либо старый, либо новый снимок. Журнал очищается только
+// после того, как переименование сброшено на диск вместе с директорией.
+func (j *Journal) Snapshot() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errors.New("journal is closed")
+	}
+	if j.records == 0 {
+		return nil // С последнего снимка ничего не изменилось.
+	}
+
+	snap := snapshot{
+		CreatedAt:        time.Now().UTC(),
+		Posts:            j.posts.all(),
+		Comments:         j.comments.all(),
+		PostRevisions:    j.posts.revisions(),
+		CommentRevisions: j.comments.revisions(),
+		Notifications:    j.comments.notifications.all(),
+	}
+
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("error encoding snapshot: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(j.dir, snapshotFileName), raw); err != nil {
+		return fmt.Errorf("error writing snapshot: %w", err)
+	}
+
+	// Все записи журнала вошли в снимок, поэтому журнал можно очистить.
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("error truncating journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing journal: %w", err)
+	}
+
+	slog.Debug("in-memory snapshot written",
+		slog.Int("posts", len(snap.Posts)), slog.Int("comments", len(snap.Comments)), slog.Int("compacted_records", j.records))
+	j.records = 0
+
+	return nil
+}
+
+// Run - метод периодического создания снимков. Блокируется до отмены ctx.
+func (j *Journal) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.Snapshot(); err != nil {
+				slog.Error("error writing in-memory snapshot", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// Close - метод завершения работы журнала: записывает итоговый снимок и закрывает файл.
+// Вызывается после остановки сервера; последующие изменения хранилищ завершатся ошибкой.
+func (j *Journal) Close() error {
+	snapshotErr := j.Snapshot()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return snapshotErr
+	}
+	err := j.file.Close()
+	j.file = nil
+
+	return errors.Join(snapshotErr, err)
+}
+
+// writeFileAtomic - вспомогательная функция записи файла через временный файл и rename.
+func writeFileAtomic(path string, raw []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // После успешного rename файла уже нет, и Remove ничего не делает.
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Без синхронизации директории rename может не пережить сбой питания, даже если данные файла уже на диске.
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir - вспомогательная функция сброса на диск записей директории (созданных и переименованных файлов).
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
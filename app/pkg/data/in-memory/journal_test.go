@@ -0,0 +1,232 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// openTestJournal - вспомогательная функция открытия журнала для новой пары хранилищ.
+func openTestJournal(t *testing.T, dir string) (*Journal, *PostStore, *CommentStore) {
+	t.Helper()
+	posts, comments := NewStores()
+	j, err := OpenJournal(dir, posts, comments)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	return j, posts, comments
+}
+
+// addTestData - вспомогательная функция добавления поста с комментарием и ответом, правки поста и комментария
+// и прочитанного и непрочитанного уведомлений.
+func addTestData(t *testing.T, posts *PostStore, comments *CommentStore) {
+	t.Helper()
+	ctx := context.Background()
+	parentID := "c1"
+	now := time.Now()
+
+	if err := posts.AddPost(ctx, &model.Post{ID: "p1", Title: "Пост", Author: "Автор", Content: "Текст", CreatedAt: now, AllowComments: true}); err != nil {
+		t.Fatalf("Failed to add post: %v", err)
+	}
+	for _, comment := range []*model.Comment{
+		{ID: "c1", PostID: "p1", Author: "А", Content: "Комментарий", CreatedAt: now},
+		{ID: "c2", PostID: "p1", ParentID: &parentID, Author: "Б", Content: "@А, ответ", CreatedAt: now,
+			Mentions: []*model.Mention{{Handle: "А", Offset: 0, Length: 2}}},
+	} {
+		if err := comments.AddComment(ctx, comment); err != nil {
+			t.Fatalf("Failed to add comment: %v", err)
+		}
+	}
+
+	content := "Исправленный текст"
+	if _, err := posts.UpdatePost(ctx, "p1", data.PostUpdate{Content: &content, EditedAt: now}); err != nil {
+		t.Fatalf("Failed to update post: %v", err)
+	}
+	if _, err := comments.UpdateComment(ctx, "c1", "Исправленный комментарий", nil, now); err != nil {
+		t.Fatalf("Failed to update comment: %v", err)
+	}
+
+	for _, notification := range []*model.Notification{
+		{ID: "n1", Recipient: "Автор", Kind: model.NotificationKindComment, Actor: "А", PostID: "p1", CommentID: "c1", CreatedAt: now},
+		{ID: "n2", Recipient: "А", Kind: model.NotificationKindReply, Actor: "Б", PostID: "p1", CommentID: "c2", CreatedAt: now},
+	} {
+		if err := comments.Notifications().AddNotification(ctx, notification); err != nil {
+			t.Fatalf("Failed to add notification: %v", err)
+		}
+	}
+	if _, err := comments.Notifications().MarkNotificationsRead(ctx, "Автор", nil, now); err != nil {
+		t.Fatalf("Failed to mark notifications read: %v", err)
+	}
+}
+
+// assertTestData - вспомогательная функция проверки восстановленных данных.
+func assertTestData(t *testing.T, posts *PostStore, comments *CommentStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	post, err := posts.GetPostByID(ctx, "p1")
+	if err != nil || post.Title != "Пост" || !post.AllowComments {
+		t.Errorf("Expected restored post, got %+v (%v)", post, err)
+	}
+	reply, err := comments.GetCommentByID(ctx, "c2")
+	if err != nil || reply.ParentID == nil || *reply.ParentID != "c1" || len(reply.Mentions) != 1 || reply.Mentions[0].Handle != "А" {
+		t.Errorf("Expected restored reply, got %+v (%v)", reply, err)
+	}
+
+	// Правки восстанавливаются вместе с историей прежних версий.
+	if post != nil && (post.Content != "Исправленный текст" || post.RevisionCount != 1 || post.EditedAt == nil) {
+		t.Errorf("Expected restored post edit, got %+v", post)
+	}
+	revisions, err := posts.GetPostRevisions(ctx, "p1", 10, nil)
+	if err != nil || len(revisions.Edges) != 1 || *revisions.Edges[0].Node.Content != "Текст" {
+		t.Errorf("Expected restored post revision, got %+v (%v)", revisions, err)
+	}
+	revisions, err = comments.GetCommentRevisions(ctx, "c1", 10, nil)
+	if err != nil || len(revisions.Edges) != 1 || *revisions.Edges[0].Node.Content != "Комментарий" {
+		t.Errorf("Expected restored comment revision, got %+v (%v)", revisions, err)
+	}
+
+	// Уведомления восстанавливаются вместе с отметками прочтения.
+	for recipient, wantRead := range map[string]bool{"Автор": true, "А": false} {
+		notifications, err := comments.Notifications().GetNotifications(ctx, recipient, 10, nil, false)
+		if err != nil || len(notifications.Edges) != 1 || (notifications.Edges[0].Node.ReadAt != nil) != wantRead {
+			t.Errorf("Expected restored notification of %s (read: %v), got %+v (%v)", recipient, wantRead, notifications, err)
+		}
+	}
+}
+
+func TestJournalRestoresFromSnapshot(t *testing.T) {
+	// TestJournalRestoresFromSnapshot проверяет, что данные переживают корректную остановку.
+	dir := t.TempDir()
+
+	j, posts, comments := openTestJournal(t, dir)
+	if !j.Empty() {
+		t.Fatal("Expected empty store on first start")
+	}
+	addTestData(t, posts, comments)
+	if err := j.Close(); err != nil {
+		t.Fatalf("Failed to close journal: %v", err)
+	}
+
+	// После Close журнал пуст, а данные находятся в снимке.
+	if info, err := os.Stat(filepath.Join(dir, journalFileName)); err != nil || info.Size() != 0 {
+		t.Errorf("Expected empty journal after snapshot, got %v (%v)", info, err)
+	}
+
+	j, posts, comments = openTestJournal(t, dir)
+	defer j.Close()
+	if j.Empty() {
+		t.Fatal("Expected restored data")
+	}
+	assertTestData(t, posts, comments)
+}
+
+func TestJournalReplaysAfterCrash(t *testing.T) {
+	// TestJournalReplaysAfterCrash проверяет восстановление из журнала без снимка
+	// и отбрасывание недописанной последней записи.
+	dir := t.TempDir()
+
+	_, posts, comments := openTestJournal(t, dir) // Журнал не закрывается, как при аварийной остановке.
+	addTestData(t, posts, comments)
+
+	// Имитация записи, прерванной на середине.
+	file, err := os.OpenFile(filepath.Join(dir, journalFileName), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	_, _ = file.WriteString(`{"op":"add_post","post":{"id":"p2"`)
+	file.Close()
+
+	j, posts, comments := openTestJournal(t, dir)
+	defer j.Close()
+	assertTestData(t, posts, comments)
+	if _, err := posts.GetPostByID(context.Background(), "p2"); err == nil {
+		t.Error("Expected incomplete record to be discarded")
+	}
+
+	// Новые записи после восстановления дописываются к целым записям и тоже восстанавливаются.
+	if err := posts.AddPost(context.Background(), &model.Post{ID: "p3", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("Failed to add post: %v", err)
+	}
+	_, posts, _ = openTestJournal(t, dir)
+	if _, err := posts.GetPostByID(context.Background(), "p3"); err != nil {
+		t.Errorf("Expected post added after recovery to be restored: %v", err)
+	}
+}
+
+func TestJournalReplaysDeletionAndPurge(t *testing.T) {
+	// TestJournalReplaysDeletionAndPurge проверяет восстановление отметок об удалении и окончательного удаления.
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	_, posts, comments := openTestJournal(t, dir)
+	addTestData(t, posts, comments)
+	deletedAt := time.Now().Add(-time.Hour)
+	if _, err := comments.DeleteComment(ctx, "c2", "Б", deletedAt); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+	if _, err := comments.DeleteComment(ctx, "c1", "А", deletedAt); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+	// Ответ c2 очищается, а родитель c1 остается: на момент запуска у него был ответ.
+	if _, _, err := NewPurger(posts, comments).Purge(ctx, time.Now()); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+
+	j, posts, comments := openTestJournal(t, dir)
+	defer j.Close()
+	if _, err := comments.GetCommentByID(ctx, "c2"); err == nil {
+		t.Error("Expected purged reply not to be restored")
+	}
+	if notifications, err := comments.Notifications().GetNotifications(ctx, "А", 10, nil, false); err != nil || len(notifications.Edges) != 0 {
+		t.Errorf("Expected notification of purged reply not to be restored, got %+v (%v)", notifications, err)
+	}
+	comment, err := comments.GetCommentByID(ctx, "c1")
+	if err != nil || comment.DeletedAt == nil || !comment.DeletedAt.Equal(data.Timestamp(deletedAt)) || comment.DeletedBy == nil || *comment.DeletedBy != "А" {
+		t.Errorf("Expected restored deletion mark, got %+v (%v)", comment, err)
+	}
+	if _, err := posts.GetPostByID(ctx, "p1"); err != nil {
+		t.Errorf("Expected post to be kept: %v", err)
+	}
+}
+
+func TestJournalConcurrentDuplicates(t *testing.T) {
+	// TestJournalConcurrentDuplicates проверяет, что из параллельных добавлений поста с одним ID
+	// в журнал попадает только успешное.
+	dir := t.TempDir()
+	ctx := context.Background()
+	now := time.Now()
+
+	_, posts, _ := openTestJournal(t, dir) // Журнал не закрывается, как при аварийной остановке.
+	var wg sync.WaitGroup
+	start := make(chan struct{}) // start - общий старт, чтобы добавления пересекались.
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_ = posts.AddPost(ctx, &model.Post{ID: "p1", Title: fmt.Sprintf("Пост %d", i), CreatedAt: now})
+		}()
+	}
+	close(start)
+	wg.Wait()
+	added, err := posts.GetPostByID(ctx, "p1")
+	if err != nil {
+		t.Fatalf("Failed to get post: %v", err)
+	}
+
+	j, posts, _ := openTestJournal(t, dir)
+	defer j.Close()
+	if j.records != 1 {
+		t.Errorf("Expected one journal record, got %d", j.records)
+	}
+	if restored, err := posts.GetPostByID(ctx, "p1"); err != nil || restored.Title != added.Title {
+		t.Errorf("Expected restored post %q, got %+v (%v)", added.Title, restored, err)
+	}
+}
@@ -0,0 +1,278 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NotificationStore реализует интерфейс data.NotificationStore для хранения уведомлений в памяти.
+// Хранилище создается вместе с хранилищем комментариев (см. CommentStore.Notifications): уведомления
+// ссылаются на его комментарии, удаляются вместе с ними при окончательном удалении и сохраняются тем же журналом.
+// Уведомления не меняются на месте: отметка о прочтении заменяет уведомление новой версией.
+type NotificationStore struct {
+	mu            sync.RWMutex                     // mu обеспечивает потокобезопасный доступ к данным хранилища.
+	notifications map[string]*model.Notification   // notifications - уведомления по ID.
+	byRecipient   map[string][]*model.Notification // byRecipient - уведомления получателя от новых к старым.
+	updateMu      sync.Mutex                       // updateMu упорядочивает добавления (проверка ID и вставка) и отметки о прочтении, чтобы в журнал попадали именно отмеченные уведомления.
+
+	comments *CommentStore // comments - хранилище комментариев, о которых сообщают уведомления.
+	posts    *PostStore    // posts - хранилище постов той же пары (см. NewStores); nil - пара не связана.
+}
+
+// newNotificationStore - функция-конструктор пустого хранилища уведомлений о комментариях comments.
+func newNotificationStore(comments *CommentStore) *NotificationStore {
+	return &NotificationStore{
+		notifications: make(map[string]*model.Notification),
+		byRecipient:   make(map[string][]*model.Notification),
+		comments:      comments,
+	}
+}
+
+// notificationBefore - функция порядка уведомлений: от новых к старым, при равных датах - по ID.
+func notificationBefore(a, b *model.Notification) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// AddNotification добавляет уведомление (см. data.NotificationStore).
+// Комментарий уведомления должен быть в хранилище комментариев, как того требует внешний ключ SQL-хранилищ.
+// В режиме с сохранением на диск уведомление сначала записывается в журнал.
+func (s *NotificationStore) AddNotification(ctx context.Context, notification *model.Notification) error {
+	s.comments.pairMu.RLock()
+	defer s.comments.pairMu.RUnlock()
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	stored := storedNotification(notification)
+	if s.exists(notification.ID) {
+		return fmt.Errorf("notification with id %s: %w", notification.ID, data.ErrConflict)
+	}
+	if !s.comments.exists(notification.CommentID) {
+		return fmt.Errorf("notification %s references a missing comment: %w", notification.ID, data.ErrNotFound)
+	}
+
+	if journal := s.comments.journal; journal != nil {
+		return journal.write(journalRecord{Op: opAddNotification, Notification: stored}, func() { s.insert(stored) })
+	}
+
+	s.insert(stored)
+	return nil
+}
+
+// storedNotification - вспомогательная функция подготовки уведомления к хранению: даты приводятся
+// к точности хранения (data.Timestamp); уведомление вызывающего кода не меняется.
+func storedNotification(notification *model.Notification) *model.Notification {
+	stored := *notification
+	stored.CreatedAt = data.Timestamp(notification.CreatedAt)
+	if notification.ReadAt != nil {
+		readAt := data.Timestamp(*notification.ReadAt)
+		stored.ReadAt = &readAt
+	}
+	return &stored
+}
+
+// GetNotifications возвращает уведомления получателя от новых к старым с пагинацией (см. data.NotificationStore).
+func (s *NotificationStore) GetNotifications(ctx context.Context, recipient string, first int32, afterCursor *string, unreadOnly bool) (*model.NotificationConnection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := s.byRecipient[recipient]
+	start := 0
+	// Выборка начинается с первого уведомления, идущего после записанных в курсоре даты и ID.
+	if afterCursor != nil && *afterCursor != "" {
+		createdAt, id, err := data.DecodeCursor(*afterCursor)
+		if err != nil {
+			return nil, err
+		}
+		key := &model.Notification{ID: id, CreatedAt: createdAt}
+		start = sort.Search(len(list), func(i int) bool { return notificationBefore(key, list[i]) })
+	}
+	if first <= 0 {
+		first = 0 // Пустая страница, как и в SQL-хранилищах.
+	}
+
+	edges := make([]*model.NotificationEdge, 0, first)
+	hasNextPage := false
+	for _, notification := range list[start:] {
+		if unreadOnly && notification.ReadAt != nil {
+			continue
+		}
+		if len(edges) == int(first) {
+			hasNextPage = first > 0
+			break
+		}
+		edges = append(edges, &model.NotificationEdge{Node: notification, Cursor: data.EncodeCursor(notification.CreatedAt, notification.ID)})
+	}
+
+	return &model.NotificationConnection{
+		Edges:    edges,
+		PageInfo: &model.PageInfo{HasNextPage: hasNextPage},
+	}, nil
+}
+
+// MarkNotificationsRead отмечает уведомления получателя прочитанными (см. data.NotificationStore).
+// В режиме с сохранением на диск в журнал записываются ID отмеченных уведомлений.
+func (s *NotificationStore) MarkNotificationsRead(ctx context.Context, recipient string, ids []string, readAt time.Time) (int, error) {
+	s.comments.pairMu.RLock()
+	defer s.comments.pairMu.RUnlock()
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	unread := s.unread(recipient, ids)
+	if len(unread) == 0 {
+		return 0, nil
+	}
+	readAt = data.Timestamp(readAt)
+
+	if journal := s.comments.journal; journal != nil {
+		record := journalRecord{Op: opReadNotifications, NotificationIDs: unread, ReadAt: readAt}
+		if err := journal.write(record, func() { s.markRead(unread, readAt) }); err != nil {
+			return 0, err
+		}
+		return len(unread), nil
+	}
+
+	s.markRead(unread, readAt)
+	return len(unread), nil
+}
+
+// KnownRecipients возвращает имена из names, которые есть среди авторов неудаленных постов и комментариев
+// (см. data.NotificationStore). Проверка идет по счетчикам authors хранилищ, а не по всем записям.
+func (s *NotificationStore) KnownRecipients(ctx context.Context, names []string) ([]string, error) {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if s.comments.hasAuthor(name) || (s.posts != nil && s.posts.hasAuthor(name)) {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// unread - вспомогательный метод выбора ID непрочитанных уведомлений получателя из ids (nil - все уведомления получателя).
+func (s *NotificationStore) unread(recipient string, ids []string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var unread []string
+	if ids == nil {
+		for _, notification := range s.byRecipient[recipient] {
+			if notification.ReadAt == nil {
+				unread = append(unread, notification.ID)
+			}
+		}
+		return unread
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		notification, ok := s.notifications[id]
+		if ok && !seen[id] && notification.Recipient == recipient && notification.ReadAt == nil {
+			unread = append(unread, id)
+		}
+		seen[id] = true
+	}
+	return unread
+}
+
+// markRead - вспомогательный метод замены уведомлений с ID из ids прочитанными версиями.
+// Отсутствующие и уже прочитанные уведомления пропускаются.
+func (s *NotificationStore) markRead(ids []string, readAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		old, ok := s.notifications[id]
+		if !ok || old.ReadAt != nil {
+			continue
+		}
+		notification := *old
+		notification.ReadAt = &readAt
+
+		s.notifications[id] = &notification
+		list := s.byRecipient[old.Recipient]
+		if i := notificationPosition(list, old); i < len(list) && list[i] == old {
+			list[i] = &notification
+		}
+	}
+}
+
+// insert - вспомогательный метод добавления уведомления в map и индекс получателя.
+// Уведомление с уже существующим ID заменяется.
+func (s *NotificationStore) insert(notification *model.Notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.notifications[notification.ID]; ok {
+		s.byRecipient[old.Recipient] = removeNotification(s.byRecipient[old.Recipient], old)
+	}
+
+	s.notifications[notification.ID] = notification
+	list := s.byRecipient[notification.Recipient]
+	i := notificationPosition(list, notification)
+	list = append(list, nil)
+	copy(list[i+1:], list[i:])
+	list[i] = notification
+	s.byRecipient[notification.Recipient] = list
+}
+
+// removeComments - вспомогательный метод удаления уведомлений об окончательно удаленных комментариях.
+// Вызывается хранилищем комментариев под его блокировкой.
+func (s *NotificationStore) removeComments(commentIDs map[string]bool) {
+	if len(commentIDs) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, notification := range s.notifications {
+		if commentIDs[notification.CommentID] {
+			delete(s.notifications, id)
+			s.byRecipient[notification.Recipient] = removeNotification(s.byRecipient[notification.Recipient], notification)
+			if len(s.byRecipient[notification.Recipient]) == 0 {
+				delete(s.byRecipient, notification.Recipient)
+			}
+		}
+	}
+}
+
+// exists - вспомогательный метод проверки, есть ли в хранилище уведомление с указанным ID.
+func (s *NotificationStore) exists(id string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.notifications[id]
+	return ok
+}
+
+// all - вспомогательный метод получения всех уведомлений (для снимка журнала).
+func (s *NotificationStore) all() []*model.Notification {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	notifications := make([]*model.Notification, 0, len(s.notifications))
+	for _, notification := range s.notifications {
+		notifications = append(notifications, notification)
+	}
+	sort.Slice(notifications, func(i, j int) bool { return notifications[i].ID < notifications[j].ID })
+	return notifications
+}
+
+// notificationPosition - вспомогательная функция поиска места уведомления в упорядоченном индексе получателя.
+func notificationPosition(list []*model.Notification, notification *model.Notification) int {
+	return sort.Search(len(list), func(i int) bool { return !notificationBefore(list[i], notification) })
+}
+
+// removeNotification - вспомогательная функция удаления уведомления из упорядоченного индекса получателя.
+func removeNotification(list []*model.Notification, notification *model.Notification) []*model.Notification {
+	if i := notificationPosition(list, notification); i < len(list) && list[i].ID == notification.ID {
+		return append(list[:i], list[i+1:]...)
+	}
+	return list
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/search"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -11,12 +14,61 @@ import (
 
 // PostStore реализует интерфейс data.PostStore для хранения постов в памяти.
 // Используется как временное хранилище данных или для целей разработки и тестирования.
-type PostStore struct{}
+// Каждый экземпляр владеет собственными данными, поэтому несколько хранилищ могут существовать одновременно.
+type PostStore struct {
+	mu      sync.RWMutex          // mu обеспечивает потокобезопасный доступ к данным хранилища.
+	posts   map[string]*postEntry // posts - посты по ID.
+	ordered []*postEntry          // ordered - посты в порядке выдачи: сначала новые.
+	// updateMu упорядочивает добавления и правки: проверка ID и добавление поста не должны разделяться
+	// другим добавлением, а новая версия строится из текущей, и параллельная правка не должна потеряться.
+	updateMu sync.Mutex
+	// searchIndex - инвертированный индекс заголовков и текстов постов для полнотекстового поиска (см. Searcher).
+	searchIndex *search.Index
+	// tagged - ID постов по тегу, аналог таблицы post_tags SQL-хранилищ.
+	tagged map[string]map[string]bool
+	// comments - хранилище комментариев той же пары (см. NewStores) для фильтра hasComments; nil - пара не связана.
+	comments *CommentStore
+	// visible - число неудаленных постов; поддерживается при каждой записи, чтобы CountPosts без фильтра
+	// не просматривал все посты.
+	visible int
+	// authors - число неудаленных постов по автору (см. NotificationStore.KnownRecipients).
+	authors map[string]int
+	// pairMu - блокировка записей пары связанных хранилищ (см. NewStores): одиночные записи берут ее на чтение
+	// и идут параллельно, а единица работы и окончательное удаление - на запись (см. lockPair).
+	pairMu *sync.RWMutex
 
-// NewPostStore создает и возвращает новый экземпляр PostStore.
+	journal *Journal // journal - журнал для сохранения на диск; nil - данные только в памяти.
+}
+
+// postEntry - пост вместе с его прежними версиями.
+type postEntry struct {
+	post      *model.Post
+	revisions []*model.Revision // revisions - прежние версии по возрастанию номера.
+}
+
+// NewPostStore создает и возвращает новый пустой экземпляр PostStore.
 // Функция является конструктором для структуры PostStore.
 func NewPostStore() *PostStore {
-	return &PostStore{}
+	return &PostStore{
+		posts:       make(map[string]*postEntry),
+		tagged:      make(map[string]map[string]bool),
+		authors:     make(map[string]int),
+		searchIndex: search.NewIndex(),
+		pairMu:      new(sync.RWMutex),
+	}
+}
+
+// NewStores создает пару пустых связанных хранилищ постов и комментариев.
+// Фильтр постов hasComments учитывает комментарии только связанного хранилища; у постов хранилища,
+// созданного NewPostStore, комментариев для фильтра нет. Так же известными получателями уведомлений
+// (KnownRecipients) считаются авторы постов только связанного хранилища. Записи в связанные хранилища
+// упорядочены общей блокировкой (см. lockPair).
+func NewStores() (*PostStore, *CommentStore) {
+	posts, comments := NewPostStore(), NewCommentStore()
+	posts.comments = comments
+	comments.notifications.posts = posts
+	comments.pairMu = posts.pairMu
+	return posts, comments
 }
 
 // PostsResult структура для хранения результатов запроса списка постов.
@@ -26,137 +78,408 @@ type PostsResult struct {
 	HasNextPage bool
 }
 
-// posts хранит посты в памяти в виде map, где ключ - ID поста, а значение - указатель на структуру Post.
-var posts map[string]*model.Post
-// postsMutex используется для обеспечения потокобезопасности при работе с map posts.
-// Это необходимо, так как к хранилищу могут обращаться несколько горутин одновременно.
-var postsMutex sync.RWMutex
-
-// InitializePosts инициализирует in-memory хранилище тестовыми данными для постов.
-// Данные используются для демонстрационных целей и при отсутствии постоянного хранилища данных.
-func InitializePosts() {
-	posts = make(map[string]*model.Post)
-	ctx := context.Background()
-	store := NewPostStore()
-
-	// Создание и добавление первого тестового поста.
-	post1 := &model.Post{
-		ID:            "1",
-		Title:         "Первый пост",
-		Content:       "Содержание первого поста",
-		Author:        "Автор 1",
-		CreatedAt:     time.Now().Add(time.Hour).Format(time.RFC3339),
-		AllowComments: true, // Разрешены комментарии к посту.
+// newPostEntry - вспомогательная функция подготовки поста к хранению.
+// Дата создания приводится к точности хранения (data.Timestamp), а теги выдаются по алфавиту,
+// как в SQL-хранилищах; пост вызывающего кода не меняется.
+func newPostEntry(post *model.Post) *postEntry {
+	stored := *post
+	stored.CreatedAt = data.Timestamp(post.CreatedAt)
+	if !slices.IsSorted(post.Tags) {
+		stored.Tags = sortedTags(post.Tags)
 	}
-	store.AddPost(ctx, post1)
+	return &postEntry{post: &stored}
+}
 
-	// Создание и добавление второго тестового поста.
-	post2 := &model.Post{
-		ID:            "2",
-		Title:         "Второй пост",
-		Content:       "Содержание второго поста",
-		Author:        "Автор 2",
-		CreatedAt:     time.Now().Format(time.RFC3339),
-		AllowComments: false, // Комментарии к посту запрещены.
+// sortedTags - вспомогательная функция копии тегов, упорядоченной по алфавиту.
+func sortedTags(tags []string) []string {
+	sorted := slices.Clone(tags)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// before - функция порядка постов: более поздняя дата идет раньше, при равных датах - по ID.
+func (e *postEntry) before(other *postEntry) bool {
+	if !e.post.CreatedAt.Equal(other.post.CreatedAt) {
+		return e.post.CreatedAt.After(other.post.CreatedAt)
 	}
-	store.AddPost(ctx, post2)
+	return e.post.ID < other.post.ID
 }
 
 // GetPostByID извлекает пост из in-memory хранилища по его уникальному идентификатору.
 // Возвращает пост и ошибку, если пост с указанным ID не найден.
-func (*PostStore) GetPostByID(ctx context.Context, id string) (*model.Post, error) {
-	postsMutex.RLock() // Устанавливаем блокировку на чтение, так как только читаем данные.
-	defer postsMutex.RUnlock()
+func (s *PostStore) GetPostByID(ctx context.Context, id string) (*model.Post, error) {
+	s.mu.RLock() // Устанавливаем блокировку на чтение, так как только читаем данные.
+	defer s.mu.RUnlock()
 
-	post, ok := posts[id] // Пытаемся получить пост из map по его ID.
+	entry, ok := s.posts[id] // Пытаемся получить пост из map по его ID.
 	if !ok {
 		// Если пост не найден в map, возвращаем ошибку.
-		return nil, fmt.Errorf("post with id %s not found", id)
+		return nil, fmt.Errorf("post with id %s: %w", id, data.ErrNotFound)
 	}
 
-	return post, nil // Возвращаем найденный пост.
+	return entry.post, nil // Возвращаем найденный пост.
 }
 
 // GetPosts получает список постов из in-memory хранилища с поддержкой пагинации.
-// `first` указывает количество постов, которые необходимо вернуть.
+// `first` указывает количество постов, которые необходимо вернуть (0 - все).
 // `afterCursor` используется для пагинации, указывая, с какого элемента начать выборку.
-func (*PostStore) GetPosts(ctx context.Context, first int32, afterCursor *string) (*model.PostConnection, error) {
-	postsMutex.RLock() // Устанавливаем блокировку на чтение для обеспечения конкурентного доступа.
-	defer postsMutex.RUnlock()
+// Посты, не подходящие под фильтр, пропускаются так же, как удаленные.
+func (s *PostStore) GetPosts(ctx context.Context, first int32, afterCursor *string, filter model.PostFilter) (*model.PostConnection, error) {
+	s.mu.RLock() // Устанавливаем блокировку на чтение для обеспечения конкурентного доступа.
+	defer s.mu.RUnlock()
 
-	var validPosts []*model.Post // Слайс для хранения валидных постов (с корректной датой).
+	startIndex := 0 // Начальный индекс для нарезки слайса (для пагинации).
 
-	// Итерируем по всем постам в map.
-	for _, post := range posts {
-		// Проверяем формат даты CreatedAt для каждого поста.
-		_, err := time.Parse(time.RFC3339, post.CreatedAt)
+	// Если указан курсор `afterCursor`, выборка начинается с первого поста, идущего после
+	// записанных в курсоре даты и ID (как и в SQL-хранилищах, сам пост может быть уже удален).
+	if afterCursor != nil && *afterCursor != "" {
+		createdAt, id, err := data.DecodeCursor(*afterCursor)
 		if err != nil {
-			// Если формат даты некорректный, возвращаем ошибку с указанием ID поста и описанием проблемы.
-			return nil, fmt.Errorf("invalid CreatedAt format for post %s: %w", post.ID, err)
+			return nil, err
 		}
+		key := &postEntry{post: &model.Post{ID: id, CreatedAt: createdAt}}
+		startIndex = sort.Search(len(s.ordered), func(i int) bool { return key.before(s.ordered[i]) })
+	}
+
+	// Удаленные посты пропускаются; `first` равное 0 означает все оставшиеся посты.
+	edges := make([]*model.PostEdge, 0)
+	hasNextPage := false
+	for _, entry := range s.ordered[startIndex:] {
+		if entry.post.DeletedAt != nil || !s.matches(entry, filter) {
+			continue
+		}
+		if first > 0 && len(edges) == int(first) {
+			hasNextPage = true // Есть ли следующая страница данных.
+			break
+		}
+		edges = append(edges, &model.PostEdge{Node: entry.post, Cursor: data.EncodeCursor(entry.post.CreatedAt, entry.post.ID)})
+	}
+
+	// Возвращаем структуру PostConnection, содержащую edges и PageInfo (информация о пагинации).
+	return &model.PostConnection{
+		Edges:    edges,
+		PageInfo: &model.PageInfo{HasNextPage: hasNextPage},
+	}, nil
+}
+
+// CountPosts возвращает число неудаленных постов, удовлетворяющих фильтру (см. data.PostStore).
+// Без фильтра используется счетчик visible, с фильтром посты проверяются так же, как в GetPosts.
+func (s *PostStore) CountPosts(ctx context.Context, filter model.PostFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if filter == (model.PostFilter{}) {
+		return s.visible, nil
+	}
+	count := 0
+	for _, entry := range s.ordered {
+		if entry.post.DeletedAt == nil && s.matches(entry, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// matches - вспомогательный метод проверки поста по фильтру; вызывается под блокировкой на чтение.
+func (s *PostStore) matches(entry *postEntry, filter model.PostFilter) bool {
+	switch {
+	case filter.Author != nil && entry.post.Author != *filter.Author,
+		filter.CreatedAfter != nil && !entry.post.CreatedAt.After(*filter.CreatedAfter),
+		filter.CreatedBefore != nil && !entry.post.CreatedAt.Before(*filter.CreatedBefore),
+		filter.AllowComments != nil && entry.post.AllowComments != *filter.AllowComments,
+		filter.Tag != nil && !s.tagged[*filter.Tag][entry.post.ID]:
+		return false
+	case filter.HasComments != nil:
+		return (s.comments != nil && s.comments.hasVisible(entry.post.ID)) == *filter.HasComments
+	}
+	return true
+}
+
+// AddPost добавляет новый пост в in-memory хранилище.
+// В режиме с сохранением на диск пост сначала записывается в журнал. Проверка ID и добавление выполняются
+// под updateMu, поэтому из параллельных добавлений с одним ID успешно только одно, остальные получают ErrConflict.
+func (s *PostStore) AddPost(ctx context.Context, post *model.Post) error {
+	s.pairMu.RLock()
+	defer s.pairMu.RUnlock()
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	entry := newPostEntry(post)
+	if s.exists(post.ID) {
+		return fmt.Errorf("post with id %s: %w", post.ID, data.ErrConflict)
+	}
 
-		validPosts = append(validPosts, post) // Добавляем пост в слайс валидных постов.
+	if s.journal != nil {
+		return s.journal.write(journalRecord{Op: opAddPost, Post: entry.post}, func() { s.insert(entry) })
 	}
 
-	// Сортируем слайс валидных постов по дате создания в обратном порядке (сначала новые).
-	sort.SliceStable(validPosts, func(i, j int) bool {
-		t1, _ := time.Parse(time.RFC3339, validPosts[i].CreatedAt)
-		t2, _ := time.Parse(time.RFC3339, validPosts[j].CreatedAt)
-		return t1.After(t2) // Сортировка: более поздняя дата идет раньше.
+	s.insert(entry)
+	return nil
+}
+
+// UpdatePost применяет изменения к посту (см. data.PostStore).
+// Пост и ревизия не меняются на месте, а заменяются новыми, поэтому уже выданные читателям данные остаются прежними.
+func (s *PostStore) UpdatePost(ctx context.Context, id string, update data.PostUpdate) (*model.Post, error) {
+	return s.change(ctx, id, func(old *model.Post) (*model.Post, *model.Revision, error) {
+		return applyPostUpdate(old, update)
+	})
+}
+
+// DeletePost помечает пост удаленным (см. data.PostStore).
+func (s *PostStore) DeletePost(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Post, error) {
+	return s.change(ctx, id, func(old *model.Post) (*model.Post, *model.Revision, error) {
+		post, err := deletedPost(old, deletedBy, deletedAt)
+		return post, nil, err
 	})
+}
 
-	sortedPosts := validPosts // Используем слайс отсортированных валидных постов.
+// RestorePost снимает с поста отметку об удалении (см. data.PostStore).
+func (s *PostStore) RestorePost(ctx context.Context, id string) (*model.Post, error) {
+	return s.change(ctx, id, func(old *model.Post) (*model.Post, *model.Revision, error) {
+		post, err := restoredPost(old)
+		return post, nil, err
+	})
+}
 
-	startIndex := 0 // Начальный индекс для нарезки слайса (для пагинации).
+// change - вспомогательный метод замены поста версией, построенной функцией next из текущей
+// (вместе с ревизией или nil). В режиме с сохранением на диск новая версия сначала записывается в журнал.
+func (s *PostStore) change(ctx context.Context, id string, next func(old *model.Post) (*model.Post, *model.Revision, error)) (*model.Post, error) {
+	s.pairMu.RLock()
+	defer s.pairMu.RUnlock()
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
 
-	// Если указан курсор `afterCursor`, определяем начальный индекс для следующей страницы.
-	if afterCursor != nil && *afterCursor != "" {
-		afterID := *afterCursor // ID поста, после которого нужно начать выборку.
+	old, err := s.GetPostByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	post, revision, err := next(old)
+	if err != nil {
+		return nil, err
+	}
 
-		// Ищем индекс поста, ID которого соответствует `afterCursor`.
-		for index, post := range sortedPosts {
-			if post.ID == afterID {
-				startIndex = index + 1 // Начинаем со следующего элемента после найденного.
-				break
-			}
+	if s.journal != nil {
+		err = s.journal.write(journalRecord{Op: opUpdatePost, Post: post, Revision: revision}, func() { s.update(post, revision) })
+		if err != nil {
+			return nil, err
 		}
+		return post, nil
 	}
 
-	numPosts := int32(len(sortedPosts) - startIndex) // Количество постов, доступных для выборки, начиная с `startIndex`.
+	if err := s.update(post, revision); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
 
-	// Если запрошенное количество `first` равно 0 или превышает доступное количество,
-	// устанавливаем `first` равным количеству доступных постов.
-	if first == 0 || first > numPosts {
-		first = numPosts
+// GetPostRevisions возвращает прежние версии поста с пагинацией (см. data.PostStore).
+// Для неизвестного поста возвращается пустая страница, как и в SQL-хранилищах.
+func (s *PostStore) GetPostRevisions(ctx context.Context, postID string, first int32, afterCursor *string) (*model.RevisionConnection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var revisions []*model.Revision
+	if entry, ok := s.posts[postID]; ok {
+		revisions = entry.revisions
 	}
+	return revisionPage(revisions, first, afterCursor)
+}
 
-	postSlice := sortedPosts[startIndex : startIndex+int(first)] // Нарезаем слайс постов для текущей страницы.
-	hasNextPage := numPosts > first                             // Определяем, есть ли следующая страница данных.
+// update - вспомогательный метод замены поста новой версией и добавления ревизии (nil - без ревизии).
+// Дата создания не меняется, поэтому пост остается на своем месте в упорядоченном списке.
+func (s *PostStore) update(post *model.Post, revision *model.Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// Возвращаем структуру PostConnection, содержащую edges (границы, в нашем случае - посты) и PageInfo (информация о пагинации).
-	return &model.PostConnection{
-		Edges: func() []*model.PostEdge { // Анонимная функция для создания слайса PostEdge.
-			var edges []*model.PostEdge
+	old, ok := s.posts[post.ID]
+	if !ok {
+		return fmt.Errorf("post with id %s: %w", post.ID, data.ErrNotFound)
+	}
+
+	entry := &postEntry{post: post, revisions: appendRevision(old.revisions, revision)}
+	s.ordered[s.position(old)] = entry
+	s.posts[post.ID] = entry
+	s.visible += visibility(post) - visibility(old.post)
+	adjust(s.authors, post.Author, visibility(post)-visibility(old.post))
+	if post.Title != old.post.Title || post.Content != old.post.Content {
+		s.searchIndex.Add(post.ID, postFields(post)...)
+	}
+	s.retag(post.ID, old.post.Tags, post.Tags)
+	return nil
+}
+
+// retag - вспомогательный метод замены тегов поста в индексе tagged; вызывается под блокировкой.
+func (s *PostStore) retag(id string, old, tags []string) {
+	for _, tag := range old {
+		delete(s.tagged[tag], id)
+		if len(s.tagged[tag]) == 0 {
+			delete(s.tagged, tag)
+		}
+	}
+	for _, tag := range tags {
+		if s.tagged[tag] == nil {
+			s.tagged[tag] = make(map[string]bool)
+		}
+		s.tagged[tag][id] = true
+	}
+}
+
+// GetTags возвращает теги неудаленных постов с числом постов (см. data.PostStore).
+func (s *PostStore) GetTags(ctx context.Context) ([]*model.Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-			// Преобразуем слайс постов в слайс PostEdge.
-			for _, post := range postSlice {
-				edges = append(edges, &model.PostEdge{Node: post}) // Создаем PostEdge для каждого поста.
+	result := make([]*model.Tag, 0, len(s.tagged))
+	for tag, ids := range s.tagged {
+		var count int32
+		for id := range ids {
+			if s.posts[id].post.DeletedAt == nil {
+				count++
 			}
+		}
+		if count > 0 {
+			result = append(result, &model.Tag{Name: tag, PostCount: count})
+		}
+	}
 
-			return edges
-		}(),
-		PageInfo: &model.PageInfo{
-			HasNextPage: hasNextPage, // Устанавливаем флаг HasNextPage в PageInfo.
-		},
-	}, nil
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].PostCount != result[j].PostCount {
+			return result[i].PostCount > result[j].PostCount
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
 }
 
-// AddPost добавляет новый пост в in-memory хранилище.
-func (*PostStore) AddPost(ctx context.Context, post *model.Post) error {
-	postsMutex.Lock() // Устанавливаем блокировку на запись, так как изменяем map posts.
-	defer postsMutex.Unlock()
+// revisions - вспомогательный метод получения ревизий всех постов, у которых они есть (для снимка журнала).
+func (s *PostStore) revisions() map[string][]*model.Revision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := make(map[string][]*model.Revision)
+	for id, entry := range s.posts {
+		if len(entry.revisions) > 0 {
+			revisions[id] = entry.revisions
+		}
+	}
+	return revisions
+}
 
-	posts[post.ID] = post // Добавляем пост в map posts, используя ID поста как ключ.
+// setRevisions - вспомогательный метод восстановления ревизий поста из снимка.
+func (s *PostStore) setRevisions(id string, revisions []*model.Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
+	entry, ok := s.posts[id]
+	if !ok {
+		return fmt.Errorf("revisions of post with id %s: %w", id, data.ErrNotFound)
+	}
+	entry.revisions = revisions
 	return nil
-}
\ No newline at end of file
+}
+
+// purge - вспомогательный метод окончательного удаления постов, помеченных удаленными раньше before.
+// Возвращает ID удаленных постов.
+func (s *PostStore) purge(before time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed []string
+	kept := s.ordered[:0]
+	for _, entry := range s.ordered {
+		if deletedBefore(entry.post.DeletedAt, before) {
+			delete(s.posts, entry.post.ID)
+			s.searchIndex.Remove(entry.post.ID)
+			s.retag(entry.post.ID, entry.post.Tags, nil)
+			removed = append(removed, entry.post.ID)
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	clear(s.ordered[len(kept):])
+	s.ordered = kept
+	return removed
+}
+
+// entry - вспомогательный метод получения записи хранилища для поста.
+func (s *PostStore) entry(id string) (*postEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.posts[id]
+	return entry, ok
+}
+
+// exists - вспомогательный метод проверки, есть ли в хранилище пост с указанным ID.
+func (s *PostStore) exists(id string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.posts[id]
+	return ok
+}
+
+// hasAuthor - вспомогательный метод проверки, есть ли у автора неудаленные посты.
+func (s *PostStore) hasAuthor(author string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.authors[author] > 0
+}
+
+// insert - вспомогательный метод добавления поста в map и упорядоченный список.
+// Пост с уже существующим ID заменяется.
+func (s *PostStore) insert(entry *postEntry) {
+	s.mu.Lock() // Устанавливаем блокировку на запись, так как изменяем данные.
+	defer s.mu.Unlock()
+
+	var oldTags []string
+	if old, ok := s.posts[entry.post.ID]; ok {
+		index := s.position(old)
+		s.ordered = append(s.ordered[:index], s.ordered[index+1:]...)
+		oldTags = old.post.Tags
+		s.visible -= visibility(old.post)
+		adjust(s.authors, old.post.Author, -visibility(old.post))
+	}
+
+	s.posts[entry.post.ID] = entry
+	s.visible += visibility(entry.post)
+	adjust(s.authors, entry.post.Author, visibility(entry.post))
+	s.searchIndex.Add(entry.post.ID, postFields(entry.post)...)
+	s.retag(entry.post.ID, oldTags, entry.post.Tags)
+
+	index := sort.Search(len(s.ordered), func(i int) bool { return entry.before(s.ordered[i]) })
+	s.ordered = append(s.ordered, nil)
+	copy(s.ordered[index+1:], s.ordered[index:])
+	s.ordered[index] = entry
+}
+
+// visibility - вспомогательная функция вклада поста в счетчики visible и authors: 1 для неудаленного поста, иначе 0.
+func visibility(post *model.Post) int {
+	if post.DeletedAt != nil {
+		return 0
+	}
+	return 1
+}
+
+// position - вспомогательный метод поиска индекса поста в упорядоченном списке.
+// Вызывается под блокировкой; пост должен присутствовать в хранилище.
+func (s *PostStore) position(entry *postEntry) int {
+	return sort.Search(len(s.ordered), func(i int) bool { return !s.ordered[i].before(entry) })
+}
+
+// all - вспомогательный метод получения всех постов (для снимка журнала).
+func (s *PostStore) all() []*model.Post {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	posts := make([]*model.Post, len(s.ordered))
+	for i, entry := range s.ordered {
+		posts[i] = entry.post
+	}
+	return posts
+}
+
+// Len возвращает количество постов в хранилище.
+func (s *PostStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.posts)
+}
@@ -15,46 +15,7 @@ func TestNewPostStore(t *testing.T) {
 	}
 }
 
-func setupPostTestEnvironment() {
-	// setupPostTestEnvironment подготавливает тестовое окружение для тестов post store.
-	// В данном случае, инициализирует мапу posts для изоляции тестов.
-	posts = make(map[string]*model.Post)
-}
-
-func TestInitializePosts(t *testing.T) {
-	// TestInitializePosts проверяет функцию InitializePosts, которая инициализирует тестовые посты.
-	InitializePosts()
-
-	// Проверяем, что после инициализации в мапе posts должно быть 2 поста.
-	if len(posts) != 2 {
-		t.Errorf("Expected 2 posts, got %d", len(posts)) // Ожидалось 2 поста, получено %d.
-	}
-
-	// Проверка существования поста с ID "1".
-	post1, exists := posts["1"]
-	if !exists {
-		t.Error("Post with ID 1 not found") // Ошибка, если пост с ID "1" не найден.
-	}
-
-	// Проверка корректности данных поста с ID "1".
-	if post1.Author != "Автор 1" || post1.Title != "Первый пост" || !post1.AllowComments {
-		t.Errorf("Post 1 has incorrect data: %+v", post1) // Ошибка, если данные поста "1" некорректны.
-	}
-
-	// Проверка существования поста с ID "2".
-	post2, exists := posts["2"]
-	if !exists {
-		t.Error("Post with ID 2 not found") // Ошибка, если пост с ID "2" не найден.
-	}
-
-	// Проверка корректности данных поста с ID "2".
-	if post2.Author != "Автор 2" || post2.Title != "Второй пост" || post2.AllowComments {
-		t.Errorf("Post 2 has incorrect data: %+v", post2) // Ошибка, если данные поста "2" некорректны.
-	}
-}
-
 func TestGetPostByID(t *testing.T) {
-	setupPostTestEnvironment() // Настройка тестового окружения для постов.
 	store := NewPostStore()     // Создание нового хранилища постов.
 	ctx := context.Background() // Создание фонового контекста.
 
@@ -64,7 +25,7 @@ func TestGetPostByID(t *testing.T) {
 		Title:         "Тестовый пост",
 		Content:       "Содержание тестового поста",
 		Author:        "Тестовый автор",
-		CreatedAt:     time.Now().Format(time.RFC3339),
+		CreatedAt:     time.Now(),
 		AllowComments: true,
 	}
 
@@ -93,7 +54,6 @@ func TestGetPostByID(t *testing.T) {
 }
 
 func TestAddPost(t *testing.T) {
-	setupPostTestEnvironment() // Настройка тестового окружения для постов.
 	store := NewPostStore()     // Создание нового хранилища постов.
 	ctx := context.Background() // Создание фонового контекста.
 
@@ -103,7 +63,7 @@ func TestAddPost(t *testing.T) {
 		Title:         "Тестовый пост",
 		Content:       "Содержание тестового поста",
 		Author:        "Тестовый автор",
-		CreatedAt:     time.Now().Format(time.RFC3339),
+		CreatedAt:     time.Now(),
 		AllowComments: true,
 	}
 
@@ -114,13 +74,11 @@ func TestAddPost(t *testing.T) {
 	}
 
 	// Проверка, что пост был успешно добавлен в хранилище.
-	postsMutex.RLock()
-	post, exists := posts["123"]
-	postsMutex.RUnlock()
+	post, err := store.GetPostByID(ctx, "123")
 
 	// Проверяем, что пост с ID "123" существует.
-	if !exists {
-		t.Error("Post was not added to the store") // Ошибка, если пост не был добавлен в хранилище.
+	if err != nil {
+		t.Fatal("Post was not added to the store") // Ошибка, если пост не был добавлен в хранилище.
 	}
 
 	// Проверка корректности данных добавленного поста.
@@ -130,7 +88,6 @@ func TestAddPost(t *testing.T) {
 }
 
 func TestGetPosts(t *testing.T) {
-	setupPostTestEnvironment() // Настройка тестового окружения для постов.
 	store := NewPostStore()     // Создание нового хранилища постов.
 	ctx := context.Background() // Создание фонового контекста.
 
@@ -141,14 +98,14 @@ func TestGetPosts(t *testing.T) {
 			Title:         "Заголовок " + string(rune('A'+i)),
 			Content:       "Содержание " + string(rune('A'+i)),
 			Author:        "Автор " + string(rune('A'+i)),
-			CreatedAt:     time.Now().Add(time.Duration(i) * time.Hour).Format(time.RFC3339), // Дата создания поста увеличивается на час с каждым постом.
-			AllowComments: i%2 == 0,                                                                // Четные посты разрешают комментарии, нечетные - нет.
+			CreatedAt:     time.Now().Add(time.Duration(i) * time.Hour), // Дата создания поста увеличивается на час с каждым постом.
+			AllowComments: i%2 == 0,                                     // Четные посты разрешают комментарии, нечетные - нет.
 		}
 		store.AddPost(ctx, post) // Добавление поста в хранилище.
 	}
 
 	// Тест: получение всех постов без пагинации.
-	connection, err := store.GetPosts(ctx, 10, nil)
+	connection, err := store.GetPosts(ctx, 10, nil, model.PostFilter{})
 	if err != nil {
 		t.Fatalf("Failed to get posts: %v", err) // Ошибка при получении постов.
 	}
@@ -160,15 +117,13 @@ func TestGetPosts(t *testing.T) {
 
 	// Проверка сортировки по дате создания (сначала самые новые).
 	for i := 0; i < len(connection.Edges)-1; i++ {
-		time1, _ := time.Parse(time.RFC3339, connection.Edges[i].Node.CreatedAt)
-		time2, _ := time.Parse(time.RFC3339, connection.Edges[i+1].Node.CreatedAt)
-		if !time1.After(time2) {
+		if !connection.Edges[i].Node.CreatedAt.After(connection.Edges[i+1].Node.CreatedAt) {
 			t.Error("Posts are not properly sorted by creation date (newest first)") // Ошибка, если посты не отсортированы по дате создания (сначала новые).
 		}
 	}
 
 	// Тест: пагинация - получение первых 2 постов.
-	connection, err = store.GetPosts(ctx, 2, nil)
+	connection, err = store.GetPosts(ctx, 2, nil, model.PostFilter{})
 	if err != nil {
 		t.Fatalf("Failed to get paginated posts: %v", err) // Ошибка при получении пагинированных постов.
 	}
@@ -184,8 +139,9 @@ func TestGetPosts(t *testing.T) {
 	}
 
 	// Тест: получение постов после курсора.
-	firstPostID := connection.Edges[0].Node.ID // ID первого поста из предыдущего запроса для использования в качестве курсора.
-	connection, err = store.GetPosts(ctx, 2, &firstPostID)
+	firstPostID := connection.Edges[0].Node.ID // ID первого поста из предыдущего запроса.
+	firstCursor := connection.Edges[0].Cursor  // Курсор первого поста для запроса следующей страницы.
+	connection, err = store.GetPosts(ctx, 2, &firstCursor, model.PostFilter{})
 	if err != nil {
 		t.Fatalf("Failed to get posts after cursor: %v", err) // Ошибка при получении постов после курсора.
 	}
@@ -196,25 +152,4 @@ func TestGetPosts(t *testing.T) {
 	} else if connection.Edges[0].Node.ID == firstPostID {
 		t.Error("Expected posts after cursor to exclude the cursor post") // Ошибка, если первый пост после курсора совпадает с курсором.
 	}
-
-	// Тест: обработка поста с некорректным форматом даты.
-	badPost := &model.Post{
-		ID:            "BadDate",
-		Title:         "Пост с некорректной датой",
-		Content:       "Содержание",
-		Author:        "Автор",
-		CreatedAt:     "неправильная дата", // Некорректный формат даты.
-		AllowComments: true,
-	}
-
-	err = store.AddPost(ctx, badPost)
-	if err != nil {
-		t.Fatalf("Failed to add post with bad date: %v", err) // Ошибка при добавлении поста с некорректной датой.
-	}
-
-	// Ожидаем ошибку при получении постов, так как есть пост с некорректной датой.
-	_, err = store.GetPosts(ctx, 10, nil)
-	if err == nil {
-		t.Error("Expected error for post with invalid date format, got nil") // Ошибка, если не получена ошибка при наличии поста с некорректной датой.
-	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,100 @@
+package inmemory
+
+import (
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"time"
+)
+
+// applyPostUpdate - вспомогательная функция построения новой версии поста.
+// Исходный пост не меняется: читатели, уже получившие его, видят согласованные данные.
+// Если меняется заголовок или текст, возвращается также ревизия с прежней версией.
+// Удаленный пост не правится (ErrConflict).
+func applyPostUpdate(old *model.Post, update data.PostUpdate) (*model.Post, *model.Revision, error) {
+	if old.DeletedAt != nil {
+		return nil, nil, fmt.Errorf("post with id %s is deleted: %w", old.ID, data.ErrConflict)
+	}
+
+	post := *old
+	if update.AllowComments != nil {
+		post.AllowComments = *update.AllowComments
+	}
+	if update.Tags != nil {
+		post.Tags = sortedTags(update.Tags)
+	}
+
+	titleChanged := update.Title != nil && *update.Title != old.Title
+	contentChanged := update.Content != nil && *update.Content != old.Content
+	if !titleChanged && !contentChanged {
+		return &post, nil, nil
+	}
+
+	title, content := old.Title, old.Content
+	revision := &model.Revision{Number: old.RevisionCount + 1, Title: &title, Content: &content, CreatedAt: versionCreatedAt(old.CreatedAt, old.EditedAt)}
+	if update.Title != nil {
+		post.Title = *update.Title
+	}
+	if update.Content != nil {
+		post.Content = *update.Content
+	}
+	editedAt := data.Timestamp(update.EditedAt)
+	post.EditedAt = &editedAt
+	post.RevisionCount++
+
+	return &post, revision, nil
+}
+
+// applyCommentUpdate - вспомогательная функция построения новой версии комментария (см. applyPostUpdate).
+func applyCommentUpdate(old *model.Comment, content string, mentions []*model.Mention, editedAt time.Time) (*model.Comment, *model.Revision, error) {
+	if old.DeletedAt != nil {
+		return nil, nil, fmt.Errorf("comment with id %s is deleted: %w", old.ID, data.ErrConflict)
+	}
+
+	comment := *old
+	if content == old.Content {
+		return &comment, nil, nil
+	}
+
+	previous := old.Content
+	revision := &model.Revision{Number: old.RevisionCount + 1, Content: &previous, CreatedAt: versionCreatedAt(old.CreatedAt, old.EditedAt)}
+	comment.Content = content
+	comment.Mentions = storedMentions(mentions)
+	editedAt = data.Timestamp(editedAt)
+	comment.EditedAt = &editedAt
+	comment.RevisionCount++
+
+	return &comment, revision, nil
+}
+
+// versionCreatedAt - вспомогательная функция даты, с которой действует текущая версия: дата последней правки
+// или, если правок не было, дата создания.
+func versionCreatedAt(createdAt time.Time, editedAt *time.Time) time.Time {
+	if editedAt != nil {
+		return *editedAt
+	}
+	return createdAt
+}
+
+// appendRevision - вспомогательная функция добавления ревизии к списку без изменения исходного слайса,
+// который могут читать параллельные запросы.
+func appendRevision(revisions []*model.Revision, revision *model.Revision) []*model.Revision {
+	if revision == nil {
+		return revisions
+	}
+	return append(revisions[:len(revisions):len(revisions)], revision)
+}
+
+// revisionPage - вспомогательная функция выборки страницы ревизий после курсора.
+// Ревизии хранятся по возрастанию номера начиная с 1, поэтому номер из курсора - это индекс начала страницы.
+func revisionPage(revisions []*model.Revision, first int32, afterCursor *string) (*model.RevisionConnection, error) {
+	start := 0
+	if afterCursor != nil && *afterCursor != "" {
+		number, err := data.DecodeRevisionCursor(*afterCursor)
+		if err != nil {
+			return nil, err
+		}
+		start = min(int(number), len(revisions))
+	}
+	return data.RevisionPage(revisions[start:], first), nil
+}
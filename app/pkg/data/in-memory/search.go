@@ -0,0 +1,106 @@
+package inmemory
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/search"
+	"sort"
+	"time"
+)
+
+// Веса слов при ранжировании: совпадение в заголовке поста важнее совпадения в тексте.
+const (
+	titleWeight   = 2.0
+	contentWeight = 1.0
+)
+
+// postFields - вспомогательная функция полей поста для поискового индекса.
+func postFields(post *model.Post) []search.Field {
+	return []search.Field{{Text: post.Title, Weight: titleWeight}, {Text: post.Content, Weight: contentWeight}}
+}
+
+// commentFields - вспомогательная функция полей комментария для поискового индекса.
+func commentFields(comment *model.Comment) []search.Field {
+	return []search.Field{{Text: comment.Content, Weight: contentWeight}}
+}
+
+// Searcher реализует интерфейс data.Searcher для пары in-memory хранилищ.
+// Хранилища поддерживают инвертированные индексы при каждом добавлении, правке и окончательном удалении,
+// поэтому поиск не просматривает все записи.
+type Searcher struct {
+	posts    *PostStore
+	comments *CommentStore
+}
+
+// NewSearcher создает Searcher для пары in-memory хранилищ.
+func NewSearcher(posts *PostStore, comments *CommentStore) *Searcher {
+	return &Searcher{posts: posts, comments: comments}
+}
+
+// searchHit - найденная запись до построения страницы.
+type searchHit struct {
+	node      model.SearchResult
+	id        string
+	createdAt time.Time
+	rank      float64
+	texts     []string // texts - тексты для фрагмента в порядке предпочтения.
+}
+
+// Search ищет посты и комментарии (см. data.Searcher).
+// Порядок: по убыванию ранга, при равном ранге - от новых к старым, затем по ID.
+func (s *Searcher) Search(ctx context.Context, query string, types []model.SearchType, first int32, after *string) (*model.SearchConnection, error) {
+	offset, err := data.SearchOffset(after)
+	if err != nil {
+		return nil, err
+	}
+	terms := search.QueryTerms(query)
+	if first <= 0 || len(terms) == 0 {
+		return data.SearchPage(nil, offset, first), nil
+	}
+
+	var hits []searchHit
+	posts, comments := data.SearchTypes(types)
+	if posts {
+		for id, rank := range s.posts.searchIndex.Match(terms) {
+			entry, ok := s.posts.entry(id)
+			if !ok || entry.post.DeletedAt != nil {
+				continue
+			}
+			hits = append(hits, searchHit{node: entry.post, id: id, createdAt: entry.post.CreatedAt, rank: rank,
+				texts: []string{entry.post.Content, entry.post.Title}})
+		}
+	}
+	if comments {
+		for id, rank := range s.comments.searchIndex.Match(terms) {
+			entry, ok := s.comments.entry(id)
+			if !ok || entry.comment.DeletedAt != nil {
+				continue
+			}
+			// Комментарии удаленного поста скрыты вместе с ним.
+			if post, ok := s.posts.entry(entry.comment.PostID); !ok || post.post.DeletedAt != nil {
+				continue
+			}
+			hits = append(hits, searchHit{node: entry.comment, id: id, createdAt: entry.comment.CreatedAt, rank: rank,
+				texts: []string{entry.comment.Content}})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].rank != hits[j].rank {
+			return hits[i].rank > hits[j].rank
+		}
+		if !hits[i].createdAt.Equal(hits[j].createdAt) {
+			return hits[i].createdAt.After(hits[j].createdAt)
+		}
+		return hits[i].id < hits[j].id
+	})
+
+	// Фрагменты строятся только для записей страницы (и одной лишней, по которой видно следующую страницу).
+	hits = hits[min(offset, len(hits)):min(offset+int(first)+1, len(hits))]
+	edges := make([]*model.SearchEdge, len(hits))
+	for i, hit := range hits {
+		edges[i] = &model.SearchEdge{Node: hit.node, Snippet: search.Snippet(terms, hit.texts...), Rank: hit.rank}
+	}
+	return data.SearchPage(edges, offset, first), nil
+}
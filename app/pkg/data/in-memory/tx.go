@@ -0,0 +1,272 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"time"
+)
+
+// UnitOfWork реализует интерфейс data.UnitOfWork для in-memory хранилищ.
+// Единица работы выполняется под блокировкой пары хранилищ на запись (см. lockPair): другие единицы работы,
+// одиночные записи и окончательное удаление ждут ее завершения. Записи копятся и применяются к хранилищам
+// только после успешного завершения fn; при ошибке они отбрасываются.
+// Внутри единицы работы AddComment проверяет существование поста и родительского комментария,
+// как это делают внешние ключи SQL-хранилищ, поэтому комментарий-сирота не появится.
+type UnitOfWork struct {
+	posts    *PostStore
+	comments *CommentStore
+}
+
+// NewUnitOfWork создает единицу работы для пары in-memory хранилищ.
+func NewUnitOfWork(posts *PostStore, comments *CommentStore) *UnitOfWork {
+	return &UnitOfWork{posts: posts, comments: comments}
+}
+
+// Do выполняет fn под блокировкой пары хранилищ (см. data.UnitOfWork).
+// Чтения по ID видят записи текущей единицы работы, выборки списков - только примененные данные.
+// Записи проверяются при выполнении fn, а до снятия блокировки хранилища не меняются, поэтому
+// записи применяются целиком: в журнал - одной записью opBatch, в память - в порядке выполнения.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(tx data.Tx) error) error {
+	unlock := lockPair(u.posts, u.comments)
+	defer unlock()
+
+	tx := &memTx{}
+	tx.posts = &txPostStore{PostStore: u.posts, tx: tx, pending: make(map[string]*model.Post)}
+	tx.comments = &txCommentStore{CommentStore: u.comments, tx: tx, pending: make(map[string]*model.Comment)}
+	tx.notifications = &txNotificationStore{NotificationStore: u.comments.notifications, tx: tx, pending: make(map[string]bool)}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if len(tx.records) == 0 {
+		return nil
+	}
+
+	batch := journalRecord{Op: opBatch, Records: tx.records}
+	var err error
+	apply := func() { err = applyRecord(u.posts, u.comments, batch) }
+	if journal := u.posts.journal; journal != nil {
+		if writeErr := journal.write(batch, apply); writeErr != nil {
+			return writeErr
+		}
+		return err
+	}
+
+	apply()
+	return err
+}
+
+// lockPair - вспомогательная функция блокировки записей пары хранилищ (см. PostStore.pairMu).
+// У несвязанных хранилищ блокировки разные, и берутся обе: сначала постов, затем комментариев.
+// Возвращает функцию снятия блокировки.
+func lockPair(posts *PostStore, comments *CommentStore) func() {
+	posts.pairMu.Lock()
+	if comments.pairMu == posts.pairMu {
+		return posts.pairMu.Unlock
+	}
+	comments.pairMu.Lock()
+	return func() {
+		comments.pairMu.Unlock()
+		posts.pairMu.Unlock()
+	}
+}
+
+// memTx - хранилища одной единицы работы и отложенные записи.
+type memTx struct {
+	posts         *txPostStore
+	comments      *txCommentStore
+	notifications *txNotificationStore
+	records       []journalRecord // records - записи, применяемые при завершении единицы работы.
+}
+
+// Posts возвращает хранилище постов единицы работы.
+func (t *memTx) Posts() data.PostStore { return t.posts }
+
+// Comments возвращает хранилище комментариев единицы работы.
+func (t *memTx) Comments() data.CommentStore { return t.comments }
+
+// Notifications возвращает хранилище уведомлений единицы работы.
+func (t *memTx) Notifications() data.NotificationStore { return t.notifications }
+
+// txPostStore - хранилище постов единицы работы: записи копятся до ее завершения.
+type txPostStore struct {
+	*PostStore
+	tx      *memTx
+	pending map[string]*model.Post // pending - добавленные или измененные посты по ID.
+}
+
+// GetPostByID возвращает пост с учетом записей единицы работы.
+func (s *txPostStore) GetPostByID(ctx context.Context, id string) (*model.Post, error) {
+	if post, ok := s.pending[id]; ok {
+		return post, nil
+	}
+	return s.PostStore.GetPostByID(ctx, id)
+}
+
+// AddPost откладывает добавление поста до завершения единицы работы.
+func (s *txPostStore) AddPost(ctx context.Context, post *model.Post) error {
+	if s.exists(post.ID) {
+		return fmt.Errorf("post with id %s: %w", post.ID, data.ErrConflict)
+	}
+
+	stored := newPostEntry(post).post
+	s.pending[post.ID] = stored
+	s.tx.records = append(s.tx.records, journalRecord{Op: opAddPost, Post: stored})
+	return nil
+}
+
+// UpdatePost откладывает правку поста до завершения единицы работы и возвращает его новую версию.
+func (s *txPostStore) UpdatePost(ctx context.Context, id string, update data.PostUpdate) (*model.Post, error) {
+	return s.change(ctx, id, func(old *model.Post) (*model.Post, *model.Revision, error) {
+		return applyPostUpdate(old, update)
+	})
+}
+
+// DeletePost откладывает удаление поста до завершения единицы работы и возвращает его новую версию.
+func (s *txPostStore) DeletePost(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Post, error) {
+	return s.change(ctx, id, func(old *model.Post) (*model.Post, *model.Revision, error) {
+		post, err := deletedPost(old, deletedBy, deletedAt)
+		return post, nil, err
+	})
+}
+
+// RestorePost откладывает восстановление поста до завершения единицы работы и возвращает его новую версию.
+func (s *txPostStore) RestorePost(ctx context.Context, id string) (*model.Post, error) {
+	return s.change(ctx, id, func(old *model.Post) (*model.Post, *model.Revision, error) {
+		post, err := restoredPost(old)
+		return post, nil, err
+	})
+}
+
+// change - вспомогательный метод изменения поста в единице работы: новая версия, построенная next
+// из текущей (с учетом записей единицы работы), видна чтениям внутри нее и применяется при ее завершении.
+func (s *txPostStore) change(ctx context.Context, id string, next func(old *model.Post) (*model.Post, *model.Revision, error)) (*model.Post, error) {
+	old, err := s.GetPostByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	post, revision, err := next(old)
+	if err != nil {
+		return nil, err
+	}
+
+	s.pending[id] = post
+	s.tx.records = append(s.tx.records, journalRecord{Op: opUpdatePost, Post: post, Revision: revision})
+	return post, nil
+}
+
+// exists - вспомогательный метод проверки поста в единице работы и в хранилище.
+func (s *txPostStore) exists(id string) bool {
+	_, ok := s.pending[id]
+	return ok || s.PostStore.exists(id)
+}
+
+// txCommentStore - хранилище комментариев единицы работы: записи копятся до ее завершения.
+type txCommentStore struct {
+	*CommentStore
+	tx      *memTx
+	pending map[string]*model.Comment // pending - добавленные или измененные комментарии по ID.
+}
+
+// GetCommentByID возвращает комментарий с учетом записей единицы работы.
+func (s *txCommentStore) GetCommentByID(ctx context.Context, id string) (*model.Comment, error) {
+	if comment, ok := s.pending[id]; ok {
+		return comment, nil
+	}
+	return s.CommentStore.GetCommentByID(ctx, id)
+}
+
+// AddComment откладывает добавление комментария до завершения единицы работы.
+// Пост и родительский комментарий должны существовать, иначе возвращается ошибка data.ErrNotFound.
+func (s *txCommentStore) AddComment(ctx context.Context, comment *model.Comment) error {
+	if s.exists(comment.ID) {
+		return fmt.Errorf("comment with id %s: %w", comment.ID, data.ErrConflict)
+	}
+	if !s.tx.posts.exists(comment.PostID) || (comment.ParentID != nil && !s.exists(*comment.ParentID)) {
+		return fmt.Errorf("comment %s references a missing post or parent comment: %w", comment.ID, data.ErrNotFound)
+	}
+
+	stored := newCommentEntry(comment).comment
+	s.pending[comment.ID] = stored
+	s.tx.records = append(s.tx.records, journalRecord{Op: opAddComment, Comment: stored})
+	return nil
+}
+
+// UpdateComment откладывает правку комментария до завершения единицы работы и возвращает его новую версию.
+func (s *txCommentStore) UpdateComment(ctx context.Context, id, content string, mentions []*model.Mention, editedAt time.Time) (*model.Comment, error) {
+	return s.change(ctx, id, func(old *model.Comment) (*model.Comment, *model.Revision, error) {
+		return applyCommentUpdate(old, content, mentions, editedAt)
+	})
+}
+
+// DeleteComment откладывает удаление комментария до завершения единицы работы и возвращает его новую версию.
+func (s *txCommentStore) DeleteComment(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Comment, error) {
+	return s.change(ctx, id, func(old *model.Comment) (*model.Comment, *model.Revision, error) {
+		comment, err := deletedComment(old, deletedBy, deletedAt)
+		return comment, nil, err
+	})
+}
+
+// RestoreComment откладывает восстановление комментария до завершения единицы работы и возвращает его новую версию.
+func (s *txCommentStore) RestoreComment(ctx context.Context, id string) (*model.Comment, error) {
+	return s.change(ctx, id, func(old *model.Comment) (*model.Comment, *model.Revision, error) {
+		comment, err := restoredComment(old)
+		return comment, nil, err
+	})
+}
+
+// change - вспомогательный метод изменения комментария в единице работы (см. txPostStore.change).
+func (s *txCommentStore) change(ctx context.Context, id string, next func(old *model.Comment) (*model.Comment, *model.Revision, error)) (*model.Comment, error) {
+	old, err := s.GetCommentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	comment, revision, err := next(old)
+	if err != nil {
+		return nil, err
+	}
+
+	s.pending[id] = comment
+	s.tx.records = append(s.tx.records, journalRecord{Op: opUpdateComment, Comment: comment, Revision: revision})
+	return comment, nil
+}
+
+// exists - вспомогательный метод проверки комментария в единице работы и в хранилище.
+func (s *txCommentStore) exists(id string) bool {
+	_, ok := s.pending[id]
+	return ok || s.CommentStore.exists(id)
+}
+
+// txNotificationStore - хранилище уведомлений единицы работы: записи копятся до ее завершения.
+type txNotificationStore struct {
+	*NotificationStore
+	tx      *memTx
+	pending map[string]bool // pending - ID добавленных уведомлений.
+}
+
+// AddNotification откладывает добавление уведомления до завершения единицы работы.
+// Комментарий уведомления должен существовать или быть добавлен в этой же единице работы.
+func (s *txNotificationStore) AddNotification(ctx context.Context, notification *model.Notification) error {
+	if s.pending[notification.ID] || s.exists(notification.ID) {
+		return fmt.Errorf("notification with id %s: %w", notification.ID, data.ErrConflict)
+	}
+	if !s.tx.comments.exists(notification.CommentID) {
+		return fmt.Errorf("notification %s references a missing comment: %w", notification.ID, data.ErrNotFound)
+	}
+
+	s.pending[notification.ID] = true
+	s.tx.records = append(s.tx.records, journalRecord{Op: opAddNotification, Notification: storedNotification(notification)})
+	return nil
+}
+
+// MarkNotificationsRead откладывает отметку уведомлений до завершения единицы работы и возвращает
+// число уведомлений, которые она отметит. Уведомления, добавленные в этой же единице работы, не отмечаются.
+func (s *txNotificationStore) MarkNotificationsRead(ctx context.Context, recipient string, ids []string, readAt time.Time) (int, error) {
+	unread := s.unread(recipient, ids)
+	if len(unread) == 0 {
+		return 0, nil // Пустой список ID в хранилище означал бы все уведомления получателя.
+	}
+	s.tx.records = append(s.tx.records, journalRecord{Op: opReadNotifications, NotificationIDs: unread, ReadAt: data.Timestamp(readAt)})
+	return len(unread), nil
+}
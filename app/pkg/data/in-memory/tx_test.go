@@ -0,0 +1,121 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"testing"
+	"time"
+)
+
+func TestUnitOfWorkExcludesPurge(t *testing.T) {
+	// TestUnitOfWorkExcludesPurge проверяет, что удаление и окончательное удаление поста между проверкой
+	// единицы работы и применением ее записей не оставляют комментарий-сироту.
+	ctx := context.Background()
+	posts, comments := NewStores()
+	now := time.Now()
+	if err := posts.AddPost(ctx, &model.Post{ID: "p1", Author: "Автор", Title: "Пост", Content: "Текст", CreatedAt: now, AllowComments: true}); err != nil {
+		t.Fatalf("Failed to add post: %v", err)
+	}
+
+	checked, purged := make(chan struct{}), make(chan error, 1)
+	go func() {
+		<-checked
+		_, err := posts.DeletePost(ctx, "p1", "Автор", now.Add(-time.Hour))
+		if err == nil {
+			_, _, err = NewPurger(posts, comments).Purge(ctx, now)
+		}
+		purged <- err
+	}()
+
+	err := NewUnitOfWork(posts, comments).Do(ctx, func(tx data.Tx) error {
+		post, err := tx.Posts().GetPostByID(ctx, "p1")
+		if err != nil || post.DeletedAt != nil {
+			return errors.New("post is not available")
+		}
+		if err := tx.Comments().AddComment(ctx, &model.Comment{ID: "c1", PostID: "p1", Author: "А", Content: "Комментарий", CreatedAt: now}); err != nil {
+			return err
+		}
+		close(checked)
+		// Без общей блокировки удаление успело бы выполниться здесь, после проверки поста, но до записи комментария.
+		select {
+		case err := <-purged:
+			purged <- err
+		case <-time.After(50 * time.Millisecond):
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit unit of work: %v", err)
+	}
+	if err := <-purged; err != nil {
+		t.Fatalf("Failed to delete and purge post: %v", err)
+	}
+
+	// Комментарий был добавлен до удаления и окончательно удален вместе с постом.
+	if _, err := posts.GetPostByID(ctx, "p1"); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected purged post, got %v", err)
+	}
+	if _, err := comments.GetCommentByID(ctx, "c1"); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected comment to be purged with its post, got %v", err)
+	}
+}
+
+func TestUnitOfWorkJournal(t *testing.T) {
+	// TestUnitOfWorkJournal проверяет, что записи единицы работы попадают в журнал одной записью и восстанавливаются
+	// после аварийной остановки, а при ошибке записи в журнал ни одна из них не применяется.
+	dir := t.TempDir()
+	ctx := context.Background()
+	now := time.Now()
+	post := &model.Post{ID: "p1", Author: "Автор", Title: "Пост", Content: "Текст", CreatedAt: now, AllowComments: true}
+	comment := &model.Comment{ID: "c1", PostID: "p1", Author: "А", Content: "Комментарий", CreatedAt: now}
+	notification := &model.Notification{ID: "n1", Recipient: "Автор", Kind: model.NotificationKindComment, Actor: "А", PostID: "p1", CommentID: "c1", CreatedAt: now}
+
+	_, posts, comments := openTestJournal(t, dir) // Журнал не закрывается, как при аварийной остановке.
+	err := NewUnitOfWork(posts, comments).Do(ctx, func(tx data.Tx) error {
+		if err := tx.Posts().AddPost(ctx, post); err != nil {
+			return err
+		}
+		if err := tx.Comments().AddComment(ctx, comment); err != nil {
+			return err
+		}
+		if _, err := tx.Comments().UpdateComment(ctx, "c1", "Исправленный комментарий", nil, now); err != nil {
+			return err
+		}
+		return tx.Notifications().AddNotification(ctx, notification)
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit unit of work: %v", err)
+	}
+
+	j, posts, comments := openTestJournal(t, dir)
+	if j.records != 1 {
+		t.Errorf("Expected one journal record for the unit of work, got %d", j.records)
+	}
+	restored, err := comments.GetCommentByID(ctx, "c1")
+	if err != nil || restored.Content != "Исправленный комментарий" || restored.RevisionCount != 1 {
+		t.Errorf("Expected restored comment edit, got %+v (%v)", restored, err)
+	}
+	page, err := comments.Notifications().GetNotifications(ctx, "Автор", 10, nil, false)
+	if err != nil || len(page.Edges) != 1 {
+		t.Errorf("Expected restored notification, got %+v (%v)", page, err)
+	}
+
+	// После закрытия журнала запись не удается, и ни пост, ни комментарий к нему не применяются.
+	if err := j.Close(); err != nil {
+		t.Fatalf("Failed to close journal: %v", err)
+	}
+	err = NewUnitOfWork(posts, comments).Do(ctx, func(tx data.Tx) error {
+		if err := tx.Posts().AddPost(ctx, &model.Post{ID: "p2", Author: "Автор", Title: "Пост", Content: "Текст", CreatedAt: now}); err != nil {
+			return err
+		}
+		return tx.Comments().AddComment(ctx, &model.Comment{ID: "c2", PostID: "p2", Author: "А", Content: "Комментарий", CreatedAt: now})
+	})
+	if err == nil {
+		t.Fatal("Expected an error from a closed journal")
+	}
+	if posts.exists("p2") || comments.exists("c2") {
+		t.Error("Expected no writes of the failed unit of work to be applied")
+	}
+}
@@ -0,0 +1,45 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+)
+
+// EncodeMentions - функция записи упоминаний комментария в JSON для колонки comments.mentions SQL-хранилищ.
+// nil записывается как пустой список, поэтому колонка всегда содержит JSON-массив.
+func EncodeMentions(mentions []*model.Mention) (string, error) {
+	if mentions == nil {
+		mentions = []*model.Mention{}
+	}
+	encoded, err := json.Marshal(mentions)
+	if err != nil {
+		return "", fmt.Errorf("encode mentions: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// DecodeMentions - функция чтения упоминаний, записанных EncodeMentions; для пустого массива - пустой список.
+func DecodeMentions(encoded []byte) ([]*model.Mention, error) {
+	mentions := []*model.Mention{}
+	if err := json.Unmarshal(encoded, &mentions); err != nil {
+		return nil, fmt.Errorf("decode mentions: %w", err)
+	}
+	return mentions, nil
+}
+
+// KeepNames - функция отбора из names имен, найденных хранилищем (found), с сохранением порядка names;
+// используется SQL-реализациями NotificationStore.KnownRecipients. Возвращает пустой, а не nil список.
+func KeepNames(names, found []string) []string {
+	isFound := make(map[string]bool, len(found))
+	for _, name := range found {
+		isFound[name] = true
+	}
+	result := make([]string, 0, len(found))
+	for _, name := range names {
+		if isFound[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -2,174 +2,254 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"graphql-comment-system/app/graph/model"
-	"sort"
+	"graphql-comment-system/app/pkg/data"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// commentColumns - колонки комментария в порядке, который ожидает scanComment.
+const commentColumns = `id, post_id, parent_id, author, content, created_at, edited_at, revision_count, deleted_at, deleted_by, mentions`
+
 // CommentStore struct - структура, реализующая хранилище комментариев.
 type CommentStore struct {
-	conn *pgx.Conn // conn - указатель на соединение с базой данных PostgreSQL.
+	db      querier // db - пул соединений с базой данных PostgreSQL или транзакция единицы работы.
+	rowLock string  // rowLock - блокировка прочитанных по ID строк внутри транзакции (FOR SHARE); пусто вне транзакции.
 }
 
 // NewCommentStore - функция-конструктор для создания нового экземпляра CommentStore.
-func NewCommentStore(conn *pgx.Conn) *CommentStore {
+func NewCommentStore(pool *pgxpool.Pool) *CommentStore {
 	return &CommentStore{
-		conn: conn, // Инициализация хранилища с переданным соединением.
+		db: pool, // Инициализация хранилища с переданным соединением.
 	}
 }
 
 // AddComment - метод для добавления нового комментария в хранилище.
+// Вставка комментария и обновление счетчиков поста и родительского комментария выполняются атомарно:
+// в собственной транзакции или, внутри единицы работы, в точке сохранения ее транзакции.
 func (c *CommentStore) AddComment(ctx context.Context, comment *model.Comment) error {
-	// Выполнение SQL-запроса для вставки нового комментария в таблицу 'comments'.
-	_, err := c.conn.Exec(ctx, `INSERT INTO comments (id, post_id, parent_id, author, content, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
-		comment.ID, comment.PostID, comment.ParentID, comment.Author, comment.Content, comment.CreatedAt)
+	mentions, err := data.EncodeMentions(comment.Mentions)
 	if err != nil {
-		// В случае ошибки при вставке, возвращается ошибка с контекстом.
-		return fmt.Errorf("error inserting comment: %w", err)
+		return storeError(ctx, "error inserting comment", err)
 	}
 
-	return nil // В случае успешного добавления комментария, возвращается nil.
+	var insertErr error // insertErr - ошибка вставки; нарушения ограничений в ней - ошибки данных запроса.
+	err = pgx.BeginFunc(ctx, c.db, func(tx pgx.Tx) error {
+		// Выполнение SQL-запроса для вставки нового комментария в таблицу 'comments'.
+		_, insertErr = tx.Exec(ctx, `INSERT INTO comments (id, post_id, parent_id, author, content, created_at, mentions) VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb)`,
+			comment.ID, comment.PostID, comment.ParentID, comment.Author, comment.Content, data.Timestamp(comment.CreatedAt), mentions)
+		if insertErr != nil {
+			return insertErr
+		}
+
+		// Счетчики обновляются в той же транзакции, поэтому всегда совпадают с числом строк.
+		if _, err := tx.Exec(ctx, `UPDATE posts SET comment_count = comment_count + 1 WHERE id = $1`, comment.PostID); err != nil {
+			return err
+		}
+		if comment.ParentID != nil {
+			if _, err := tx.Exec(ctx, `UPDATE comments SET reply_count = reply_count + 1 WHERE id = $1`, *comment.ParentID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if insertErr != nil {
+		return insertError(ctx, "comment", comment.ID, insertErr)
+	}
+	if err != nil {
+		// В случае ошибки обновления счетчиков или фиксации транзакции, возвращается ошибка с контекстом.
+		return storeError(ctx, "error inserting comment", err)
+	}
+
+	return nil // В случае успеха, возвращается nil.
 }
 
 // GetCommentByID - метод для получения комментария по его уникальному идентификатору.
 func (c *CommentStore) GetCommentByID(ctx context.Context, id string) (*model.Comment, error) {
 	// Выполнение SQL-запроса для выбора комментария из таблицы 'comments' по ID.
-	row := c.conn.QueryRow(ctx, `SELECT id, post_id, parent_id, author, content, created_at FROM comments WHERE id = $1`, id)
-	var comment model.Comment // Объявление переменной для хранения результата запроса.
+	if !isUUID(id) {
+		return nil, fmt.Errorf("comment with id %s: %w", id, data.ErrNotFound)
+	}
+
+	// Внутри транзакции комментарий блокируется от удаления до ее завершения.
+	row := c.db.QueryRow(ctx, `SELECT `+commentColumns+` FROM comments WHERE id = $1`+c.rowLock, id)
 
-	// Сканирование данных из строки результата запроса в структуру comment.
-	err := row.Scan(&comment.ID, &comment.PostID, &comment.ParentID, &comment.Author, &comment.Content, &comment.CreatedAt)
+	// Сканирование данных из строки результата запроса в структуру комментария.
+	comment, err := scanComment(row)
+	if isNotFound(err) {
+		return nil, fmt.Errorf("comment with id %s: %w", id, data.ErrNotFound)
+	}
 	if err != nil {
 		// В случае ошибки при получении комментария, возвращается nil и ошибка с контекстом.
-		return nil, fmt.Errorf("error getting comment by ID: %w", err)
+		return nil, storeError(ctx, "error getting comment by ID", err)
 	}
 
-	return &comment, nil // В случае успеха, возвращается указатель на найденный комментарий и nil.
+	return comment, nil // В случае успеха, возвращается указатель на найденный комментарий и nil.
 }
 
-// GetCommentsForPost - метод для получения комментариев к определенному посту с поддержкой пагинации.
+// GetCommentsForPost - метод для получения комментариев к определенному посту с keyset-пагинацией.
+// Комментарии упорядочены от старых к новым, при равной дате - по ID.
 func (c *CommentStore) GetCommentsForPost(ctx context.Context, postID string, first int32, after *string) (*model.CommentConnection, error) {
-	// Выполнение SQL-запроса для выбора комментариев к посту из таблицы 'comments'.
-	rows, err := c.conn.Query(ctx, `SELECT id, post_id, parent_id, author, content, created_at FROM comments WHERE post_id = $1`, postID)
-	if err != nil {
-		// В случае ошибки при запросе комментариев, возвращается nil и ошибка с контекстом.
-		return nil, fmt.Errorf("error getting comments for post: %w", err)
-	}
+	return c.page(ctx, "error getting comments for post", "post_id", postID, first, after)
+}
 
-	defer rows.Close() // Ensure rows are closed after function execution.
+// GetRepliesForComment - метод для получения ответов на конкретный комментарий (ветка ответов) с keyset-пагинацией.
+func (c *CommentStore) GetRepliesForComment(ctx context.Context, commentID string, first int32, after *string) (*model.CommentConnection, error) {
+	return c.page(ctx, "error getting replies for comment", "parent_id", commentID, first, after)
+}
 
-	var comments []*model.Comment // Объявление слайса для хранения списка комментариев.
+// CountCommentsForPost - метод подсчета неудаленных комментариев поста (см. data.CommentStore).
+func (c *CommentStore) CountCommentsForPost(ctx context.Context, postID string) (int, error) {
+	return c.count(ctx, "error counting comments for post", "post_id", postID)
+}
 
-	// Итерация по каждой строке, возвращенной запросом.
-	for rows.Next() {
-		var comment model.Comment // Объявление переменной для хранения текущего комментария.
+// CountRepliesForComment - метод подсчета неудаленных ответов на комментарий (см. data.CommentStore).
+func (c *CommentStore) CountRepliesForComment(ctx context.Context, commentID string) (int, error) {
+	return c.count(ctx, "error counting replies for comment", "parent_id", commentID)
+}
 
-		// Сканирование данных из текущей строки в структуру comment.
-		err := rows.Scan(&comment.ID, &comment.PostID, &comment.ParentID, &comment.Author, &comment.Content, &comment.CreatedAt)
-		if err != nil {
-			// В случае ошибки при сканировании комментария, возвращается nil и ошибка с контекстом.
-			return nil, fmt.Errorf("error scanning comments: %w", err)
-		}
+// count - вспомогательный метод подсчета неудаленных комментариев, у которых колонка column равна value
+// (запрос использует индексы comments_post_id_idx и comments_parent_id_idx). Внутренние ошибки записываются в лог с сообщением msg.
+func (c *CommentStore) count(ctx context.Context, msg, column, value string) (int, error) {
+	if !isUUID(value) {
+		return 0, nil // У записи с ID не в формате UUID комментариев нет.
+	}
+	var count int
+	if err := c.db.QueryRow(ctx, `SELECT count(*) FROM comments WHERE `+column+` = $1 AND deleted_at IS NULL`, value).Scan(&count); err != nil {
+		return 0, storeError(ctx, msg, err)
+	}
+	return count, nil
+}
 
-		comments = append(comments, &comment) // Добавление сканированного комментария в список.
+// page - вспомогательный метод выборки страницы комментариев, у которых колонка column равна value.
+// `first` равное 0 возвращает пустую страницу; выбирается на одну запись больше, чтобы узнать о следующей странице.
+// Внутренние ошибки записываются в лог с сообщением msg.
+func (c *CommentStore) page(ctx context.Context, msg, column, value string, first int32, after *string) (*model.CommentConnection, error) {
+	if first <= 0 || !isUUID(value) {
+		// Пустая страница: запрошено 0 записей или ID не UUID (у такой записи комментариев нет).
+		return &model.CommentConnection{Edges: []*model.CommentEdge{}, PageInfo: &model.PageInfo{}}, nil
 	}
-	// Сортировка комментариев по дате создания, от старых к новым.
-	sort.Slice(comments, func(i, j int) bool {
-		timeI, err := time.Parse(time.RFC3339, comments[i].CreatedAt) // Парсинг времени создания первого комментария.
-		if err != nil {
-			return false // В случае ошибки парсинга, порядок не меняется.
-		}
 
-		timeJ, err := time.Parse(time.RFC3339, comments[j].CreatedAt) // Парсинг времени создания второго комментария.
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE ` + column + ` = $1 AND deleted_at IS NULL` // Удаленные комментарии не выдаются.
+	args := []any{value}
+
+	if after != nil && *after != "" {
+		createdAt, id, err := data.DecodeCursor(*after)
 		if err != nil {
-			return true // В случае ошибки парсинга, порядок меняется.
+			return nil, err
 		}
+		query += ` AND (created_at > $2 OR (created_at = $2 AND id::text > $3))`
+		args = append(args, createdAt, id)
+	}
 
-		return timeI.Before(timeJ) // Возвращает true, если время создания первого комментария раньше второго.
-	})
+	query += fmt.Sprintf(` ORDER BY created_at ASC, id::text ASC LIMIT $%d`, len(args)+1)
+	args = append(args, first+1)
 
-	var commentEdges []*model.CommentEdge // Объявление слайса для хранения edges комментариев.
+	rows, err := c.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, storeError(ctx, msg, err)
+	}
+	defer rows.Close()
 
-	// Преобразование списка комментариев в список edges для GraphQL Connection.
-	for _, comment := range comments {
-		commentEdges = append(commentEdges, &model.CommentEdge{
-			Node:   comment,    // Установка комментария в качестве ноды edge.
-			Cursor: comment.ID, // Установка ID комментария в качестве курсора edge.
-		})
+	edges := make([]*model.CommentEdge, 0, first)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, storeError(ctx, msg, err)
+		}
+		edges = append(edges, &model.CommentEdge{Node: comment, Cursor: data.EncodeCursor(comment.CreatedAt, comment.ID)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, msg, err)
 	}
 
-	pageInfo := &model.PageInfo{
-		HasNextPage: false, // По умолчанию, предполагается отсутствие следующей страницы.
+	hasNextPage := len(edges) > int(first)
+	if hasNextPage {
+		edges = edges[:first]
 	}
 
 	return &model.CommentConnection{
-		Edges:    commentEdges, // Установка списка edges в Connection.
-		PageInfo: pageInfo,     // Установка PageInfo в Connection.
-	}, nil // Возвращается структура CommentConnection и nil в случае успеха.
+		Edges:    edges,
+		PageInfo: &model.PageInfo{HasNextPage: hasNextPage},
+	}, nil
 }
 
-// GetRepliesForComment - метод для получения ответов на конкретный комментарий (ветка ответов).
-func (c *CommentStore) GetRepliesForComment(ctx context.Context, commentID string, first int32, after *string) (*model.CommentConnection, error) {
-	// Выполнение SQL-запроса для выбора комментариев-ответов на комментарий из таблицы 'comments'.
-	rows, err := c.conn.Query(ctx, `SELECT id, post_id, parent_id, author, content, created_at FROM comments WHERE parent_id = $1`, commentID)
+// UpdateComment - метод замены текста комментария и упоминаний в нем (см. data.CommentStore).
+// Текущая версия блокируется (FOR UPDATE), поэтому комментарий, удаленный параллельным запросом, не будет изменен;
+// она копируется в comment_revisions, если текст меняется, и заменяется новой в одной транзакции
+// или, внутри единицы работы, в точке сохранения.
+func (c *CommentStore) UpdateComment(ctx context.Context, id, content string, mentions []*model.Mention, editedAt time.Time) (*model.Comment, error) {
+	if !isUUID(id) {
+		return nil, fmt.Errorf("comment with id %s: %w", id, data.ErrNotFound)
+	}
+	encoded, err := data.EncodeMentions(mentions)
 	if err != nil {
-		// В случае ошибки при запросе ответов, возвращается nil и ошибка с контекстом.
-		return nil, fmt.Errorf("error getting replies for comment: %w", err)
+		return nil, storeError(ctx, "error updating comment", err)
 	}
 
-	defer rows.Close() // Ensure rows are closed after function execution.
-
-	var comments []*model.Comment // Объявление слайса для хранения списка комментариев-ответов.
-
-	// Итерация по каждой строке, возвращенной запросом.
-	for rows.Next() {
-		var comment model.Comment // Объявление переменной для хранения текущего комментария-ответа.
-
-		// Сканирование данных из текущей строки в структуру comment.
-		err := rows.Scan(&comment.ID, &comment.PostID, &comment.ParentID, &comment.Author, &comment.Content, &comment.CreatedAt)
+	var comment *model.Comment
+	err = pgx.BeginFunc(ctx, c.db, func(tx pgx.Tx) error {
+		old, err := scanComment(tx.QueryRow(ctx, `SELECT `+commentColumns+` FROM comments WHERE id = $1 FOR UPDATE`, id))
 		if err != nil {
-			// В случае ошибки при сканировании комментария-ответа, возвращается nil и ошибка с контекстом.
-			return nil, fmt.Errorf("error scanning comments: %w", err)
+			return err
 		}
-
-		comments = append(comments, &comment) // Добавление сканированного комментария-ответа в список.
-	}
-
-	// Сортировка комментариев-ответов по дате создания, от старых к новым.
-	sort.Slice(comments, func(i, j int) bool {
-		timeI, err := time.Parse(time.RFC3339, comments[i].CreatedAt) // Парсинг времени создания первого комментария-ответа.
-		if err != nil {
-			return false // В случае ошибки парсинга, порядок не меняется.
+		if old.DeletedAt != nil {
+			return fmt.Errorf("comment with id %s is deleted: %w", id, data.ErrConflict)
+		}
+		if old.Content == content {
+			comment = old
+			return nil
 		}
 
-		timeJ, err := time.Parse(time.RFC3339, comments[j].CreatedAt) // Парсинг времени создания второго комментария-ответа.
+		// Прежняя версия действовала с даты последней правки или, если правок не было, с даты создания.
+		_, err = tx.Exec(ctx, `INSERT INTO comment_revisions (comment_id, number, content, created_at)
+			SELECT id, revision_count + 1, content, COALESCE(edited_at, created_at) FROM comments WHERE id = $1`, id)
 		if err != nil {
-			return true // В случае ошибки парсинга, порядок меняется.
+			return err
 		}
 
-		return timeI.Before(timeJ) // Возвращает true, если время создания первого комментария-ответа раньше второго.
+		comment, err = scanComment(tx.QueryRow(ctx, `UPDATE comments SET content = $2, mentions = $3::jsonb, edited_at = $4, revision_count = revision_count + 1
+			WHERE id = $1 RETURNING `+commentColumns, id, content, encoded, data.Timestamp(editedAt)))
+		return err
 	})
+	if isNotFound(err) {
+		return nil, fmt.Errorf("comment with id %s: %w", id, data.ErrNotFound)
+	}
+	if errors.Is(err, data.ErrConflict) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error updating comment", err)
+	}
 
-	var commentEdges []*model.CommentEdge // Объявление слайса для хранения edges комментариев-ответов.
+	return comment, nil
+}
 
-	// Преобразование списка комментариев-ответов в список edges для GraphQL Connection.
-	for _, comment := range comments {
-		commentEdges = append(commentEdges, &model.CommentEdge{
-			Node:   comment,    // Установка комментария-ответа в качестве ноды edge.
-			Cursor: comment.ID, // Установка ID комментария-ответа в качестве курсора edge.
-		})
-	}
+// GetCommentRevisions - метод получения прежних версий комментария с пагинацией (см. data.CommentStore).
+func (c *CommentStore) GetCommentRevisions(ctx context.Context, commentID string, first int32, after *string) (*model.RevisionConnection, error) {
+	return revisionPage(ctx, c.db, "error getting comment revisions",
+		`SELECT number, NULL::text, content, created_at FROM comment_revisions WHERE comment_id = $1 AND number > $2 ORDER BY number LIMIT $3`,
+		commentID, first, after)
+}
 
-	pageInfo := &model.PageInfo{
-		HasNextPage: false, // По умолчанию, предполагается отсутствие следующей страницы.
+// scanComment - вспомогательная функция чтения комментария из строки результата.
+func scanComment(row pgx.Row) (*model.Comment, error) {
+	var comment model.Comment
+	var mentions []byte
+	if err := row.Scan(&comment.ID, &comment.PostID, &comment.ParentID, &comment.Author, &comment.Content, &comment.CreatedAt, &comment.EditedAt,
+		&comment.RevisionCount, &comment.DeletedAt, &comment.DeletedBy, &mentions); err != nil {
+		return nil, err
 	}
-
-	return &model.CommentConnection{
-		Edges:    commentEdges, // Установка списка edges в Connection.
-		PageInfo: pageInfo,     // Установка PageInfo в Connection.
-	}, nil // Возвращается структура CommentConnection и nil в случае успеха.
+	var err error
+	if comment.Mentions, err = data.DecodeMentions(mentions); err != nil {
+		return nil, err
+	}
+	comment.CreatedAt = comment.CreatedAt.UTC()
+	comment.EditedAt = optionalUTC(comment.EditedAt)
+	comment.DeletedAt = optionalUTC(comment.DeletedAt)
+	return &comment, nil
 }
@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeletePost - метод пометки поста удаленным (см. data.PostStore).
+func (p *PostStore) DeletePost(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Post, error) {
+	deletedAt = data.Timestamp(deletedAt)
+	return p.setDeleted(ctx, id, &deletedAt, &deletedBy)
+}
+
+// RestorePost - метод снятия с поста отметки об удалении (см. data.PostStore).
+func (p *PostStore) RestorePost(ctx context.Context, id string) (*model.Post, error) {
+	return p.setDeleted(ctx, id, nil, nil)
+}
+
+// setDeleted - вспомогательный метод установки (deletedAt не nil) или снятия отметки об удалении поста.
+// Строка блокируется (FOR UPDATE), поэтому параллельное удаление того же поста получит ErrConflict.
+func (p *PostStore) setDeleted(ctx context.Context, id string, deletedAt *time.Time, deletedBy *string) (*model.Post, error) {
+	if !isUUID(id) {
+		return nil, fmt.Errorf("post with id %s: %w", id, data.ErrNotFound)
+	}
+
+	var post *model.Post
+	err := pgx.BeginFunc(ctx, p.db, func(tx pgx.Tx) error {
+		old, err := scanPost(tx.QueryRow(ctx, `SELECT `+postColumns+` FROM posts WHERE id = $1 FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+		if err := deletionConflict("post", id, old.DeletedAt != nil, deletedAt != nil); err != nil {
+			return err
+		}
+
+		post, err = scanPost(tx.QueryRow(ctx, `UPDATE posts SET deleted_at = $2, deleted_by = $3 WHERE id = $1 RETURNING `+postColumns,
+			id, deletedAt, deletedBy))
+		return err
+	})
+	if isNotFound(err) {
+		return nil, fmt.Errorf("post with id %s: %w", id, data.ErrNotFound)
+	}
+	if errors.Is(err, data.ErrConflict) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error changing post deletion", err)
+	}
+
+	return post, nil
+}
+
+// DeleteComment - метод пометки комментария удаленным (см. data.CommentStore).
+func (c *CommentStore) DeleteComment(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Comment, error) {
+	deletedAt = data.Timestamp(deletedAt)
+	return c.setDeleted(ctx, id, &deletedAt, &deletedBy)
+}
+
+// RestoreComment - метод снятия с комментария отметки об удалении (см. data.CommentStore).
+func (c *CommentStore) RestoreComment(ctx context.Context, id string) (*model.Comment, error) {
+	return c.setDeleted(ctx, id, nil, nil)
+}
+
+// setDeleted - вспомогательный метод установки (deletedAt не nil) или снятия отметки об удалении комментария.
+// Счетчики комментариев поста и ответов родителя учитывают только видимые комментарии,
+// поэтому меняются в той же транзакции.
+func (c *CommentStore) setDeleted(ctx context.Context, id string, deletedAt *time.Time, deletedBy *string) (*model.Comment, error) {
+	if !isUUID(id) {
+		return nil, fmt.Errorf("comment with id %s: %w", id, data.ErrNotFound)
+	}
+
+	delta := 1 // delta - изменение счетчиков: +1 при восстановлении, -1 при удалении.
+	if deletedAt != nil {
+		delta = -1
+	}
+
+	var comment *model.Comment
+	err := pgx.BeginFunc(ctx, c.db, func(tx pgx.Tx) error {
+		old, err := scanComment(tx.QueryRow(ctx, `SELECT `+commentColumns+` FROM comments WHERE id = $1 FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+		if err := deletionConflict("comment", id, old.DeletedAt != nil, deletedAt != nil); err != nil {
+			return err
+		}
+
+		comment, err = scanComment(tx.QueryRow(ctx, `UPDATE comments SET deleted_at = $2, deleted_by = $3 WHERE id = $1 RETURNING `+commentColumns,
+			id, deletedAt, deletedBy))
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE posts SET comment_count = comment_count + $2 WHERE id = $1`, comment.PostID, delta); err != nil {
+			return err
+		}
+		if comment.ParentID != nil {
+			if _, err := tx.Exec(ctx, `UPDATE comments SET reply_count = reply_count + $2 WHERE id = $1`, *comment.ParentID, delta); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if isNotFound(err) {
+		return nil, fmt.Errorf("comment with id %s: %w", id, data.ErrNotFound)
+	}
+	if errors.Is(err, data.ErrConflict) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error changing comment deletion", err)
+	}
+
+	return comment, nil
+}
+
+// deletionConflict - вспомогательная функция проверки, что запись kind можно удалить (deleting) или восстановить.
+func deletionConflict(kind, id string, deleted, deleting bool) error {
+	if deleting && deleted {
+		return fmt.Errorf("%s with id %s is already deleted: %w", kind, id, data.ErrConflict)
+	}
+	if !deleting && !deleted {
+		return fmt.Errorf("%s with id %s is not deleted: %w", kind, id, data.ErrConflict)
+	}
+	return nil
+}
+
+// Purger - реализация data.Purger для PostgreSQL.
+type Purger struct {
+	db querier // db - пул соединений с базой данных PostgreSQL.
+}
+
+// NewPurger - функция-конструктор, возвращает Purger для пула соединений.
+func NewPurger(pool *pgxpool.Pool) *Purger {
+	return &Purger{db: pool}
+}
+
+// Purge - метод окончательного удаления записей, помеченных удаленными раньше deletedBefore (см. data.Purger).
+// Комментарии постов удаляются каскадно (ON DELETE CASCADE), а удаленные комментарии - только без ответов,
+// чтобы каскад не удалил видимые ответы.
+func (p *Purger) Purge(ctx context.Context, before time.Time) (int, int, error) {
+	var posts, comments int64
+	err := pgx.BeginFunc(ctx, p.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM posts WHERE deleted_at < $1`, before)
+		if err != nil {
+			return err
+		}
+		posts = tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `DELETE FROM comments c WHERE c.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = c.id)`, before)
+		if err != nil {
+			return err
+		}
+		comments = tag.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, 0, storeError(ctx, "error purging deleted records", err)
+	}
+
+	return int(posts), int(comments), nil
+}
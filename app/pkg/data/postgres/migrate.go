@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationsFS - встроенные в бинарный файл SQL-миграции.
+// Файлы именуются как NNNN_описание.sql и применяются по возрастанию номера.
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLockID - ключ advisory-блокировки, чтобы миграции не применялись одновременно несколькими экземплярами.
+const migrationLockID = 7_305_241_001
+
+// migration - одна миграция схемы.
+type migration struct {
+	version int    // version - номер миграции из имени файла.
+	name    string // name - имя файла миграции.
+	sql     string // sql - текст миграции.
+}
+
+// loadMigrations - вспомогательная функция чтения встроенных миграций, отсортированных по номеру.
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	for _, path := range entries {
+		name := strings.TrimPrefix(path, "migrations/")
+		number, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration name %s", name)
+		}
+		version, err := strconv.Atoi(number)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", name, err)
+		}
+
+		raw, err := migrationsFS.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: string(raw)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+// Migrate - функция применения недостающих миграций схемы.
+// Каждая миграция выполняется в отдельной транзакции вместе с записью в schema_migrations.
+// Возвращает номер последней примененной миграции.
+func Migrate(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, fmt.Errorf("error loading migrations: %w", err)
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error acquiring connection for migrations: %w", err)
+	}
+	defer conn.Release()
+
+	// Блокировка на уровне сессии: остальные экземпляры подождут, пока миграции не будут применены.
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return 0, fmt.Errorf("error acquiring migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		return 0, fmt.Errorf("error creating schema_migrations: %w", err)
+	}
+
+	current, err := currentVersion(ctx, conn.Conn())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue // Миграция уже применена.
+		}
+
+		err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, m.sql); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.version, m.name)
+			return err
+		})
+		if err != nil {
+			return current, fmt.Errorf("error applying migration %s: %w", m.name, err)
+		}
+
+		current = m.version
+		slog.InfoContext(ctx, "applied migration", slog.String("migration", m.name))
+	}
+
+	return current, nil
+}
+
+// MigrationVersion - функция получения номера последней примененной миграции.
+// Возвращает 0, если миграции еще не применялись.
+func MigrationVersion(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error acquiring connection: %w", err)
+	}
+	defer conn.Release()
+
+	return currentVersion(ctx, conn.Conn())
+}
+
+// currentVersion - вспомогательная функция чтения максимального номера из schema_migrations.
+func currentVersion(ctx context.Context, conn *pgx.Conn) (int, error) {
+	var version int
+	err := conn.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("error reading migration version: %w", err)
+	}
+	return version, nil
+}
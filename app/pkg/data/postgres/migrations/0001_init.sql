@@ -0,0 +1,33 @@
+-- Начальная схема: посты и древовидные комментарии.
+
+-- Создание таблицы posts
+CREATE TABLE IF NOT EXISTS posts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),              -- UUID в качестве Primary Key, генерируется автоматически
+    author TEXT NOT NULL,                                        -- Имя автора поста (не может быть пустым)
+    title TEXT NOT NULL,                                         -- Заголовок поста (не может быть пустым)
+    content TEXT NOT NULL,                                       -- Содержание поста (не может быть пустым)
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),  -- Дата и время создания, по умолчанию текущее время
+    allow_comments BOOLEAN NOT NULL DEFAULT TRUE                 -- Разрешены ли комментарии к посту, по умолчанию разрешены
+);
+
+-- Индекс по дате создания для таблицы posts (для быстрой сортировки по дате)
+CREATE INDEX IF NOT EXISTS posts_created_at_idx ON posts(created_at);
+
+-- Создание таблицы comments
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),               -- UUID в качестве Primary Key, генерируется автоматически
+    author TEXT NOT NULL,                                        -- Имя автора комментария (не может быть пустым)
+    content TEXT NOT NULL,                                       -- Содержание комментария (не может быть пустым)
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),  -- Дата и время создания, по умолчанию текущее время
+    post_id UUID NOT NULL,                                       -- ID поста, к которому относится комментарий
+    parent_id UUID,                                              -- ID родительского комментария (может быть NULL)
+    -- При удалении поста все связанные комментарии также удаляются
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    -- При удалении родительского комментария все дочерние комментарии также удаляются
+    CONSTRAINT fk_parent_comment FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+-- Индексы для получения комментариев к посту, ответов на комментарий и сортировки по дате
+CREATE INDEX IF NOT EXISTS comments_post_id_idx ON comments(post_id);
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON comments(parent_id);
+CREATE INDEX IF NOT EXISTS comments_created_at_idx ON comments(created_at);
@@ -0,0 +1,9 @@
+-- Счетчики комментариев к посту и ответов на комментарий.
+-- Обновляются в одной транзакции со вставкой комментария.
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS comment_count INTEGER NOT NULL DEFAULT 0;   -- Число комментариев к посту (всех уровней)
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS reply_count INTEGER NOT NULL DEFAULT 0;  -- Число прямых ответов на комментарий
+
+-- Заполнение счетчиков для уже существующих данных
+UPDATE posts p SET comment_count = (SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id);
+UPDATE comments p SET reply_count = (SELECT COUNT(*) FROM comments c WHERE c.parent_id = p.id);
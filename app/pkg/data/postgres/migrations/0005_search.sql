@@ -0,0 +1,21 @@
+-- Полнотекстовый поиск по постам и комментариям.
+-- Конфигурация russian приводит к основе и русские слова (стеммер russian_stem), и английские (english_stem).
+-- Слова заголовка поста имеют вес A, слова текста - вес B, поэтому совпадение в заголовке ранжируется выше.
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', title), 'A') || setweight(to_tsvector('russian', content), 'B')
+) STORED;  -- Основы слов заголовка и текста поста
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', content), 'B')
+) STORED;  -- Основы слов текста комментария
+
+-- GIN-индексы для поиска по основам слов
+CREATE INDEX IF NOT EXISTS posts_search_idx ON posts USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS comments_search_idx ON comments USING GIN (search_vector);
+
+-- Экранирование HTML перед ts_headline: во фрагменте теги есть только у выделенных слов.
+-- Тело в кавычках $$, а не RETURN из стандарта SQL, которое появилось только в PostgreSQL 14.
+CREATE OR REPLACE FUNCTION html_escape(value TEXT) RETURNS TEXT
+    LANGUAGE SQL IMMUTABLE STRICT PARALLEL SAFE
+    AS $$ SELECT replace(replace(replace(value, '&', '&amp;'), '<', '&lt;'), '>', '&gt;') $$;
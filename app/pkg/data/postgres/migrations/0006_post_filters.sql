@@ -0,0 +1,12 @@
+-- Индексы для фильтра списка постов (Query.posts(filter: ...)).
+-- Индексы частичные: список выдает только неудаленные посты. Порядок created_at DESC совпадает
+-- с порядком выдачи, поэтому страница читается из индекса без сортировки всей выборки.
+
+-- Фильтр по автору (с границами дат и курсором по created_at)
+CREATE INDEX IF NOT EXISTS posts_author_created_at_idx ON posts(author, created_at DESC) WHERE deleted_at IS NULL;
+
+-- Фильтр по разрешению комментариев
+CREATE INDEX IF NOT EXISTS posts_allow_comments_created_at_idx ON posts(allow_comments, created_at DESC) WHERE deleted_at IS NULL;
+
+-- Фильтр hasComments: true - по счетчику comment_count
+CREATE INDEX IF NOT EXISTS posts_commented_created_at_idx ON posts(created_at DESC) WHERE deleted_at IS NULL AND comment_count > 0;
@@ -0,0 +1,7 @@
+-- Упоминания @имя в комментариях: список упоминаний известных авторов хранится вместе с комментарием
+-- и заменяется при правке текста. Известные имена - авторы неудаленных постов и комментариев.
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS mentions JSONB NOT NULL DEFAULT '[]'; -- [{"handle", "offset", "length"}]
+
+-- Поиск авторов неудаленных комментариев (KnownRecipients); для постов подходит posts_author_created_at_idx
+CREATE INDEX IF NOT EXISTS comments_author_idx ON comments(author) WHERE deleted_at IS NULL;
@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// notificationColumns - колонки уведомления в порядке, который ожидает scanNotification.
+const notificationColumns = `id, recipient, kind, actor, post_id, comment_id, created_at, read_at`
+
+// NotificationStore struct - структура, реализующая хранилище уведомлений.
+type NotificationStore struct {
+	db querier // db - пул соединений с базой данных PostgreSQL или транзакция единицы работы.
+}
+
+// NewNotificationStore - функция-конструктор для создания нового экземпляра NotificationStore.
+func NewNotificationStore(pool *pgxpool.Pool) *NotificationStore {
+	return &NotificationStore{db: pool}
+}
+
+// AddNotification - метод добавления уведомления (см. data.NotificationStore).
+// Существование комментария проверяет внешний ключ; внутри единицы работы вставка идет в точке сохранения,
+// поэтому нарушение ограничения не прерывает ее транзакцию.
+func (n *NotificationStore) AddNotification(ctx context.Context, notification *model.Notification) error {
+	err := pgx.BeginFunc(ctx, n.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO notifications (`+notificationColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			notification.ID, notification.Recipient, string(notification.Kind), notification.Actor, notification.PostID, notification.CommentID,
+			data.Timestamp(notification.CreatedAt), optionalTimestamp(notification.ReadAt))
+		return err
+	})
+	if err != nil {
+		return insertError(ctx, "notification", notification.ID, err)
+	}
+	return nil
+}
+
+// GetNotifications - метод получения уведомлений получателя с keyset-пагинацией (см. data.NotificationStore).
+// Порядок и курсоры совпадают со списком постов: от новых к старым, при равной дате - по ID.
+func (n *NotificationStore) GetNotifications(ctx context.Context, recipient string, first int32, after *string, unreadOnly bool) (*model.NotificationConnection, error) {
+	if first <= 0 {
+		return &model.NotificationConnection{Edges: []*model.NotificationEdge{}, PageInfo: &model.PageInfo{}}, nil
+	}
+
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE recipient = $1`
+	args := []any{recipient}
+	if unreadOnly {
+		query += ` AND read_at IS NULL` // Условие совпадает с частичным индексом notifications_unread_idx.
+	}
+
+	if after != nil && *after != "" {
+		createdAt, id, err := data.DecodeCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (created_at < $2 OR (created_at = $2 AND id::text > $3))`
+		args = append(args, createdAt, id)
+	}
+
+	query += fmt.Sprintf(` ORDER BY created_at DESC, id::text ASC LIMIT $%d`, len(args)+1)
+	args = append(args, first+1) // На одну запись больше, чтобы узнать, есть ли следующая страница.
+
+	rows, err := n.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, storeError(ctx, "error getting notifications", err)
+	}
+	defer rows.Close()
+
+	edges := make([]*model.NotificationEdge, 0, first)
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, storeError(ctx, "error scanning notifications", err)
+		}
+		edges = append(edges, &model.NotificationEdge{Node: notification, Cursor: data.EncodeCursor(notification.CreatedAt, notification.ID)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, "error getting notifications", err)
+	}
+
+	hasNextPage := len(edges) > int(first)
+	if hasNextPage {
+		edges = edges[:first]
+	}
+
+	return &model.NotificationConnection{
+		Edges:    edges,
+		PageInfo: &model.PageInfo{HasNextPage: hasNextPage},
+	}, nil
+}
+
+// MarkNotificationsRead - метод отметки уведомлений получателя прочитанными (см. data.NotificationStore).
+// ID не в формате UUID пропускаются: таких уведомлений нет.
+func (n *NotificationStore) MarkNotificationsRead(ctx context.Context, recipient string, ids []string, readAt time.Time) (int, error) {
+	query := `UPDATE notifications SET read_at = $1 WHERE recipient = $2 AND read_at IS NULL`
+	args := []any{data.Timestamp(readAt), recipient}
+
+	if ids != nil {
+		valid := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if isUUID(id) {
+				valid = append(valid, id)
+			}
+		}
+		if len(valid) == 0 {
+			return 0, nil
+		}
+		query += ` AND id = ANY($3::uuid[])`
+		args = append(args, valid)
+	}
+
+	tag, err := n.db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, storeError(ctx, "error marking notifications read", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// KnownRecipients - метод отбора имен авторов неудаленных постов и комментариев (см. data.NotificationStore).
+// Условия совпадают с частичными индексами posts_author_created_at_idx и comments_author_idx.
+func (n *NotificationStore) KnownRecipients(ctx context.Context, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return []string{}, nil
+	}
+
+	rows, err := n.db.Query(ctx, `SELECT author FROM posts WHERE author = ANY($1) AND deleted_at IS NULL
+		UNION SELECT author FROM comments WHERE author = ANY($1) AND deleted_at IS NULL`, names)
+	if err != nil {
+		return nil, storeError(ctx, "error getting known recipients", err)
+	}
+	defer rows.Close()
+
+	var known []string
+	for rows.Next() {
+		var author string
+		if err := rows.Scan(&author); err != nil {
+			return nil, storeError(ctx, "error scanning known recipients", err)
+		}
+		known = append(known, author)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, "error getting known recipients", err)
+	}
+	return data.KeepNames(names, known), nil
+}
+
+// optionalTimestamp - вспомогательная функция приведения необязательной даты к точности хранения (nil - даты нет).
+func optionalTimestamp(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	stored := data.Timestamp(*t)
+	return &stored
+}
+
+// scanNotification - вспомогательная функция чтения уведомления из строки результата.
+func scanNotification(row pgx.Row) (*model.Notification, error) {
+	var notification model.Notification
+	var kind string
+	if err := row.Scan(&notification.ID, &notification.Recipient, &kind, &notification.Actor, &notification.PostID,
+		&notification.CommentID, &notification.CreatedAt, &notification.ReadAt); err != nil {
+		return nil, err
+	}
+	notification.Kind = model.NotificationKind(kind)
+	notification.CreatedAt = notification.CreatedAt.UTC()
+	notification.ReadAt = optionalUTC(notification.ReadAt)
+	return &notification, nil
+}
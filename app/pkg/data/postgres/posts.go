@@ -2,34 +2,46 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"graphql-comment-system/app/graph/model"
-	"sort"
-	"time"
+	"graphql-comment-system/app/pkg/data"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// postColumns - колонки поста в порядке, который ожидает scanPost; последняя - теги поста по алфавиту (см. postTags).
+const postColumns = `id, author, title, content, created_at, allow_comments, edited_at, revision_count, deleted_at, deleted_by, ` + postTags
+
 // PostStore struct - структура хранилища постов.
 type PostStore struct {
-	conn *pgx.Conn // conn - это экземпляр подключения к базе данных PostgreSQL.
+	db      querier // db - пул соединений с базой данных PostgreSQL или транзакция единицы работы.
+	rowLock string  // rowLock - блокировка прочитанных по ID строк внутри транзакции (FOR SHARE); пусто вне транзакции.
 }
 
 // NewPostStore - функция-конструктор, возвращает новый экземпляр PostStore.
-func NewPostStore(conn *pgx.Conn) *PostStore {
+func NewPostStore(pool *pgxpool.Pool) *PostStore {
 	return &PostStore{
-		conn: conn, // Инициализация PostStore с переданным подключением к БД.
+		db: pool, // Инициализация PostStore с переданным подключением к БД.
 	}
 }
 
 // AddPost - метод для добавления нового поста в хранилище данных.
 func (p *PostStore) AddPost(ctx context.Context, post *model.Post) error {
-	// SQL-запрос для вставки данных нового поста в таблицу "posts".
-	_, err := p.conn.Exec(ctx, `INSERT INTO posts (id, author, title, content, created_at, allow_comments) VALUES ($1, $2, $3, $4, $5, $6)`,
-		post.ID, post.Author, post.Title, post.Content, post.CreatedAt, post.AllowComments)
+	// Пост и его теги добавляются в одной транзакции или, внутри единицы работы, в точке сохранения.
+	err := pgx.BeginFunc(ctx, p.db, func(tx pgx.Tx) error {
+		// SQL-запрос для вставки данных нового поста в таблицу "posts".
+		_, err := tx.Exec(ctx, `INSERT INTO posts (id, author, title, content, created_at, allow_comments) VALUES ($1, $2, $3, $4, $5, $6)`,
+			post.ID, post.Author, post.Title, post.Content, data.Timestamp(post.CreatedAt), post.AllowComments)
+		if err != nil {
+			return err
+		}
+		return setPostTags(ctx, tx, post.ID, post.Tags)
+	})
 	if err != nil {
-		// В случае ошибки при выполнении SQL-запроса, возвращаем ошибку с форматированием.
-		return fmt.Errorf("error inserting post: %w", err)
+		// Нарушения ограничений переводятся в ошибки пакета data, остальные ошибки - внутренние.
+		return insertError(ctx, "post", post.ID, err)
 	}
 
 	return nil // В случае успешного добавления поста, возвращаем nil (отсутствие ошибки).
@@ -38,77 +50,205 @@ func (p *PostStore) AddPost(ctx context.Context, post *model.Post) error {
 // GetPostByID - метод для получения поста из хранилища данных по его уникальному идентификатору.
 func (p *PostStore) GetPostByID(ctx context.Context, id string) (*model.Post, error) {
 	// SQL-запрос для выбора всех полей поста из таблицы "posts" по заданному ID.
-	row := p.conn.QueryRow(ctx, `SELECT id, author, title, content, created_at, allow_comments FROM posts WHERE id = $1`, id)
-	var post model.Post // Объявляем переменную для хранения данных поста.
+	if !isUUID(id) {
+		return nil, fmt.Errorf("post with id %s: %w", id, data.ErrNotFound)
+	}
+
+	// Внутри транзакции пост блокируется от удаления до ее завершения.
+	row := p.db.QueryRow(ctx, `SELECT `+postColumns+` FROM posts WHERE id = $1`+p.rowLock, id)
 
-	// Сканируем данные из первой строки результата SQL-запроса в структуру 'post'.
-	err := row.Scan(&post.ID, &post.Author, &post.Title, &post.Content, &post.CreatedAt, &post.AllowComments)
+	// Сканируем данные из первой строки результата SQL-запроса в структуру поста.
+	post, err := scanPost(row)
+	if isNotFound(err) {
+		return nil, fmt.Errorf("post with id %s: %w", id, data.ErrNotFound)
+	}
 	if err != nil {
-		// В случае ошибки сканирования данных (например, пост не найден), возвращаем ошибку.
-		return nil, fmt.Errorf("error getting post by ID: %w", err)
+		// В случае ошибки сканирования данных возвращаем внутреннюю ошибку.
+		return nil, storeError(ctx, "error getting post by ID", err)
 	}
 
-	return &post, nil // Возвращаем указатель на структуру 'post' с полученными данными.
+	return post, nil // Возвращаем указатель на структуру 'post' с полученными данными.
 }
 
-// GetPosts - метод для получения списка постов из хранилища данных с поддержкой пагинации.
-func (p *PostStore) GetPosts(ctx context.Context, first int32, after *string) (*model.PostConnection, error) {
-	// SQL-запрос для выбора всех полей из таблицы "posts".
-	rows, err := p.conn.Query(ctx, `SELECT id, author, title, content, created_at, allow_comments FROM posts`)
-	if err != nil {
-		// В случае ошибки выполнения SQL-запроса, возвращаем ошибку.
-		return nil, fmt.Errorf("error getting posts: %w", err)
+// GetPosts - метод для получения списка постов из хранилища данных с keyset-пагинацией.
+// Посты упорядочены от новых к старым, при равной дате - по ID; `first` равное 0 возвращает все посты.
+// Курсор содержит дату создания и ID последнего поста предыдущей страницы (см. data.EncodeCursor).
+// Условия фильтра добавляются к запросу, поэтому курсор продолжает отфильтрованную выборку.
+func (p *PostStore) GetPosts(ctx context.Context, first int32, after *string, filter model.PostFilter) (*model.PostConnection, error) {
+	query := `SELECT ` + postColumns + ` FROM posts WHERE deleted_at IS NULL` // Удаленные посты не выдаются.
+	var args []any
+
+	if after != nil && *after != "" {
+		createdAt, id, err := data.DecodeCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (created_at < $1 OR (created_at = $1 AND id::text > $2))`
+		args = append(args, createdAt, id)
 	}
 
-	defer rows.Close() // Ensure rows are closed after function completion.
+	conditions, filterArgs := postFilterConditions(filter, len(args))
+	query += conditions
+	args = append(args, filterArgs...)
 
-	var posts []*model.Post // Объявляем слайс для хранения указателей на структуры 'Post'.
+	query += ` ORDER BY created_at DESC, id::text ASC`
+	if first > 0 {
+		// Запрашивается на одну запись больше, чтобы узнать, есть ли следующая страница.
+		query += fmt.Sprintf(` LIMIT $%d`, len(args)+1)
+		args = append(args, first+1)
+	}
 
-	// Итерируем по каждой строке, возвращенной SQL-запросом.
-	for rows.Next() {
-		var post model.Post // Объявляем структуру для сканирования данных каждой строки.
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, storeError(ctx, "error getting posts", err)
+	}
+	defer rows.Close()
 
-		// Сканируем данные из текущей строки в структуру 'post'.
-		err := rows.Scan(&post.ID, &post.Author, &post.Title, &post.Content, &post.CreatedAt, &post.AllowComments)
+	edges := make([]*model.PostEdge, 0)
+	for rows.Next() {
+		post, err := scanPost(rows)
 		if err != nil {
-			// В случае ошибки сканирования, возвращаем ошибку.
-			return nil, fmt.Errorf("error scanning posts: %w", err)
+			return nil, storeError(ctx, "error scanning posts", err)
 		}
+		edges = append(edges, &model.PostEdge{Node: post, Cursor: data.EncodeCursor(post.CreatedAt, post.ID)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, "error getting posts", err)
+	}
 
-		posts = append(posts, &post) // Добавляем полученный пост в слайс 'posts'.
+	hasNextPage := first > 0 && len(edges) > int(first)
+	if hasNextPage {
+		edges = edges[:first]
 	}
 
-	// Сортируем слайс постов по дате создания (CreatedAt) в порядке возрастания.
-	sort.Slice(posts, func(i, j int) bool {
-		timeI, err := time.Parse(time.RFC3339, posts[i].CreatedAt) // Преобразуем строку даты в time.Time для поста i.
-		if err != nil {
-			return false // В случае ошибки парсинга даты, не меняем порядок (оставляем как есть).
+	return &model.PostConnection{
+		Edges:    edges,
+		PageInfo: &model.PageInfo{HasNextPage: hasNextPage},
+	}, nil
+}
+
+// CountPosts - метод подсчета неудаленных постов, удовлетворяющих фильтру (см. data.PostStore).
+// Условия те же, что у GetPosts, поэтому COUNT использует те же частичные индексы.
+func (p *PostStore) CountPosts(ctx context.Context, filter model.PostFilter) (int, error) {
+	conditions, args := postFilterConditions(filter, 0)
+	var count int
+	if err := p.db.QueryRow(ctx, `SELECT count(*) FROM posts WHERE deleted_at IS NULL`+conditions, args...).Scan(&count); err != nil {
+		return 0, storeError(ctx, "error counting posts", err)
+	}
+	return count, nil
+}
+
+// postFilterConditions - вспомогательная функция условий WHERE для фильтра постов.
+// Параметры нумеруются после первых argc параметров запроса. Наличие комментариев проверяется
+// по счетчику comment_count, который учитывает только неудаленные комментарии.
+func postFilterConditions(filter model.PostFilter, argc int) (string, []any) {
+	var conditions string
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions += fmt.Sprintf(` AND `+condition, argc+len(args))
+	}
+	if filter.Author != nil {
+		add(`author = $%d`, *filter.Author)
+	}
+	if filter.CreatedAfter != nil {
+		add(`created_at > $%d`, *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		add(`created_at < $%d`, *filter.CreatedBefore)
+	}
+	if filter.AllowComments != nil {
+		add(`allow_comments = $%d`, *filter.AllowComments)
+	}
+	if filter.Tag != nil {
+		add(`EXISTS (SELECT 1 FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = posts.id AND t.name = $%d)`, *filter.Tag)
+	}
+	if filter.HasComments != nil {
+		// Условие без параметра, чтобы планировщик мог использовать частичный индекс по comment_count > 0.
+		if *filter.HasComments {
+			conditions += ` AND comment_count > 0`
+		} else {
+			conditions += ` AND comment_count = 0`
 		}
+	}
+	return conditions, args
+}
+
+// UpdatePost - метод применения изменений к посту (см. data.PostStore).
+// Текущая версия блокируется (FOR UPDATE), поэтому пост, удаленный параллельным запросом, не будет изменен;
+// она копируется в post_revisions, если меняются заголовок или текст, и заменяется новой - все в одной транзакции
+// или, внутри единицы работы, в точке сохранения.
+func (p *PostStore) UpdatePost(ctx context.Context, id string, update data.PostUpdate) (*model.Post, error) {
+	if !isUUID(id) {
+		return nil, fmt.Errorf("post with id %s: %w", id, data.ErrNotFound)
+	}
 
-		timeJ, err := time.Parse(time.RFC3339, posts[j].CreatedAt) // Преобразуем строку даты в time.Time для поста j.
+	var post *model.Post
+	err := pgx.BeginFunc(ctx, p.db, func(tx pgx.Tx) error {
+		old, err := scanPost(tx.QueryRow(ctx, `SELECT `+postColumns+` FROM posts WHERE id = $1 FOR UPDATE`, id))
 		if err != nil {
-			return true // В случае ошибки парсинга даты, меняем порядок (j < i).
+			return err
+		}
+		if old.DeletedAt != nil {
+			return fmt.Errorf("post with id %s is deleted: %w", id, data.ErrConflict)
 		}
 
-		return timeI.Before(timeJ) // Возвращаем true, если дата поста i раньше даты поста j (сортировка по возрастанию).
-	})
+		// Теги заменяются до UPDATE, чтобы RETURNING вернул уже новый список.
+		if update.Tags != nil {
+			if err := setPostTags(ctx, tx, id, update.Tags); err != nil {
+				return err
+			}
+		}
 
-	var postEdges []*model.PostEdge // Объявляем слайс для хранения GraphQL edges для постов.
+		changed := (update.Title != nil && *update.Title != old.Title) || (update.Content != nil && *update.Content != old.Content)
+		if changed {
+			// Прежняя версия действовала с даты последней правки или, если правок не было, с даты создания.
+			_, err := tx.Exec(ctx, `INSERT INTO post_revisions (post_id, number, title, content, created_at)
+				SELECT id, revision_count + 1, title, content, COALESCE(edited_at, created_at) FROM posts WHERE id = $1`, id)
+			if err != nil {
+				return err
+			}
+		}
 
-	// Итерируем по слайсу постов для преобразования в формат GraphQL Connection.
-	for _, post := range posts {
-		postEdges = append(postEdges, &model.PostEdge{ // Создаем новый PostEdge для каждого поста.
-			Node:   post,    // Устанавливаем текущий пост в качестве Node.
-			Cursor: post.ID, // В качестве курсора используем ID поста.
-		})
+		post, err = scanPost(tx.QueryRow(ctx, `UPDATE posts SET
+				title = COALESCE($2, title),
+				content = COALESCE($3, content),
+				allow_comments = COALESCE($4, allow_comments),
+				edited_at = CASE WHEN $5 THEN $6 ELSE edited_at END,
+				revision_count = revision_count + CASE WHEN $5 THEN 1 ELSE 0 END
+			WHERE id = $1 RETURNING `+postColumns,
+			id, update.Title, update.Content, update.AllowComments, changed, data.Timestamp(update.EditedAt)))
+		return err
+	})
+	if isNotFound(err) {
+		return nil, fmt.Errorf("post with id %s: %w", id, data.ErrNotFound)
 	}
-
-	pageInfo := &model.PageInfo{
-		HasNextPage: false, // В текущей реализации пагинация не реализована, HasNextPage всегда false.
+	if errors.Is(err, data.ErrConflict) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error updating post", err)
 	}
 
-	return &model.PostConnection{
-		Edges:    postEdges, // Устанавливаем слайс edges в PostConnection.
-		PageInfo: pageInfo,  // Устанавливаем PageInfo в PostConnection.
-	}, nil
+	return post, nil
+}
+
+// GetPostRevisions - метод получения прежних версий поста с пагинацией (см. data.PostStore).
+func (p *PostStore) GetPostRevisions(ctx context.Context, postID string, first int32, after *string) (*model.RevisionConnection, error) {
+	return revisionPage(ctx, p.db, "error getting post revisions",
+		`SELECT number, title, content, created_at FROM post_revisions WHERE post_id = $1 AND number > $2 ORDER BY number LIMIT $3`,
+		postID, first, after)
+}
+
+// scanPost - вспомогательная функция чтения поста из строки результата.
+func scanPost(row pgx.Row) (*model.Post, error) {
+	var post model.Post
+	if err := row.Scan(&post.ID, &post.Author, &post.Title, &post.Content, &post.CreatedAt, &post.AllowComments, &post.EditedAt, &post.RevisionCount,
+		&post.DeletedAt, &post.DeletedBy, &post.Tags); err != nil {
+		return nil, err
+	}
+	post.CreatedAt = post.CreatedAt.UTC()
+	post.EditedAt = optionalUTC(post.EditedAt)
+	post.DeletedAt = optionalUTC(post.DeletedAt)
+	return &post, nil
 }
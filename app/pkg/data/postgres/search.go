@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/search"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// headlineOptions - параметры ts_headline: найденные слова выделяются так же, как во фрагментах других хранилищ
+// (см. search.MarkStart), фрагмент - один отрывок до 20 слов.
+var headlineOptions = fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=20, MinWords=5, MaxFragments=1", search.MarkStart, search.MarkEnd)
+
+// searchQuery - запрос поиска по колонкам search_vector (миграция 0005_search.sql).
+// $1 - текст запроса, $2 и $3 - искать ли посты и комментарии, $4 и $5 - LIMIT и OFFSET, $6 - параметры ts_headline.
+// Текст экранируется как HTML до ts_headline, поэтому теги во фрагменте - только выделение найденных слов.
+// Фрагмент поста берется из текста, если в нем есть все слова запроса, иначе из заголовка.
+const searchQuery = `WITH q AS (SELECT websearch_to_tsquery('russian', $1) AS query),
+	hits AS (
+		SELECT 'post' AS kind, p.id, ts_rank(p.search_vector, q.query) AS rank, p.created_at
+		FROM posts p, q
+		WHERE $2 AND p.search_vector @@ q.query AND p.deleted_at IS NULL
+		UNION ALL
+		SELECT 'comment', c.id, ts_rank(c.search_vector, q.query), c.created_at
+		FROM comments c JOIN posts p ON p.id = c.post_id, q
+		WHERE $3 AND c.search_vector @@ q.query AND c.deleted_at IS NULL AND p.deleted_at IS NULL
+		ORDER BY rank DESC, created_at DESC, id
+		LIMIT $4 OFFSET $5)
+	SELECT h.kind, h.id::text, h.rank::float8, CASE h.kind
+		WHEN 'post' THEN (SELECT CASE WHEN to_tsvector('russian', p.content) @@ q.query
+				THEN ts_headline('russian', html_escape(p.content), q.query, $6)
+				ELSE ts_headline('russian', html_escape(p.title), q.query, $6) END
+			FROM posts p WHERE p.id = h.id)
+		ELSE (SELECT ts_headline('russian', html_escape(c.content), q.query, $6) FROM comments c WHERE c.id = h.id)
+		END
+	FROM hits h, q
+	ORDER BY h.rank DESC, h.created_at DESC, h.id`
+
+// Searcher - полнотекстовый поиск в базе PostgreSQL по tsvector-колонкам с GIN-индексами, реализует data.Searcher.
+type Searcher struct {
+	db querier // db - пул соединений с базой данных PostgreSQL.
+}
+
+// NewSearcher - функция-конструктор, возвращает Searcher для пула соединений.
+func NewSearcher(pool *pgxpool.Pool) *Searcher {
+	return &Searcher{db: pool}
+}
+
+// searchHit - найденная запись до загрузки поста или комментария.
+type searchHit struct {
+	kind, id, snippet string
+	rank              float64
+}
+
+// Search - метод поиска постов и комментариев (см. data.Searcher).
+// Конфигурация russian приводит к основе и русские, и английские слова; порядок - по убыванию ts_rank,
+// при равном ранге - от новых к старым, затем по ID.
+func (s *Searcher) Search(ctx context.Context, query string, types []model.SearchType, first int32, after *string) (*model.SearchConnection, error) {
+	offset, err := data.SearchOffset(after)
+	if err != nil {
+		return nil, err
+	}
+	posts, comments := data.SearchTypes(types)
+	if first <= 0 || len(search.QueryTerms(query)) == 0 || (!posts && !comments) {
+		return data.SearchPage(nil, offset, first), nil
+	}
+
+	// Запрашивается на одну запись больше, чтобы узнать, есть ли следующая страница.
+	rows, err := s.db.Query(ctx, searchQuery, query, posts, comments, first+1, offset, headlineOptions)
+	if err != nil {
+		return nil, storeError(ctx, "error searching", err)
+	}
+	defer rows.Close()
+
+	var hits []searchHit
+	for rows.Next() {
+		var hit searchHit
+		if err := rows.Scan(&hit.kind, &hit.id, &hit.rank, &hit.snippet); err != nil {
+			return nil, storeError(ctx, "error scanning search hit", err)
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, "error iterating search hits", err)
+	}
+
+	edges, err := s.load(ctx, hits)
+	if err != nil {
+		return nil, err
+	}
+	return data.SearchPage(edges, offset, first), nil
+}
+
+// load - вспомогательный метод загрузки найденных постов и комментариев.
+// Запись, удаленная между поиском и загрузкой, пропускается.
+func (s *Searcher) load(ctx context.Context, hits []searchHit) ([]*model.SearchEdge, error) {
+	posts, comments := &PostStore{db: s.db}, &CommentStore{db: s.db}
+
+	edges := make([]*model.SearchEdge, 0, len(hits))
+	for _, hit := range hits {
+		edge := &model.SearchEdge{Snippet: hit.snippet, Rank: hit.rank}
+		var err error
+		if hit.kind == "post" {
+			edge.Node, err = posts.GetPostByID(ctx, hit.id)
+		} else {
+			edge.Node, err = comments.GetCommentByID(ctx, hit.id)
+		}
+		if errors.Is(err, data.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+	return edges, nil
+}
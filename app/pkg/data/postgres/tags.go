@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+)
+
+// postTags - подзапрос тегов поста для postColumns. Названия упорядочены побайтно (COLLATE "C"),
+// как в остальных хранилищах, независимо от локали базы.
+const postTags = `ARRAY(SELECT t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+	WHERE pt.post_id = posts.id ORDER BY t.name COLLATE "C") AS tags`
+
+// setPostTags - вспомогательная функция замены тегов поста; новые названия добавляются в справочник tags.
+// Вызывается внутри транзакции вместе с записью самого поста.
+func setPostTags(ctx context.Context, db querier, postID string, tags []string) error {
+	if _, err := db.Exec(ctx, `DELETE FROM post_tags WHERE post_id = $1`, postID); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if _, err := db.Exec(ctx, `INSERT INTO tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING`, tags); err != nil {
+		return err
+	}
+	_, err := db.Exec(ctx, `INSERT INTO post_tags (post_id, tag_id) SELECT $1, id FROM tags WHERE name = ANY($2)`, postID, tags)
+	return err
+}
+
+// GetTags - метод получения тегов неудаленных постов с числом постов (см. data.PostStore).
+func (p *PostStore) GetTags(ctx context.Context) ([]*model.Tag, error) {
+	rows, err := p.db.Query(ctx, `SELECT t.name, COUNT(*) FROM tags t
+		JOIN post_tags pt ON pt.tag_id = t.id
+		JOIN posts ON posts.id = pt.post_id AND posts.deleted_at IS NULL
+		GROUP BY t.name
+		ORDER BY COUNT(*) DESC, t.name COLLATE "C"`)
+	if err != nil {
+		return nil, storeError(ctx, "error getting tags", err)
+	}
+	defer rows.Close()
+
+	result := make([]*model.Tag, 0)
+	for rows.Next() {
+		var tag model.Tag
+		if err := rows.Scan(&tag.Name, &tag.PostCount); err != nil {
+			return nil, storeError(ctx, "error scanning tags", err)
+		}
+		result = append(result, &tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, "error getting tags", err)
+	}
+	return result, nil
+}
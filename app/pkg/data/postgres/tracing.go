@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTracer - реализация pgx.QueryTracer, создающая спан OpenTelemetry на каждый SQL-запрос.
+// Текст запроса записывается в атрибут db.statement, значения параметров не записываются.
+type queryTracer struct{}
+
+var _ pgx.QueryTracer = queryTracer{}
+
+// TraceQueryStart - метод, открывающий спан перед выполнением запроса.
+func (queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := strings.ToUpper(firstWord(data.SQL)) // Тип запроса: SELECT, INSERT и т.д.
+
+	ctx, _ = otel.Tracer("graphql-comment-system/postgres").Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", data.SQL),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd - метод, закрывающий спан после выполнения запроса.
+func (queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
+
+// firstWord - вспомогательная функция, возвращающая первое слово SQL-запроса.
+func firstWord(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"graphql-comment-system/app/pkg/data"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// querier - общий интерфейс пула соединений и транзакции pgx, через который работают хранилища.
+// Begin у транзакции создает точку сохранения, поэтому вложенные pgx.BeginFunc безопасны.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// rowLockForShare - блокировка строк, прочитанных по ID внутри транзакции: параллельные транзакции
+// не могут удалить или изменить их, пока транзакция не завершится.
+const rowLockForShare = " FOR SHARE"
+
+// UnitOfWork struct - единица работы поверх транзакции PostgreSQL.
+type UnitOfWork struct {
+	pool *pgxpool.Pool // pool - пул соединений, в котором открываются транзакции.
+}
+
+// NewUnitOfWork - функция-конструктор единицы работы для PostgreSQL.
+func NewUnitOfWork(pool *pgxpool.Pool) *UnitOfWork {
+	return &UnitOfWork{pool: pool}
+}
+
+// Do - метод выполнения fn в транзакции (см. data.UnitOfWork).
+func (u *UnitOfWork) Do(ctx context.Context, fn func(tx data.Tx) error) error {
+	var fnErr error // fnErr - ошибка fn; возвращается без изменений.
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		fnErr = fn(txStores{
+			posts:         &PostStore{db: tx, rowLock: rowLockForShare},
+			comments:      &CommentStore{db: tx, rowLock: rowLockForShare},
+			notifications: &NotificationStore{db: tx},
+		})
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		// Транзакцию не удалось начать или зафиксировать.
+		return storeError(ctx, "error committing transaction", err)
+	}
+
+	return nil
+}
+
+// txStores - хранилища, работающие внутри одной транзакции.
+type txStores struct {
+	posts         *PostStore
+	comments      *CommentStore
+	notifications *NotificationStore
+}
+
+// Posts - метод получения хранилища постов транзакции.
+func (t txStores) Posts() data.PostStore { return t.posts }
+
+// Comments - метод получения хранилища комментариев транзакции.
+func (t txStores) Comments() data.CommentStore { return t.comments }
+
+// Notifications - метод получения хранилища уведомлений транзакции.
+func (t txStores) Notifications() data.NotificationStore { return t.notifications }
@@ -0,0 +1,241 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"time"
+)
+
+// commentColumns - колонки комментария в порядке, который ожидает scanComment.
+const commentColumns = `id, post_id, parent_id, author, content, created_at, edited_at, revision_count, deleted_at, deleted_by, mentions`
+
+// CommentStore - хранилище комментариев в базе SQLite, реализует интерфейс data.CommentStore.
+type CommentStore struct {
+	db querier // db - соединение с базой SQLite или транзакция единицы работы.
+}
+
+// NewCommentStore - функция-конструктор для создания нового экземпляра CommentStore.
+func NewCommentStore(db *sql.DB) *CommentStore {
+	return &CommentStore{db: db}
+}
+
+// AddComment - метод для добавления нового комментария в хранилище.
+// Пост и родительский комментарий должны существовать: это проверяют внешние ключи.
+// Вставка и обновление счетчиков поста и родительского комментария выполняются атомарно.
+func (c *CommentStore) AddComment(ctx context.Context, comment *model.Comment) error {
+	mentions, err := data.EncodeMentions(comment.Mentions)
+	if err != nil {
+		return storeError(ctx, "error inserting comment", err)
+	}
+
+	var insertErr error // insertErr - ошибка вставки; нарушения ограничений в ней - ошибки данных запроса.
+	err = atomically(ctx, c.db, func(q querier) error {
+		_, insertErr = q.ExecContext(ctx, `INSERT INTO comments (id, post_id, parent_id, author, content, created_at, mentions) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			comment.ID, comment.PostID, comment.ParentID, comment.Author, comment.Content, formatTime(comment.CreatedAt), mentions)
+		if insertErr != nil {
+			return insertErr
+		}
+
+		if _, err := q.ExecContext(ctx, `UPDATE posts SET comment_count = comment_count + 1 WHERE id = ?`, comment.PostID); err != nil {
+			return err
+		}
+		if comment.ParentID != nil {
+			if _, err := q.ExecContext(ctx, `UPDATE comments SET reply_count = reply_count + 1 WHERE id = ?`, *comment.ParentID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if insertErr != nil {
+		return insertError(ctx, "comment", comment.ID, insertErr)
+	}
+	if err != nil {
+		return storeError(ctx, "error inserting comment", err)
+	}
+
+	return nil
+}
+
+// GetCommentByID - метод для получения комментария по его уникальному идентификатору.
+func (c *CommentStore) GetCommentByID(ctx context.Context, id string) (*model.Comment, error) {
+	row := c.db.QueryRowContext(ctx, `SELECT `+commentColumns+` FROM comments WHERE id = ?`, id)
+
+	comment, err := scanComment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("comment with id %s: %w", id, data.ErrNotFound)
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error getting comment by ID", err)
+	}
+
+	return comment, nil
+}
+
+// GetCommentsForPost - метод для получения комментариев к определенному посту с keyset-пагинацией.
+// Комментарии упорядочены от старых к новым, при равной дате - по ID.
+func (c *CommentStore) GetCommentsForPost(ctx context.Context, postID string, first int32, after *string) (*model.CommentConnection, error) {
+	return c.page(ctx, "error getting comments for post", "post_id", postID, first, after)
+}
+
+// GetRepliesForComment - метод для получения ответов на конкретный комментарий с keyset-пагинацией.
+func (c *CommentStore) GetRepliesForComment(ctx context.Context, commentID string, first int32, after *string) (*model.CommentConnection, error) {
+	return c.page(ctx, "error getting replies for comment", "parent_id", commentID, first, after)
+}
+
+// CountCommentsForPost - метод подсчета неудаленных комментариев поста (см. data.CommentStore).
+func (c *CommentStore) CountCommentsForPost(ctx context.Context, postID string) (int, error) {
+	return c.count(ctx, "error counting comments for post", "post_id", postID)
+}
+
+// CountRepliesForComment - метод подсчета неудаленных ответов на комментарий (см. data.CommentStore).
+func (c *CommentStore) CountRepliesForComment(ctx context.Context, commentID string) (int, error) {
+	return c.count(ctx, "error counting replies for comment", "parent_id", commentID)
+}
+
+// count - вспомогательный метод подсчета неудаленных комментариев, у которых колонка column равна value.
+// Внутренние ошибки записываются в лог с сообщением msg.
+func (c *CommentStore) count(ctx context.Context, msg, column, value string) (int, error) {
+	var count int
+	if err := c.db.QueryRowContext(ctx, `SELECT count(*) FROM comments WHERE `+column+` = ? AND deleted_at IS NULL`, value).Scan(&count); err != nil {
+		return 0, storeError(ctx, msg, err)
+	}
+	return count, nil
+}
+
+// page - вспомогательный метод выборки страницы комментариев, у которых колонка column равна value.
+// `first` равное 0 возвращает пустую страницу; выбирается на одну запись больше, чтобы узнать о следующей странице.
+// Внутренние ошибки записываются в лог с сообщением msg.
+func (c *CommentStore) page(ctx context.Context, msg, column, value string, first int32, after *string) (*model.CommentConnection, error) {
+	if first <= 0 {
+		return &model.CommentConnection{Edges: []*model.CommentEdge{}, PageInfo: &model.PageInfo{}}, nil
+	}
+
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE ` + column + ` = ? AND deleted_at IS NULL` // Удаленные комментарии не выдаются.
+	args := []any{value}
+
+	if after != nil && *after != "" {
+		createdAt, id, err := data.DecodeCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (created_at > ? OR (created_at = ? AND id > ?))`
+		args = append(args, formatTime(createdAt), formatTime(createdAt), id)
+	}
+
+	query += ` ORDER BY created_at ASC, id ASC LIMIT ?`
+	args = append(args, first+1)
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storeError(ctx, msg, err)
+	}
+	defer rows.Close()
+
+	edges := make([]*model.CommentEdge, 0, first)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, storeError(ctx, msg, err)
+		}
+		edges = append(edges, &model.CommentEdge{Node: comment, Cursor: data.EncodeCursor(comment.CreatedAt, comment.ID)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, msg, err)
+	}
+
+	hasNextPage := len(edges) > int(first)
+	if hasNextPage {
+		edges = edges[:first]
+	}
+
+	return &model.CommentConnection{
+		Edges:    edges,
+		PageInfo: &model.PageInfo{HasNextPage: hasNextPage},
+	}, nil
+}
+
+// UpdateComment - метод замены текста комментария и упоминаний в нем (см. data.CommentStore).
+// Прежняя версия копируется в comment_revisions, если текст меняется, и заменяется новой атомарно.
+// Удаленный комментарий не меняется (ErrConflict).
+func (c *CommentStore) UpdateComment(ctx context.Context, id, content string, mentions []*model.Mention, editedAt time.Time) (*model.Comment, error) {
+	encoded, err := data.EncodeMentions(mentions)
+	if err != nil {
+		return nil, storeError(ctx, "error updating comment", err)
+	}
+
+	var comment *model.Comment
+	err = atomically(ctx, c.db, func(q querier) error {
+		old, err := scanComment(q.QueryRowContext(ctx, `SELECT `+commentColumns+` FROM comments WHERE id = ?`, id))
+		if err != nil {
+			return err
+		}
+		if old.DeletedAt != nil {
+			return fmt.Errorf("comment with id %s is deleted: %w", id, data.ErrConflict)
+		}
+		if old.Content == content {
+			comment = old
+			return nil
+		}
+
+		// Прежняя версия действовала с даты последней правки или, если правок не было, с даты создания.
+		_, err = q.ExecContext(ctx, `INSERT INTO comment_revisions (comment_id, number, content, created_at)
+			SELECT id, revision_count + 1, content, COALESCE(edited_at, created_at) FROM comments WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		comment, err = scanComment(q.QueryRowContext(ctx, `UPDATE comments SET content = ?, mentions = ?, edited_at = ?, revision_count = revision_count + 1
+			WHERE id = ? RETURNING `+commentColumns, content, encoded, formatTime(editedAt), id))
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("comment with id %s: %w", id, data.ErrNotFound)
+	}
+	if errors.Is(err, data.ErrConflict) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error updating comment", err)
+	}
+
+	return comment, nil
+}
+
+// GetCommentRevisions - метод получения прежних версий комментария с пагинацией (см. data.CommentStore).
+func (c *CommentStore) GetCommentRevisions(ctx context.Context, commentID string, first int32, after *string) (*model.RevisionConnection, error) {
+	return revisionPage(ctx, c.db, "error getting comment revisions",
+		`SELECT number, NULL, content, created_at FROM comment_revisions WHERE comment_id = ? AND number > ? ORDER BY number LIMIT ?`,
+		commentID, first, after)
+}
+
+// scanComment - вспомогательная функция чтения комментария из строки результата.
+func scanComment(row scanner) (*model.Comment, error) {
+	var comment model.Comment
+	var createdAt string
+	var editedAt, deletedAt sql.NullString
+	var mentions []byte
+	if err := row.Scan(&comment.ID, &comment.PostID, &comment.ParentID, &comment.Author, &comment.Content, &createdAt, &editedAt, &comment.RevisionCount,
+		&deletedAt, &comment.DeletedBy, &mentions); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if comment.Mentions, err = data.DecodeMentions(mentions); err != nil {
+		return nil, err
+	}
+	if comment.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if comment.EditedAt, err = parseOptionalTime(editedAt); err != nil {
+		return nil, err
+	}
+	if comment.DeletedAt, err = parseOptionalTime(deletedAt); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
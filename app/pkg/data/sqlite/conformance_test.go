@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/data/storetest"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	// TestConformance запускает общий набор тестов хранилищ; каждый тест получает новую базу.
+	storetest.Run(t, func(t *testing.T) (data.PostStore, data.CommentStore) {
+		db := openTestDB(t)
+		return NewPostStore(db), NewCommentStore(db)
+	})
+}
+
+func TestUnitOfWorkConformance(t *testing.T) {
+	// TestUnitOfWorkConformance запускает общий набор тестов единицы работы для SQLite.
+	storetest.RunUnitOfWork(t, func(t *testing.T) (data.PostStore, data.CommentStore, data.UnitOfWork) {
+		db := openTestDB(t)
+		return NewPostStore(db), NewCommentStore(db), NewUnitOfWork(db)
+	})
+}
+
+func TestPurgerConformance(t *testing.T) {
+	// TestPurgerConformance запускает общий набор тестов окончательного удаления для SQLite.
+	storetest.RunPurger(t, func(t *testing.T) (data.PostStore, data.CommentStore, data.Purger) {
+		db := openTestDB(t)
+		return NewPostStore(db), NewCommentStore(db), NewPurger(db)
+	})
+}
+
+func TestSearcherConformance(t *testing.T) {
+	// TestSearcherConformance запускает общий набор тестов полнотекстового поиска для SQLite.
+	storetest.RunSearcher(t, func(t *testing.T) (data.PostStore, data.CommentStore, data.Searcher) {
+		db := openTestDB(t)
+		return NewPostStore(db), NewCommentStore(db), NewSearcher(db)
+	})
+}
+
+func TestNotificationConformance(t *testing.T) {
+	// TestNotificationConformance запускает общий набор тестов уведомлений для SQLite.
+	storetest.RunNotifications(t, func(t *testing.T) (data.PostStore, data.CommentStore, data.NotificationStore, data.Purger) {
+		db := openTestDB(t)
+		return NewPostStore(db), NewCommentStore(db), NewNotificationStore(db), NewPurger(db)
+	})
+}
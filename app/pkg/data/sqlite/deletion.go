@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"time"
+)
+
+// DeletePost - метод пометки поста удаленным (см. data.PostStore).
+func (p *PostStore) DeletePost(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Post, error) {
+	deletedTime := formatTime(deletedAt)
+	return p.setDeleted(ctx, id, &deletedTime, &deletedBy)
+}
+
+// RestorePost - метод снятия с поста отметки об удалении (см. data.PostStore).
+func (p *PostStore) RestorePost(ctx context.Context, id string) (*model.Post, error) {
+	return p.setDeleted(ctx, id, nil, nil)
+}
+
+// setDeleted - вспомогательный метод установки (deletedAt не nil) или снятия отметки об удалении поста.
+func (p *PostStore) setDeleted(ctx context.Context, id string, deletedAt, deletedBy *string) (*model.Post, error) {
+	var post *model.Post
+	err := atomically(ctx, p.db, func(q querier) error {
+		old, err := scanPost(q.QueryRowContext(ctx, `SELECT `+postColumns+` FROM posts WHERE id = ?`, id))
+		if err != nil {
+			return err
+		}
+		if err := deletionConflict("post", id, old.DeletedAt != nil, deletedAt != nil); err != nil {
+			return err
+		}
+
+		post, err = scanPost(q.QueryRowContext(ctx, `UPDATE posts SET deleted_at = ?, deleted_by = ? WHERE id = ? RETURNING `+postColumns,
+			deletedAt, deletedBy, id))
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("post with id %s: %w", id, data.ErrNotFound)
+	}
+	if errors.Is(err, data.ErrConflict) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error changing post deletion", err)
+	}
+
+	return post, nil
+}
+
+// DeleteComment - метод пометки комментария удаленным (см. data.CommentStore).
+func (c *CommentStore) DeleteComment(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Comment, error) {
+	deletedTime := formatTime(deletedAt)
+	return c.setDeleted(ctx, id, &deletedTime, &deletedBy)
+}
+
+// RestoreComment - метод снятия с комментария отметки об удалении (см. data.CommentStore).
+func (c *CommentStore) RestoreComment(ctx context.Context, id string) (*model.Comment, error) {
+	return c.setDeleted(ctx, id, nil, nil)
+}
+
+// setDeleted - вспомогательный метод установки (deletedAt не nil) или снятия отметки об удалении комментария.
+// Счетчики комментариев поста и ответов родителя учитывают только видимые комментарии и меняются атомарно с отметкой.
+func (c *CommentStore) setDeleted(ctx context.Context, id string, deletedAt, deletedBy *string) (*model.Comment, error) {
+	delta := 1 // delta - изменение счетчиков: +1 при восстановлении, -1 при удалении.
+	if deletedAt != nil {
+		delta = -1
+	}
+
+	var comment *model.Comment
+	err := atomically(ctx, c.db, func(q querier) error {
+		old, err := scanComment(q.QueryRowContext(ctx, `SELECT `+commentColumns+` FROM comments WHERE id = ?`, id))
+		if err != nil {
+			return err
+		}
+		if err := deletionConflict("comment", id, old.DeletedAt != nil, deletedAt != nil); err != nil {
+			return err
+		}
+
+		comment, err = scanComment(q.QueryRowContext(ctx, `UPDATE comments SET deleted_at = ?, deleted_by = ? WHERE id = ? RETURNING `+commentColumns,
+			deletedAt, deletedBy, id))
+		if err != nil {
+			return err
+		}
+
+		if _, err := q.ExecContext(ctx, `UPDATE posts SET comment_count = comment_count + ? WHERE id = ?`, delta, comment.PostID); err != nil {
+			return err
+		}
+		if comment.ParentID != nil {
+			if _, err := q.ExecContext(ctx, `UPDATE comments SET reply_count = reply_count + ? WHERE id = ?`, delta, *comment.ParentID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("comment with id %s: %w", id, data.ErrNotFound)
+	}
+	if errors.Is(err, data.ErrConflict) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error changing comment deletion", err)
+	}
+
+	return comment, nil
+}
+
+// deletionConflict - вспомогательная функция проверки, что запись kind можно удалить (deleting) или восстановить.
+func deletionConflict(kind, id string, deleted, deleting bool) error {
+	if deleting && deleted {
+		return fmt.Errorf("%s with id %s is already deleted: %w", kind, id, data.ErrConflict)
+	}
+	if !deleting && !deleted {
+		return fmt.Errorf("%s with id %s is not deleted: %w", kind, id, data.ErrConflict)
+	}
+	return nil
+}
+
+// Purger - реализация data.Purger для SQLite.
+type Purger struct {
+	db *sql.DB // db - соединение с базой SQLite.
+}
+
+// NewPurger - функция-конструктор, возвращает Purger для базы SQLite.
+func NewPurger(db *sql.DB) *Purger {
+	return &Purger{db: db}
+}
+
+// Purge - метод окончательного удаления записей, помеченных удаленными раньше deletedBefore (см. data.Purger).
+// Комментарии постов удаляются каскадно, а удаленные комментарии - только без ответов,
+// чтобы каскад не удалил видимые ответы.
+func (p *Purger) Purge(ctx context.Context, deletedBefore time.Time) (int, int, error) {
+	before := formatTime(deletedBefore)
+
+	var posts, comments int64
+	err := atomically(ctx, p.db, func(q querier) error {
+		// Даты хранятся в формате фиксированной длины (timeFormat), поэтому их можно сравнивать как строки.
+		result, err := q.ExecContext(ctx, `DELETE FROM posts WHERE deleted_at < ?`, before)
+		if err != nil {
+			return err
+		}
+		if posts, err = result.RowsAffected(); err != nil {
+			return err
+		}
+
+		result, err = q.ExecContext(ctx, `DELETE FROM comments WHERE deleted_at < ?
+			AND NOT EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = comments.id)`, before)
+		if err != nil {
+			return err
+		}
+		comments, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, 0, storeError(ctx, "error purging deleted records", err)
+	}
+
+	return int(posts), int(comments), nil
+}
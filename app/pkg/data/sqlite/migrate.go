@@ -0,0 +1,137 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationsFS - встроенные в бинарный файл SQL-миграции.
+// Файлы именуются как NNNN_описание.sql и применяются по возрастанию номера.
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migration - одна миграция схемы.
+type migration struct {
+	version int    // version - номер миграции из имени файла.
+	name    string // name - имя файла миграции.
+	sql     string // sql - текст миграции.
+}
+
+// loadMigrations - вспомогательная функция чтения встроенных миграций, отсортированных по номеру.
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	for _, path := range entries {
+		name := strings.TrimPrefix(path, "migrations/")
+		number, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration name %s", name)
+		}
+		version, err := strconv.Atoi(number)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", name, err)
+		}
+
+		raw, err := migrationsFS.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: string(raw)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+// Migrate - функция применения недостающих миграций схемы.
+// Каждая миграция выполняется в отдельной транзакции вместе с записью в schema_migrations;
+// транзакции открываются с блокировкой на запись, поэтому другие процессы ждут их завершения.
+// Возвращает номер последней примененной миграции.
+func Migrate(ctx context.Context, db *sql.DB) (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, fmt.Errorf("error loading migrations: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+	)`)
+	if err != nil {
+		return 0, fmt.Errorf("error creating schema_migrations: %w", err)
+	}
+
+	current, err := MigrationVersion(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue // Миграция уже применена.
+		}
+
+		if err := applyMigration(ctx, db, m); err != nil {
+			return current, fmt.Errorf("error applying migration %s: %w", m.name, err)
+		}
+
+		current = m.version
+		slog.InfoContext(ctx, "applied migration", slog.String("migration", m.name))
+	}
+
+	return current, nil
+}
+
+// applyMigration - вспомогательная функция применения одной миграции в транзакции.
+// Если миграцию уже применил другой процесс, пока эта транзакция ждала блокировки, она пропускается.
+func applyMigration(ctx context.Context, db *sql.DB, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // После Commit ничего не делает.
+
+	var applied bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)`, m.version).Scan(&applied); err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, m.version, m.name); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MigrationVersion - функция получения номера последней примененной миграции.
+// Возвращает 0, если миграции еще не применялись.
+func MigrationVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("error reading migration version: %w", err)
+	}
+	return version, nil
+}
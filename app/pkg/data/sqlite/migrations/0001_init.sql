@@ -0,0 +1,34 @@
+-- Начальная схема: посты и древовидные комментарии.
+-- Схема повторяет схему PostgreSQL; даты хранятся как текст фиксированной ширины в UTC
+-- (2006-01-02T15:04:05.000000000Z), поэтому строковое сравнение совпадает с хронологическим.
+
+-- Создание таблицы posts
+CREATE TABLE IF NOT EXISTS posts (
+    id TEXT PRIMARY KEY,                         -- Идентификатор поста
+    author TEXT NOT NULL,                        -- Имя автора поста (не может быть пустым)
+    title TEXT NOT NULL,                         -- Заголовок поста (не может быть пустым)
+    content TEXT NOT NULL,                       -- Содержание поста (не может быть пустым)
+    created_at TEXT NOT NULL,                    -- Дата и время создания в UTC
+    allow_comments INTEGER NOT NULL DEFAULT 1    -- Разрешены ли комментарии к посту, по умолчанию разрешены
+);
+
+-- Индекс по дате создания для таблицы posts (для keyset-пагинации от новых к старым)
+CREATE INDEX IF NOT EXISTS posts_created_at_idx ON posts(created_at, id);
+
+-- Создание таблицы comments
+CREATE TABLE IF NOT EXISTS comments (
+    id TEXT PRIMARY KEY,                         -- Идентификатор комментария
+    author TEXT NOT NULL,                        -- Имя автора комментария (не может быть пустым)
+    content TEXT NOT NULL,                       -- Содержание комментария (не может быть пустым)
+    created_at TEXT NOT NULL,                    -- Дата и время создания в UTC
+    post_id TEXT NOT NULL,                       -- ID поста, к которому относится комментарий
+    parent_id TEXT,                              -- ID родительского комментария (может быть NULL)
+    -- При удалении поста все связанные комментарии также удаляются
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    -- При удалении родительского комментария все дочерние комментарии также удаляются
+    CONSTRAINT fk_parent_comment FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+-- Индексы для получения комментариев к посту и ответов на комментарий в порядке создания
+CREATE INDEX IF NOT EXISTS comments_post_id_idx ON comments(post_id, created_at, id);
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON comments(parent_id, created_at, id);
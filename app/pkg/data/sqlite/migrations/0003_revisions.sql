@@ -0,0 +1,30 @@
+-- История правок: прежние версии постов и комментариев.
+-- При правке заголовка или текста прежняя версия копируется в таблицу ревизий в той же транзакции.
+
+ALTER TABLE posts ADD COLUMN edited_at TEXT;                                -- Дата последней правки в UTC (NULL, если правок не было)
+ALTER TABLE posts ADD COLUMN revision_count INTEGER NOT NULL DEFAULT 0;     -- Число сохраненных прежних версий
+ALTER TABLE comments ADD COLUMN edited_at TEXT;                             -- Дата последней правки в UTC (NULL, если правок не было)
+ALTER TABLE comments ADD COLUMN revision_count INTEGER NOT NULL DEFAULT 0;  -- Число сохраненных прежних версий
+
+-- Прежние версии постов; номер 1 - исходная версия
+CREATE TABLE IF NOT EXISTS post_revisions (
+    post_id TEXT NOT NULL,                       -- ID поста
+    number INTEGER NOT NULL,                     -- Номер версии
+    title TEXT NOT NULL,                         -- Заголовок версии
+    content TEXT NOT NULL,                       -- Текст версии
+    created_at TEXT NOT NULL,                    -- Дата, с которой действовала версия, в UTC
+    PRIMARY KEY (post_id, number),
+    -- Ревизии удаляются вместе с постом
+    CONSTRAINT fk_post_revision FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+-- Прежние версии комментариев
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    comment_id TEXT NOT NULL,                    -- ID комментария
+    number INTEGER NOT NULL,                     -- Номер версии
+    content TEXT NOT NULL,                       -- Текст версии
+    created_at TEXT NOT NULL,                    -- Дата, с которой действовала версия, в UTC
+    PRIMARY KEY (comment_id, number),
+    -- Ревизии удаляются вместе с комментарием
+    CONSTRAINT fk_comment_revision FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+);
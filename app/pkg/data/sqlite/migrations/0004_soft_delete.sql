@@ -0,0 +1,11 @@
+-- Мягкое удаление: удаленные посты и комментарии скрываются из списков и окончательно удаляются
+-- после срока хранения, в течение которого модератор может их восстановить.
+
+ALTER TABLE posts ADD COLUMN deleted_at TEXT;     -- Дата удаления в UTC (NULL, если пост не удален)
+ALTER TABLE posts ADD COLUMN deleted_by TEXT;     -- Кто удалил пост
+ALTER TABLE comments ADD COLUMN deleted_at TEXT;  -- Дата удаления в UTC (NULL, если комментарий не удален)
+ALTER TABLE comments ADD COLUMN deleted_by TEXT;  -- Кто удалил комментарий
+
+-- Частичные индексы для поиска записей с истекшим сроком хранения
+CREATE INDEX IF NOT EXISTS posts_deleted_at_idx ON posts(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS comments_deleted_at_idx ON comments(deleted_at) WHERE deleted_at IS NOT NULL;
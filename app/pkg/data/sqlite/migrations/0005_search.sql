@@ -0,0 +1,62 @@
+-- Полнотекстовый поиск по постам и комментариям.
+-- SQLite не умеет приводить русские слова к основе, поэтому слова заранее заменяются основами
+-- функцией search_terms (стеммер Snowball, регистрируется пакетом хранилища), а индекс FTS5
+-- строится уже по основам. Документы поддерживаются триггерами, в том числе при каскадном удалении.
+
+-- Документы поиска: основы слов заголовка и текста поста или текста комментария
+CREATE TABLE IF NOT EXISTS search_documents (
+    id INTEGER PRIMARY KEY,                      -- Идентификатор документа (rowid индекса FTS5)
+    kind TEXT NOT NULL,                          -- Тип записи: post или comment
+    ref TEXT NOT NULL,                           -- ID поста или комментария
+    title TEXT NOT NULL,                         -- Основы слов заголовка (пусто для комментариев)
+    content TEXT NOT NULL,                       -- Основы слов текста
+    UNIQUE (kind, ref)
+);
+
+-- Полнотекстовый индекс по документам (внешнее содержимое - таблица search_documents)
+CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+    title, content, content = 'search_documents', content_rowid = 'id'
+);
+
+-- Синхронизация индекса с документами
+CREATE TRIGGER IF NOT EXISTS search_documents_insert AFTER INSERT ON search_documents BEGIN
+    INSERT INTO search_index (rowid, title, content) VALUES (new.id, new.title, new.content);
+END;
+CREATE TRIGGER IF NOT EXISTS search_documents_delete AFTER DELETE ON search_documents BEGIN
+    INSERT INTO search_index (search_index, rowid, title, content) VALUES ('delete', old.id, old.title, old.content);
+END;
+CREATE TRIGGER IF NOT EXISTS search_documents_update AFTER UPDATE ON search_documents BEGIN
+    INSERT INTO search_index (search_index, rowid, title, content) VALUES ('delete', old.id, old.title, old.content);
+    INSERT INTO search_index (rowid, title, content) VALUES (new.id, new.title, new.content);
+END;
+
+-- Документы постов
+CREATE TRIGGER IF NOT EXISTS posts_search_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO search_documents (kind, ref, title, content)
+    VALUES ('post', new.id, search_terms(new.title), search_terms(new.content));
+END;
+CREATE TRIGGER IF NOT EXISTS posts_search_update AFTER UPDATE OF title, content ON posts BEGIN
+    UPDATE search_documents SET title = search_terms(new.title), content = search_terms(new.content)
+    WHERE kind = 'post' AND ref = new.id;
+END;
+CREATE TRIGGER IF NOT EXISTS posts_search_delete AFTER DELETE ON posts BEGIN
+    DELETE FROM search_documents WHERE kind = 'post' AND ref = old.id;
+END;
+
+-- Документы комментариев
+CREATE TRIGGER IF NOT EXISTS comments_search_insert AFTER INSERT ON comments BEGIN
+    INSERT INTO search_documents (kind, ref, title, content)
+    VALUES ('comment', new.id, '', search_terms(new.content));
+END;
+CREATE TRIGGER IF NOT EXISTS comments_search_update AFTER UPDATE OF content ON comments BEGIN
+    UPDATE search_documents SET content = search_terms(new.content) WHERE kind = 'comment' AND ref = new.id;
+END;
+CREATE TRIGGER IF NOT EXISTS comments_search_delete AFTER DELETE ON comments BEGIN
+    DELETE FROM search_documents WHERE kind = 'comment' AND ref = old.id;
+END;
+
+-- Индексация уже существующих данных
+INSERT INTO search_documents (kind, ref, title, content)
+SELECT 'post', id, search_terms(title), search_terms(content) FROM posts;
+INSERT INTO search_documents (kind, ref, title, content)
+SELECT 'comment', id, '', search_terms(content) FROM comments;
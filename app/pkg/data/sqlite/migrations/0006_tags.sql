@@ -0,0 +1,19 @@
+-- Теги постов: справочник тегов и связь постов с тегами (многие ко многим).
+-- Названия тегов хранятся нормализованными (см. пакет tags), поэтому сравниваются как есть.
+
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY,                      -- ID тега
+    name TEXT NOT NULL UNIQUE                    -- Нормализованное название
+);
+
+CREATE TABLE IF NOT EXISTS post_tags (
+    post_id TEXT NOT NULL,                       -- ID поста
+    tag_id INTEGER NOT NULL,                     -- ID тега
+    PRIMARY KEY (post_id, tag_id),
+    -- Связи удаляются вместе с постом или тегом
+    CONSTRAINT fk_post_tag_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_post_tag_tag FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+-- Посты с тегом (запрос tag и фильтр постов по тегу)
+CREATE INDEX IF NOT EXISTS post_tags_tag_id_idx ON post_tags(tag_id, post_id);
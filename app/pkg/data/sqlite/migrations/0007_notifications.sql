@@ -0,0 +1,20 @@
+-- Уведомления о новых комментариях: автору поста - о комментариях к нему, автору комментария - об ответах.
+-- Получатель - имя автора (отдельной системы пользователей нет). Уведомление удаляется вместе с комментарием.
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id TEXT PRIMARY KEY,                         -- ID уведомления
+    recipient TEXT NOT NULL,                     -- Получатель
+    kind TEXT NOT NULL,                          -- Причина: COMMENT, REPLY или MENTION
+    actor TEXT NOT NULL,                         -- Автор нового комментария
+    post_id TEXT NOT NULL,                       -- ID поста
+    comment_id TEXT NOT NULL,                    -- ID нового комментария
+    created_at TEXT NOT NULL,                    -- Дата создания в UTC
+    read_at TEXT,                                -- Дата прочтения (NULL, если не прочитано)
+    CONSTRAINT fk_notification_comment FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+-- Уведомления получателя от новых к старым; непрочитанные - частичным индексом
+CREATE INDEX IF NOT EXISTS notifications_recipient_idx ON notifications(recipient, created_at DESC, id);
+CREATE INDEX IF NOT EXISTS notifications_unread_idx ON notifications(recipient, created_at DESC, id) WHERE read_at IS NULL;
+-- Каскадное удаление вместе с комментарием
+CREATE INDEX IF NOT EXISTS notifications_comment_id_idx ON notifications(comment_id);
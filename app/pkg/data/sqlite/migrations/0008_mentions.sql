@@ -0,0 +1,8 @@
+-- Упоминания @имя в комментариях: список упоминаний известных авторов хранится вместе с комментарием
+-- и заменяется при правке текста. Известные имена - авторы неудаленных постов и комментариев.
+
+ALTER TABLE comments ADD COLUMN mentions TEXT NOT NULL DEFAULT '[]'; -- JSON: [{"handle", "offset", "length"}]
+
+-- Поиск авторов неудаленных постов и комментариев (KnownRecipients)
+CREATE INDEX IF NOT EXISTS posts_author_idx ON posts(author) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS comments_author_idx ON comments(author) WHERE deleted_at IS NULL;
@@ -0,0 +1,178 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"strings"
+	"time"
+)
+
+// notificationColumns - колонки уведомления в порядке, который ожидает scanNotification.
+const notificationColumns = `id, recipient, kind, actor, post_id, comment_id, created_at, read_at`
+
+// NotificationStore - хранилище уведомлений в базе SQLite, реализует интерфейс data.NotificationStore.
+type NotificationStore struct {
+	db querier // db - соединение с базой SQLite или транзакция единицы работы.
+}
+
+// NewNotificationStore - функция-конструктор для создания нового экземпляра NotificationStore.
+func NewNotificationStore(db *sql.DB) *NotificationStore {
+	return &NotificationStore{db: db}
+}
+
+// AddNotification - метод добавления уведомления (см. data.NotificationStore).
+// Существование комментария проверяет внешний ключ.
+func (n *NotificationStore) AddNotification(ctx context.Context, notification *model.Notification) error {
+	var readAt *string
+	if notification.ReadAt != nil {
+		formatted := formatTime(*notification.ReadAt)
+		readAt = &formatted
+	}
+
+	_, err := n.db.ExecContext(ctx, `INSERT INTO notifications (`+notificationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		notification.ID, notification.Recipient, string(notification.Kind), notification.Actor, notification.PostID, notification.CommentID,
+		formatTime(notification.CreatedAt), readAt)
+	if err != nil {
+		return insertError(ctx, "notification", notification.ID, err)
+	}
+	return nil
+}
+
+// GetNotifications - метод получения уведомлений получателя с keyset-пагинацией (см. data.NotificationStore).
+// Порядок и курсоры совпадают с хранилищем PostgreSQL: от новых к старым, при равной дате - по ID.
+func (n *NotificationStore) GetNotifications(ctx context.Context, recipient string, first int32, after *string, unreadOnly bool) (*model.NotificationConnection, error) {
+	if first <= 0 {
+		return &model.NotificationConnection{Edges: []*model.NotificationEdge{}, PageInfo: &model.PageInfo{}}, nil
+	}
+
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE recipient = ?`
+	args := []any{recipient}
+	if unreadOnly {
+		query += ` AND read_at IS NULL` // Условие совпадает с частичным индексом notifications_unread_idx.
+	}
+
+	if after != nil && *after != "" {
+		createdAt, id, err := data.DecodeCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (created_at < ? OR (created_at = ? AND id > ?))`
+		args = append(args, formatTime(createdAt), formatTime(createdAt), id)
+	}
+
+	query += ` ORDER BY created_at DESC, id ASC LIMIT ?`
+	args = append(args, first+1) // На одну запись больше, чтобы узнать, есть ли следующая страница.
+
+	rows, err := n.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storeError(ctx, "error getting notifications", err)
+	}
+	defer rows.Close()
+
+	edges := make([]*model.NotificationEdge, 0, first)
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, storeError(ctx, "error scanning notifications", err)
+		}
+		edges = append(edges, &model.NotificationEdge{Node: notification, Cursor: data.EncodeCursor(notification.CreatedAt, notification.ID)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, "error getting notifications", err)
+	}
+
+	hasNextPage := len(edges) > int(first)
+	if hasNextPage {
+		edges = edges[:first]
+	}
+
+	return &model.NotificationConnection{
+		Edges:    edges,
+		PageInfo: &model.PageInfo{HasNextPage: hasNextPage},
+	}, nil
+}
+
+// MarkNotificationsRead - метод отметки уведомлений получателя прочитанными (см. data.NotificationStore).
+func (n *NotificationStore) MarkNotificationsRead(ctx context.Context, recipient string, ids []string, readAt time.Time) (int, error) {
+	query := `UPDATE notifications SET read_at = ? WHERE recipient = ? AND read_at IS NULL`
+	args := []any{formatTime(readAt), recipient}
+
+	if ids != nil {
+		if len(ids) == 0 {
+			return 0, nil
+		}
+		query += ` AND id IN (?` + strings.Repeat(`, ?`, len(ids)-1) + `)`
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+
+	result, err := n.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, storeError(ctx, "error marking notifications read", err)
+	}
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return 0, storeError(ctx, "error marking notifications read", err)
+	}
+	return int(marked), nil
+}
+
+// KnownRecipients - метод отбора имен авторов неудаленных постов и комментариев (см. data.NotificationStore).
+// Условия совпадают с частичными индексами posts_author_idx и comments_author_idx.
+func (n *NotificationStore) KnownRecipients(ctx context.Context, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return []string{}, nil
+	}
+
+	list := `(?` + strings.Repeat(`, ?`, len(names)-1) + `)`
+	args := make([]any, 0, 2*len(names))
+	for range 2 {
+		for _, name := range names {
+			args = append(args, name)
+		}
+	}
+	rows, err := n.db.QueryContext(ctx, `SELECT author FROM posts WHERE author IN `+list+` AND deleted_at IS NULL
+		UNION SELECT author FROM comments WHERE author IN `+list+` AND deleted_at IS NULL`, args...)
+	if err != nil {
+		return nil, storeError(ctx, "error getting known recipients", err)
+	}
+	defer rows.Close()
+
+	var known []string
+	for rows.Next() {
+		var author string
+		if err := rows.Scan(&author); err != nil {
+			return nil, storeError(ctx, "error scanning known recipients", err)
+		}
+		known = append(known, author)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, "error getting known recipients", err)
+	}
+	return data.KeepNames(names, known), nil
+}
+
+// scanNotification - вспомогательная функция чтения уведомления из строки результата.
+func scanNotification(row scanner) (*model.Notification, error) {
+	var notification model.Notification
+	var kind, createdAt string
+	var readAt sql.NullString
+	if err := row.Scan(&notification.ID, &notification.Recipient, &kind, &notification.Actor, &notification.PostID,
+		&notification.CommentID, &createdAt, &readAt); err != nil {
+		return nil, err
+	}
+	notification.Kind = model.NotificationKind(kind)
+
+	var err error
+	if notification.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if notification.ReadAt, err = parseOptionalTime(readAt); err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
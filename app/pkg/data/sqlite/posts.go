@@ -0,0 +1,251 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+)
+
+// postColumns - колонки поста в порядке, который ожидает scanPost; последняя - теги поста по алфавиту (см. postTags).
+const postColumns = `id, author, title, content, created_at, allow_comments, edited_at, revision_count, deleted_at, deleted_by, ` + postTags
+
+// PostStore - хранилище постов в базе SQLite, реализует интерфейс data.PostStore.
+type PostStore struct {
+	db querier // db - соединение с базой SQLite или транзакция единицы работы.
+}
+
+// NewPostStore - функция-конструктор, возвращает новый экземпляр PostStore.
+func NewPostStore(db *sql.DB) *PostStore {
+	return &PostStore{db: db}
+}
+
+// AddPost - метод для добавления нового поста в хранилище данных.
+func (p *PostStore) AddPost(ctx context.Context, post *model.Post) error {
+	// Пост и его теги добавляются атомарно.
+	err := atomically(ctx, p.db, func(q querier) error {
+		_, err := q.ExecContext(ctx, `INSERT INTO posts (id, author, title, content, created_at, allow_comments) VALUES (?, ?, ?, ?, ?, ?)`,
+			post.ID, post.Author, post.Title, post.Content, formatTime(post.CreatedAt), post.AllowComments)
+		if err != nil {
+			return err
+		}
+		return setPostTags(ctx, q, post.ID, post.Tags)
+	})
+	if err != nil {
+		return insertError(ctx, "post", post.ID, err)
+	}
+
+	return nil
+}
+
+// GetPostByID - метод для получения поста из хранилища данных по его уникальному идентификатору.
+func (p *PostStore) GetPostByID(ctx context.Context, id string) (*model.Post, error) {
+	row := p.db.QueryRowContext(ctx, `SELECT `+postColumns+` FROM posts WHERE id = ?`, id)
+
+	post, err := scanPost(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("post with id %s: %w", id, data.ErrNotFound)
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error getting post by ID", err)
+	}
+
+	return post, nil
+}
+
+// GetPosts - метод для получения списка постов с keyset-пагинацией.
+// Порядок и курсоры совпадают с хранилищем PostgreSQL: от новых к старым, при равной дате - по ID;
+// `first` равное 0 возвращает все посты. Условия фильтра добавляются к запросу, поэтому курсор
+// продолжает отфильтрованную выборку.
+func (p *PostStore) GetPosts(ctx context.Context, first int32, after *string, filter model.PostFilter) (*model.PostConnection, error) {
+	query := `SELECT ` + postColumns + ` FROM posts WHERE deleted_at IS NULL` // Удаленные посты не выдаются.
+	var args []any
+
+	if after != nil && *after != "" {
+		createdAt, id, err := data.DecodeCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (created_at < ? OR (created_at = ? AND id > ?))`
+		args = append(args, formatTime(createdAt), formatTime(createdAt), id)
+	}
+
+	conditions, filterArgs := postFilterConditions(filter)
+	query += conditions
+	args = append(args, filterArgs...)
+
+	query += ` ORDER BY created_at DESC, id ASC`
+	if first > 0 {
+		// Запрашивается на одну запись больше, чтобы узнать, есть ли следующая страница.
+		query += ` LIMIT ?`
+		args = append(args, first+1)
+	}
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storeError(ctx, "error getting posts", err)
+	}
+	defer rows.Close()
+
+	edges := make([]*model.PostEdge, 0)
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, storeError(ctx, "error scanning posts", err)
+		}
+		edges = append(edges, &model.PostEdge{Node: post, Cursor: data.EncodeCursor(post.CreatedAt, post.ID)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, "error getting posts", err)
+	}
+
+	hasNextPage := first > 0 && len(edges) > int(first)
+	if hasNextPage {
+		edges = edges[:first]
+	}
+
+	return &model.PostConnection{
+		Edges:    edges,
+		PageInfo: &model.PageInfo{HasNextPage: hasNextPage},
+	}, nil
+}
+
+// CountPosts - метод подсчета неудаленных постов, удовлетворяющих фильтру (см. data.PostStore).
+func (p *PostStore) CountPosts(ctx context.Context, filter model.PostFilter) (int, error) {
+	conditions, args := postFilterConditions(filter)
+	var count int
+	if err := p.db.QueryRowContext(ctx, `SELECT count(*) FROM posts WHERE deleted_at IS NULL`+conditions, args...).Scan(&count); err != nil {
+		return 0, storeError(ctx, "error counting posts", err)
+	}
+	return count, nil
+}
+
+// postFilterConditions - вспомогательная функция условий WHERE для фильтра постов.
+// Даты сравниваются как строки formatTime, а наличие комментариев - по счетчику comment_count,
+// который учитывает только неудаленные комментарии.
+func postFilterConditions(filter model.PostFilter) (string, []any) {
+	var conditions string
+	var args []any
+	if filter.Author != nil {
+		conditions += ` AND author = ?`
+		args = append(args, *filter.Author)
+	}
+	if filter.CreatedAfter != nil {
+		conditions += ` AND created_at > ?`
+		args = append(args, formatTime(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		conditions += ` AND created_at < ?`
+		args = append(args, formatTime(*filter.CreatedBefore))
+	}
+	if filter.AllowComments != nil {
+		conditions += ` AND allow_comments = ?`
+		args = append(args, *filter.AllowComments)
+	}
+	if filter.Tag != nil {
+		conditions += ` AND EXISTS (SELECT 1 FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = posts.id AND t.name = ?)`
+		args = append(args, *filter.Tag)
+	}
+	if filter.HasComments != nil {
+		if *filter.HasComments {
+			conditions += ` AND comment_count > 0`
+		} else {
+			conditions += ` AND comment_count = 0`
+		}
+	}
+	return conditions, args
+}
+
+// UpdatePost - метод применения изменений к посту (см. data.PostStore).
+// Прежняя версия копируется в post_revisions, если меняются заголовок или текст, и заменяется новой атомарно.
+// Удаленный пост не меняется (ErrConflict).
+func (p *PostStore) UpdatePost(ctx context.Context, id string, update data.PostUpdate) (*model.Post, error) {
+	var post *model.Post
+	err := atomically(ctx, p.db, func(q querier) error {
+		old, err := scanPost(q.QueryRowContext(ctx, `SELECT `+postColumns+` FROM posts WHERE id = ?`, id))
+		if err != nil {
+			return err
+		}
+		if old.DeletedAt != nil {
+			return fmt.Errorf("post with id %s is deleted: %w", id, data.ErrConflict)
+		}
+
+		// Теги заменяются до UPDATE, чтобы RETURNING вернул уже новый список.
+		if update.Tags != nil {
+			if err := setPostTags(ctx, q, id, update.Tags); err != nil {
+				return err
+			}
+		}
+
+		changed := (update.Title != nil && *update.Title != old.Title) || (update.Content != nil && *update.Content != old.Content)
+		if changed {
+			// Прежняя версия действовала с даты последней правки или, если правок не было, с даты создания.
+			_, err := q.ExecContext(ctx, `INSERT INTO post_revisions (post_id, number, title, content, created_at)
+				SELECT id, revision_count + 1, title, content, COALESCE(edited_at, created_at) FROM posts WHERE id = ?`, id)
+			if err != nil {
+				return err
+			}
+		}
+
+		post, err = scanPost(q.QueryRowContext(ctx, `UPDATE posts SET
+				title = COALESCE(?, title),
+				content = COALESCE(?, content),
+				allow_comments = COALESCE(?, allow_comments),
+				edited_at = CASE WHEN ? THEN ? ELSE edited_at END,
+				revision_count = revision_count + CASE WHEN ? THEN 1 ELSE 0 END
+			WHERE id = ? RETURNING `+postColumns,
+			update.Title, update.Content, update.AllowComments, changed, formatTime(update.EditedAt), changed, id))
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("post with id %s: %w", id, data.ErrNotFound)
+	}
+	if errors.Is(err, data.ErrConflict) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, storeError(ctx, "error updating post", err)
+	}
+
+	return post, nil
+}
+
+// GetPostRevisions - метод получения прежних версий поста с пагинацией (см. data.PostStore).
+func (p *PostStore) GetPostRevisions(ctx context.Context, postID string, first int32, after *string) (*model.RevisionConnection, error) {
+	return revisionPage(ctx, p.db, "error getting post revisions",
+		`SELECT number, title, content, created_at FROM post_revisions WHERE post_id = ? AND number > ? ORDER BY number LIMIT ?`,
+		postID, first, after)
+}
+
+// scanner - общий интерфейс *sql.Row и *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanPost - вспомогательная функция чтения поста из строки результата.
+func scanPost(row scanner) (*model.Post, error) {
+	var post model.Post
+	var createdAt string
+	var editedAt, deletedAt sql.NullString
+	var tags string
+	if err := row.Scan(&post.ID, &post.Author, &post.Title, &post.Content, &createdAt, &post.AllowComments, &editedAt, &post.RevisionCount,
+		&deletedAt, &post.DeletedBy, &tags); err != nil {
+		return nil, err
+	}
+	post.Tags = splitTags(tags)
+
+	var err error
+	if post.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if post.EditedAt, err = parseOptionalTime(editedAt); err != nil {
+		return nil, err
+	}
+	if post.DeletedAt, err = parseOptionalTime(deletedAt); err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
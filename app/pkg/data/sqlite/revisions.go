@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+)
+
+// revisionPage - вспомогательная функция выборки страницы ревизий запросом query с параметрами
+// (ID записи, номер ревизии из курсора, лимит). Выбирается на одну ревизию больше, чтобы узнать о следующей странице.
+func revisionPage(ctx context.Context, db querier, msg, query, id string, first int32, after *string) (*model.RevisionConnection, error) {
+	if first <= 0 {
+		return data.RevisionPage(nil, first), nil
+	}
+
+	var afterNumber int32
+	if after != nil && *after != "" {
+		number, err := data.DecodeRevisionCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		afterNumber = number
+	}
+
+	rows, err := db.QueryContext(ctx, query, id, afterNumber, first+1)
+	if err != nil {
+		return nil, storeError(ctx, msg, err)
+	}
+	defer rows.Close()
+
+	revisions := make([]*model.Revision, 0, first+1)
+	for rows.Next() {
+		var revision model.Revision
+		var createdAt string
+		if err := rows.Scan(&revision.Number, &revision.Title, &revision.Content, &createdAt); err != nil {
+			return nil, storeError(ctx, msg, err)
+		}
+		if revision.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, storeError(ctx, msg, err)
+		}
+		revisions = append(revisions, &revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, msg, err)
+	}
+
+	return data.RevisionPage(revisions, first), nil
+}
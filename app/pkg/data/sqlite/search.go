@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/search"
+	"strings"
+
+	driver "modernc.org/sqlite"
+)
+
+// Функция search_terms(text) заменяет слова текста их основами (см. search.Terms); ее вызывают триггеры
+// поисковых документов (миграция 0005_search.sql). Функция доступна всем соединениям, открытым после регистрации.
+func init() {
+	driver.MustRegisterDeterministicScalarFunction("search_terms", 1, func(_ *driver.FunctionContext, args []sqldriver.Value) (sqldriver.Value, error) {
+		switch text := args[0].(type) {
+		case nil:
+			return "", nil
+		case string:
+			return strings.Join(search.Terms(text), " "), nil
+		default:
+			return nil, fmt.Errorf("search_terms: unexpected argument type %T", args[0])
+		}
+	})
+}
+
+// Веса колонок search_index для bm25: совпадение в заголовке поста важнее совпадения в тексте.
+const searchRank = `-bm25(search_index, 2.0, 1.0)`
+
+// Searcher - полнотекстовый поиск в базе SQLite по индексу FTS5, реализует интерфейс data.Searcher.
+type Searcher struct {
+	db *sql.DB
+}
+
+// NewSearcher - функция-конструктор поиска по базе SQLite.
+func NewSearcher(db *sql.DB) *Searcher {
+	return &Searcher{db: db}
+}
+
+// searchHit - найденная запись до загрузки поста или комментария.
+type searchHit struct {
+	kind, id string
+	rank     float64
+}
+
+// Search - метод поиска постов и комментариев (см. data.Searcher).
+// Порядок: по убыванию ранга bm25, при равном ранге - от новых к старым, затем по ID.
+func (s *Searcher) Search(ctx context.Context, query string, types []model.SearchType, first int32, after *string) (*model.SearchConnection, error) {
+	offset, err := data.SearchOffset(after)
+	if err != nil {
+		return nil, err
+	}
+	terms := search.QueryTerms(query)
+	posts, comments := data.SearchTypes(types)
+	if first <= 0 || len(terms) == 0 || (!posts && !comments) {
+		return data.SearchPage(nil, offset, first), nil
+	}
+
+	// Основы берутся в кавычки, чтобы FTS5 не принял их за операторы; основы через пробел должны встретиться все.
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + term + `"`
+	}
+
+	var kinds []string
+	if posts {
+		kinds = append(kinds, `'post'`)
+	}
+	if comments {
+		kinds = append(kinds, `'comment'`)
+	}
+
+	// Запрашивается на одну запись больше, чтобы узнать, есть ли следующая страница.
+	rows, err := s.db.QueryContext(ctx, `SELECT d.kind, d.ref, `+searchRank+` AS rank
+		FROM search_index
+		JOIN search_documents d ON d.id = search_index.rowid
+		LEFT JOIN posts p ON d.kind = 'post' AND p.id = d.ref
+		LEFT JOIN comments c ON d.kind = 'comment' AND c.id = d.ref
+		LEFT JOIN posts cp ON cp.id = c.post_id
+		WHERE search_index MATCH ? AND d.kind IN (`+strings.Join(kinds, ", ")+`)
+			AND COALESCE(p.id, c.id) IS NOT NULL
+			AND p.deleted_at IS NULL AND c.deleted_at IS NULL AND cp.deleted_at IS NULL
+		ORDER BY rank DESC, COALESCE(p.created_at, c.created_at) DESC, d.ref
+		LIMIT ? OFFSET ?`,
+		strings.Join(quoted, " "), first+1, offset)
+	if err != nil {
+		return nil, storeError(ctx, "error searching", err)
+	}
+	defer rows.Close()
+
+	var hits []searchHit
+	for rows.Next() {
+		var hit searchHit
+		if err := rows.Scan(&hit.kind, &hit.id, &hit.rank); err != nil {
+			return nil, storeError(ctx, "error scanning search hit", err)
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, "error iterating search hits", err)
+	}
+
+	edges, err := s.load(ctx, hits, terms)
+	if err != nil {
+		return nil, err
+	}
+	return data.SearchPage(edges, offset, first), nil
+}
+
+// load - вспомогательный метод загрузки найденных постов и комментариев и построения фрагментов.
+// Запись, удаленная между поиском и загрузкой, пропускается.
+func (s *Searcher) load(ctx context.Context, hits []searchHit, terms []string) ([]*model.SearchEdge, error) {
+	posts, comments := NewPostStore(s.db), NewCommentStore(s.db)
+
+	edges := make([]*model.SearchEdge, 0, len(hits))
+	for _, hit := range hits {
+		edge := &model.SearchEdge{Rank: hit.rank}
+		switch hit.kind {
+		case "post":
+			post, err := posts.GetPostByID(ctx, hit.id)
+			if errors.Is(err, data.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			edge.Node, edge.Snippet = post, search.Snippet(terms, post.Content, post.Title)
+		default:
+			comment, err := comments.GetCommentByID(ctx, hit.id)
+			if errors.Is(err, data.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			edge.Node, edge.Snippet = comment, search.Snippet(terms, comment.Content)
+		}
+		edges = append(edges, edge)
+	}
+	return edges, nil
+}
@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/pkg/data"
+	"log/slog"
+	"net/url"
+	"time"
+
+	driver "modernc.org/sqlite" // Драйвер SQLite на чистом Go, cgo не требуется.
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// timeFormat - формат хранения дат: UTC с фиксированным числом знаков,
+// чтобы сортировка и сравнение строк совпадали с хронологическим порядком.
+const timeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// busyTimeout - сколько ждать снятия блокировки базы другим соединением, прежде чем вернуть SQLITE_BUSY.
+const busyTimeout = 5 * time.Second
+
+// Open - функция открытия (и создания при отсутствии) базы SQLite по пути к файлу.
+// Для каждого соединения включаются внешние ключи (для каскадного удаления) и журнал WAL.
+// SQLite допускает одного писателя, поэтому пул ограничен одним соединением:
+// запросы выполняются по очереди вместо ошибок SQLITE_BUSY под нагрузкой.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	query := url.Values{
+		"_pragma": {
+			"foreign_keys(1)",
+			"journal_mode(WAL)",
+			fmt.Sprintf("busy_timeout(%d)", busyTimeout.Milliseconds()),
+		},
+		"_txlock": {"immediate"}, // Транзакции сразу берут блокировку на запись, а не повышают ее посередине.
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("unable to open database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	// sql.Open не открывает файл, поэтому доступность базы проверяется явно.
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to open database %s: %w", path, err)
+	}
+
+	return db, nil
+}
+
+// formatTime - вспомогательная функция перевода даты в формат хранения с точностью data.TimePrecision.
+func formatTime(t time.Time) string {
+	return data.Timestamp(t).Format(timeFormat)
+}
+
+// parseTime - вспомогательная функция разбора даты из формата хранения.
+func parseTime(value string) (time.Time, error) {
+	return time.Parse(timeFormat, value)
+}
+
+// parseOptionalTime - вспомогательная функция разбора необязательной даты (правки, удаления)
+// из формата хранения (nil - даты нет).
+func parseOptionalTime(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// insertError - вспомогательная функция перевода ошибки вставки записи kind с указанным ID в ошибку пакета data.
+// Нарушения ограничений - ошибки данных запроса, остальные ошибки считаются внутренними (см. storeError).
+func insertError(ctx context.Context, kind, id string, err error) error {
+	var sqliteErr *driver.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+			return fmt.Errorf("%s with id %s: %w", kind, id, data.ErrConflict)
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return fmt.Errorf("%s %s references a missing record: %w", kind, id, data.ErrNotFound)
+		}
+	}
+	return storeError(ctx, "error inserting "+kind, err)
+}
+
+// storeError - вспомогательная функция, записывающая внутреннюю ошибку хранилища в лог и оборачивающая ее контекстом.
+// Текст таких ошибок содержит детали SQL, поэтому resolvers не передают его клиенту.
+func storeError(ctx context.Context, msg string, err error) error {
+	slog.ErrorContext(ctx, msg, slog.Any("error", err))
+
+	return fmt.Errorf("%s: %w", msg, err)
+}
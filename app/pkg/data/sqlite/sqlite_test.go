@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestDB - вспомогательная функция открытия новой базы с примененными миграциями.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := Open(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := Migrate(ctx, db); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	return db
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	db := openTestDB(t)
+
+	version, err := Migrate(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Failed to migrate database again: %v", err)
+	}
+	if current, _ := MigrationVersion(context.Background(), db); version == 0 || current != version {
+		t.Errorf("Expected version %d to be recorded, got %d", version, current)
+	}
+}
+
+func TestPostsKeysetPagination(t *testing.T) {
+	db := openTestDB(t)
+	posts := NewPostStore(db)
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Посты "b" и "c" созданы одновременно: порядок между ними определяется ID.
+	for i, id := range []string{"a", "b", "c", "d"} {
+		createdAt := base.Add(time.Duration(i) * time.Hour)
+		if id == "c" {
+			createdAt = base.Add(time.Hour)
+		}
+		err := posts.AddPost(ctx, &model.Post{ID: id, Author: "Автор", Title: "Пост " + id, Content: "Текст", CreatedAt: createdAt, AllowComments: id != "d"})
+		if err != nil {
+			t.Fatalf("Failed to add post: %v", err)
+		}
+	}
+
+	var got []string
+	var after *string
+	for page := 0; ; page++ {
+		connection, err := posts.GetPosts(ctx, 2, after, model.PostFilter{})
+		if err != nil {
+			t.Fatalf("Failed to get posts: %v", err)
+		}
+		for _, edge := range connection.Edges {
+			got = append(got, edge.Node.ID)
+		}
+		if !connection.PageInfo.HasNextPage {
+			break
+		}
+		after = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	if fmt.Sprint(got) != "[d b c a]" {
+		t.Errorf("Expected posts newest first with ties by ID, got %v", got)
+	}
+
+	post, err := posts.GetPostByID(ctx, "d")
+	if err != nil || post.AllowComments || !post.CreatedAt.Equal(base.Add(3*time.Hour)) {
+		t.Errorf("Unexpected post %+v (%v)", post, err)
+	}
+	if _, err := posts.GetPostByID(ctx, "missing"); err == nil {
+		t.Error("Expected error when getting non-existent post")
+	}
+}
+
+func TestCommentsPaginationAndCascade(t *testing.T) {
+	db := openTestDB(t)
+	posts, comments := NewPostStore(db), NewCommentStore(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := posts.AddPost(ctx, &model.Post{ID: "p1", Author: "Автор", Title: "Пост", Content: "Текст", CreatedAt: now, AllowComments: true}); err != nil {
+		t.Fatalf("Failed to add post: %v", err)
+	}
+
+	parentID := "c1"
+	for i, comment := range []*model.Comment{
+		{ID: "c1", PostID: "p1", Author: "А", Content: "Первый"},
+		{ID: "c2", PostID: "p1", Author: "Б", Content: "Второй"},
+		{ID: "r1", PostID: "p1", ParentID: &parentID, Author: "В", Content: "Ответ"},
+	} {
+		comment.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		if err := comments.AddComment(ctx, comment); err != nil {
+			t.Fatalf("Failed to add comment: %v", err)
+		}
+	}
+
+	// Комментарий к несуществующему посту отклоняется внешним ключом.
+	if err := comments.AddComment(ctx, &model.Comment{ID: "x", PostID: "missing", CreatedAt: now}); err == nil {
+		t.Error("Expected error when adding comment to non-existent post")
+	}
+
+	connection, err := comments.GetCommentsForPost(ctx, "p1", 2, nil)
+	if err != nil || len(connection.Edges) != 2 || !connection.PageInfo.HasNextPage || connection.Edges[0].Node.ID != "c1" {
+		t.Fatalf("Unexpected first page %+v (%v)", connection, err)
+	}
+	connection, err = comments.GetCommentsForPost(ctx, "p1", 2, &connection.Edges[1].Cursor)
+	if err != nil || len(connection.Edges) != 1 || connection.PageInfo.HasNextPage || connection.Edges[0].Node.ID != "r1" {
+		t.Fatalf("Unexpected second page %+v (%v)", connection, err)
+	}
+
+	replies, err := comments.GetRepliesForComment(ctx, "c1", 10, nil)
+	if err != nil || len(replies.Edges) != 1 || *replies.Edges[0].Node.ParentID != "c1" {
+		t.Fatalf("Unexpected replies %+v (%v)", replies, err)
+	}
+
+	// Удаление поста каскадно удаляет его комментарии и ответы.
+	if _, err := db.ExecContext(ctx, `DELETE FROM posts WHERE id = ?`, "p1"); err != nil {
+		t.Fatalf("Failed to delete post: %v", err)
+	}
+	if _, err := comments.GetCommentByID(ctx, "r1"); err == nil {
+		t.Error("Expected comments to be deleted together with the post")
+	}
+}
+
+func TestInvalidCursor(t *testing.T) {
+	posts := NewPostStore(openTestDB(t))
+
+	cursor := "not a cursor"
+	if _, err := posts.GetPosts(context.Background(), 10, &cursor, model.PostFilter{}); err == nil {
+		t.Error("Expected error for invalid cursor")
+	}
+}
+
+func TestCommentCounters(t *testing.T) {
+	db := openTestDB(t)
+	posts, comments := NewPostStore(db), NewCommentStore(db)
+	uow := NewUnitOfWork(db)
+	ctx := context.Background()
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := posts.AddPost(ctx, &model.Post{ID: "p", Author: "Автор", Title: "Пост", Content: "Текст", CreatedAt: createdAt, AllowComments: true}); err != nil {
+		t.Fatalf("Failed to add post: %v", err)
+	}
+	parent := "c1"
+	if err := comments.AddComment(ctx, &model.Comment{ID: parent, PostID: "p", Author: "А", Content: "Текст", CreatedAt: createdAt}); err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+	// Ответ добавляется внутри единицы работы, а отклоненная вставка не должна менять счетчики.
+	err := uow.Do(ctx, func(tx data.Tx) error {
+		if err := tx.Comments().AddComment(ctx, &model.Comment{ID: parent, PostID: "p", Author: "А", Content: "Текст", CreatedAt: createdAt}); err == nil {
+			t.Error("Expected duplicate comment to be rejected")
+		}
+		return tx.Comments().AddComment(ctx, &model.Comment{ID: "c2", PostID: "p", ParentID: &parent, Author: "Б", Content: "Ответ", CreatedAt: createdAt})
+	})
+	if err != nil {
+		t.Fatalf("Failed to add reply: %v", err)
+	}
+
+	var commentCount, replyCount int
+	if err := db.QueryRowContext(ctx, `SELECT comment_count FROM posts WHERE id = 'p'`).Scan(&commentCount); err != nil {
+		t.Fatalf("Failed to read comment count: %v", err)
+	}
+	if err := db.QueryRowContext(ctx, `SELECT reply_count FROM comments WHERE id = ?`, parent).Scan(&replyCount); err != nil {
+		t.Fatalf("Failed to read reply count: %v", err)
+	}
+	if commentCount != 2 || replyCount != 1 {
+		t.Errorf("Expected comment_count 2 and reply_count 1, got %d and %d", commentCount, replyCount)
+	}
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"strings"
+)
+
+// postTags - подзапрос тегов поста для postColumns: названия через запятую по алфавиту (см. splitTags).
+// Нормализованные названия не содержат запятых, поэтому разделитель однозначен.
+const postTags = `COALESCE((SELECT group_concat(t.name, ',' ORDER BY t.name) FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+	WHERE pt.post_id = posts.id), '') AS tags`
+
+// splitTags - вспомогательная функция разбора списка тегов из postTags; для поста без тегов - пустой список.
+func splitTags(tags string) []string {
+	if tags == "" {
+		return []string{}
+	}
+	return strings.Split(tags, ",")
+}
+
+// setPostTags - вспомогательная функция замены тегов поста; новые названия добавляются в справочник tags.
+// Вызывается атомарно вместе с записью самого поста.
+func setPostTags(ctx context.Context, q querier, postID string, tags []string) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM post_tags WHERE post_id = ?`, postID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := q.ExecContext(ctx, `INSERT INTO tags (name) VALUES (?) ON CONFLICT (name) DO NOTHING`, tag); err != nil {
+			return err
+		}
+		if _, err := q.ExecContext(ctx, `INSERT INTO post_tags (post_id, tag_id) SELECT ?, id FROM tags WHERE name = ?`, postID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTags - метод получения тегов неудаленных постов с числом постов (см. data.PostStore).
+func (p *PostStore) GetTags(ctx context.Context) ([]*model.Tag, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT t.name, COUNT(*) AS post_count FROM tags t
+		JOIN post_tags pt ON pt.tag_id = t.id
+		JOIN posts ON posts.id = pt.post_id AND posts.deleted_at IS NULL
+		GROUP BY t.name
+		ORDER BY post_count DESC, t.name`)
+	if err != nil {
+		return nil, storeError(ctx, "error getting tags", err)
+	}
+	defer rows.Close()
+
+	result := make([]*model.Tag, 0)
+	for rows.Next() {
+		var tag model.Tag
+		if err := rows.Scan(&tag.Name, &tag.PostCount); err != nil {
+			return nil, storeError(ctx, "error scanning tags", err)
+		}
+		result = append(result, &tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeError(ctx, "error getting tags", err)
+	}
+	return result, nil
+}
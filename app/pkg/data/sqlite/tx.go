@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"graphql-comment-system/app/pkg/data"
+)
+
+// querier - общий интерфейс *sql.DB и *sql.Tx, через который работают хранилища.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// atomically - вспомогательная функция выполнения нескольких запросов как одного целого.
+// Вне транзакции открывается собственная транзакция; внутри единицы работы используется точка сохранения,
+// чтобы ошибка откатывала только эти запросы, а решение о транзакции оставалось за единицей работы.
+func atomically(ctx context.Context, db querier, fn func(q querier) error) error {
+	switch db := db.(type) {
+	case *sql.DB:
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback() // После Commit ничего не делает.
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	default:
+		if _, err := db.ExecContext(ctx, `SAVEPOINT atomic`); err != nil {
+			return err
+		}
+		if err := fn(db); err != nil {
+			db.ExecContext(ctx, `ROLLBACK TO atomic`)
+			db.ExecContext(ctx, `RELEASE atomic`)
+			return err
+		}
+		_, err := db.ExecContext(ctx, `RELEASE atomic`)
+		return err
+	}
+}
+
+// UnitOfWork - единица работы поверх транзакции SQLite, реализует интерфейс data.UnitOfWork.
+// Транзакции открываются с немедленной блокировкой на запись (_txlock=immediate, см. Open),
+// поэтому единицы работы выполняются строго по очереди.
+type UnitOfWork struct {
+	db *sql.DB // db - соединение с базой SQLite.
+}
+
+// NewUnitOfWork - функция-конструктор единицы работы для SQLite.
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Do - метод выполнения fn в транзакции (см. data.UnitOfWork).
+// Пул ограничен одним соединением, поэтому внутри fn можно работать только с хранилищами из tx.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(tx data.Tx) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return storeError(ctx, "error starting transaction", err)
+	}
+	defer tx.Rollback() // После Commit ничего не делает.
+
+	if err := fn(txStores{posts: &PostStore{db: tx}, comments: &CommentStore{db: tx}, notifications: &NotificationStore{db: tx}}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return storeError(ctx, "error committing transaction", err)
+	}
+
+	return nil
+}
+
+// txStores - хранилища, работающие внутри одной транзакции.
+type txStores struct {
+	posts         *PostStore
+	comments      *CommentStore
+	notifications *NotificationStore
+}
+
+// Posts - метод получения хранилища постов транзакции.
+func (t txStores) Posts() data.PostStore { return t.posts }
+
+// Comments - метод получения хранилища комментариев транзакции.
+func (t txStores) Comments() data.CommentStore { return t.comments }
+
+// Notifications - метод получения хранилища уведомлений транзакции.
+func (t txStores) Notifications() data.NotificationStore { return t.notifications }
@@ -3,35 +3,77 @@ package data
 import (
 	"context"
 	"graphql-comment-system/app/graph/model"
+	"time"
 )
 
 // PostStore определяет интерфейс для хранилища данных постов.
 // Этот интерфейс абстрагирует способ доступа к данным постов, позволяя использовать различные реализации хранения данных.
+// Даты сохраняются с точностью TimePrecision и возвращаются в UTC (см. Timestamp).
 type PostStore interface {
 	// AddPost добавляет новый пост в хранилище данных.
 	// Принимает контекст для управления временем выполнения и структуру Post для сохранения.
-	// Возвращает ошибку, если добавление поста не удалось.
+	// Возвращает ошибку, если добавление поста не удалось: ErrConflict, если пост с таким ID уже есть,
+	// ErrInvalidInput для некорректных данных. Теги поста (post.Tags) должны быть нормализованы и без повторов.
 	AddPost(ctx context.Context, post *model.Post) error
 	// GetPostByID извлекает пост из хранилища данных по его уникальному идентификатору.
 	// Принимает контекст и строковый ID поста.
-	// Возвращает структуру Post и ошибку, если пост не найден или произошла ошибка при извлечении.
+	// Возвращает структуру Post и ошибку, если пост не найден (ErrNotFound) или произошла ошибка при извлечении.
 	GetPostByID(ctx context.Context, id string) (*model.Post, error)
 	// GetPosts извлекает список постов из хранилища данных с поддержкой пагинации.
-	// Принимает контекст, количество постов для извлечения (`first`) и курсор (`after`) для пагинации.
-	// Возвращает структуру PostConnection, содержащую список постов и информацию о пагинации, а также ошибку в случае ошибки.
-	GetPosts(ctx context.Context, first int32, after *string) (*model.PostConnection, error)
+	// Принимает контекст, количество постов для извлечения (`first`), курсор (`after`) для пагинации
+	// и фильтр: выдаются только посты, удовлетворяющие всем его заданным условиям (пустой фильтр - все посты).
+	// Границы createdAfter и createdBefore не включаются, hasComments учитывает только неудаленные комментарии,
+	// tag сравнивается с нормализованными тегами поста как есть (см. пакет tags).
+	// Возвращает структуру PostConnection, содержащую список постов и информацию о пагинации, а также ошибку в случае ошибки
+	// (ErrInvalidCursor для поврежденного курсора).
+	GetPosts(ctx context.Context, first int32, after *string, filter model.PostFilter) (*model.PostConnection, error)
+	// CountPosts возвращает число неудаленных постов, удовлетворяющих фильтру, - всех страниц GetPosts
+	// с тем же фильтром (totalCount списка постов).
+	CountPosts(ctx context.Context, filter model.PostFilter) (int, error)
+	// UpdatePost применяет изменения к посту и возвращает его новую версию.
+	// Если меняются заголовок или текст, прежняя версия сохраняется как ревизия, revisionCount
+	// увеличивается, а editedAt становится равным update.EditedAt; изменение одного allowComments ревизию не создает.
+	// Возвращает ErrNotFound, если поста нет, и ErrConflict, если он удален (в том числе параллельным запросом).
+	UpdatePost(ctx context.Context, id string, update PostUpdate) (*model.Post, error)
+	// GetPostRevisions извлекает предыдущие версии поста по возрастанию номера с пагинацией.
+	// `first` равное 0 возвращает пустую страницу; ErrInvalidCursor для поврежденного курсора.
+	GetPostRevisions(ctx context.Context, postID string, first int32, after *string) (*model.RevisionConnection, error)
+	// DeletePost помечает пост удаленным (deletedAt, deletedBy) и возвращает его новую версию.
+	// Удаленный пост не выдается GetPosts, но остается доступен по ID до окончательного удаления (см. Purger).
+	// Возвращает ErrNotFound, если поста нет, и ErrConflict, если он уже удален.
+	DeletePost(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Post, error)
+	// RestorePost снимает с поста отметку об удалении и возвращает его новую версию.
+	// Возвращает ErrNotFound, если поста нет, и ErrConflict, если он не удален.
+	RestorePost(ctx context.Context, id string) (*model.Post, error)
+	// GetTags возвращает теги, которые есть хотя бы у одного неудаленного поста, с числом таких постов:
+	// по убыванию числа постов, при равенстве - по названию.
+	GetTags(ctx context.Context) ([]*model.Tag, error)
+}
+
+// PostUpdate - изменения поста для UpdatePost; поля со значением nil не меняются.
+type PostUpdate struct {
+	Title         *string   // Title - новый заголовок.
+	Content       *string   // Content - новый текст.
+	AllowComments *bool     // AllowComments - разрешены ли комментарии.
+	EditedAt      time.Time // EditedAt - время правки.
+	// Tags - новый список нормализованных тегов без повторов; nil - теги не меняются, пустой список удаляет все.
+	// Как и allowComments, изменение тегов ревизию не создает.
+	Tags []string
 }
 
 // CommentStore определяет интерфейс для хранилища данных комментариев.
 // Аналогично PostStore, этот интерфейс абстрагирует логику доступа к данным комментариев.
+// Даты сохраняются так же, как в PostStore. Упоминания (comment.Mentions) хранятся вместе с комментарием
+// и выдаются в том же порядке; для комментария без упоминаний выдается пустой список.
 type CommentStore interface {
 	// AddComment добавляет новый комментарий в хранилище данных.
 	// Принимает контекст и структуру Comment для сохранения.
-	// Возвращает ошибку, если добавление комментария не удалось.
+	// Возвращает ошибку, если добавление комментария не удалось: ErrConflict, если комментарий с таким ID уже есть,
+	// ErrNotFound, если хранилище проверяет ссылки и поста или родительского комментария нет.
 	AddComment(ctx context.Context, comment *model.Comment) error
 	// GetCommentByID извлекает комментарий из хранилища данных по его уникальному идентификатору.
 	// Принимает контекст и строковый ID комментария.
-	// Возвращает структуру Comment и ошибку, если комментарий не найден или произошла ошибка.
+	// Возвращает структуру Comment и ошибку, если комментарий не найден (ErrNotFound) или произошла ошибка.
 	GetCommentByID(ctx context.Context, id string) (*model.Comment, error)
 	// GetCommentsForPost извлекает список комментариев для определенного поста с поддержкой пагинации.
 	// Принимает контекст, ID поста, количество комментариев (`first`) и курсор (`after`).
@@ -41,4 +83,65 @@ type CommentStore interface {
 	// Принимает контекст, ID родительского комментария, количество ответов (`first`) и курсор (`after`).
 	// Возвращает CommentConnection с ответами и информацией о пагинации для указанного комментария, и ошибку в случае ошибки.
 	GetRepliesForComment(ctx context.Context, commentID string, first int32, after *string) (*model.CommentConnection, error)
+	// CountCommentsForPost возвращает число неудаленных комментариев поста - всех страниц GetCommentsForPost.
+	// Для неизвестного поста возвращается 0.
+	CountCommentsForPost(ctx context.Context, postID string) (int, error)
+	// CountRepliesForComment возвращает число неудаленных ответов на комментарий - всех страниц GetRepliesForComment.
+	// Для неизвестного комментария возвращается 0.
+	CountRepliesForComment(ctx context.Context, commentID string) (int, error)
+	// UpdateComment заменяет текст комментария и упоминания в нем и возвращает его новую версию.
+	// Если текст меняется, прежняя версия сохраняется как ревизия (без упоминаний), revisionCount увеличивается,
+	// а editedAt становится равным editedAt; если не меняется, комментарий остается прежним вместе с упоминаниями.
+	// Возвращает ErrNotFound, если комментария нет, и ErrConflict, если он удален (в том числе параллельным запросом).
+	UpdateComment(ctx context.Context, id, content string, mentions []*model.Mention, editedAt time.Time) (*model.Comment, error)
+	// GetCommentRevisions извлекает предыдущие версии комментария по возрастанию номера с пагинацией.
+	// `first` равное 0 возвращает пустую страницу; ErrInvalidCursor для поврежденного курсора.
+	GetCommentRevisions(ctx context.Context, commentID string, first int32, after *string) (*model.RevisionConnection, error)
+	// DeleteComment помечает комментарий удаленным (deletedAt, deletedBy) и возвращает его новую версию.
+	// Удаленный комментарий не выдается GetCommentsForPost и GetRepliesForComment, но остается доступен по ID;
+	// ответы на него остаются видимыми. Возвращает ErrNotFound, если комментария нет, и ErrConflict, если он уже удален.
+	DeleteComment(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Comment, error)
+	// RestoreComment снимает с комментария отметку об удалении и возвращает его новую версию.
+	// Возвращает ErrNotFound, если комментария нет, и ErrConflict, если он не удален.
+	RestoreComment(ctx context.Context, id string) (*model.Comment, error)
+}
+
+// NotificationStore определяет интерфейс хранилища уведомлений о новых комментариях.
+// Отдельной системы пользователей в сервисе нет, поэтому получатель уведомления - имя автора
+// поста или комментария, как в поле author.
+type NotificationStore interface {
+	// AddNotification добавляет уведомление о комментарии notification.CommentID.
+	// Возвращает ErrConflict, если уведомление с таким ID уже есть, и ErrNotFound, если хранилище проверяет ссылки
+	// и комментария нет. Уведомление удаляется вместе с комментарием при его окончательном удалении (см. Purger).
+	AddNotification(ctx context.Context, notification *model.Notification) error
+	// GetNotifications извлекает уведомления получателя от новых к старым (при равной дате - по ID) с пагинацией;
+	// unreadOnly - только непрочитанные. `first` равное 0 возвращает пустую страницу; ErrInvalidCursor для поврежденного курсора.
+	GetNotifications(ctx context.Context, recipient string, first int32, after *string, unreadOnly bool) (*model.NotificationConnection, error)
+	// MarkNotificationsRead отмечает прочитанными (readAt) непрочитанные уведомления получателя с ID из ids,
+	// а если ids равен nil - все его непрочитанные уведомления. Возвращает число отмеченных уведомлений;
+	// чужие, неизвестные и уже прочитанные ID пропускаются.
+	MarkNotificationsRead(ctx context.Context, recipient string, ids []string, readAt time.Time) (int, error)
+	// KnownRecipients возвращает имена из names, которым можно адресовать уведомление, - авторов хотя бы одного
+	// неудаленного поста или комментария - в порядке names. Упоминания остальных имен уведомлений не создают.
+	KnownRecipients(ctx context.Context, names []string) ([]string, error)
+}
+
+// Purger определяет окончательное удаление записей, помеченных удаленными.
+type Purger interface {
+	// Purge безвозвратно удаляет посты и комментарии, помеченные удаленными раньше deletedBefore,
+	// и возвращает число удаленных постов и комментариев (комментарии, удаленные вместе с постами, не считаются).
+	// Вместе с постом удаляются все его комментарии и ревизии, вместе с комментарием - уведомления о нем;
+	// удаленный комментарий, на который есть ответы,
+	// сохраняется, пока остаются ответы.
+	Purge(ctx context.Context, deletedBefore time.Time) (posts, comments int, err error)
+}
+
+// Searcher определяет полнотекстовый поиск по постам и комментариям.
+type Searcher interface {
+	// Search ищет записи типов types (пустой список - все типы), содержащие все значимые слова query
+	// с учетом словоформ русских и английских слов, и возвращает их по убыванию релевантности с пагинацией.
+	// Удаленные записи и комментарии удаленных постов не выдаются. У каждой записи есть фрагмент текста
+	// с найденными словами, выделенными тегом <mark>. `first` равное 0 или запрос без значимых слов
+	// возвращают пустую страницу; ErrInvalidCursor для поврежденного курсора.
+	Search(ctx context.Context, query string, types []model.SearchType, first int32, after *string) (*model.SearchConnection, error)
 }
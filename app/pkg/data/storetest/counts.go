@@ -0,0 +1,115 @@
+package storetest
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// assertCount - вспомогательная функция сравнения результата подсчета с ожидаемым числом.
+func assertCount(t *testing.T, what string, count int, err error, want int) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Failed to count %s: %v", what, err)
+	}
+	if count != want {
+		t.Errorf("Unexpected number of %s: got %d, want %d", what, count, want)
+	}
+}
+
+// testPostCounts проверяет, что CountPosts считает неудаленные посты с тем же фильтром, что и GetPosts,
+// и учитывает удаление и восстановление.
+func testPostCounts(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	count, err := posts.CountPosts(ctx, model.PostFilter{})
+	assertCount(t, "posts in an empty store", count, err, 0)
+
+	first := addPostBy(t, posts, "Анна", true, 0)
+	addPostBy(t, posts, "Борис", false, time.Hour)
+	addTaggedPost(t, posts, 2*time.Hour, "go")
+	addComment(t, comments, first.ID, nil, 3*time.Hour)
+
+	count, err = posts.CountPosts(ctx, model.PostFilter{})
+	assertCount(t, "posts", count, err, 3)
+
+	author, allowComments, hasComments, tag := "Анна", false, true, "go"
+	count, err = posts.CountPosts(ctx, model.PostFilter{Author: &author})
+	assertCount(t, "posts by author", count, err, 1)
+	count, err = posts.CountPosts(ctx, model.PostFilter{AllowComments: &allowComments})
+	assertCount(t, "posts without comments allowed", count, err, 1)
+	count, err = posts.CountPosts(ctx, model.PostFilter{HasComments: &hasComments})
+	assertCount(t, "posts with comments", count, err, 1)
+	count, err = posts.CountPosts(ctx, model.PostFilter{Tag: &tag, CreatedAfter: filterDate(time.Hour)})
+	assertCount(t, "tagged posts", count, err, 1)
+
+	// Число совпадает с числом постов на всех страницах GetPosts.
+	page, err := posts.GetPosts(ctx, 0, nil, model.PostFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get posts: %v", err)
+	}
+	assertCount(t, "posts on all pages", len(page.Edges), nil, 3)
+
+	if _, err := posts.DeletePost(ctx, first.ID, "Модератор", deletedAt(4*time.Hour)); err != nil {
+		t.Fatalf("Failed to delete post: %v", err)
+	}
+	count, err = posts.CountPosts(ctx, model.PostFilter{})
+	assertCount(t, "posts after deletion", count, err, 2)
+	count, err = posts.CountPosts(ctx, model.PostFilter{Author: &author})
+	assertCount(t, "deleted posts by author", count, err, 0)
+
+	if _, err := posts.RestorePost(ctx, first.ID); err != nil {
+		t.Fatalf("Failed to restore post: %v", err)
+	}
+	count, err = posts.CountPosts(ctx, model.PostFilter{})
+	assertCount(t, "posts after restore", count, err, 3)
+}
+
+// testCommentCounts проверяет подсчет неудаленных комментариев поста и ответов на комментарий.
+func testCommentCounts(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	other := addPost(t, posts, time.Hour)
+
+	root := addComment(t, comments, post.ID, nil, time.Minute)
+	addComment(t, comments, post.ID, &root.ID, 2*time.Minute)
+	reply := addComment(t, comments, post.ID, &root.ID, 3*time.Minute)
+	addComment(t, comments, post.ID, nil, 4*time.Minute)
+	addComment(t, comments, other.ID, nil, 5*time.Minute)
+
+	count, err := comments.CountCommentsForPost(ctx, post.ID)
+	assertCount(t, "comments of post", count, err, 4)
+	count, err = comments.CountRepliesForComment(ctx, root.ID)
+	assertCount(t, "replies", count, err, 2)
+	count, err = comments.CountRepliesForComment(ctx, reply.ID)
+	assertCount(t, "replies of a leaf comment", count, err, 0)
+
+	// Удаленный ответ не считается ни в комментариях поста, ни в ответах; ответы удаленного комментария считаются.
+	if _, err := comments.DeleteComment(ctx, reply.ID, "Модератор", deletedAt(time.Hour)); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+	if _, err := comments.DeleteComment(ctx, root.ID, "Модератор", deletedAt(time.Hour)); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+	count, err = comments.CountCommentsForPost(ctx, post.ID)
+	assertCount(t, "comments of post after deletion", count, err, 2)
+	count, err = comments.CountRepliesForComment(ctx, root.ID)
+	assertCount(t, "replies after deletion", count, err, 1)
+
+	if _, err := comments.RestoreComment(ctx, reply.ID); err != nil {
+		t.Fatalf("Failed to restore comment: %v", err)
+	}
+	count, err = comments.CountRepliesForComment(ctx, root.ID)
+	assertCount(t, "replies after restore", count, err, 2)
+
+	// Неизвестная запись - ноль, а не ошибка.
+	for _, id := range []string{uuid.NewString(), "missing"} {
+		count, err = comments.CountCommentsForPost(ctx, id)
+		assertCount(t, "comments of unknown post", count, err, 0)
+		count, err = comments.CountRepliesForComment(ctx, id)
+		assertCount(t, "replies of unknown comment", count, err, 0)
+	}
+}
@@ -0,0 +1,242 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PurgerFactory - функция создания пустых хранилищ и data.Purger над ними для одного теста.
+type PurgerFactory func(t *testing.T) (data.PostStore, data.CommentStore, data.Purger)
+
+// RunPurger - функция запуска тестов соответствия для реализаций data.Purger.
+func RunPurger(t *testing.T, newStores PurgerFactory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, posts data.PostStore, comments data.CommentStore, purger data.Purger)
+	}{
+		{"PurgeExpired", testPurgeExpired},
+		{"PurgeKeepsCommentsWithReplies", testPurgeKeepsCommentsWithReplies},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, comments, purger := newStores(t)
+			tt.test(t, posts, comments, purger)
+		})
+	}
+}
+
+// deletedAt - вспомогательная функция времени удаления через offset после baseTime.
+func deletedAt(offset time.Duration) time.Time {
+	return baseTime.Add(offset)
+}
+
+// testPostDeletion проверяет удаление и восстановление поста: удаленный пост скрыт из списка,
+// но доступен по ID с отметкой об удалении, а правка его отклоняется.
+func testPostDeletion(t *testing.T, posts data.PostStore, _ data.CommentStore) {
+	ctx := context.Background()
+	kept := addPost(t, posts, 0)
+	post := addPost(t, posts, time.Hour)
+
+	deleted, err := posts.DeletePost(ctx, post.ID, "Модератор", deletedAt(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to delete post: %v", err)
+	}
+	if deleted.DeletedAt == nil || !deleted.DeletedAt.Equal(deletedAt(2*time.Hour)) || deleted.DeletedBy == nil || *deleted.DeletedBy != "Модератор" {
+		t.Errorf("Unexpected deleted post: %+v", deleted)
+	}
+
+	connection, err := posts.GetPosts(ctx, 0, nil, model.PostFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get posts: %v", err)
+	}
+	assertIDs(t, "posts after deletion", postIDs(connection), []string{kept.ID})
+
+	stored, err := posts.GetPostByID(ctx, post.ID)
+	if err != nil || stored.DeletedAt == nil {
+		t.Errorf("Expected deleted post by id, got %+v, %v", stored, err)
+	}
+
+	if _, err := posts.DeletePost(ctx, post.ID, "Модератор", deletedAt(3*time.Hour)); !errors.Is(err, data.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a repeated deletion, got %v", err)
+	}
+	if _, err := posts.DeletePost(ctx, uuid.NewString(), "Модератор", deletedAt(3*time.Hour)); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing post, got %v", err)
+	}
+	title := "Правка удаленного поста"
+	if _, err := posts.UpdatePost(ctx, post.ID, data.PostUpdate{Title: &title, EditedAt: editedAt(3 * time.Hour)}); !errors.Is(err, data.ErrConflict) {
+		t.Errorf("Expected ErrConflict for updating a deleted post, got %v", err)
+	}
+	if stored, err := posts.GetPostByID(ctx, post.ID); err != nil || stored.Title == title || stored.RevisionCount != 0 {
+		t.Errorf("Expected the deleted post to stay unchanged, got %+v, %v", stored, err)
+	}
+
+	restored, err := posts.RestorePost(ctx, post.ID)
+	if err != nil {
+		t.Fatalf("Failed to restore post: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.DeletedBy != nil {
+		t.Errorf("Expected restored post without deletion mark, got %+v", restored)
+	}
+	connection, err = posts.GetPosts(ctx, 0, nil, model.PostFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get posts: %v", err)
+	}
+	assertIDs(t, "posts after restore", postIDs(connection), []string{post.ID, kept.ID})
+
+	if _, err := posts.RestorePost(ctx, post.ID); !errors.Is(err, data.ErrConflict) {
+		t.Errorf("Expected ErrConflict for restoring a post that is not deleted, got %v", err)
+	}
+}
+
+// testCommentDeletion проверяет удаление и восстановление комментария: удаленный комментарий скрыт
+// из комментариев поста и ответов и не правится, а ответы на него остаются видимыми.
+func testCommentDeletion(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	parent := addComment(t, comments, post.ID, nil, time.Second)
+	reply := addComment(t, comments, post.ID, &parent.ID, 2*time.Second)
+	deletedReply := addComment(t, comments, post.ID, &parent.ID, 3*time.Second)
+
+	if _, err := comments.DeleteComment(ctx, parent.ID, "Комментатор", deletedAt(time.Hour)); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+	if _, err := comments.DeleteComment(ctx, deletedReply.ID, "Модератор", deletedAt(time.Hour)); err != nil {
+		t.Fatalf("Failed to delete reply: %v", err)
+	}
+
+	connection, err := comments.GetCommentsForPost(ctx, post.ID, 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to get comments: %v", err)
+	}
+	assertIDs(t, "comments after deletion", commentIDs(connection), []string{reply.ID})
+
+	replies, err := comments.GetRepliesForComment(ctx, parent.ID, 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to get replies: %v", err)
+	}
+	assertIDs(t, "replies after deletion", commentIDs(replies), []string{reply.ID})
+
+	if _, err := comments.DeleteComment(ctx, parent.ID, "Комментатор", deletedAt(2*time.Hour)); !errors.Is(err, data.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a repeated deletion, got %v", err)
+	}
+	if _, err := comments.UpdateComment(ctx, parent.ID, "Правка удаленного комментария", nil, editedAt(2*time.Hour)); !errors.Is(err, data.ErrConflict) {
+		t.Errorf("Expected ErrConflict for updating a deleted comment, got %v", err)
+	}
+	if stored, err := comments.GetCommentByID(ctx, parent.ID); err != nil || stored.Content != parent.Content || stored.RevisionCount != 0 {
+		t.Errorf("Expected the deleted comment to stay unchanged, got %+v, %v", stored, err)
+	}
+
+	restored, err := comments.RestoreComment(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("Failed to restore comment: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.DeletedBy != nil {
+		t.Errorf("Expected restored comment without deletion mark, got %+v", restored)
+	}
+	connection, err = comments.GetCommentsForPost(ctx, post.ID, 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to get comments: %v", err)
+	}
+	assertIDs(t, "comments after restore", commentIDs(connection), []string{parent.ID, reply.ID})
+
+	if _, err := comments.RestoreComment(ctx, reply.ID); !errors.Is(err, data.ErrConflict) {
+		t.Errorf("Expected ErrConflict for restoring a comment that is not deleted, got %v", err)
+	}
+	if _, err := comments.RestoreComment(ctx, uuid.NewString()); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing comment, got %v", err)
+	}
+}
+
+// testPurgeExpired проверяет окончательное удаление: удаленные раньше границы посты исчезают вместе
+// с комментариями, а удаленные позже и неудаленные записи остаются.
+func testPurgeExpired(t *testing.T, posts data.PostStore, comments data.CommentStore, purger data.Purger) {
+	ctx := context.Background()
+	expired := addPost(t, posts, 0)
+	expiredComment := addComment(t, comments, expired.ID, nil, time.Second)
+	recent := addPost(t, posts, time.Minute)
+	kept := addPost(t, posts, 2*time.Minute)
+	oldComment := addComment(t, comments, kept.ID, nil, time.Second)
+	keptComment := addComment(t, comments, kept.ID, nil, 2*time.Second)
+
+	if _, err := posts.DeletePost(ctx, expired.ID, "Автор", deletedAt(time.Hour)); err != nil {
+		t.Fatalf("Failed to delete post: %v", err)
+	}
+	if _, err := posts.DeletePost(ctx, recent.ID, "Автор", deletedAt(3*time.Hour)); err != nil {
+		t.Fatalf("Failed to delete post: %v", err)
+	}
+	if _, err := comments.DeleteComment(ctx, oldComment.ID, "Комментатор", deletedAt(time.Hour)); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+
+	purgedPosts, purgedComments, err := purger.Purge(ctx, deletedAt(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	if purgedPosts != 1 || purgedComments != 1 {
+		t.Errorf("Expected 1 post and 1 comment purged, got %d and %d", purgedPosts, purgedComments)
+	}
+
+	if _, err := posts.GetPostByID(ctx, expired.ID); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected purged post to be gone, got %v", err)
+	}
+	for _, id := range []string{expiredComment.ID, oldComment.ID} {
+		if _, err := comments.GetCommentByID(ctx, id); !errors.Is(err, data.ErrNotFound) {
+			t.Errorf("Expected purged comment %s to be gone, got %v", id, err)
+		}
+	}
+	if _, err := posts.GetPostByID(ctx, recent.ID); err != nil {
+		t.Errorf("Expected recently deleted post to be kept, got %v", err)
+	}
+	if _, err := comments.GetCommentByID(ctx, keptComment.ID); err != nil {
+		t.Errorf("Expected comment to be kept, got %v", err)
+	}
+
+	// Повторная очистка с той же границей ничего не удаляет.
+	purgedPosts, purgedComments, err = purger.Purge(ctx, deletedAt(2*time.Hour))
+	if err != nil || purgedPosts != 0 || purgedComments != 0 {
+		t.Errorf("Expected nothing to purge, got %d, %d, %v", purgedPosts, purgedComments, err)
+	}
+}
+
+// testPurgeKeepsCommentsWithReplies проверяет, что удаленный комментарий с ответами не удаляется окончательно,
+// чтобы не потерять ответы, а после очистки ответов удаляется при следующем запуске.
+func testPurgeKeepsCommentsWithReplies(t *testing.T, posts data.PostStore, comments data.CommentStore, purger data.Purger) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	parent := addComment(t, comments, post.ID, nil, time.Second)
+	reply := addComment(t, comments, post.ID, &parent.ID, 2*time.Second)
+
+	if _, err := comments.DeleteComment(ctx, parent.ID, "Комментатор", deletedAt(time.Hour)); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+	if _, _, err := purger.Purge(ctx, deletedAt(2*time.Hour)); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	if _, err := comments.GetCommentByID(ctx, parent.ID); err != nil {
+		t.Errorf("Expected deleted comment with replies to be kept, got %v", err)
+	}
+
+	if _, err := comments.DeleteComment(ctx, reply.ID, "Комментатор", deletedAt(time.Hour)); err != nil {
+		t.Fatalf("Failed to delete reply: %v", err)
+	}
+	if _, _, err := purger.Purge(ctx, deletedAt(2*time.Hour)); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	if _, err := comments.GetCommentByID(ctx, reply.ID); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected reply to be purged, got %v", err)
+	}
+	// Родитель получает право на очистку только после удаления ответа, то есть при следующем запуске.
+	if _, _, err := purger.Purge(ctx, deletedAt(2*time.Hour)); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	if _, err := comments.GetCommentByID(ctx, parent.ID); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected parent comment to be purged, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package storetest
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// addPostBy - вспомогательная функция добавления поста с заданными автором и разрешением комментариев.
+func addPostBy(t *testing.T, posts data.PostStore, author string, allowComments bool, offset time.Duration) *model.Post {
+	t.Helper()
+	post := &model.Post{
+		ID:            uuid.NewString(),
+		Author:        author,
+		Title:         "Пост",
+		Content:       "Текст поста",
+		CreatedAt:     baseTime.Add(offset),
+		AllowComments: allowComments,
+	}
+	if err := posts.AddPost(context.Background(), post); err != nil {
+		t.Fatalf("Failed to add post: %v", err)
+	}
+	return post
+}
+
+// filterDate - вспомогательная функция даты фильтра через offset после baseTime.
+func filterDate(offset time.Duration) *time.Time {
+	date := baseTime.Add(offset)
+	return &date
+}
+
+// testPostsFilter проверяет каждое условие фильтра постов и их сочетание.
+func testPostsFilter(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	commented := addPostBy(t, posts, "Анна", true, 0)
+	closed := addPostBy(t, posts, "Борис", false, time.Hour)
+	deletedComments := addPostBy(t, posts, "Анна", true, 2*time.Hour)
+	latest := addPostBy(t, posts, "Анна", true, 3*time.Hour)
+
+	addComment(t, comments, commented.ID, nil, 4*time.Hour)
+	comment := addComment(t, comments, deletedComments.ID, nil, 4*time.Hour)
+	if _, err := comments.DeleteComment(ctx, comment.ID, "Модератор", deletedAt(5*time.Hour)); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+
+	author, yes, no := "Анна", true, false
+	for _, tc := range []struct {
+		name   string
+		filter model.PostFilter
+		want   []string
+	}{
+		{"empty", model.PostFilter{}, []string{latest.ID, deletedComments.ID, closed.ID, commented.ID}},
+		{"author", model.PostFilter{Author: &author}, []string{latest.ID, deletedComments.ID, commented.ID}},
+		{"dates", model.PostFilter{CreatedAfter: filterDate(0), CreatedBefore: filterDate(3 * time.Hour)},
+			[]string{deletedComments.ID, closed.ID}},
+		{"allowComments", model.PostFilter{AllowComments: &no}, []string{closed.ID}},
+		{"hasComments", model.PostFilter{HasComments: &yes}, []string{commented.ID}},
+		{"noComments", model.PostFilter{HasComments: &no}, []string{latest.ID, deletedComments.ID, closed.ID}},
+		{"combined", model.PostFilter{Author: &author, HasComments: &no}, []string{latest.ID, deletedComments.ID}},
+	} {
+		connection, err := posts.GetPosts(ctx, 0, nil, tc.filter)
+		if err != nil {
+			t.Fatalf("Failed to get posts with %s filter: %v", tc.name, err)
+		}
+		assertIDs(t, tc.name+" filter", postIDs(connection), tc.want)
+	}
+}
+
+// testPostsFilterPagination проверяет, что курсоры продолжают отфильтрованную выборку
+// без пропусков и без постов, не подходящих под фильтр.
+func testPostsFilterPagination(t *testing.T, posts data.PostStore, _ data.CommentStore) {
+	ctx := context.Background()
+	var want []string
+	for i := range 5 {
+		author := "Анна"
+		if i%2 == 1 {
+			author = "Борис"
+		}
+		post := addPostBy(t, posts, author, true, time.Duration(i)*time.Hour)
+		if author == "Анна" {
+			want = append([]string{post.ID}, want...)
+		}
+	}
+
+	author := "Анна"
+	filter := model.PostFilter{Author: &author}
+	var got []string
+	var after *string
+	for page := 0; ; page++ {
+		connection, err := posts.GetPosts(ctx, 2, after, filter)
+		if err != nil {
+			t.Fatalf("Failed to get page %d: %v", page, err)
+		}
+		got = append(got, postIDs(connection)...)
+		if !connection.PageInfo.HasNextPage {
+			break
+		}
+		if page > len(want) {
+			t.Fatalf("Pagination does not terminate")
+		}
+		after = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+	assertIDs(t, "filtered pages", got, want)
+}
@@ -0,0 +1,91 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"testing"
+	"time"
+)
+
+// formatMentions - вспомогательная функция записи упоминаний в виде "имя@позиция+длина" для сравнения.
+func formatMentions(mentions []*model.Mention) string {
+	if mentions == nil {
+		return "nil"
+	}
+	result := ""
+	for _, mention := range mentions {
+		result += fmt.Sprintf("%s@%d+%d ", mention.Handle, mention.Offset, mention.Length)
+	}
+	return result
+}
+
+// testCommentMentions проверяет хранение упоминаний: сохраняются вместе с комментарием, заменяются при правке
+// текста и остаются прежними, если текст не изменился; у комментария без упоминаний - пустой список.
+func testCommentMentions(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+
+	plain := addComment(t, comments, post.ID, nil, time.Second)
+	if got, err := comments.GetCommentByID(ctx, plain.ID); err != nil || got.Mentions == nil || len(got.Mentions) != 0 {
+		t.Errorf("Expected empty mentions, got %s (%v)", formatMentions(got.Mentions), err)
+	}
+
+	comment := newComment(post.ID, nil, 2*time.Second)
+	comment.Content = "@Анна и @Борис, @Анна"
+	comment.Mentions = []*model.Mention{{Handle: "Анна", Offset: 0, Length: 5}, {Handle: "Борис", Offset: 8, Length: 6}, {Handle: "Анна", Offset: 16, Length: 5}}
+	if err := comments.AddComment(ctx, comment); err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+	want := formatMentions(comment.Mentions)
+	comment.Mentions[0].Handle = "Изменено" // Хранилище не должно зависеть от списка вызывающего кода.
+	got, err := comments.GetCommentByID(ctx, comment.ID)
+	if err != nil || formatMentions(got.Mentions) != want {
+		t.Errorf("Unexpected stored mentions:\n got: %s\nwant: %s (%v)", formatMentions(got.Mentions), want, err)
+	}
+	page, err := comments.GetCommentsForPost(ctx, post.ID, 10, nil)
+	if err != nil || len(page.Edges) != 2 || formatMentions(page.Edges[1].Node.Mentions) != want {
+		t.Errorf("Expected mentions in comment list, got %+v (%v)", page, err)
+	}
+
+	unchanged, err := comments.UpdateComment(ctx, comment.ID, "@Анна и @Борис, @Анна", nil, editedAt(time.Hour))
+	if err != nil || formatMentions(unchanged.Mentions) != want {
+		t.Errorf("Expected mentions of unchanged text to be kept, got %s (%v)", formatMentions(unchanged.Mentions), err)
+	}
+
+	updated, err := comments.UpdateComment(ctx, comment.ID, "Спасибо, @Вера", []*model.Mention{{Handle: "Вера", Offset: 9, Length: 5}}, editedAt(2*time.Hour))
+	if err != nil || formatMentions(updated.Mentions) != "Вера@9+5 " {
+		t.Errorf("Expected replaced mentions, got %s (%v)", formatMentions(updated.Mentions), err)
+	}
+	if got, err := comments.GetCommentByID(ctx, comment.ID); err != nil || formatMentions(got.Mentions) != "Вера@9+5 " {
+		t.Errorf("Expected replaced mentions to be stored, got %s (%v)", formatMentions(got.Mentions), err)
+	}
+
+	cleared, err := comments.UpdateComment(ctx, comment.ID, "Без упоминаний", nil, editedAt(3*time.Hour))
+	if err != nil || cleared.Mentions == nil || len(cleared.Mentions) != 0 {
+		t.Errorf("Expected empty mentions after edit, got %s (%v)", formatMentions(cleared.Mentions), err)
+	}
+}
+
+// testKnownRecipients проверяет отбор известных имен: авторы неудаленных постов и комментариев в порядке запроса.
+func testKnownRecipients(t *testing.T, posts data.PostStore, comments data.CommentStore, notifications data.NotificationStore, _ data.Purger) {
+	ctx := context.Background()
+	post := addPostBy(t, posts, "Автор", true, 0)
+	addComment(t, comments, post.ID, nil, time.Second) // Автор комментария - "Комментатор".
+
+	deletedPost := addPostBy(t, posts, "Удаленный", true, 2*time.Second)
+	if _, err := posts.DeletePost(ctx, deletedPost.ID, "Модератор", deletedAt(time.Hour)); err != nil {
+		t.Fatalf("Failed to delete post: %v", err)
+	}
+
+	known, err := notifications.KnownRecipients(ctx, []string{"Незнакомец", "Комментатор", "Удаленный", "Автор", "автор"})
+	assertIDs(t, "known recipients", known, []string{"Комментатор", "Автор"})
+	if err != nil {
+		t.Errorf("Failed to get known recipients: %v", err)
+	}
+
+	if known, err := notifications.KnownRecipients(ctx, nil); err != nil || known == nil || len(known) != 0 {
+		t.Errorf("Expected an empty list for no names, got %#v (%v)", known, err)
+	}
+}
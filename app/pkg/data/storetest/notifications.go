@@ -0,0 +1,216 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationFactory - функция создания пустых хранилищ, хранилища уведомлений и data.Purger над ними для одного теста.
+type NotificationFactory func(t *testing.T) (data.PostStore, data.CommentStore, data.NotificationStore, data.Purger)
+
+// RunNotifications - функция запуска тестов соответствия для реализаций data.NotificationStore.
+func RunNotifications(t *testing.T, newStores NotificationFactory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, posts data.PostStore, comments data.CommentStore, notifications data.NotificationStore, purger data.Purger)
+	}{
+		{"NotificationsOrdering", testNotificationsOrdering},
+		{"MarkNotificationsRead", testMarkNotificationsRead},
+		{"NotificationReferences", testNotificationReferences},
+		{"PurgeRemovesNotifications", testPurgeRemovesNotifications},
+		{"KnownRecipients", testKnownRecipients},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, comments, notifications, purger := newStores(t)
+			tt.test(t, posts, comments, notifications, purger)
+		})
+	}
+}
+
+// newNotification - вспомогательная функция создания уведомления получателю о комментарии (без добавления в хранилище).
+func newNotification(recipient string, comment *model.Comment, offset time.Duration) *model.Notification {
+	return &model.Notification{
+		ID:        uuid.NewString(),
+		Recipient: recipient,
+		Kind:      model.NotificationKindComment,
+		Actor:     comment.Author,
+		PostID:    comment.PostID,
+		CommentID: comment.ID,
+		CreatedAt: baseTime.Add(offset),
+	}
+}
+
+// addNotification - вспомогательная функция добавления уведомления получателю о комментарии.
+func addNotification(t *testing.T, notifications data.NotificationStore, recipient string, comment *model.Comment, offset time.Duration) *model.Notification {
+	t.Helper()
+	notification := newNotification(recipient, comment, offset)
+	if err := notifications.AddNotification(context.Background(), notification); err != nil {
+		t.Fatalf("Failed to add notification: %v", err)
+	}
+	return notification
+}
+
+// getNotifications - вспомогательная функция получения всех уведомлений получателя одной страницей.
+func getNotifications(t *testing.T, notifications data.NotificationStore, recipient string, unreadOnly bool) []*model.Notification {
+	t.Helper()
+	page, err := notifications.GetNotifications(context.Background(), recipient, 100, nil, unreadOnly)
+	if err != nil {
+		t.Fatalf("Failed to get notifications: %v", err)
+	}
+	result := make([]*model.Notification, len(page.Edges))
+	for i, edge := range page.Edges {
+		result[i] = edge.Node
+	}
+	return result
+}
+
+// notificationIDs - вспомогательная функция получения ID уведомлений в порядке выдачи.
+func notificationIDs(notifications []*model.Notification) []string {
+	ids := make([]string, len(notifications))
+	for i, notification := range notifications {
+		ids[i] = notification.ID
+	}
+	return ids
+}
+
+// testNotificationsOrdering проверяет порядок уведомлений (от новых к старым, при равной дате - по ID),
+// выборку по получателю и пагинацию.
+func testNotificationsOrdering(t *testing.T, posts data.PostStore, comments data.CommentStore, notifications data.NotificationStore, _ data.Purger) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	comment := addComment(t, comments, post.ID, nil, time.Second)
+
+	older := addNotification(t, notifications, "Анна", comment, time.Hour)
+	sameA := addNotification(t, notifications, "Анна", comment, 2*time.Hour)
+	sameB := addNotification(t, notifications, "Анна", comment, 2*time.Hour)
+	other := addNotification(t, notifications, "Борис", comment, 3*time.Hour)
+	if sameB.ID < sameA.ID {
+		sameA, sameB = sameB, sameA
+	}
+
+	want := []string{sameA.ID, sameB.ID, older.ID}
+	assertIDs(t, "notifications", notificationIDs(getNotifications(t, notifications, "Анна", false)), want)
+	assertIDs(t, "notifications of another recipient", notificationIDs(getNotifications(t, notifications, "Борис", false)), []string{other.ID})
+
+	// Постраничный обход по одному уведомлению дает тот же порядок.
+	var got []string
+	var after *string
+	for page := 0; page < len(want)+1; page++ {
+		result, err := notifications.GetNotifications(ctx, "Анна", 1, after, false)
+		if err != nil {
+			t.Fatalf("Failed to get notifications page: %v", err)
+		}
+		for _, edge := range result.Edges {
+			got = append(got, edge.Node.ID)
+			after = &edge.Cursor
+		}
+		if !result.PageInfo.HasNextPage {
+			break
+		}
+	}
+	assertIDs(t, "paginated notifications", got, want)
+
+	first := getNotifications(t, notifications, "Анна", false)[2]
+	if !first.CreatedAt.Equal(older.CreatedAt) || first.Kind != model.NotificationKindComment || first.CommentID != comment.ID || first.PostID != post.ID || first.ReadAt != nil {
+		t.Errorf("Unexpected notification fields: %+v", first)
+	}
+
+	if result, err := notifications.GetNotifications(ctx, "Анна", 0, nil, false); err != nil || len(result.Edges) != 0 {
+		t.Errorf("Expected an empty page for first = 0, got %v (%v)", result, err)
+	}
+	invalid := "not-a-cursor"
+	if _, err := notifications.GetNotifications(ctx, "Анна", 1, &invalid, false); !errors.Is(err, data.ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+// testMarkNotificationsRead проверяет отметку прочтения: только уведомления получателя, только непрочитанные,
+// все уведомления при ids = nil и фильтр unreadOnly.
+func testMarkNotificationsRead(t *testing.T, posts data.PostStore, comments data.CommentStore, notifications data.NotificationStore, _ data.Purger) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	comment := addComment(t, comments, post.ID, nil, time.Second)
+
+	first := addNotification(t, notifications, "Анна", comment, time.Hour)
+	second := addNotification(t, notifications, "Анна", comment, 2*time.Hour)
+	third := addNotification(t, notifications, "Анна", comment, 3*time.Hour)
+	foreign := addNotification(t, notifications, "Борис", comment, 4*time.Hour)
+
+	// Чужие, неизвестные и повторные ID пропускаются.
+	readAt := baseTime.Add(5 * time.Hour)
+	marked, err := notifications.MarkNotificationsRead(ctx, "Анна", []string{first.ID, foreign.ID, uuid.NewString(), first.ID}, readAt)
+	assertCount(t, "marked notifications", marked, err, 1)
+
+	all := getNotifications(t, notifications, "Анна", false)
+	if read := all[2]; read.ID != first.ID || read.ReadAt == nil || !read.ReadAt.Equal(readAt) {
+		t.Errorf("Expected %s to be read at %v, got %+v", first.ID, readAt, read)
+	}
+	assertIDs(t, "unread notifications", notificationIDs(getNotifications(t, notifications, "Анна", true)), []string{third.ID, second.ID})
+
+	// Повторная отметка не меняет дату прочтения и не считается.
+	marked, err = notifications.MarkNotificationsRead(ctx, "Анна", []string{first.ID}, readAt.Add(time.Hour))
+	assertCount(t, "marked read notifications", marked, err, 0)
+	marked, err = notifications.MarkNotificationsRead(ctx, "Анна", []string{}, readAt)
+	assertCount(t, "marked notifications for an empty list", marked, err, 0)
+
+	marked, err = notifications.MarkNotificationsRead(ctx, "Анна", nil, readAt.Add(time.Hour))
+	assertCount(t, "marked remaining notifications", marked, err, 2)
+	assertIDs(t, "unread notifications after marking all", notificationIDs(getNotifications(t, notifications, "Анна", true)), []string{})
+	if read := getNotifications(t, notifications, "Анна", false)[2]; !read.ReadAt.Equal(readAt) {
+		t.Errorf("Expected the first read date to be kept, got %v", read.ReadAt)
+	}
+
+	assertIDs(t, "unread notifications of another recipient", notificationIDs(getNotifications(t, notifications, "Борис", true)), []string{foreign.ID})
+}
+
+// testNotificationReferences проверяет ошибки добавления: повторный ID и ссылку на несуществующий комментарий.
+func testNotificationReferences(t *testing.T, posts data.PostStore, comments data.CommentStore, notifications data.NotificationStore, _ data.Purger) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	comment := addComment(t, comments, post.ID, nil, time.Second)
+
+	notification := addNotification(t, notifications, "Анна", comment, time.Hour)
+	if err := notifications.AddNotification(ctx, notification); !errors.Is(err, data.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a duplicate notification, got %v", err)
+	}
+
+	missing := newNotification("Анна", &model.Comment{ID: uuid.NewString(), PostID: post.ID, Author: "Комментатор"}, time.Hour)
+	if err := notifications.AddNotification(ctx, missing); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing comment, got %v", err)
+	}
+}
+
+// testPurgeRemovesNotifications проверяет, что уведомления удаляются вместе с окончательно удаленным комментарием
+// и с комментариями окончательно удаленного поста.
+func testPurgeRemovesNotifications(t *testing.T, posts data.PostStore, comments data.CommentStore, notifications data.NotificationStore, purger data.Purger) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	removed := addComment(t, comments, post.ID, nil, time.Second)
+	kept := addComment(t, comments, post.ID, nil, 2*time.Second)
+	addNotification(t, notifications, "Анна", removed, time.Hour)
+	keptNotification := addNotification(t, notifications, "Анна", kept, 2*time.Hour)
+
+	if _, err := comments.DeleteComment(ctx, removed.ID, "Модератор", deletedAt(3*time.Hour)); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+	if _, _, err := purger.Purge(ctx, deletedAt(4*time.Hour)); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	assertIDs(t, "notifications after purging a comment", notificationIDs(getNotifications(t, notifications, "Анна", false)), []string{keptNotification.ID})
+
+	if _, err := posts.DeletePost(ctx, post.ID, "Модератор", deletedAt(5*time.Hour)); err != nil {
+		t.Fatalf("Failed to delete post: %v", err)
+	}
+	if _, _, err := purger.Purge(ctx, deletedAt(6*time.Hour)); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	assertIDs(t, "notifications after purging a post", notificationIDs(getNotifications(t, notifications, "Анна", false)), []string{})
+}
@@ -0,0 +1,162 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// editedAt - вспомогательная функция времени правки через offset после baseTime.
+func editedAt(offset time.Duration) time.Time {
+	return baseTime.Add(offset)
+}
+
+// revisionNumbers - вспомогательная функция номеров ревизий страницы в порядке выдачи.
+func revisionNumbers(connection *model.RevisionConnection) []int32 {
+	numbers := make([]int32, 0, len(connection.Edges))
+	for _, edge := range connection.Edges {
+		numbers = append(numbers, edge.Node.Number)
+	}
+	return numbers
+}
+
+// assertRevisions - вспомогательная функция сравнения номеров ревизий с ожидаемыми.
+func assertRevisions(t *testing.T, what string, got *model.RevisionConnection, want []int32) {
+	t.Helper()
+	numbers := revisionNumbers(got)
+	if len(numbers) != len(want) {
+		t.Fatalf("%s: expected revisions %v, got %v", what, want, numbers)
+	}
+	for i := range want {
+		if numbers[i] != want[i] {
+			t.Fatalf("%s: expected revisions %v, got %v", what, want, numbers)
+		}
+	}
+}
+
+// testPostRevisions проверяет правку поста: прежняя версия сохраняется только при изменении заголовка или текста.
+func testPostRevisions(t *testing.T, posts data.PostStore, _ data.CommentStore) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+
+	title := "Новый заголовок"
+	updated, err := posts.UpdatePost(ctx, post.ID, data.PostUpdate{Title: &title, EditedAt: editedAt(time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to update post: %v", err)
+	}
+	if updated.Title != title || updated.Content != post.Content || updated.RevisionCount != 1 ||
+		updated.EditedAt == nil || !updated.EditedAt.Equal(editedAt(time.Hour)) {
+		t.Errorf("Unexpected updated post: %+v", updated)
+	}
+
+	// Изменение только allowComments не создает ревизию и не меняет editedAt.
+	allow := false
+	updated, err = posts.UpdatePost(ctx, post.ID, data.PostUpdate{AllowComments: &allow, EditedAt: editedAt(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to update post: %v", err)
+	}
+	if updated.AllowComments || updated.RevisionCount != 1 || !updated.EditedAt.Equal(editedAt(time.Hour)) {
+		t.Errorf("Expected allowComments change without a revision, got %+v", updated)
+	}
+
+	stored, err := posts.GetPostByID(ctx, post.ID)
+	if err != nil {
+		t.Fatalf("Failed to get post: %v", err)
+	}
+	if stored.Title != title || stored.RevisionCount != 1 {
+		t.Errorf("Expected stored post to be updated, got %+v", stored)
+	}
+
+	page, err := posts.GetPostRevisions(ctx, post.ID, 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to get post revisions: %v", err)
+	}
+	assertRevisions(t, "post revisions", page, []int32{1})
+	revision := page.Edges[0].Node
+	if revision.Title == nil || *revision.Title != post.Title || revision.Content == nil || *revision.Content != post.Content ||
+		!revision.CreatedAt.Equal(post.CreatedAt) {
+		t.Errorf("Expected the original version in the revision, got %+v", revision)
+	}
+
+	if _, err := posts.UpdatePost(ctx, uuid.NewString(), data.PostUpdate{Title: &title, EditedAt: editedAt(time.Hour)}); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing post, got %v", err)
+	}
+	page, err = posts.GetPostRevisions(ctx, uuid.NewString(), 10, nil)
+	if err != nil || len(page.Edges) != 0 {
+		t.Errorf("Expected empty revisions for a missing post, got %v, %v", page, err)
+	}
+}
+
+// testCommentRevisions проверяет правку комментария и пагинацию его ревизий по курсорам.
+func testCommentRevisions(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	comment := addComment(t, comments, post.ID, nil, time.Second)
+
+	// Повтор того же текста не создает ревизию.
+	unchanged, err := comments.UpdateComment(ctx, comment.ID, comment.Content, nil, editedAt(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to update comment: %v", err)
+	}
+	if unchanged.RevisionCount != 0 || unchanged.EditedAt != nil {
+		t.Errorf("Expected unchanged comment, got %+v", unchanged)
+	}
+
+	const edits = 5
+	for i := 1; i <= edits; i++ {
+		content := "Правка " + string(rune('0'+i))
+		updated, err := comments.UpdateComment(ctx, comment.ID, content, nil, editedAt(time.Duration(i)*time.Hour))
+		if err != nil {
+			t.Fatalf("Failed to update comment: %v", err)
+		}
+		if updated.Content != content || updated.RevisionCount != int32(i) {
+			t.Fatalf("Unexpected updated comment: %+v", updated)
+		}
+	}
+
+	var got []int32
+	var after *string
+	for {
+		page, err := comments.GetCommentRevisions(ctx, comment.ID, 2, after)
+		if err != nil {
+			t.Fatalf("Failed to get comment revisions: %v", err)
+		}
+		got = append(got, revisionNumbers(page)...)
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		after = &page.Edges[len(page.Edges)-1].Cursor
+	}
+	want := []int32{1, 2, 3, 4, 5}
+	if len(got) != len(want) {
+		t.Fatalf("Expected revisions %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected revisions %v, got %v", want, got)
+		}
+	}
+
+	// Вторая ревизия - версия после первой правки, созданная в момент этой правки.
+	page, err := comments.GetCommentRevisions(ctx, comment.ID, 2, nil)
+	if err != nil {
+		t.Fatalf("Failed to get comment revisions: %v", err)
+	}
+	second := page.Edges[1].Node
+	if second.Content == nil || *second.Content != "Правка 1" || !second.CreatedAt.Equal(editedAt(time.Hour)) || second.Title != nil {
+		t.Errorf("Unexpected second revision: %+v", second)
+	}
+
+	if _, err := comments.UpdateComment(ctx, uuid.NewString(), "Текст", nil, editedAt(time.Hour)); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing comment, got %v", err)
+	}
+	invalid := "not-a-cursor"
+	if _, err := comments.GetCommentRevisions(ctx, comment.ID, 2, &invalid); !errors.Is(err, data.ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/search"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearcherFactory - функция создания пустых хранилищ и data.Searcher над ними для одного теста.
+type SearcherFactory func(t *testing.T) (data.PostStore, data.CommentStore, data.Searcher)
+
+// RunSearcher - функция запуска тестов соответствия для реализаций data.Searcher.
+func RunSearcher(t *testing.T, newStores SearcherFactory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, posts data.PostStore, comments data.CommentStore, searcher data.Searcher)
+	}{
+		{"SearchWordForms", testSearchWordForms},
+		{"SearchAllTerms", testSearchAllTerms},
+		{"SearchTitleRanksHigher", testSearchTitleRanksHigher},
+		{"SearchTypeFilter", testSearchTypeFilter},
+		{"SearchExcludesDeleted", testSearchExcludesDeleted},
+		{"SearchPagination", testSearchPagination},
+		{"SearchInvalidCursor", testSearchInvalidCursor},
+		{"SearchReindexesUpdates", testSearchReindexesUpdates},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, comments, searcher := newStores(t)
+			tt.test(t, posts, comments, searcher)
+		})
+	}
+}
+
+// addPostWithText - вспомогательная функция добавления поста с заданными заголовком и текстом.
+func addPostWithText(t *testing.T, posts data.PostStore, title, content string, offset time.Duration) *model.Post {
+	t.Helper()
+	post := &model.Post{
+		ID:            uuid.NewString(),
+		Author:        "Автор",
+		Title:         title,
+		Content:       content,
+		CreatedAt:     baseTime.Add(offset),
+		AllowComments: true,
+	}
+	if err := posts.AddPost(context.Background(), post); err != nil {
+		t.Fatalf("Failed to add post: %v", err)
+	}
+	return post
+}
+
+// addCommentWithText - вспомогательная функция добавления комментария к посту с заданным текстом.
+func addCommentWithText(t *testing.T, comments data.CommentStore, postID, content string, offset time.Duration) *model.Comment {
+	t.Helper()
+	comment := &model.Comment{
+		ID:        uuid.NewString(),
+		PostID:    postID,
+		Author:    "Комментатор",
+		Content:   content,
+		CreatedAt: baseTime.Add(offset),
+	}
+	if err := comments.AddComment(context.Background(), comment); err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+	return comment
+}
+
+// searchIDs - вспомогательная функция поиска первых 100 результатов; возвращает ID найденных записей по порядку.
+func searchIDs(t *testing.T, searcher data.Searcher, query string, types ...model.SearchType) []string {
+	t.Helper()
+	connection, err := searcher.Search(context.Background(), query, types, 100, nil)
+	if err != nil {
+		t.Fatalf("Failed to search %q: %v", query, err)
+	}
+	return hitIDs(connection)
+}
+
+// hitIDs - вспомогательная функция получения ID найденных записей страницы.
+func hitIDs(connection *model.SearchConnection) []string {
+	ids := make([]string, len(connection.Edges))
+	for i, edge := range connection.Edges {
+		switch node := edge.Node.(type) {
+		case *model.Post:
+			ids[i] = node.ID
+		case *model.Comment:
+			ids[i] = node.ID
+		}
+	}
+	return ids
+}
+
+// testSearchWordForms проверяет, что русские и английские слова находятся в других формах,
+// а найденные слова выделены во фрагменте.
+func testSearchWordForms(t *testing.T, posts data.PostStore, _ data.CommentStore, searcher data.Searcher) {
+	russian := addPostWithText(t, posts, "Домашние животные", "Наши кошки любят молоко", 0)
+	english := addPostWithText(t, posts, "Pets", "The cats are running around", time.Hour)
+
+	connection, err := searcher.Search(context.Background(), "кошка", nil, 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	assertIDs(t, "russian word forms", hitIDs(connection), []string{russian.ID})
+	if snippet := connection.Edges[0].Snippet; !strings.Contains(snippet, search.MarkStart+"кошки"+search.MarkEnd) {
+		t.Errorf("Expected highlighted word in snippet, got %q", snippet)
+	}
+
+	assertIDs(t, "english word forms", searchIDs(t, searcher, "cat run"), []string{english.ID})
+}
+
+// testSearchAllTerms проверяет, что находятся только записи со всеми значимыми словами запроса,
+// а запрос из одних стоп-слов ничего не находит.
+func testSearchAllTerms(t *testing.T, posts data.PostStore, _ data.CommentStore, searcher data.Searcher) {
+	both := addPostWithText(t, posts, "Кошка и собака", "Живут вместе", 0)
+	addPostWithText(t, posts, "Кошка", "Живет одна", time.Hour)
+
+	assertIDs(t, "all terms", searchIDs(t, searcher, "кошки собаки"), []string{both.ID})
+	assertIDs(t, "stop words", searchIDs(t, searcher, "и в на"), []string{})
+}
+
+// testSearchTitleRanksHigher проверяет, что совпадение в заголовке поста ранжируется выше совпадения в тексте.
+func testSearchTitleRanksHigher(t *testing.T, posts data.PostStore, _ data.CommentStore, searcher data.Searcher) {
+	inContent := addPostWithText(t, posts, "Заметка", "Сегодня кошка спала весь день на диване", time.Hour)
+	inTitle := addPostWithText(t, posts, "Кошки", "Сегодня спала весь день на диване", 0)
+
+	assertIDs(t, "title ranking", searchIDs(t, searcher, "кошка"), []string{inTitle.ID, inContent.ID})
+}
+
+// testSearchTypeFilter проверяет фильтр типов найденных записей.
+func testSearchTypeFilter(t *testing.T, posts data.PostStore, comments data.CommentStore, searcher data.Searcher) {
+	post := addPostWithText(t, posts, "Молоко", "Свежее молоко", 0)
+	comment := addCommentWithText(t, comments, post.ID, "Молоко закончилось", time.Hour)
+
+	if ids := searchIDs(t, searcher, "молоко"); len(ids) != 2 {
+		t.Errorf("Expected post and comment without filter, got %v", ids)
+	}
+	assertIDs(t, "posts only", searchIDs(t, searcher, "молоко", model.SearchTypePost), []string{post.ID})
+	assertIDs(t, "comments only", searchIDs(t, searcher, "молоко", model.SearchTypeComment), []string{comment.ID})
+}
+
+// testSearchExcludesDeleted проверяет, что удаленные записи и комментарии удаленных постов не находятся.
+func testSearchExcludesDeleted(t *testing.T, posts data.PostStore, comments data.CommentStore, searcher data.Searcher) {
+	ctx := context.Background()
+	kept := addPostWithText(t, posts, "Сад", "Яблоки созрели", 0)
+	deletedComment := addCommentWithText(t, comments, kept.ID, "Яблоки кислые", time.Hour)
+	deletedPost := addPostWithText(t, posts, "Огород", "Яблоки упали", 2*time.Hour)
+	addCommentWithText(t, comments, deletedPost.ID, "Яблоки собрали", 3*time.Hour)
+
+	if _, err := comments.DeleteComment(ctx, deletedComment.ID, "Модератор", deletedAt(4*time.Hour)); err != nil {
+		t.Fatalf("Failed to delete comment: %v", err)
+	}
+	if _, err := posts.DeletePost(ctx, deletedPost.ID, "Модератор", deletedAt(4*time.Hour)); err != nil {
+		t.Fatalf("Failed to delete post: %v", err)
+	}
+
+	assertIDs(t, "search after deletion", searchIDs(t, searcher, "яблоко"), []string{kept.ID})
+}
+
+// testSearchPagination проверяет страницы результатов: при равном ранге записи идут от новых к старым,
+// курсор последней записи страницы продолжает выдачу без пропусков и повторов.
+func testSearchPagination(t *testing.T, posts data.PostStore, _ data.CommentStore, searcher data.Searcher) {
+	ctx := context.Background()
+	var want []string
+	for i := range 3 {
+		post := addPostWithText(t, posts, "Погода", "Идет снег", time.Duration(i)*time.Hour)
+		want = append([]string{post.ID}, want...)
+	}
+
+	connection, err := searcher.Search(ctx, "снег", nil, 2, nil)
+	if err != nil {
+		t.Fatalf("Failed to search first page: %v", err)
+	}
+	assertIDs(t, "first page", hitIDs(connection), want[:2])
+	if !connection.PageInfo.HasNextPage {
+		t.Errorf("Expected next page after first page")
+	}
+
+	connection, err = searcher.Search(ctx, "снег", nil, 2, &connection.Edges[1].Cursor)
+	if err != nil {
+		t.Fatalf("Failed to search second page: %v", err)
+	}
+	assertIDs(t, "second page", hitIDs(connection), want[2:])
+	if connection.PageInfo.HasNextPage {
+		t.Errorf("Expected no next page after last page")
+	}
+
+	connection, err = searcher.Search(ctx, "снег", nil, 0, nil)
+	if err != nil || len(connection.Edges) != 0 {
+		t.Errorf("Expected empty page for first=0, got %v, %v", connection, err)
+	}
+}
+
+// testSearchInvalidCursor проверяет, что некорректный курсор возвращает data.ErrInvalidCursor.
+func testSearchInvalidCursor(t *testing.T, posts data.PostStore, _ data.CommentStore, searcher data.Searcher) {
+	addPostWithText(t, posts, "Погода", "Идет снег", 0)
+
+	for _, cursor := range []string{"garbage", data.EncodeCursor(baseTime, uuid.NewString())} {
+		if _, err := searcher.Search(context.Background(), "снег", nil, 10, &cursor); !errors.Is(err, data.ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor for cursor %q, got %v", cursor, err)
+		}
+	}
+}
+
+// testSearchReindexesUpdates проверяет, что правка поста и комментария меняет то, по каким словам они находятся.
+func testSearchReindexesUpdates(t *testing.T, posts data.PostStore, comments data.CommentStore, searcher data.Searcher) {
+	ctx := context.Background()
+	post := addPostWithText(t, posts, "Заметка", "Про кошку", 0)
+	comment := addCommentWithText(t, comments, post.ID, "Тоже про кошку", time.Hour)
+
+	content, editedAt := "Про собаку", baseTime.Add(2*time.Hour)
+	if _, err := posts.UpdatePost(ctx, post.ID, data.PostUpdate{Content: &content, EditedAt: editedAt}); err != nil {
+		t.Fatalf("Failed to update post: %v", err)
+	}
+	if _, err := comments.UpdateComment(ctx, comment.ID, "Тоже про собаку", nil, editedAt); err != nil {
+		t.Fatalf("Failed to update comment: %v", err)
+	}
+
+	assertIDs(t, "old words", searchIDs(t, searcher, "кошка"), []string{})
+	if ids := searchIDs(t, searcher, "собака"); len(ids) != 2 {
+		t.Errorf("Expected updated post and comment to match new words, got %v", ids)
+	}
+}
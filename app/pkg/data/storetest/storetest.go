@@ -0,0 +1,564 @@
+// Package storetest - общий набор тестов соответствия для реализаций data.PostStore и data.CommentStore.
+// Пакет хранилища запускает его из своего теста, передавая функцию создания пустых хранилищ:
+//
+//	func TestConformance(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) (data.PostStore, data.CommentStore) { ... })
+//	}
+//
+// Идентификаторы в тестах - UUID, а даты - с точностью до микросекунды, поэтому набор подходит и для PostgreSQL.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Factory - функция создания пары пустых хранилищ для одного теста.
+// Хранилища, созданные для разных тестов, не должны видеть данные друг друга.
+type Factory func(t *testing.T) (data.PostStore, data.CommentStore)
+
+// baseTime - дата создания первой записи в тестах; последующие записи создаются позже.
+var baseTime = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// Run - функция запуска всего набора тестов соответствия для хранилищ, создаваемых newStores.
+func Run(t *testing.T, newStores Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, posts data.PostStore, comments data.CommentStore)
+	}{
+		{"PostsOrdering", testPostsOrdering},
+		{"PostsPaginationEdges", testPostsPaginationEdges},
+		{"PostsCursorRoundTrip", testPostsCursorRoundTrip},
+		{"PostsFilter", testPostsFilter},
+		{"PostsFilterPagination", testPostsFilterPagination},
+		{"PostsTagFilter", testPostsTagFilter},
+		{"PostTags", testPostTags},
+		{"TagCounts", testTagCounts},
+		{"CommentsOrderingAndScoping", testCommentsOrderingAndScoping},
+		{"CommentsPaginationEdges", testCommentsPaginationEdges},
+		{"CommentsCursorRoundTrip", testCommentsCursorRoundTrip},
+		{"RepliesScoping", testRepliesScoping},
+		{"InvalidCursor", testInvalidCursor},
+		{"NotFound", testNotFound},
+		{"Conflict", testConflict},
+		{"ConcurrentWrites", testConcurrentWrites},
+		{"ConcurrentConflict", testConcurrentConflict},
+		{"PostRevisions", testPostRevisions},
+		{"CommentRevisions", testCommentRevisions},
+		{"CommentMentions", testCommentMentions},
+		{"PostDeletion", testPostDeletion},
+		{"CommentDeletion", testCommentDeletion},
+		{"TimestampPrecision", testTimestampPrecision},
+		{"SubSecondOrdering", testSubSecondOrdering},
+		{"PostCounts", testPostCounts},
+		{"CommentCounts", testCommentCounts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, comments := newStores(t)
+			tt.test(t, posts, comments)
+		})
+	}
+}
+
+// addPost - вспомогательная функция добавления поста, созданного через offset после baseTime.
+func addPost(t *testing.T, posts data.PostStore, offset time.Duration) *model.Post {
+	t.Helper()
+	post := &model.Post{
+		ID:            uuid.NewString(),
+		Author:        "Автор",
+		Title:         "Пост",
+		Content:       "Текст поста",
+		CreatedAt:     baseTime.Add(offset),
+		AllowComments: true,
+	}
+	if err := posts.AddPost(context.Background(), post); err != nil {
+		t.Fatalf("Failed to add post: %v", err)
+	}
+	return post
+}
+
+// addComment - вспомогательная функция добавления комментария (ответа, если parentID не nil).
+func addComment(t *testing.T, comments data.CommentStore, postID string, parentID *string, offset time.Duration) *model.Comment {
+	t.Helper()
+	comment := &model.Comment{
+		ID:        uuid.NewString(),
+		PostID:    postID,
+		ParentID:  parentID,
+		Author:    "Комментатор",
+		Content:   "Текст комментария",
+		CreatedAt: baseTime.Add(offset),
+	}
+	if err := comments.AddComment(context.Background(), comment); err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+	return comment
+}
+
+// postIDs - вспомогательная функция получения ID постов страницы.
+func postIDs(connection *model.PostConnection) []string {
+	ids := make([]string, len(connection.Edges))
+	for i, edge := range connection.Edges {
+		ids[i] = edge.Node.ID
+	}
+	return ids
+}
+
+// commentIDs - вспомогательная функция получения ID комментариев страницы.
+func commentIDs(connection *model.CommentConnection) []string {
+	ids := make([]string, len(connection.Edges))
+	for i, edge := range connection.Edges {
+		ids[i] = edge.Node.ID
+	}
+	return ids
+}
+
+// assertIDs - вспомогательная функция сравнения списков ID с учетом порядка.
+func assertIDs(t *testing.T, what string, got, want []string) {
+	t.Helper()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Unexpected %s:\n got: %v\nwant: %v", what, got, want)
+	}
+}
+
+// testPostsOrdering проверяет порядок постов: от новых к старым, при равной дате - по возрастанию ID.
+func testPostsOrdering(t *testing.T, posts data.PostStore, _ data.CommentStore) {
+	oldest := addPost(t, posts, 0)
+	newest := addPost(t, posts, 2*time.Hour)
+	tie := []string{addPost(t, posts, time.Hour).ID, addPost(t, posts, time.Hour).ID, addPost(t, posts, time.Hour).ID}
+	sort.Strings(tie)
+
+	connection, err := posts.GetPosts(context.Background(), 0, nil, model.PostFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get posts: %v", err)
+	}
+
+	want := append(append([]string{newest.ID}, tie...), oldest.ID)
+	assertIDs(t, "post order", postIDs(connection), want)
+
+	got := connection.Edges[len(connection.Edges)-1].Node
+	if got.Title != oldest.Title || got.Author != oldest.Author || got.Content != oldest.Content ||
+		!got.CreatedAt.Equal(oldest.CreatedAt) || got.AllowComments != oldest.AllowComments {
+		t.Errorf("Post fields were not preserved: got %+v, want %+v", got, oldest)
+	}
+}
+
+// testPostsPaginationEdges проверяет границы страниц: first равное 0, ровно все записи, больше записей и пустое хранилище.
+func testPostsPaginationEdges(t *testing.T, posts data.PostStore, _ data.CommentStore) {
+	ctx := context.Background()
+
+	connection, err := posts.GetPosts(ctx, 10, nil, model.PostFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get posts: %v", err)
+	}
+	if len(connection.Edges) != 0 || connection.PageInfo.HasNextPage {
+		t.Errorf("Expected empty page for empty store, got %d posts (hasNextPage %t)", len(connection.Edges), connection.PageInfo.HasNextPage)
+	}
+
+	for i := 0; i < 3; i++ {
+		addPost(t, posts, time.Duration(i)*time.Minute)
+	}
+
+	for _, tc := range []struct {
+		first       int32
+		wantEdges   int
+		hasNextPage bool
+	}{
+		{first: 0, wantEdges: 3, hasNextPage: false}, // 0 - все посты.
+		{first: 1, wantEdges: 1, hasNextPage: true},
+		{first: 2, wantEdges: 2, hasNextPage: true},
+		{first: 3, wantEdges: 3, hasNextPage: false}, // Ровно все посты: следующей страницы нет.
+		{first: 10, wantEdges: 3, hasNextPage: false},
+	} {
+		connection, err := posts.GetPosts(ctx, tc.first, nil, model.PostFilter{})
+		if err != nil {
+			t.Fatalf("Failed to get posts (first %d): %v", tc.first, err)
+		}
+		if len(connection.Edges) != tc.wantEdges || connection.PageInfo.HasNextPage != tc.hasNextPage {
+			t.Errorf("first %d: expected %d posts (hasNextPage %t), got %d (hasNextPage %t)",
+				tc.first, tc.wantEdges, tc.hasNextPage, len(connection.Edges), connection.PageInfo.HasNextPage)
+		}
+	}
+
+	// Курсор последнего поста дает пустую последнюю страницу.
+	all, _ := posts.GetPosts(ctx, 0, nil, model.PostFilter{})
+	last := all.Edges[len(all.Edges)-1].Cursor
+	connection, err = posts.GetPosts(ctx, 10, &last, model.PostFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get posts after last cursor: %v", err)
+	}
+	if len(connection.Edges) != 0 || connection.PageInfo.HasNextPage {
+		t.Errorf("Expected empty page after last post, got %v", postIDs(connection))
+	}
+}
+
+// testPostsCursorRoundTrip проверяет, что обход по курсорам возвращает все посты ровно один раз и в том же порядке.
+func testPostsCursorRoundTrip(t *testing.T, posts data.PostStore, _ data.CommentStore) {
+	ctx := context.Background()
+	for i := 0; i < 7; i++ {
+		addPost(t, posts, time.Duration(i/2)*time.Minute) // Пары постов с одинаковой датой.
+	}
+
+	all, err := posts.GetPosts(ctx, 0, nil, model.PostFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get posts: %v", err)
+	}
+
+	var walked []string
+	var after *string
+	for pages := 0; pages < 10; pages++ {
+		connection, err := posts.GetPosts(ctx, 3, after, model.PostFilter{})
+		if err != nil {
+			t.Fatalf("Failed to get page %d: %v", pages, err)
+		}
+		for _, edge := range connection.Edges {
+			if edge.Cursor == "" {
+				t.Fatalf("Expected non-empty cursor for post %s", edge.Node.ID)
+			}
+		}
+		walked = append(walked, postIDs(connection)...)
+		if !connection.PageInfo.HasNextPage {
+			break
+		}
+		after = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	assertIDs(t, "posts walked by cursor", walked, postIDs(all))
+
+	// Курсор из середины списка продолжает выборку сразу после своего поста.
+	middle := all.Edges[3].Cursor
+	connection, err := posts.GetPosts(ctx, 2, &middle, model.PostFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get posts after middle cursor: %v", err)
+	}
+	assertIDs(t, "posts after middle cursor", postIDs(connection), postIDs(all)[4:6])
+}
+
+// testCommentsOrderingAndScoping проверяет порядок комментариев (от старых к новым, при равной дате - по ID)
+// и то, что в выборку попадают только комментарии своего поста.
+func testCommentsOrderingAndScoping(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	post := addPost(t, posts, 0)
+	other := addPost(t, posts, 0)
+
+	first := addComment(t, comments, post.ID, nil, time.Minute)
+	tie := []string{addComment(t, comments, post.ID, nil, 2*time.Minute).ID, addComment(t, comments, post.ID, nil, 2*time.Minute).ID}
+	sort.Strings(tie)
+	reply := addComment(t, comments, post.ID, &first.ID, 3*time.Minute) // Ответы тоже относятся к посту.
+	addComment(t, comments, other.ID, nil, 0)
+
+	connection, err := comments.GetCommentsForPost(context.Background(), post.ID, 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to get comments: %v", err)
+	}
+
+	want := append(append([]string{first.ID}, tie...), reply.ID)
+	assertIDs(t, "comment order", commentIDs(connection), want)
+
+	got := connection.Edges[0].Node
+	if got.PostID != post.ID || got.ParentID != nil || got.Author != first.Author || got.Content != first.Content || !got.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("Comment fields were not preserved: got %+v, want %+v", got, first)
+	}
+	if got := connection.Edges[3].Node; got.ParentID == nil || *got.ParentID != first.ID {
+		t.Errorf("Expected reply to keep parent %s, got %v", first.ID, got.ParentID)
+	}
+}
+
+// testCommentsPaginationEdges проверяет границы страниц комментариев; first равное 0 дает пустую страницу.
+func testCommentsPaginationEdges(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+
+	connection, err := comments.GetCommentsForPost(ctx, post.ID, 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to get comments: %v", err)
+	}
+	if len(connection.Edges) != 0 || connection.PageInfo.HasNextPage {
+		t.Errorf("Expected no comments for new post, got %v", commentIDs(connection))
+	}
+
+	for i := 0; i < 3; i++ {
+		addComment(t, comments, post.ID, nil, time.Duration(i)*time.Minute)
+	}
+
+	for _, tc := range []struct {
+		first       int32
+		wantEdges   int
+		hasNextPage bool
+	}{
+		{first: 0, wantEdges: 0, hasNextPage: false}, // 0 - пустая страница.
+		{first: 2, wantEdges: 2, hasNextPage: true},
+		{first: 3, wantEdges: 3, hasNextPage: false}, // Ровно все комментарии: следующей страницы нет.
+		{first: 10, wantEdges: 3, hasNextPage: false},
+	} {
+		connection, err := comments.GetCommentsForPost(ctx, post.ID, tc.first, nil)
+		if err != nil {
+			t.Fatalf("Failed to get comments (first %d): %v", tc.first, err)
+		}
+		if len(connection.Edges) != tc.wantEdges || connection.PageInfo.HasNextPage != tc.hasNextPage {
+			t.Errorf("first %d: expected %d comments (hasNextPage %t), got %d (hasNextPage %t)",
+				tc.first, tc.wantEdges, tc.hasNextPage, len(connection.Edges), connection.PageInfo.HasNextPage)
+		}
+	}
+}
+
+// testCommentsCursorRoundTrip проверяет обход комментариев поста по курсорам.
+func testCommentsCursorRoundTrip(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+
+	var want []string
+	for i := 0; i < 5; i++ {
+		want = append(want, addComment(t, comments, post.ID, nil, time.Duration(i)*time.Minute).ID)
+	}
+
+	var walked []string
+	var after *string
+	for pages := 0; pages < 10; pages++ {
+		connection, err := comments.GetCommentsForPost(ctx, post.ID, 2, after)
+		if err != nil {
+			t.Fatalf("Failed to get page %d: %v", pages, err)
+		}
+		walked = append(walked, commentIDs(connection)...)
+		if !connection.PageInfo.HasNextPage {
+			break
+		}
+		after = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	assertIDs(t, "comments walked by cursor", walked, want)
+}
+
+// testRepliesScoping проверяет, что ответы выбираются только для своего родителя, без вложенных ответов,
+// и что курсоры ответов работают так же, как курсоры комментариев поста.
+func testRepliesScoping(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+
+	parent := addComment(t, comments, post.ID, nil, 0)
+	sibling := addComment(t, comments, post.ID, nil, time.Minute)
+
+	var want []string
+	for i := 0; i < 3; i++ {
+		want = append(want, addComment(t, comments, post.ID, &parent.ID, time.Duration(i+2)*time.Minute).ID)
+	}
+	addComment(t, comments, post.ID, &want[0], 10*time.Minute) // Ответ на ответ - не прямой ответ parent.
+	addComment(t, comments, post.ID, &sibling.ID, 11*time.Minute)
+
+	connection, err := comments.GetRepliesForComment(ctx, parent.ID, 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to get replies: %v", err)
+	}
+	assertIDs(t, "replies", commentIDs(connection), want)
+
+	connection, err = comments.GetRepliesForComment(ctx, parent.ID, 2, nil)
+	if err != nil || !connection.PageInfo.HasNextPage {
+		t.Fatalf("Expected first page of replies with next page, got %+v (%v)", connection, err)
+	}
+	connection, err = comments.GetRepliesForComment(ctx, parent.ID, 2, &connection.Edges[1].Cursor)
+	if err != nil {
+		t.Fatalf("Failed to get replies after cursor: %v", err)
+	}
+	assertIDs(t, "replies after cursor", commentIDs(connection), want[2:])
+
+	connection, err = comments.GetRepliesForComment(ctx, want[2], 10, nil)
+	if err != nil || len(connection.Edges) != 0 {
+		t.Errorf("Expected no replies for leaf comment, got %+v (%v)", connection, err)
+	}
+}
+
+// testInvalidCursor проверяет, что поврежденный курсор приводит к ошибке ErrInvalidCursor, а не к выборке с начала.
+func testInvalidCursor(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	addComment(t, comments, post.ID, nil, 0)
+
+	for _, cursor := range []string{"not a cursor", post.ID, data.EncodeCursor(baseTime, "")} {
+		if _, err := posts.GetPosts(ctx, 10, &cursor, model.PostFilter{}); !errors.Is(err, data.ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor for posts cursor %q, got %v", cursor, err)
+		}
+		if _, err := comments.GetCommentsForPost(ctx, post.ID, 10, &cursor); !errors.Is(err, data.ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor for comments cursor %q, got %v", cursor, err)
+		}
+	}
+}
+
+// testNotFound проверяет, что несуществующие записи дают ErrNotFound, в том числе для ID не в формате UUID.
+func testNotFound(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	addPost(t, posts, 0)
+
+	for _, id := range []string{uuid.NewString(), "missing"} {
+		if post, err := posts.GetPostByID(ctx, id); !errors.Is(err, data.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for missing post %q, got %+v (%v)", id, post, err)
+		}
+		if comment, err := comments.GetCommentByID(ctx, id); !errors.Is(err, data.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for missing comment %q, got %+v (%v)", id, comment, err)
+		}
+	}
+
+	connection, err := comments.GetCommentsForPost(ctx, uuid.NewString(), 10, nil)
+	if err != nil || len(connection.Edges) != 0 {
+		t.Errorf("Expected empty comments for missing post, got %+v (%v)", connection, err)
+	}
+}
+
+// testConflict проверяет, что повторное добавление записи с тем же ID дает ErrConflict и не меняет исходную запись.
+func testConflict(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	comment := addComment(t, comments, post.ID, nil, 0)
+
+	duplicatePost := *post
+	duplicatePost.Title = "Другой заголовок"
+	if err := posts.AddPost(ctx, &duplicatePost); !errors.Is(err, data.ErrConflict) {
+		t.Errorf("Expected ErrConflict for duplicate post, got %v", err)
+	}
+	duplicateComment := *comment
+	duplicateComment.Content = "Другой текст"
+	if err := comments.AddComment(ctx, &duplicateComment); !errors.Is(err, data.ErrConflict) {
+		t.Errorf("Expected ErrConflict for duplicate comment, got %v", err)
+	}
+
+	if got, err := posts.GetPostByID(ctx, post.ID); err != nil || got.Title != post.Title {
+		t.Errorf("Expected original post to be kept, got %+v (%v)", got, err)
+	}
+	if got, err := comments.GetCommentByID(ctx, comment.ID); err != nil || got.Content != comment.Content {
+		t.Errorf("Expected original comment to be kept, got %+v (%v)", got, err)
+	}
+}
+
+// testConcurrentConflict проверяет, что из параллельных добавлений записей с одним ID успешно ровно одно,
+// остальные получают ErrConflict, а в хранилище остается запись, добавленная успешно.
+func testConcurrentConflict(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	const workers = 8
+	ctx := context.Background()
+	post := newPost(0)
+	comment := newComment(post.ID, nil, time.Second)
+
+	postErrs, commentErrs := make([]error, workers), make([]error, workers)
+	concurrently(workers, func(w int) {
+		duplicate := *post
+		duplicate.Title = fmt.Sprintf("Пост %d", w)
+		postErrs[w] = posts.AddPost(ctx, &duplicate)
+	})
+	concurrently(workers, func(w int) {
+		duplicate := *comment
+		duplicate.Content = fmt.Sprintf("Комментарий %d", w)
+		commentErrs[w] = comments.AddComment(ctx, &duplicate)
+	})
+
+	winner := assertOneAdded(t, "post", postErrs)
+	if got, err := posts.GetPostByID(ctx, post.ID); err != nil || got.Title != fmt.Sprintf("Пост %d", winner) {
+		t.Errorf("Expected post of worker %d, got %+v (%v)", winner, got, err)
+	}
+	winner = assertOneAdded(t, "comment", commentErrs)
+	if got, err := comments.GetCommentByID(ctx, comment.ID); err != nil || got.Content != fmt.Sprintf("Комментарий %d", winner) {
+		t.Errorf("Expected comment of worker %d, got %+v (%v)", winner, got, err)
+	}
+	page, err := comments.GetCommentsForPost(ctx, post.ID, workers, nil)
+	if err != nil || len(page.Edges) != 1 {
+		t.Errorf("Expected a single comment, got %+v (%v)", page, err)
+	}
+}
+
+// concurrently - вспомогательная функция одновременного запуска fn в workers горутинах: все горутины
+// ждут общего старта, чтобы вызовы действительно пересекались. Возвращается после завершения всех вызовов.
+func concurrently(workers int, fn func(w int)) {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			fn(w)
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+// assertOneAdded - вспомогательная функция проверки результатов параллельных добавлений с одним ID:
+// ровно одно без ошибки, остальные с ErrConflict. Возвращает номер успешного добавления.
+func assertOneAdded(t *testing.T, kind string, errs []error) int {
+	t.Helper()
+	winner := -1
+	for w, err := range errs {
+		switch {
+		case err == nil && winner < 0:
+			winner = w
+		case err == nil:
+			t.Errorf("Expected a single %s to be added, workers %d and %d succeeded", kind, winner, w)
+		case !errors.Is(err, data.ErrConflict):
+			t.Errorf("Expected ErrConflict for duplicate %s, got %v", kind, err)
+		}
+	}
+	if winner < 0 {
+		t.Fatalf("Expected one %s to be added, all failed", kind)
+	}
+	return winner
+}
+
+// testConcurrentWrites проверяет, что параллельное добавление постов и комментариев не теряет записи.
+func testConcurrentWrites(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	const (
+		workers   = 8
+		perWorker = 10
+	)
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				offset := time.Duration(w*perWorker+i) * time.Second
+				errs <- posts.AddPost(ctx, &model.Post{
+					ID: uuid.NewString(), Author: "Автор", Title: "Пост", Content: "Текст",
+					CreatedAt: baseTime.Add(offset), AllowComments: true,
+				})
+				errs <- comments.AddComment(ctx, &model.Comment{
+					ID: uuid.NewString(), PostID: post.ID, Author: "Комментатор", Content: "Текст",
+					CreatedAt: baseTime.Add(offset),
+				})
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Concurrent write failed: %v", err)
+		}
+	}
+
+	allPosts, err := posts.GetPosts(ctx, 0, nil, model.PostFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get posts: %v", err)
+	}
+	if got, want := len(allPosts.Edges), workers*perWorker+1; got != want {
+		t.Errorf("Expected %d posts after concurrent writes, got %d", want, got)
+	}
+
+	allComments, err := comments.GetCommentsForPost(ctx, post.ID, 2*workers*perWorker, nil)
+	if err != nil {
+		t.Fatalf("Failed to get comments: %v", err)
+	}
+	if got, want := len(allComments.Edges), workers*perWorker; got != want {
+		t.Errorf("Expected %d comments after concurrent writes, got %d", want, got)
+	}
+}
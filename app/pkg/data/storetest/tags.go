@@ -0,0 +1,137 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// addTaggedPost - вспомогательная функция добавления поста с нормализованными тегами.
+func addTaggedPost(t *testing.T, posts data.PostStore, offset time.Duration, tags ...string) *model.Post {
+	t.Helper()
+	post := &model.Post{
+		ID:            uuid.NewString(),
+		Author:        "Автор",
+		Title:         "Пост",
+		Content:       "Текст поста",
+		CreatedAt:     baseTime.Add(offset),
+		AllowComments: true,
+		Tags:          tags,
+	}
+	if err := posts.AddPost(context.Background(), post); err != nil {
+		t.Fatalf("Failed to add post: %v", err)
+	}
+	return post
+}
+
+// assertTags - вспомогательная функция сравнения тегов поста, прочитанного по ID, с ожидаемыми.
+func assertTags(t *testing.T, posts data.PostStore, id string, want ...string) {
+	t.Helper()
+	post, err := posts.GetPostByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to get post: %v", err)
+	}
+	if fmt.Sprint(post.Tags) != fmt.Sprint(want) {
+		t.Errorf("Unexpected tags of post %s: got %v, want %v", id, post.Tags, want)
+	}
+}
+
+// testPostTags проверяет сохранение тегов при добавлении поста и их замену при правке:
+// теги выдаются по алфавиту, правка без тегов их не меняет, пустой список удаляет все.
+func testPostTags(t *testing.T, posts data.PostStore, _ data.CommentStore) {
+	ctx := context.Background()
+	post := addTaggedPost(t, posts, 0, "новости", "go")
+	untagged := addTaggedPost(t, posts, time.Hour)
+	assertTags(t, posts, post.ID, "go", "новости")
+	assertTags(t, posts, untagged.ID)
+
+	title, editedAt := "Новый заголовок", baseTime.Add(2*time.Hour)
+	updated, err := posts.UpdatePost(ctx, post.ID, data.PostUpdate{Tags: []string{"базы-данных", "go"}, EditedAt: editedAt})
+	if err != nil {
+		t.Fatalf("Failed to update tags: %v", err)
+	}
+	if fmt.Sprint(updated.Tags) != "[go базы-данных]" || updated.RevisionCount != 0 {
+		t.Errorf("Expected replaced tags without revision, got %v and %d revisions", updated.Tags, updated.RevisionCount)
+	}
+
+	if _, err := posts.UpdatePost(ctx, post.ID, data.PostUpdate{Title: &title, EditedAt: editedAt}); err != nil {
+		t.Fatalf("Failed to update title: %v", err)
+	}
+	assertTags(t, posts, post.ID, "go", "базы-данных")
+
+	if _, err := posts.UpdatePost(ctx, post.ID, data.PostUpdate{Tags: []string{}, EditedAt: editedAt}); err != nil {
+		t.Fatalf("Failed to clear tags: %v", err)
+	}
+	assertTags(t, posts, post.ID)
+}
+
+// testTagCounts проверяет список тегов: число неудаленных постов с тегом и порядок
+// по убыванию числа, при равенстве - по названию.
+func testTagCounts(t *testing.T, posts data.PostStore, _ data.CommentStore) {
+	ctx := context.Background()
+	addTaggedPost(t, posts, 0, "go", "новости")
+	addTaggedPost(t, posts, time.Hour, "go", "sql")
+	addTaggedPost(t, posts, 2*time.Hour, "sql")
+	deleted := addTaggedPost(t, posts, 3*time.Hour, "go", "удаленный")
+	if _, err := posts.DeletePost(ctx, deleted.ID, "Модератор", deletedAt(4*time.Hour)); err != nil {
+		t.Fatalf("Failed to delete post: %v", err)
+	}
+
+	result, err := posts.GetTags(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get tags: %v", err)
+	}
+	var got []string
+	for _, tag := range result {
+		got = append(got, fmt.Sprintf("%s:%d", tag.Name, tag.PostCount))
+	}
+	if want := "[go:2 sql:2 новости:1]"; fmt.Sprint(got) != want {
+		t.Errorf("Unexpected tags: got %v, want %s", got, want)
+	}
+}
+
+// testPostsTagFilter проверяет фильтр постов по тегу вместе с пагинацией и правкой тегов.
+func testPostsTagFilter(t *testing.T, posts data.PostStore, _ data.CommentStore) {
+	ctx := context.Background()
+	first := addTaggedPost(t, posts, 0, "go")
+	addTaggedPost(t, posts, time.Hour, "sql")
+	second := addTaggedPost(t, posts, 2*time.Hour, "go", "sql")
+	retagged := addTaggedPost(t, posts, 3*time.Hour, "go")
+
+	editedAt := baseTime.Add(4 * time.Hour)
+	if _, err := posts.UpdatePost(ctx, retagged.ID, data.PostUpdate{Tags: []string{"sql"}, EditedAt: editedAt}); err != nil {
+		t.Fatalf("Failed to update tags: %v", err)
+	}
+
+	tag := "go"
+	filter := model.PostFilter{Tag: &tag}
+	connection, err := posts.GetPosts(ctx, 1, nil, filter)
+	if err != nil {
+		t.Fatalf("Failed to get first page: %v", err)
+	}
+	assertIDs(t, "first tagged page", postIDs(connection), []string{second.ID})
+	if !connection.PageInfo.HasNextPage {
+		t.Errorf("Expected next tagged page")
+	}
+
+	connection, err = posts.GetPosts(ctx, 1, &connection.Edges[0].Cursor, filter)
+	if err != nil {
+		t.Fatalf("Failed to get second page: %v", err)
+	}
+	assertIDs(t, "second tagged page", postIDs(connection), []string{first.ID})
+	if connection.PageInfo.HasNextPage {
+		t.Errorf("Expected no next tagged page")
+	}
+
+	unknown := "нет-такого"
+	connection, err = posts.GetPosts(ctx, 0, nil, model.PostFilter{Tag: &unknown})
+	if err != nil {
+		t.Fatalf("Failed to get posts with unknown tag: %v", err)
+	}
+	assertIDs(t, "unknown tag", postIDs(connection), []string{})
+}
@@ -0,0 +1,88 @@
+package storetest
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// testTimestampPrecision проверяет, что даты хранятся с точностью data.TimePrecision и возвращаются в UTC,
+// в каком бы часовом поясе их ни передали.
+func testTimestampPrecision(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	moscow := time.FixedZone("MSK", 3*60*60)
+	createdAt := baseTime.Add(123456789 * time.Nanosecond).In(moscow)
+
+	post := &model.Post{ID: uuid.NewString(), Author: "Автор", Title: "Пост", Content: "Текст", CreatedAt: createdAt}
+	if err := posts.AddPost(ctx, post); err != nil {
+		t.Fatalf("Failed to add post: %v", err)
+	}
+	assertTimestamp(t, "post createdAt", getPost(t, posts, post.ID).CreatedAt, baseTime.Add(123456*time.Microsecond))
+
+	comment := addComment(t, comments, post.ID, nil, time.Second)
+	edited, err := comments.UpdateComment(ctx, comment.ID, "Правка", nil, baseTime.Add(2*time.Second+999*time.Nanosecond))
+	if err != nil {
+		t.Fatalf("Failed to update comment: %v", err)
+	}
+	if edited.EditedAt == nil {
+		t.Fatal("Expected editedAt to be set")
+	}
+	assertTimestamp(t, "comment editedAt", *edited.EditedAt, baseTime.Add(2*time.Second))
+
+	stored, err := comments.GetCommentByID(ctx, comment.ID)
+	if err != nil {
+		t.Fatalf("Failed to get comment: %v", err)
+	}
+	assertTimestamp(t, "stored comment editedAt", *stored.EditedAt, baseTime.Add(2*time.Second))
+}
+
+// testSubSecondOrdering проверяет, что комментарии, созданные в одну секунду, выдаются в порядке создания,
+// в том числе постранично.
+func testSubSecondOrdering(t *testing.T, posts data.PostStore, comments data.CommentStore) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	third := addComment(t, comments, post.ID, nil, 300*time.Millisecond)
+	first := addComment(t, comments, post.ID, nil, 100*time.Millisecond)
+	second := addComment(t, comments, post.ID, nil, 100*time.Millisecond+time.Microsecond)
+	want := []string{first.ID, second.ID, third.ID}
+
+	connection, err := comments.GetCommentsForPost(ctx, post.ID, 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to get comments: %v", err)
+	}
+	assertIDs(t, "comments", commentIDs(connection), want)
+
+	var paged []string
+	var after *string
+	for range want {
+		page, err := comments.GetCommentsForPost(ctx, post.ID, 1, after)
+		if err != nil {
+			t.Fatalf("Failed to get comments page: %v", err)
+		}
+		paged = append(paged, commentIDs(page)...)
+		after = &page.Edges[len(page.Edges)-1].Cursor
+	}
+	assertIDs(t, "paged comments", paged, want)
+}
+
+// getPost - вспомогательная функция получения поста по ID.
+func getPost(t *testing.T, posts data.PostStore, id string) *model.Post {
+	t.Helper()
+	post, err := posts.GetPostByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to get post: %v", err)
+	}
+	return post
+}
+
+// assertTimestamp - вспомогательная функция сравнения даты с ожидаемой, включая часовой пояс UTC.
+func assertTimestamp(t *testing.T, what string, got, want time.Time) {
+	t.Helper()
+	if !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("Unexpected %s: got %v, want %v", what, got, want)
+	}
+}
@@ -0,0 +1,252 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UnitOfWorkFactory - функция создания пустых хранилищ и единицы работы над ними для одного теста.
+type UnitOfWorkFactory func(t *testing.T) (data.PostStore, data.CommentStore, data.UnitOfWork)
+
+// RunUnitOfWork - функция запуска тестов соответствия для реализаций data.UnitOfWork.
+func RunUnitOfWork(t *testing.T, newStores UnitOfWorkFactory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, posts data.PostStore, comments data.CommentStore, uow data.UnitOfWork)
+	}{
+		{"Commit", testTxCommit},
+		{"Rollback", testTxRollback},
+		{"MissingReferences", testTxMissingReferences},
+		{"ConcurrentComments", testTxConcurrentComments},
+		{"Notifications", testTxNotifications},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, comments, uow := newStores(t)
+			tt.test(t, posts, comments, uow)
+		})
+	}
+}
+
+// newPost - вспомогательная функция создания поста (без добавления в хранилище).
+func newPost(offset time.Duration) *model.Post {
+	return &model.Post{
+		ID:            uuid.NewString(),
+		Author:        "Автор",
+		Title:         "Пост",
+		Content:       "Текст поста",
+		CreatedAt:     baseTime.Add(offset),
+		AllowComments: true,
+	}
+}
+
+// newComment - вспомогательная функция создания комментария (без добавления в хранилище).
+func newComment(postID string, parentID *string, offset time.Duration) *model.Comment {
+	return &model.Comment{
+		ID:        uuid.NewString(),
+		PostID:    postID,
+		ParentID:  parentID,
+		Author:    "Комментатор",
+		Content:   "Текст комментария",
+		CreatedAt: baseTime.Add(offset),
+	}
+}
+
+// testTxCommit проверяет, что записи фиксируются, а чтения внутри транзакции видят ее же записи.
+func testTxCommit(t *testing.T, posts data.PostStore, comments data.CommentStore, uow data.UnitOfWork) {
+	ctx := context.Background()
+	post := newPost(0)
+	comment := newComment(post.ID, nil, time.Second)
+	reply := newComment(post.ID, &comment.ID, 2*time.Second)
+
+	err := uow.Do(ctx, func(tx data.Tx) error {
+		if err := tx.Posts().AddPost(ctx, post); err != nil {
+			return err
+		}
+		if _, err := tx.Posts().GetPostByID(ctx, post.ID); err != nil {
+			return err
+		}
+		if err := tx.Comments().AddComment(ctx, comment); err != nil {
+			return err
+		}
+		if _, err := tx.Comments().GetCommentByID(ctx, comment.ID); err != nil {
+			return err
+		}
+		return tx.Comments().AddComment(ctx, reply)
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit unit of work: %v", err)
+	}
+
+	if _, err := posts.GetPostByID(ctx, post.ID); err != nil {
+		t.Errorf("Expected committed post, got %v", err)
+	}
+	page, err := comments.GetCommentsForPost(ctx, post.ID, 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to get comments: %v", err)
+	}
+	assertIDs(t, "committed comments", commentIDs(page), []string{comment.ID, reply.ID})
+}
+
+// testTxRollback проверяет, что при ошибке fn записи отменяются, а ошибка возвращается без изменений.
+func testTxRollback(t *testing.T, posts data.PostStore, comments data.CommentStore, uow data.UnitOfWork) {
+	ctx := context.Background()
+	existing := addPost(t, posts, 0)
+	post := newPost(time.Second)
+	comment := newComment(existing.ID, nil, 2*time.Second)
+	errAbort := errors.New("abort")
+
+	err := uow.Do(ctx, func(tx data.Tx) error {
+		if err := tx.Posts().AddPost(ctx, post); err != nil {
+			return err
+		}
+		if err := tx.Comments().AddComment(ctx, comment); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("Expected the error of fn unchanged, got %v", err)
+	}
+
+	if _, err := posts.GetPostByID(ctx, post.ID); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected rolled back post to be missing, got %v", err)
+	}
+	if _, err := comments.GetCommentByID(ctx, comment.ID); !errors.Is(err, data.ErrNotFound) {
+		t.Errorf("Expected rolled back comment to be missing, got %v", err)
+	}
+}
+
+// testTxNotifications проверяет, что уведомление фиксируется вместе с комментарием, на который ссылается,
+// и исчезает при откате, а отметка прочтения в транзакции видна после фиксации.
+func testTxNotifications(t *testing.T, posts data.PostStore, _ data.CommentStore, uow data.UnitOfWork) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	comment := newComment(post.ID, nil, time.Second)
+	notification := newNotification("Автор", comment, time.Second)
+
+	err := uow.Do(ctx, func(tx data.Tx) error {
+		if err := tx.Comments().AddComment(ctx, comment); err != nil {
+			return err
+		}
+		return tx.Notifications().AddNotification(ctx, notification)
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	rolledBack := newComment(post.ID, nil, 2*time.Second)
+	errAbort := errors.New("abort")
+	err = uow.Do(ctx, func(tx data.Tx) error {
+		if err := tx.Comments().AddComment(ctx, rolledBack); err != nil {
+			return err
+		}
+		if err := tx.Notifications().AddNotification(ctx, newNotification("Автор", rolledBack, 2*time.Second)); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("Expected the error of fn unchanged, got %v", err)
+	}
+
+	readAt := baseTime.Add(time.Hour)
+	err = uow.Do(ctx, func(tx data.Tx) error {
+		marked, err := tx.Notifications().MarkNotificationsRead(ctx, "Автор", nil, readAt)
+		assertCount(t, "marked notifications", marked, err, 1)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	err = uow.Do(ctx, func(tx data.Tx) error {
+		page, err := tx.Notifications().GetNotifications(ctx, "Автор", 10, nil, false)
+		if err != nil {
+			return err
+		}
+		if len(page.Edges) != 1 || page.Edges[0].Node.ID != notification.ID {
+			t.Fatalf("Expected only the committed notification, got %d", len(page.Edges))
+		}
+		if got := page.Edges[0].Node.ReadAt; got == nil || !got.Equal(readAt) {
+			t.Errorf("Expected the notification to be read at %v, got %v", readAt, got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to read notifications: %v", err)
+	}
+}
+
+// testTxMissingReferences проверяет, что комментарий к несуществующему посту или родителю не добавляется,
+// а транзакция после такой ошибки остается рабочей.
+func testTxMissingReferences(t *testing.T, posts data.PostStore, comments data.CommentStore, uow data.UnitOfWork) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+	missingParent := uuid.NewString()
+	comment := newComment(post.ID, nil, 3*time.Second)
+
+	err := uow.Do(ctx, func(tx data.Tx) error {
+		if err := tx.Comments().AddComment(ctx, newComment(uuid.NewString(), nil, time.Second)); !errors.Is(err, data.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for a missing post, got %v", err)
+		}
+		if err := tx.Comments().AddComment(ctx, newComment(post.ID, &missingParent, 2*time.Second)); !errors.Is(err, data.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for a missing parent, got %v", err)
+		}
+		return tx.Comments().AddComment(ctx, comment)
+	})
+	if err != nil {
+		t.Fatalf("Expected the transaction to stay usable, got %v", err)
+	}
+
+	page, err := comments.GetCommentsForPost(ctx, post.ID, 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to get comments: %v", err)
+	}
+	assertIDs(t, "comments", commentIDs(page), []string{comment.ID})
+}
+
+// testTxConcurrentComments проверяет параллельные единицы работы с проверкой и вставкой.
+func testTxConcurrentComments(t *testing.T, posts data.PostStore, comments data.CommentStore, uow data.UnitOfWork) {
+	ctx := context.Background()
+	post := addPost(t, posts, 0)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- uow.Do(ctx, func(tx data.Tx) error {
+				if _, err := tx.Posts().GetPostByID(ctx, post.ID); err != nil {
+					return err
+				}
+				return tx.Comments().AddComment(ctx, newComment(post.ID, nil, time.Duration(i+1)*time.Second))
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Failed to add comment in unit of work: %v", err)
+		}
+	}
+	page, err := comments.GetCommentsForPost(ctx, post.ID, workers+1, nil)
+	if err != nil {
+		t.Fatalf("Failed to get comments: %v", err)
+	}
+	if len(page.Edges) != workers {
+		t.Errorf("Expected %d comments, got %d", workers, len(page.Edges))
+	}
+}
@@ -0,0 +1,13 @@
+package data
+
+import "time"
+
+// TimePrecision - точность, с которой хранилища сохраняют даты. PostgreSQL хранит timestamptz
+// с точностью до микросекунды, поэтому остальные хранилища отбрасывают более мелкие доли секунды так же,
+// и сохраненная дата не зависит от выбранного хранилища.
+const TimePrecision = time.Microsecond
+
+// Timestamp - функция приведения даты к виду, в котором ее возвращают хранилища: UTC с точностью TimePrecision.
+func Timestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(TimePrecision)
+}
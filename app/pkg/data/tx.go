@@ -0,0 +1,25 @@
+package data
+
+import "context"
+
+// Tx - хранилища, все операции которых выполняются в рамках одной единицы работы (транзакции).
+// Чтения внутри транзакции видят ее же записи; в SQL-хранилищах прочитанные записи
+// блокируются от удаления до ее завершения.
+type Tx interface {
+	// Posts возвращает хранилище постов, работающее внутри транзакции.
+	Posts() PostStore
+	// Comments возвращает хранилище комментариев, работающее внутри транзакции.
+	Comments() CommentStore
+	// Notifications возвращает хранилище уведомлений, работающее внутри транзакции.
+	Notifications() NotificationStore
+}
+
+// UnitOfWork определяет интерфейс выполнения нескольких операций с хранилищами как одной атомарной единицы.
+// Используется там, где проверка и запись должны идти без вмешательства параллельных запросов,
+// например при создании комментария: проверка поста и родителя, вставка, обновление счетчиков и уведомления.
+type UnitOfWork interface {
+	// Do выполняет fn в транзакции. Если fn вернула nil, изменения фиксируются, иначе откатываются,
+	// и Do возвращает ошибку fn без изменений, чтобы по ней можно было проверить errors.Is.
+	// Хранилища из tx нельзя использовать после возврата из fn.
+	Do(ctx context.Context, fn func(tx Tx) error) error
+}
@@ -0,0 +1,150 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// checkTimeout - максимальное время проверки доступности хранилища в /readyz и /status.
+const checkTimeout = 2 * time.Second
+
+// PoolStats - статистика пула соединений с базой данных.
+type PoolStats struct {
+	TotalConns    int32 `json:"totalConns"`    // TotalConns - всего соединений в пуле.
+	IdleConns     int32 `json:"idleConns"`     // IdleConns - простаивающие соединения.
+	AcquiredConns int32 `json:"acquiredConns"` // AcquiredConns - соединения, занятые запросами.
+	MaxConns      int32 `json:"maxConns"`      // MaxConns - максимальный размер пула.
+}
+
+// Storage - описание хранилища для проверок состояния.
+// Все функции необязательны: хранилище без Ping (например, in-memory) всегда считается доступным.
+type Storage struct {
+	Type             string                                 // Type - тип хранилища: inmemory или postgres.
+	Ping             func(ctx context.Context) error        // Ping - проверка доступности хранилища.
+	MigrationVersion func(ctx context.Context) (int, error) // MigrationVersion - номер примененной миграции схемы.
+	PoolStats        func() *PoolStats                      // PoolStats - статистика пула соединений.
+}
+
+// Status - содержимое ответа /status.
+type Status struct {
+	Version          string     `json:"version"`                    // Version - версия приложения.
+	Storage          string     `json:"storage"`                    // Storage - тип хранилища.
+	Ready            bool       `json:"ready"`                      // Ready - готов ли экземпляр принимать трафик.
+	StartedAt        time.Time  `json:"startedAt"`                  // StartedAt - время запуска процесса.
+	Uptime           string     `json:"uptime"`                     // Uptime - время работы в читаемом виде.
+	UptimeSeconds    int64      `json:"uptimeSeconds"`              // UptimeSeconds - время работы в секундах.
+	StorageError     string     `json:"storageError,omitempty"`     // StorageError - ошибка проверки хранилища, если есть.
+	MigrationVersion *int       `json:"migrationVersion,omitempty"` // MigrationVersion - номер миграции схемы (только для SQL-хранилищ).
+	Pool             *PoolStats `json:"pool,omitempty"`             // Pool - статистика пула соединений (только для SQL-хранилищ).
+}
+
+// Health - обработчики проверок состояния процесса для оркестратора и балансировщика.
+type Health struct {
+	version   string
+	storage   Storage
+	startedAt time.Time
+	shutdown  atomic.Bool // shutdown - экземпляр начал остановку и больше не должен получать трафик.
+}
+
+// New - функция-конструктор проверок состояния.
+func New(version string, storage Storage) *Health {
+	return &Health{version: version, storage: storage, startedAt: time.Now()}
+}
+
+// SetShuttingDown - метод, переводящий экземпляр в состояние остановки.
+// После вызова /readyz отвечает 503, и балансировщик перестает направлять сюда запросы.
+func (h *Health) SetShuttingDown() {
+	h.shutdown.Store(true)
+}
+
+// Liveness - обработчик /healthz: процесс жив и обслуживает HTTP-запросы.
+func (h *Health) Liveness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	})
+}
+
+// Readiness - обработчик /readyz: экземпляр не останавливается и хранилище доступно.
+func (h *Health) Readiness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		if h.shutdown.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("shutting down\n"))
+			return
+		}
+
+		if err := h.ping(r.Context()); err != nil {
+			slog.WarnContext(r.Context(), "readiness check failed", slog.Any("error", err))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("storage unavailable\n"))
+			return
+		}
+
+		_, _ = w.Write([]byte("ok\n"))
+	})
+}
+
+// StatusHandler - обработчик /status: подробное состояние экземпляра в формате JSON.
+func (h *Health) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := h.Status(r.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
+
+// Status - метод сбора текущего состояния экземпляра.
+func (h *Health) Status(ctx context.Context) Status {
+	uptime := time.Since(h.startedAt)
+
+	status := Status{
+		Version:       h.version,
+		Storage:       h.storage.Type,
+		Ready:         !h.shutdown.Load(),
+		StartedAt:     h.startedAt.UTC(),
+		Uptime:        uptime.Round(time.Second).String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+	}
+
+	if err := h.ping(ctx); err != nil {
+		status.Ready = false
+		status.StorageError = err.Error()
+	}
+
+	if h.storage.MigrationVersion != nil {
+		ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+		defer cancel()
+		if version, err := h.storage.MigrationVersion(ctx); err == nil {
+			status.MigrationVersion = &version
+		}
+	}
+
+	if h.storage.PoolStats != nil {
+		status.Pool = h.storage.PoolStats()
+	}
+
+	return status
+}
+
+// ping - вспомогательный метод проверки доступности хранилища с ограничением по времени.
+func (h *Health) ping(ctx context.Context) error {
+	if h.storage.Ping == nil {
+		return nil // Хранилище в памяти всегда доступно.
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	return h.storage.Ping(ctx)
+}
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serve - вспомогательная функция выполнения запроса к обработчику.
+func serve(handler http.Handler) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec
+}
+
+func TestReadinessInMemory(t *testing.T) {
+	// TestReadinessInMemory проверяет, что хранилище без Ping готово до начала остановки.
+	h := New("test", Storage{Type: "inmemory"})
+
+	if rec := serve(h.Liveness()); rec.Code != http.StatusOK {
+		t.Errorf("Expected /healthz 200, got %d", rec.Code)
+	}
+	if rec := serve(h.Readiness()); rec.Code != http.StatusOK {
+		t.Errorf("Expected /readyz 200, got %d", rec.Code)
+	}
+
+	h.SetShuttingDown()
+
+	if rec := serve(h.Readiness()); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected /readyz 503 during shutdown, got %d", rec.Code)
+	}
+	if rec := serve(h.Liveness()); rec.Code != http.StatusOK {
+		t.Errorf("Expected /healthz to stay 200 during shutdown, got %d", rec.Code)
+	}
+}
+
+func TestReadinessStorageUnavailable(t *testing.T) {
+	// TestReadinessStorageUnavailable проверяет, что ошибка Ping делает экземпляр неготовым.
+	h := New("test", Storage{
+		Type: "postgres",
+		Ping: func(ctx context.Context) error { return errors.New("connection refused") },
+	})
+
+	if rec := serve(h.Readiness()); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected /readyz 503, got %d", rec.Code)
+	}
+
+	status := h.Status(context.Background())
+	if status.Ready || status.StorageError != "connection refused" {
+		t.Errorf("Expected not ready status with storage error, got %+v", status)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	// TestStatus проверяет содержимое ответа /status для SQL-хранилища.
+	h := New("1.2.3", Storage{
+		Type:             "postgres",
+		Ping:             func(ctx context.Context) error { return nil },
+		MigrationVersion: func(ctx context.Context) (int, error) { return 1, nil },
+		PoolStats:        func() *PoolStats { return &PoolStats{TotalConns: 2, MaxConns: 4} },
+	})
+
+	rec := serve(h.StatusHandler())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected /status 200, got %d", rec.Code)
+	}
+
+	var status Status
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if status.Version != "1.2.3" || status.Storage != "postgres" || !status.Ready {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if status.MigrationVersion == nil || *status.MigrationVersion != 1 {
+		t.Errorf("Expected migration version 1, got %v", status.MigrationVersion)
+	}
+	if status.Pool == nil || status.Pool.TotalConns != 2 || status.Pool.MaxConns != 4 {
+		t.Errorf("Unexpected pool stats: %+v", status.Pool)
+	}
+}
@@ -0,0 +1,60 @@
+// Package ids - генераторы идентификаторов новых постов и комментариев.
+//
+// UUIDv4 - случайные идентификаторы. UUIDv7 начинаются с метки времени в миллисекундах, поэтому
+// упорядочены по времени создания: в PostgreSQL новые записи попадают в конец B-tree индекса первичного ключа,
+// а не в случайные страницы. Оба формата - обычные UUID, хранилища принимают их одинаково.
+package ids
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Форматы идентификаторов.
+const (
+	KindUUIDv4 = "uuidv4" // KindUUIDv4 - случайные UUID версии 4.
+	KindUUIDv7 = "uuidv7" // KindUUIDv7 - упорядоченные по времени UUID версии 7.
+)
+
+// Generator - интерфейс генератора уникальных идентификаторов.
+type Generator interface {
+	NewID() string
+}
+
+// UUIDv4 - генератор случайных UUID версии 4.
+type UUIDv4 struct{}
+
+// NewID - метод генерации UUID версии 4.
+func (UUIDv4) NewID() string {
+	return uuid.NewString()
+}
+
+// UUIDv7 - генератор UUID версии 7. Идентификаторы, созданные одним процессом, строго возрастают
+// (в пределах миллисекунды порядок обеспечивает счетчик библиотеки uuid).
+type UUIDv7 struct{}
+
+// NewID - метод генерации UUID версии 7. Если случайные байты получить не удалось, вызывает панику, как uuid.NewString.
+func (UUIDv7) NewID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// Func - адаптер, позволяющий использовать обычную функцию как Generator (например, последовательность в тестах).
+type Func func() string
+
+// NewID - метод, вызывающий f.
+func (f Func) NewID() string {
+	return f()
+}
+
+// New - функция выбора генератора по названию формата (KindUUIDv4 или KindUUIDv7).
+func New(kind string) (Generator, error) {
+	switch kind {
+	case KindUUIDv4:
+		return UUIDv4{}, nil
+	case KindUUIDv7:
+		return UUIDv7{}, nil
+	default:
+		return nil, fmt.Errorf("unknown ID format %q (expected %s or %s)", kind, KindUUIDv4, KindUUIDv7)
+	}
+}
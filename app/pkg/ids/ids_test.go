@@ -0,0 +1,49 @@
+package ids
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNew(t *testing.T) {
+	// TestNew проверяет выбор генератора по формату и версию создаваемых UUID.
+	for kind, version := range map[string]uuid.Version{KindUUIDv4: 4, KindUUIDv7: 7} {
+		generator, err := New(kind)
+		if err != nil {
+			t.Fatalf("New(%q) failed: %v", kind, err)
+		}
+		id, err := uuid.Parse(generator.NewID())
+		if err != nil {
+			t.Fatalf("%s generated an invalid UUID: %v", kind, err)
+		}
+		if id.Version() != version {
+			t.Errorf("%s generated UUID version %d", kind, id.Version())
+		}
+	}
+
+	if _, err := New("ulid"); err == nil {
+		t.Error("Expected an error for an unknown ID format")
+	}
+}
+
+func TestUUIDv7Ordered(t *testing.T) {
+	// TestUUIDv7Ordered проверяет, что UUIDv7 возрастают в порядке создания, в том числе в пределах миллисекунды.
+	generator := UUIDv7{}
+	previous := generator.NewID()
+	for range 1000 {
+		id := generator.NewID()
+		if id <= previous {
+			t.Fatalf("Expected %s to sort after %s", id, previous)
+		}
+		previous = id
+	}
+}
+
+func TestFunc(t *testing.T) {
+	// TestFunc проверяет адаптер функции к интерфейсу Generator.
+	var generator Generator = Func(func() string { return "fixed" })
+	if id := generator.NewID(); id != "fixed" {
+		t.Errorf("Expected fixed, got %s", id)
+	}
+}
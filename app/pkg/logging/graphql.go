@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/99designs/gqlgen/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+)
+
+// OperationLogger - расширение gqlgen, записывающее в лог каждую выполненную GraphQL-операцию:
+// имя операции, длительность, количество ошибок и сложность запроса.
+type OperationLogger struct {
+	Logger *slog.Logger // Logger - логгер, в который пишутся записи об операциях.
+
+	schema graphql.ExecutableSchema // schema - схема, нужная для расчета сложности запроса.
+}
+
+var _ interface {
+	graphql.ResponseInterceptor
+	graphql.HandlerExtension
+} = &OperationLogger{}
+
+// ExtensionName - метод, возвращающий имя расширения.
+func (l *OperationLogger) ExtensionName() string {
+	return "OperationLogger"
+}
+
+// Validate - метод, сохраняющий схему при подключении расширения к серверу.
+func (l *OperationLogger) Validate(schema graphql.ExecutableSchema) error {
+	l.schema = schema
+	if l.Logger == nil {
+		l.Logger = slog.Default()
+	}
+	return nil
+}
+
+// InterceptResponse - метод, вызываемый вокруг каждого ответа GraphQL.
+// Для подписок он срабатывает на каждое отправленное событие.
+func (l *OperationLogger) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if resp == nil || !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+
+	opCtx := graphql.GetOperationContext(ctx)
+
+	level := slog.LevelInfo
+	if len(resp.Errors) > 0 {
+		level = slog.LevelWarn // Операции с ошибками выделяются уровнем, чтобы их было проще найти.
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", operationName(opCtx)),
+		slog.Duration("duration", time.Since(opCtx.Stats.OperationStart)),
+		slog.Int("errors", len(resp.Errors)),
+	}
+	if opCtx.Operation != nil {
+		attrs = append(attrs,
+			slog.String("type", string(opCtx.Operation.Operation)),
+			slog.Int("complexity", l.complexity(ctx, opCtx)),
+		)
+	}
+
+	l.Logger.LogAttrs(ctx, level, "graphql operation", attrs...)
+
+	return resp
+}
+
+// complexity - вспомогательный метод получения сложности запроса.
+// Если подключено расширение ComplexityLimit, используется уже посчитанное им значение.
+func (l *OperationLogger) complexity(ctx context.Context, opCtx *graphql.OperationContext) int {
+	if stats := extension.GetComplexityStats(ctx); stats != nil {
+		return stats.Complexity
+	}
+	return complexity.Calculate(l.schema, opCtx.Operation, opCtx.Variables)
+}
+
+// operationName - вспомогательная функция, возвращающая имя операции или "anonymous" для безымянных запросов.
+func operationName(opCtx *graphql.OperationContext) string {
+	if opCtx.OperationName != "" {
+		return opCtx.OperationName
+	}
+	if opCtx.Operation != nil && opCtx.Operation.Name != "" {
+		return opCtx.Operation.Name
+	}
+	return "anonymous"
+}
@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Форматы вывода логов.
+const (
+	FormatText = "text" // FormatText - человекочитаемый формат key=value.
+	FormatJSON = "json" // FormatJSON - JSON, по одной записи на строку.
+)
+
+// requestIDKey - ключ контекста для хранения идентификатора запроса.
+type requestIDKey struct{}
+
+// New - функция-конструктор логгера с заданным уровнем (debug, info, warn, error) и форматом (text, json).
+// Пустые значения означают info и text соответственно.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if level != "" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+		}
+	}
+
+	options := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		handler = slog.NewTextHandler(w, options)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return nil, fmt.Errorf("invalid log format %q: expected text or json", format)
+	}
+
+	return slog.New(ContextHandler{Handler: handler}), nil
+}
+
+// ContextHandler - обертка над slog.Handler, добавляющая к записям идентификатор запроса из контекста.
+// Благодаря ей достаточно вызвать slog.ErrorContext(ctx, ...), чтобы запись была привязана к запросу.
+type ContextHandler struct {
+	slog.Handler
+}
+
+// Handle - метод, дополняющий запись атрибутом request_id, если он есть в контексте.
+func (h ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs - метод, сохраняющий обертку при добавлении атрибутов.
+func (h ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup - метод, сохраняющий обертку при создании группы.
+func (h ContextHandler) WithGroup(name string) slog.Handler {
+	return ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// WithRequestID - функция, возвращающая контекст с идентификатором запроса.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID - функция, возвращающая идентификатор запроса из контекста или пустую строку.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	// TestNew проверяет разбор уровня и формата логирования.
+	var buf bytes.Buffer
+
+	logger, err := New(&buf, "warn", "json")
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Info("hidden")
+	logger.Warn("visible")
+
+	if strings.Contains(buf.String(), "hidden") {
+		t.Error("Expected info record to be filtered out at warn level")
+	}
+	if !strings.Contains(buf.String(), `"msg":"visible"`) {
+		t.Errorf("Expected JSON record, got %q", buf.String())
+	}
+
+	// Некорректные значения должны приводить к понятной ошибке.
+	if _, err := New(&buf, "loud", "text"); err == nil {
+		t.Error("Expected error for invalid level")
+	}
+	if _, err := New(&buf, "info", "xml"); err == nil {
+		t.Error("Expected error for invalid format")
+	}
+}
+
+func TestContextHandlerAddsRequestID(t *testing.T) {
+	// TestContextHandlerAddsRequestID проверяет, что запись получает request_id из контекста.
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", "text")
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.With(slog.String("component", "test")).ErrorContext(WithRequestID(context.Background(), "req-1"), "store failed")
+
+	if !strings.Contains(buf.String(), "request_id=req-1") || !strings.Contains(buf.String(), "component=test") {
+		t.Errorf("Expected request_id and component attributes, got %q", buf.String())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	// TestMiddleware проверяет назначение и проброс X-Request-ID.
+	var seen string
+	handler := Middleware(slog.Default(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+	}))
+
+	// Корректный идентификатор от клиента используется повторно.
+	req := httptest.NewRequest(http.MethodGet, "/query", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if seen != "abc-123" || rec.Header().Get(RequestIDHeader) != "abc-123" {
+		t.Errorf("Expected propagated id abc-123, got context %q, header %q", seen, rec.Header().Get(RequestIDHeader))
+	}
+
+	// Некорректный идентификатор заменяется сгенерированным.
+	req = httptest.NewRequest(http.MethodGet, "/query", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if seen == "" || seen == "bad id\n" || rec.Header().Get(RequestIDHeader) != seen {
+		t.Errorf("Expected generated id, got context %q, header %q", seen, rec.Header().Get(RequestIDHeader))
+	}
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader - заголовок, в котором передается идентификатор запроса.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength - максимальная длина идентификатора, принимаемого от клиента.
+const maxRequestIDLength = 128
+
+// Middleware - HTTP middleware, назначающее каждому запросу идентификатор.
+// Если клиент или балансировщик прислал корректный X-Request-ID, он используется повторно,
+// иначе генерируется новый. Идентификатор возвращается в ответе и сохраняется в контексте.
+func Middleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		ctx := WithRequestID(r.Context(), id)
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		logger.DebugContext(ctx, "http request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Duration("duration", time.Since(start)),
+		)
+	})
+}
+
+// validRequestID - вспомогательная функция проверки идентификатора, пришедшего от клиента.
+// Допускаются только печатные ASCII-символы, чтобы идентификатор нельзя было использовать для подделки логов.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// statusRecorder - обертка над http.ResponseWriter, запоминающая код ответа.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader - метод, сохраняющий код ответа перед его отправкой.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap - метод, дающий http.ResponseController доступ к исходному ResponseWriter.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Hijack - метод перехвата соединения, необходимый для WebSocket-транспорта.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
+
+// Flush - метод отправки буферизованных данных клиенту (используется потоковыми транспортами).
+func (r *statusRecorder) Flush() {
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
@@ -0,0 +1,234 @@
+// Package markdown - отрисовка текста постов и комментариев в HTML.
+//
+// Поддерживается безопасное подмножество CommonMark: абзацы, выделение (*курсив*, **полужирный**), код
+// (`код`, блоки с отступом и в ```), ссылки [текст](адрес), автоссылки (<https://...> и адреса без скобок)
+// и цитаты (>). Заголовки, списки, горизонтальные линии, картинки и HTML не поддерживаются: Unsupported
+// находит их в тексте, чтобы валидатор отклонил такой текст, а Render показывает их обычным текстом
+// (картинку - ссылкой на нее). Результат дополнительно очищается санитайзером, а ссылки получают
+// rel="nofollow ugc": весь текст пишут пользователи.
+package markdown
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// DefaultCacheSize - число отрисованных текстов, которые Renderer по умолчанию держит в кэше.
+const DefaultCacheSize = 1000
+
+// LinkRel - значение атрибута rel у ссылок в отрисованном тексте.
+const LinkRel = "nofollow ugc"
+
+// Renderer - отрисовка текста в очищенный HTML с кэшем результатов. Кэш ключуется хэшем текста,
+// поэтому правка поста или комментария не требует сброса: новый текст просто отрисовывается заново.
+// Безопасен для одновременного использования.
+type Renderer struct {
+	markdown goldmark.Markdown  // markdown - парсер и отрисовка поддерживаемого подмножества.
+	policy   *bluemonday.Policy // policy - разрешенные элементы и атрибуты HTML.
+	cache    *lru.LRU[string]   // cache - отрисованные тексты по хэшу; nil, если кэш отключен.
+}
+
+// NewRenderer - функция-конструктор Renderer с кэшем на cacheSize текстов; при cacheSize <= 0 кэш отключен.
+func NewRenderer(cacheSize int) *Renderer {
+	r := &Renderer{
+		markdown: goldmark.New(
+			goldmark.WithParser(parser.NewParser(
+				parser.WithBlockParsers(
+					util.Prioritized(parser.NewCodeBlockParser(), 500),
+					util.Prioritized(parser.NewFencedCodeBlockParser(), 700),
+					util.Prioritized(parser.NewBlockquoteParser(), 800),
+					util.Prioritized(parser.NewParagraphParser(), 1000),
+				),
+				parser.WithInlineParsers(
+					util.Prioritized(parser.NewCodeSpanParser(), 100),
+					util.Prioritized(parser.NewLinkParser(), 200),
+					util.Prioritized(parser.NewAutoLinkParser(), 300),
+					util.Prioritized(parser.NewEmphasisParser(), 500),
+				),
+				parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+				parser.WithASTTransformers(util.Prioritized(linkTransformer{}, 1000)),
+			)),
+			goldmark.WithExtensions(extension.Linkify),
+		),
+		policy: newPolicy(),
+	}
+	if cacheSize > 0 {
+		r.cache = lru.New[string](cacheSize)
+	}
+	return r
+}
+
+// Render - метод отрисовки текста content в очищенный HTML.
+func (r *Renderer) Render(ctx context.Context, content string) (string, error) {
+	key := cacheKey(content)
+	if r.cache != nil {
+		if rendered, ok := r.cache.Get(ctx, key); ok {
+			return rendered, nil
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := r.markdown.Convert([]byte(content), &buf); err != nil {
+		return "", fmt.Errorf("error rendering markdown: %w", err)
+	}
+	rendered := string(r.policy.SanitizeBytes(buf.Bytes()))
+
+	if r.cache != nil {
+		r.cache.Add(ctx, key, rendered)
+	}
+	return rendered, nil
+}
+
+// Unsupported - функция поиска в тексте неподдерживаемой разметки CommonMark: возвращает названия
+// найденных конструкций ("headings", "lists", "thematic breaks", "images", "HTML") в порядке первого появления.
+// Для текста без такой разметки возвращает nil.
+func Unsupported(content string) []string {
+	source := []byte(content)
+	document := parser.NewParser(
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+	).Parse(text.NewReader(source))
+
+	seen := make(map[string]bool)
+	var result []string
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		name := ""
+		switch node.Kind() {
+		case ast.KindHeading:
+			name = "headings"
+		case ast.KindList:
+			name = "lists"
+		case ast.KindThematicBreak:
+			name = "thematic breaks"
+		case ast.KindImage:
+			name = "images"
+		case ast.KindHTMLBlock, ast.KindRawHTML:
+			name = "HTML"
+		}
+		if name != "" && !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+		return ast.WalkContinue, nil
+	})
+	return result
+}
+
+// cacheKey - функция ключа кэша для текста: хэш вместо самого текста, чтобы длинные тексты не занимали память дважды.
+func cacheKey(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// linkTransformer - преобразование дерева разобранного текста: всем ссылкам (включая автоссылки) добавляется
+// rel=LinkRel, картинки заменяются ссылками на них, а ссылки с адресами, которые не пропустит санитайзер
+// (схемы кроме http, https и mailto), заменяются своим текстом.
+type linkTransformer struct{}
+
+// Transform - реализация parser.ASTTransformer.
+func (linkTransformer) Transform(document *ast.Document, reader text.Reader, _ parser.Context) {
+	var nodes []ast.Node
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			switch node.Kind() {
+			case ast.KindLink, ast.KindImage, ast.KindAutoLink:
+				nodes = append(nodes, node)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	source := reader.Source()
+	for _, node := range nodes {
+		parent := node.Parent()
+		switch n := node.(type) {
+		case *ast.Link:
+			if !allowedURL(n.Destination) {
+				unwrap(n)
+				continue
+			}
+			n.SetAttributeString("rel", []byte(LinkRel))
+		case *ast.Image:
+			if !allowedURL(n.Destination) {
+				unwrap(n)
+				continue
+			}
+			link := ast.NewLink()
+			link.Destination = n.Destination
+			link.Title = n.Title
+			moveChildren(n, link)
+			parent.ReplaceChild(parent, n, link)
+			link.SetAttributeString("rel", []byte(LinkRel))
+		case *ast.AutoLink:
+			if !allowedURL(n.URL(source)) {
+				parent.ReplaceChild(parent, n, ast.NewString(n.Label(source)))
+				continue
+			}
+			n.SetAttributeString("rel", []byte(LinkRel))
+		}
+	}
+}
+
+// allowedURL - функция проверки адреса ссылки: относительный или со схемой http, https или mailto.
+func allowedURL(destination []byte) bool {
+	u, err := url.Parse(string(destination))
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// moveChildren - вспомогательная функция переноса дочерних узлов from в конец to.
+func moveChildren(from, to ast.Node) {
+	for child := from.FirstChild(); child != nil; {
+		next := child.NextSibling()
+		to.AppendChild(to, child)
+		child = next
+	}
+}
+
+// unwrap - вспомогательная функция замены узла его дочерними узлами (ссылки - ее текстом).
+func unwrap(node ast.Node) {
+	parent := node.Parent()
+	for child := node.FirstChild(); child != nil; {
+		next := child.NextSibling()
+		parent.InsertBefore(parent, node, child)
+		child = next
+	}
+	parent.RemoveChild(parent, node)
+}
+
+// newPolicy - функция создания политики санитайзера: разрешены только элементы, которые может выдать
+// отрисовка поддерживаемого подмножества, и ссылки со стандартными схемами (http, https, mailto).
+func newPolicy() *bluemonday.Policy {
+	policy := bluemonday.NewPolicy()
+	policy.AllowElements("p", "br", "em", "strong", "code", "pre", "blockquote")
+	policy.AllowStandardURLs()
+	policy.AllowAttrs("href", "title").OnElements("a")
+	policy.AllowAttrs("rel").Matching(regexp.MustCompile(`^` + LinkRel + `$`)).OnElements("a")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	policy.RequireNoFollowOnLinks(true)
+	return policy
+}
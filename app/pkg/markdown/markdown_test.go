@@ -0,0 +1,73 @@
+package markdown
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	// TestRender проверяет поддерживаемое подмножество, автоссылки с rel, показ неподдерживаемой разметки
+	// обычным текстом и очистку опасных ссылок.
+	r := NewRenderer(0)
+	for content, want := range map[string]string{
+		"*курсив* и **жирный**": "<p><em>курсив</em> и <strong>жирный</strong></p>\n",
+		"`x < y`":               "<p><code>x &lt; y</code></p>\n",
+		"```go\nfmt.Println()\n```": `<pre><code class="language-go">fmt.Println()
+</code></pre>
+`,
+		"> цитата":                                          "<blockquote>\n<p>цитата</p>\n</blockquote>\n",
+		"[сайт](https://example.com)":                       `<p><a href="https://example.com" rel="nofollow ugc">сайт</a></p>` + "\n",
+		"<https://example.com>":                             `<p><a href="https://example.com" rel="nofollow ugc">https://example.com</a></p>` + "\n",
+		"см. https://example.com/a":                         `<p>см. <a href="https://example.com/a" rel="nofollow ugc">https://example.com/a</a></p>` + "\n",
+		"![логотип](https://example.com/logo.png)":          `<p><a href="https://example.com/logo.png" rel="nofollow ugc">логотип</a></p>` + "\n",
+		"# Заголовок\n- пункт":                              "<p># Заголовок\n- пункт</p>\n",
+		"<b onclick=\"x()\">жирный</b>":                     "<p>&lt;b onclick=&#34;x()&#34;&gt;жирный&lt;/b&gt;</p>\n",
+		"[ссылка](javascript:alert(1))":                     "<p>ссылка</p>\n",
+		"[файл](ftp://example.com/a) <ftp://example.com/b>": "<p>файл ftp://example.com/b</p>\n",
+		"[страница](/posts/1) и me@example.com":             `<p><a href="/posts/1" rel="nofollow ugc">страница</a> и <a href="mailto:me@example.com" rel="nofollow ugc">me@example.com</a></p>` + "\n",
+	} {
+		got, err := r.Render(context.Background(), content)
+		if err != nil {
+			t.Fatalf("Render(%q) failed: %v", content, err)
+		}
+		if got != want {
+			t.Errorf("Render(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestRenderCache(t *testing.T) {
+	// TestRenderCache проверяет, что повторная отрисовка берется из кэша, а другой текст отрисовывается заново.
+	ctx := context.Background()
+	r := NewRenderer(2)
+	first, err := r.Render(ctx, "*текст*")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if cached, ok := r.cache.Get(ctx, cacheKey("*текст*")); !ok || cached != first {
+		t.Errorf("Expected %q in the cache, got %q (%v)", first, cached, ok)
+	}
+	if second, _ := r.Render(ctx, "**текст**"); second == first {
+		t.Errorf("Expected another text to be rendered anew, got %q", second)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	// TestUnsupported проверяет поиск неподдерживаемой разметки.
+	for content, want := range map[string]string{
+		"*курсив*, `код`, [ссылка](https://example.com)\n\n> цитата": "[]",
+		"# Заголовок\n\nтекст\n---":                                  "[headings]",
+		"- один\n- два\n\n1. три":                                    "[lists]",
+		"***\n\n![картинка](https://example.com/a.png)":              "[thematic breaks images]",
+		"<div>блок</div>\n\nтекст <b>жирный</b>":                     "[HTML]",
+	} {
+		if got := fmt.Sprint(Unsupported(content)); got != want {
+			t.Errorf("Unsupported(%q) = %s, want %s", content, got, want)
+		}
+	}
+	if got := Unsupported(strings.Repeat("обычный текст ", 10)); got != nil {
+		t.Errorf("Expected nil for plain text, got %v", got)
+	}
+}
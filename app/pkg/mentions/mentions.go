@@ -0,0 +1,112 @@
+// Package mentions - поиск упоминаний авторов (@имя) в тексте комментариев.
+//
+// Имя в упоминании - последовательность букв любого алфавита, цифр и "_"; точка и дефис допускаются
+// только между ними, поэтому "@Анна." и "@ivan-petrov," дают имена "Анна" и "ivan-petrov".
+// "@" после буквы, цифры или знаков адреса (". _ - + /") - часть адреса электронной почты или ссылки,
+// а не упоминание: в "ivan@почта.рф" и "https://example.com/@ivan" упоминаний нет.
+package mentions
+
+import (
+	"graphql-comment-system/app/graph/model"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ограничения упоминаний: длина имени в символах, число различных имен в одном комментарии и число первых
+// различных имен, которые проверяются на существование (см. Candidates). Упоминания сверх этих ограничений
+// остаются обычным текстом и не создают уведомлений.
+const (
+	MaxLength     = 64
+	MaxHandles    = 10
+	MaxCandidates = 50
+)
+
+// Find - функция поиска упоминаний в тексте в порядке появления, в том числе повторных упоминаний одного имени.
+// Offset и Length считаются в символах (rune). Известно ли имя, Find не проверяет (см. Filter).
+func Find(content string) []*model.Mention {
+	text := []rune(content)
+	var result []*model.Mention
+	for i := 0; i < len(text); i++ {
+		if text[i] != '@' || (i > 0 && !boundary(text[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(text) && (handleRune(text[end]) || separator(text[end]) && end+1 < len(text) && handleRune(text[end+1])) {
+			end++
+		}
+		handle := string(text[i+1 : end])
+		if handle == "" || utf8.RuneCountInString(handle) > MaxLength || end < len(text) && text[end] == '@' {
+			i = end - 1
+			continue
+		}
+
+		result = append(result, &model.Mention{Handle: handle, Offset: int32(i), Length: int32(end - i)})
+		i = end - 1
+	}
+	return result
+}
+
+// Handles - функция получения различных имен из упоминаний в порядке первого появления.
+func Handles(mentions []*model.Mention) []string {
+	seen := make(map[string]bool, len(mentions))
+	var result []string
+	for _, mention := range mentions {
+		if !seen[mention.Handle] {
+			seen[mention.Handle] = true
+			result = append(result, mention.Handle)
+		}
+	}
+	return result
+}
+
+// Candidates - функция получения имен для проверки на существование: различные имена упоминаний
+// в порядке первого появления, не больше MaxCandidates.
+func Candidates(mentions []*model.Mention) []string {
+	handles := Handles(mentions)
+	if len(handles) > MaxCandidates {
+		handles = handles[:MaxCandidates]
+	}
+	return handles
+}
+
+// Filter - функция отбора упоминаний известных имен known: остальные остаются обычным текстом.
+// Остаются упоминания не более MaxHandles различных имен, первых по порядку в тексте.
+// Для текста без упоминаний возвращается пустой, а не nil список.
+func Filter(mentions []*model.Mention, known []string) []*model.Mention {
+	isKnown := make(map[string]bool, len(known))
+	for _, handle := range known {
+		isKnown[handle] = true
+	}
+
+	kept := make(map[string]bool)
+	result := make([]*model.Mention, 0)
+	for _, mention := range mentions {
+		if !isKnown[mention.Handle] {
+			continue
+		}
+		if !kept[mention.Handle] {
+			if len(kept) == MaxHandles {
+				continue
+			}
+			kept[mention.Handle] = true
+		}
+		result = append(result, mention)
+	}
+	return result
+}
+
+// handleRune - функция проверки символа имени.
+func handleRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// separator - функция проверки разделителя, допустимого внутри имени.
+func separator(r rune) bool {
+	return r == '.' || r == '-'
+}
+
+// boundary - функция проверки символа перед "@": упоминание начинается не внутри слова, адреса или ссылки.
+func boundary(r rune) bool {
+	return !handleRune(r) && !separator(r) && r != '+' && r != '/' && r != '@'
+}
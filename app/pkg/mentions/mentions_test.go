@@ -0,0 +1,77 @@
+package mentions
+
+import (
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"strings"
+	"testing"
+)
+
+// format - вспомогательная функция записи упоминаний в виде "имя@позиция+длина".
+func format(mentions []*model.Mention) string {
+	parts := make([]string, len(mentions))
+	for i, mention := range mentions {
+		parts[i] = fmt.Sprintf("%s@%d+%d", mention.Handle, mention.Offset, mention.Length)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestFind(t *testing.T) {
+	// TestFind проверяет границы имен, кириллические имена, позиции в символах и пропуск адресов.
+	for content, want := range map[string]string{
+		"@Анна, посмотрите":                "Анна@0+5",
+		"Спасибо, @Борис.":                 "Борис@9+6",
+		"(@ivan-petrov) и @ivan_petrov!":   "ivan-petrov@1+12 ivan_petrov@17+12",
+		"@Ёжик_42 и @anna.smith.":          "Ёжик_42@0+8 anna.smith@11+11",
+		"пишите на ivan@почта.рф":          "",
+		"адрес @ivan@example.com":          "",
+		"https://example.com/@ivan":        "",
+		"a+b@mail.ru, x.y@mail.ru, @ и @-": "",
+		"@Анна @Анна":                      "Анна@0+5 Анна@6+5",
+	} {
+		if got := format(Find(content)); got != want {
+			t.Errorf("Find(%q) = %q, want %q", content, got, want)
+		}
+	}
+
+	longest := strings.Repeat("я", MaxLength)
+	if got, want := format(Find("@"+longest)), fmt.Sprintf("%s@0+%d", longest, MaxLength+1); got != want {
+		t.Errorf("Expected the longest handle %q, got %q", want, got)
+	}
+	if got := Find("@" + longest + "я"); len(got) != 0 {
+		t.Errorf("Expected a too long handle to be skipped, got %s", format(got))
+	}
+}
+
+func TestFilter(t *testing.T) {
+	// TestFilter проверяет отбор известных имен, повторные упоминания и ограничение числа имен.
+	found := Find("@Анна и @Незнакомец, снова @Анна, @Борис")
+	if got := Handles(found); fmt.Sprint(got) != "[Анна Незнакомец Борис]" {
+		t.Errorf("Unexpected handles %v", got)
+	}
+	if got := format(Filter(found, []string{"Анна", "Борис"})); got != "Анна@0+5 Анна@27+5 Борис@34+6" {
+		t.Errorf("Unexpected filtered mentions %q", got)
+	}
+	if got := Filter(nil, nil); got == nil || len(got) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %#v", got)
+	}
+
+	var content strings.Builder
+	var known []string
+	for i := range MaxHandles + 2 {
+		handle := fmt.Sprintf("user%d", i)
+		known = append(known, handle)
+		fmt.Fprintf(&content, "@%s @%s ", handle, handle)
+	}
+	if got := Handles(Filter(Find(content.String()), known)); len(got) != MaxHandles || got[MaxHandles-1] != known[MaxHandles-1] {
+		t.Errorf("Expected the first %d handles, got %v", MaxHandles, got)
+	}
+
+	content.Reset()
+	for i := range MaxCandidates + 1 {
+		fmt.Fprintf(&content, "@user%d ", i)
+	}
+	if got := Candidates(Find(content.String())); len(got) != MaxCandidates || got[0] != "user0" {
+		t.Errorf("Expected the first %d candidates, got %v", MaxCandidates, got)
+	}
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// Значения метки operation, кроме имен операций.
+const (
+	anonymousOperation = "anonymous" // anonymousOperation - операция без имени.
+	otherOperation     = "other"     // otherOperation - операция с именем, не получившим собственного значения.
+)
+
+// MaxOperationNames - сколько различных имен операций получают собственное значение метки operation,
+// если не задан Tracer.KnownOperations. Имя выбирает клиент, поэтому без ограничения каждый новый запрос
+// (например, через Automatic Persisted Queries) создавал бы новые ряды метрик.
+const MaxOperationNames = 100
+
+// Tracer - расширение gqlgen, собирающее метрики GraphQL-операций и resolvers полей.
+type Tracer struct {
+	Metrics *Metrics // Metrics - набор метрик, в который пишутся наблюдения.
+	// KnownOperations - проверка, что имя операции известно заранее (например, persisted.Manifest.HasOperation):
+	// только такие имена попадают в метку operation, остальные считаются как "other".
+	// nil - собственное значение получают первые MaxOperationNames различных имен.
+	KnownOperations func(name string) bool
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Tracer{}
+
+// ExtensionName - метод, возвращающий имя расширения.
+func (t Tracer) ExtensionName() string {
+	return "PrometheusMetrics"
+}
+
+// Validate - метод проверки конфигурации расширения.
+func (t Tracer) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse - метод, считающий операции и их длительность по имени, типу и результату.
+func (t Tracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if resp == nil || !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+
+	opCtx := graphql.GetOperationContext(ctx)
+
+	name, opType := anonymousOperation, "unknown"
+	if opCtx.Operation != nil {
+		opType = string(opCtx.Operation.Operation)
+		if opCtx.Operation.Name != "" {
+			name = t.operationLabel(opCtx.Operation.Name)
+		}
+	}
+
+	result := outcomeSuccess
+	if len(resp.Errors) > 0 {
+		result = outcomeError
+	}
+
+	t.Metrics.operations.WithLabelValues(name, opType, result).Inc()
+	t.Metrics.operationDuration.WithLabelValues(name, opType, result).Observe(time.Since(opCtx.Stats.OperationStart).Seconds())
+
+	return resp
+}
+
+// operationLabel - вспомогательный метод значения метки operation для имени операции: имя, если оно известно
+// (KnownOperations) или входит в первые MaxOperationNames, иначе "other".
+func (t Tracer) operationLabel(name string) string {
+	if t.KnownOperations != nil {
+		if t.KnownOperations(name) {
+			return name
+		}
+		return otherOperation
+	}
+
+	t.Metrics.namesMu.Lock()
+	defer t.Metrics.namesMu.Unlock()
+	if !t.Metrics.operationNames[name] {
+		if len(t.Metrics.operationNames) >= MaxOperationNames {
+			return otherOperation
+		}
+		t.Metrics.operationNames[name] = true
+	}
+	return name
+}
+
+// InterceptField - метод, измеряющий длительность полей с собственными resolvers
+// (Post.comments, Comment.replies, Comment.post и корневых полей Query/Mutation).
+// Поля, которые просто читаются из структуры модели, не измеряются.
+func (t Tracer) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	start := time.Now()
+	res, err := next(ctx)
+	t.Metrics.resolverDuration.WithLabelValues(fc.Object+"."+fc.Field.Name, outcome(err)).Observe(time.Since(start).Seconds())
+
+	return res, err
+}
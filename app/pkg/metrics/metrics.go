@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace - общий префикс имен всех метрик приложения.
+const namespace = "comment_system"
+
+// Значения метки outcome.
+const (
+	outcomeSuccess = "success" // outcomeSuccess - операция завершилась без ошибок.
+	outcomeError   = "error"   // outcomeError - операция вернула ошибку.
+)
+
+// Metrics - набор метрик приложения, зарегистрированных в собственном реестре Prometheus.
+type Metrics struct {
+	registry *prometheus.Registry // registry - реестр, из которого отдается /metrics.
+
+	namesMu        sync.Mutex      // namesMu - защищает operationNames.
+	operationNames map[string]bool // operationNames - имена операций, получившие собственное значение метки (см. Tracer).
+
+	operations        *prometheus.CounterVec   // operations - количество GraphQL-операций.
+	operationDuration *prometheus.HistogramVec // operationDuration - длительность GraphQL-операций.
+	resolverDuration  *prometheus.HistogramVec // resolverDuration - длительность выполнения resolvers полей.
+	storeDuration     *prometheus.HistogramVec // storeDuration - длительность вызовов методов хранилищ.
+}
+
+// New - функция-конструктор, создающая и регистрирующая все метрики приложения,
+// а также стандартные метрики Go runtime и процесса.
+func New() *Metrics {
+	m := &Metrics{
+		registry:       prometheus.NewRegistry(),
+		operationNames: make(map[string]bool),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "graphql_operations_total",
+			Help:      "Number of GraphQL operations by operation name, type and outcome.",
+		}, []string{"operation", "type", "outcome"}),
+		operationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "graphql_operation_duration_seconds",
+			Help:      "Duration of GraphQL operations by operation name, type and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "type", "outcome"}),
+		resolverDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "graphql_resolver_duration_seconds",
+			Help:      "Duration of custom field resolvers such as Post.comments or Comment.replies.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"field", "outcome"}),
+		storeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "store_operation_duration_seconds",
+			Help:      "Duration of PostStore and CommentStore calls by store, method and outcome.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"store", "method", "outcome"}),
+	}
+
+	m.registry.MustRegister(
+		m.operations,
+		m.operationDuration,
+		m.resolverDuration,
+		m.storeDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// Register - метод регистрации дополнительных коллекторов (например, статистики пула pgx).
+func (m *Metrics) Register(collector prometheus.Collector) error {
+	return m.registry.Register(collector)
+}
+
+// Handler - метод, возвращающий HTTP-обработчик для endpoint /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// observeStore - метод записи длительности вызова метода хранилища.
+// Предназначен для вызова через defer: время начала вычисляется в момент defer,
+// а ошибка читается по указателю уже после завершения метода.
+func (m *Metrics) observeStore(store, method string, start time.Time, errp *error) {
+	m.storeDuration.WithLabelValues(store, method, outcome(*errp)).Observe(time.Since(start).Seconds())
+}
+
+// outcome - вспомогательная функция, возвращающая значение метки outcome по ошибке.
+func outcome(err error) string {
+	if err != nil {
+		return outcomeError
+	}
+	return outcomeSuccess
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// stubPostStore - заглушка хранилища постов для проверки декоратора.
+type stubPostStore struct{}
+
+func (stubPostStore) AddPost(ctx context.Context, post *model.Post) error { return nil }
+
+func (stubPostStore) GetPostByID(ctx context.Context, id string) (*model.Post, error) {
+	return nil, errors.New("not found")
+}
+
+func (stubPostStore) GetPosts(ctx context.Context, first int32, after *string, filter model.PostFilter) (*model.PostConnection, error) {
+	return &model.PostConnection{}, nil
+}
+
+func (stubPostStore) CountPosts(ctx context.Context, filter model.PostFilter) (int, error) {
+	return 0, nil
+}
+
+func (stubPostStore) UpdatePost(ctx context.Context, id string, update data.PostUpdate) (*model.Post, error) {
+	return &model.Post{}, nil
+}
+
+func (stubPostStore) GetPostRevisions(ctx context.Context, postID string, first int32, after *string) (*model.RevisionConnection, error) {
+	return &model.RevisionConnection{}, nil
+}
+
+func (stubPostStore) DeletePost(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Post, error) {
+	return &model.Post{}, nil
+}
+
+func (stubPostStore) RestorePost(ctx context.Context, id string) (*model.Post, error) {
+	return &model.Post{}, nil
+}
+
+func (stubPostStore) GetTags(ctx context.Context) ([]*model.Tag, error) {
+	return nil, nil
+}
+
+func TestInstrumentPostStore(t *testing.T) {
+	// TestInstrumentPostStore проверяет, что декоратор пишет длительность вызовов с правильным результатом.
+	m := New()
+	store := InstrumentPostStore(stubPostStore{}, m)
+	ctx := context.Background()
+
+	_ = store.AddPost(ctx, &model.Post{})
+	_, _ = store.GetPostByID(ctx, "1")
+	_, _ = store.GetPostByID(ctx, "2")
+
+	if n := testutil.CollectAndCount(m.storeDuration); n != 2 {
+		t.Errorf("Expected 2 label combinations, got %d", n)
+	}
+
+	// Проверка количества наблюдений для неуспешного вызова GetPostByID в выводе /metrics.
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	if !strings.Contains(body, `comment_system_store_operation_duration_seconds_count{method="GetPostByID",outcome="error",store="post"} 2`) {
+		t.Errorf("Expected 2 failed GetPostByID observations, got:\n%s", body)
+	}
+}
+
+// traceOperation - вспомогательная функция прохождения через Tracer запроса с операцией name.
+func traceOperation(tracer Tracer, name string) {
+	ctx := graphql.WithOperationContext(context.Background(), &graphql.OperationContext{
+		Operation: &ast.OperationDefinition{Name: name, Operation: ast.Query},
+		Stats:     graphql.Stats{OperationStart: time.Now()},
+	})
+	tracer.InterceptResponse(ctx, func(ctx context.Context) *graphql.Response { return &graphql.Response{} })
+}
+
+func TestTracerOperationNames(t *testing.T) {
+	// TestTracerOperationNames проверяет, что произвольные имена операций не создают неограниченно много рядов:
+	// без списка известных операций отдельные метки получают первые MaxOperationNames имен, а со списком - только из него.
+	m := New()
+	tracer := Tracer{Metrics: m}
+	for i := range 3 * MaxOperationNames {
+		traceOperation(tracer, fmt.Sprintf("Operation%d", i))
+	}
+	if n := testutil.CollectAndCount(m.operations); n != MaxOperationNames+1 {
+		t.Errorf("Expected %d series, got %d", MaxOperationNames+1, n)
+	}
+	if got := testutil.ToFloat64(m.operations.WithLabelValues(otherOperation, "query", outcomeSuccess)); got != 2*MaxOperationNames {
+		t.Errorf("Expected %d operations counted as other, got %v", 2*MaxOperationNames, got)
+	}
+
+	m = New()
+	tracer = Tracer{Metrics: m, KnownOperations: func(name string) bool { return name == "GetPosts" }}
+	for i := range 10 {
+		traceOperation(tracer, fmt.Sprintf("Operation%d", i))
+	}
+	traceOperation(tracer, "GetPosts")
+	traceOperation(tracer, "")
+	if n := testutil.CollectAndCount(m.operations); n != 3 {
+		t.Errorf("Expected series for GetPosts, other and anonymous, got %d", n)
+	}
+	if got := testutil.ToFloat64(m.operations.WithLabelValues(otherOperation, "query", outcomeSuccess)); got != 10 {
+		t.Errorf("Expected 10 operations counted as other, got %v", got)
+	}
+}
@@ -0,0 +1,254 @@
+package metrics
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"time"
+)
+
+// postStore - декоратор data.PostStore, измеряющий длительность каждого вызова.
+// Декоратор не зависит от реализации хранилища, поэтому метрики есть у всех бэкендов.
+type postStore struct {
+	next    data.PostStore // next - оборачиваемое хранилище.
+	metrics *Metrics
+}
+
+// InstrumentPostStore - функция, оборачивающая хранилище постов сбором метрик.
+func InstrumentPostStore(store data.PostStore, m *Metrics) data.PostStore {
+	return &postStore{next: store, metrics: m}
+}
+
+// AddPost - метод добавления поста с измерением длительности.
+func (s *postStore) AddPost(ctx context.Context, post *model.Post) (err error) {
+	defer s.metrics.observeStore("post", "AddPost", time.Now(), &err)
+	return s.next.AddPost(ctx, post)
+}
+
+// GetPostByID - метод получения поста по ID с измерением длительности.
+func (s *postStore) GetPostByID(ctx context.Context, id string) (_ *model.Post, err error) {
+	defer s.metrics.observeStore("post", "GetPostByID", time.Now(), &err)
+	return s.next.GetPostByID(ctx, id)
+}
+
+// GetPosts - метод получения списка постов с измерением длительности.
+func (s *postStore) GetPosts(ctx context.Context, first int32, after *string, filter model.PostFilter) (_ *model.PostConnection, err error) {
+	defer s.metrics.observeStore("post", "GetPosts", time.Now(), &err)
+	return s.next.GetPosts(ctx, first, after, filter)
+}
+
+// CountPosts - метод подсчета постов с измерением длительности.
+func (s *postStore) CountPosts(ctx context.Context, filter model.PostFilter) (_ int, err error) {
+	defer s.metrics.observeStore("post", "CountPosts", time.Now(), &err)
+	return s.next.CountPosts(ctx, filter)
+}
+
+// UpdatePost - метод правки поста с измерением длительности.
+func (s *postStore) UpdatePost(ctx context.Context, id string, update data.PostUpdate) (_ *model.Post, err error) {
+	defer s.metrics.observeStore("post", "UpdatePost", time.Now(), &err)
+	return s.next.UpdatePost(ctx, id, update)
+}
+
+// GetPostRevisions - метод получения ревизий поста с измерением длительности.
+func (s *postStore) GetPostRevisions(ctx context.Context, postID string, first int32, after *string) (_ *model.RevisionConnection, err error) {
+	defer s.metrics.observeStore("post", "GetPostRevisions", time.Now(), &err)
+	return s.next.GetPostRevisions(ctx, postID, first, after)
+}
+
+// DeletePost - метод удаления поста с измерением длительности.
+func (s *postStore) DeletePost(ctx context.Context, id, deletedBy string, deletedAt time.Time) (_ *model.Post, err error) {
+	defer s.metrics.observeStore("post", "DeletePost", time.Now(), &err)
+	return s.next.DeletePost(ctx, id, deletedBy, deletedAt)
+}
+
+// RestorePost - метод восстановления поста с измерением длительности.
+func (s *postStore) RestorePost(ctx context.Context, id string) (_ *model.Post, err error) {
+	defer s.metrics.observeStore("post", "RestorePost", time.Now(), &err)
+	return s.next.RestorePost(ctx, id)
+}
+
+// GetTags - метод получения тегов с измерением длительности.
+func (s *postStore) GetTags(ctx context.Context) (_ []*model.Tag, err error) {
+	defer s.metrics.observeStore("post", "GetTags", time.Now(), &err)
+	return s.next.GetTags(ctx)
+}
+
+// commentStore - декоратор data.CommentStore, измеряющий длительность каждого вызова.
+type commentStore struct {
+	next    data.CommentStore // next - оборачиваемое хранилище.
+	metrics *Metrics
+}
+
+// InstrumentCommentStore - функция, оборачивающая хранилище комментариев сбором метрик.
+func InstrumentCommentStore(store data.CommentStore, m *Metrics) data.CommentStore {
+	return &commentStore{next: store, metrics: m}
+}
+
+// AddComment - метод добавления комментария с измерением длительности.
+func (s *commentStore) AddComment(ctx context.Context, comment *model.Comment) (err error) {
+	defer s.metrics.observeStore("comment", "AddComment", time.Now(), &err)
+	return s.next.AddComment(ctx, comment)
+}
+
+// GetCommentByID - метод получения комментария по ID с измерением длительности.
+func (s *commentStore) GetCommentByID(ctx context.Context, id string) (_ *model.Comment, err error) {
+	defer s.metrics.observeStore("comment", "GetCommentByID", time.Now(), &err)
+	return s.next.GetCommentByID(ctx, id)
+}
+
+// GetCommentsForPost - метод получения комментариев к посту с измерением длительности.
+func (s *commentStore) GetCommentsForPost(ctx context.Context, postID string, first int32, after *string) (_ *model.CommentConnection, err error) {
+	defer s.metrics.observeStore("comment", "GetCommentsForPost", time.Now(), &err)
+	return s.next.GetCommentsForPost(ctx, postID, first, after)
+}
+
+// GetRepliesForComment - метод получения ответов на комментарий с измерением длительности.
+func (s *commentStore) GetRepliesForComment(ctx context.Context, commentID string, first int32, after *string) (_ *model.CommentConnection, err error) {
+	defer s.metrics.observeStore("comment", "GetRepliesForComment", time.Now(), &err)
+	return s.next.GetRepliesForComment(ctx, commentID, first, after)
+}
+
+// CountCommentsForPost - метод подсчета комментариев к посту с измерением длительности.
+func (s *commentStore) CountCommentsForPost(ctx context.Context, postID string) (_ int, err error) {
+	defer s.metrics.observeStore("comment", "CountCommentsForPost", time.Now(), &err)
+	return s.next.CountCommentsForPost(ctx, postID)
+}
+
+// CountRepliesForComment - метод подсчета ответов на комментарий с измерением длительности.
+func (s *commentStore) CountRepliesForComment(ctx context.Context, commentID string) (_ int, err error) {
+	defer s.metrics.observeStore("comment", "CountRepliesForComment", time.Now(), &err)
+	return s.next.CountRepliesForComment(ctx, commentID)
+}
+
+// UpdateComment - метод правки комментария с измерением длительности.
+func (s *commentStore) UpdateComment(ctx context.Context, id, content string, mentions []*model.Mention, editedAt time.Time) (_ *model.Comment, err error) {
+	defer s.metrics.observeStore("comment", "UpdateComment", time.Now(), &err)
+	return s.next.UpdateComment(ctx, id, content, mentions, editedAt)
+}
+
+// GetCommentRevisions - метод получения ревизий комментария с измерением длительности.
+func (s *commentStore) GetCommentRevisions(ctx context.Context, commentID string, first int32, after *string) (_ *model.RevisionConnection, err error) {
+	defer s.metrics.observeStore("comment", "GetCommentRevisions", time.Now(), &err)
+	return s.next.GetCommentRevisions(ctx, commentID, first, after)
+}
+
+// DeleteComment - метод удаления комментария с измерением длительности.
+func (s *commentStore) DeleteComment(ctx context.Context, id, deletedBy string, deletedAt time.Time) (_ *model.Comment, err error) {
+	defer s.metrics.observeStore("comment", "DeleteComment", time.Now(), &err)
+	return s.next.DeleteComment(ctx, id, deletedBy, deletedAt)
+}
+
+// RestoreComment - метод восстановления комментария с измерением длительности.
+func (s *commentStore) RestoreComment(ctx context.Context, id string) (_ *model.Comment, err error) {
+	defer s.metrics.observeStore("comment", "RestoreComment", time.Now(), &err)
+	return s.next.RestoreComment(ctx, id)
+}
+
+// notificationStore - декоратор data.NotificationStore, измеряющий длительность каждого вызова.
+type notificationStore struct {
+	next    data.NotificationStore // next - оборачиваемое хранилище.
+	metrics *Metrics
+}
+
+// InstrumentNotificationStore - функция, оборачивающая хранилище уведомлений сбором метрик.
+func InstrumentNotificationStore(store data.NotificationStore, m *Metrics) data.NotificationStore {
+	return &notificationStore{next: store, metrics: m}
+}
+
+// AddNotification - метод добавления уведомления с измерением длительности.
+func (s *notificationStore) AddNotification(ctx context.Context, notification *model.Notification) (err error) {
+	defer s.metrics.observeStore("notification", "AddNotification", time.Now(), &err)
+	return s.next.AddNotification(ctx, notification)
+}
+
+// GetNotifications - метод получения уведомлений получателя с измерением длительности.
+func (s *notificationStore) GetNotifications(ctx context.Context, recipient string, first int32, after *string, unreadOnly bool) (_ *model.NotificationConnection, err error) {
+	defer s.metrics.observeStore("notification", "GetNotifications", time.Now(), &err)
+	return s.next.GetNotifications(ctx, recipient, first, after, unreadOnly)
+}
+
+// MarkNotificationsRead - метод отметки уведомлений прочитанными с измерением длительности.
+func (s *notificationStore) MarkNotificationsRead(ctx context.Context, recipient string, ids []string, readAt time.Time) (_ int, err error) {
+	defer s.metrics.observeStore("notification", "MarkNotificationsRead", time.Now(), &err)
+	return s.next.MarkNotificationsRead(ctx, recipient, ids, readAt)
+}
+
+// KnownRecipients - метод проверки имен получателей с измерением длительности.
+func (s *notificationStore) KnownRecipients(ctx context.Context, names []string) (_ []string, err error) {
+	defer s.metrics.observeStore("notification", "KnownRecipients", time.Now(), &err)
+	return s.next.KnownRecipients(ctx, names)
+}
+
+// purger - декоратор data.Purger, измеряющий длительность окончательного удаления.
+type purger struct {
+	next    data.Purger // next - оборачиваемая реализация.
+	metrics *Metrics
+}
+
+// InstrumentPurger - функция, оборачивающая окончательное удаление сбором метрик.
+func InstrumentPurger(p data.Purger, m *Metrics) data.Purger {
+	return &purger{next: p, metrics: m}
+}
+
+// Purge - метод окончательного удаления с измерением длительности.
+func (p *purger) Purge(ctx context.Context, deletedBefore time.Time) (_, _ int, err error) {
+	defer p.metrics.observeStore("purger", "Purge", time.Now(), &err)
+	return p.next.Purge(ctx, deletedBefore)
+}
+
+// unitOfWork - декоратор data.UnitOfWork, измеряющий длительность транзакции целиком
+// и вызовы хранилищ внутри нее.
+type unitOfWork struct {
+	next    data.UnitOfWork // next - оборачиваемая единица работы.
+	metrics *Metrics
+}
+
+// InstrumentUnitOfWork - функция, оборачивающая единицу работы сбором метрик.
+func InstrumentUnitOfWork(uow data.UnitOfWork, m *Metrics) data.UnitOfWork {
+	return &unitOfWork{next: uow, metrics: m}
+}
+
+// Do - метод выполнения единицы работы с измерением длительности.
+func (u *unitOfWork) Do(ctx context.Context, fn func(tx data.Tx) error) (err error) {
+	defer u.metrics.observeStore("tx", "Do", time.Now(), &err)
+	return u.next.Do(ctx, func(tx data.Tx) error {
+		return fn(instrumentedTx{
+			posts:         InstrumentPostStore(tx.Posts(), u.metrics),
+			comments:      InstrumentCommentStore(tx.Comments(), u.metrics),
+			notifications: InstrumentNotificationStore(tx.Notifications(), u.metrics),
+		})
+	})
+}
+
+// instrumentedTx - хранилища транзакции, обернутые сбором метрик.
+type instrumentedTx struct {
+	posts         data.PostStore
+	comments      data.CommentStore
+	notifications data.NotificationStore
+}
+
+// Posts - метод получения хранилища постов транзакции.
+func (t instrumentedTx) Posts() data.PostStore { return t.posts }
+
+// Comments - метод получения хранилища комментариев транзакции.
+func (t instrumentedTx) Comments() data.CommentStore { return t.comments }
+
+// Notifications - метод получения хранилища уведомлений транзакции.
+func (t instrumentedTx) Notifications() data.NotificationStore { return t.notifications }
+
+// searcher - декоратор data.Searcher, измеряющий длительность поиска.
+type searcher struct {
+	next    data.Searcher // next - оборачиваемая реализация.
+	metrics *Metrics
+}
+
+// InstrumentSearcher - функция, оборачивающая полнотекстовый поиск сбором метрик.
+func InstrumentSearcher(s data.Searcher, m *Metrics) data.Searcher {
+	return &searcher{next: s, metrics: m}
+}
+
+// Search - метод поиска с измерением длительности.
+func (s *searcher) Search(ctx context.Context, query string, types []model.SearchType, first int32, after *string) (_ *model.SearchConnection, err error) {
+	defer s.metrics.observeStore("searcher", "Search", time.Now(), &err)
+	return s.next.Search(ctx, query, types, first, after)
+}
@@ -0,0 +1,91 @@
+package persisted
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Коды ошибок, возвращаемые клиенту в extensions.code.
+const (
+	errPersistedQueryNotFoundCode = "PERSISTED_QUERY_NOT_FOUND"   // Операции с таким хэшем нет в манифесте.
+	errOperationNotAllowedCode    = "PERSISTED_QUERY_NOT_ALLOWED" // Произвольный текст запроса, отсутствующий в манифесте.
+)
+
+// Allowlist - расширение gqlgen, пропускающее только операции из манифеста.
+// В отличие от extension.AutomaticPersistedQuery, новые запросы не регистрируются:
+// любая операция, которой нет в манифесте, отклоняется.
+type Allowlist struct {
+	Manifest *Manifest // Manifest - набор разрешенных операций.
+}
+
+var _ interface {
+	graphql.OperationParameterMutator
+	graphql.HandlerExtension
+} = Allowlist{}
+
+// ExtensionName - метод, возвращающий имя расширения.
+func (a Allowlist) ExtensionName() string {
+	return "PersistedQueryAllowlist"
+}
+
+// Validate - метод проверки конфигурации расширения при его подключении к серверу.
+func (a Allowlist) Validate(schema graphql.ExecutableSchema) error {
+	if a.Manifest == nil {
+		return errors.New("PersistedQueryAllowlist.Manifest can not be nil")
+	}
+	return nil
+}
+
+// MutateOperationParameters - метод, подставляющий текст запроса из манифеста по хэшу
+// и отклоняющий запросы, которых в манифесте нет.
+func (a Allowlist) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	hash := persistedQueryHash(rawParams.Extensions)
+
+	if hash == "" {
+		// Клиент прислал только текст запроса: он допустим, если совпадает с операцией из манифеста.
+		if _, ok := a.Manifest.Lookup(QueryHash(rawParams.Query)); !ok {
+			return notAllowed()
+		}
+		return nil
+	}
+
+	op, ok := a.Manifest.Lookup(hash)
+	if !ok {
+		err := gqlerror.Errorf("PersistedQueryNotFound")
+		errcode.Set(err, errPersistedQueryNotFoundCode)
+		return err
+	}
+
+	// Если вместе с хэшем пришел и текст запроса, он должен совпадать с текстом из манифеста.
+	if rawParams.Query != "" && rawParams.Query != op.Body {
+		return notAllowed()
+	}
+
+	rawParams.Query = op.Body
+	if rawParams.OperationName == "" {
+		rawParams.OperationName = op.Name
+	}
+
+	return nil
+}
+
+// persistedQueryHash - вспомогательная функция извлечения хэша из extensions.persistedQuery.sha256Hash.
+func persistedQueryHash(extensions map[string]any) string {
+	persistedQuery, ok := extensions["persistedQuery"].(map[string]any)
+	if !ok {
+		return ""
+	}
+	hash, _ := persistedQuery["sha256Hash"].(string)
+	return hash
+}
+
+// notAllowed - вспомогательная функция, создающая ошибку для операции не из манифеста.
+func notAllowed() *gqlerror.Error {
+	err := gqlerror.Errorf("operation is not in the persisted query allowlist")
+	errcode.Set(err, errOperationNotAllowedCode)
+	return err
+}
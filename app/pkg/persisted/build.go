@@ -0,0 +1,155 @@
+package persisted
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// CollectFiles - функция сбора путей к .graphql файлам.
+// Каталоги обходятся рекурсивно, обычные файлы добавляются как есть.
+func CollectFiles(paths []string) ([]string, error) {
+	var files []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		// Рекурсивный обход каталога в поисках файлов с документами GraphQL.
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && (strings.HasSuffix(p, ".graphql") || strings.HasSuffix(p, ".gql")) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error walking %s: %w", path, err)
+		}
+	}
+
+	return files, nil
+}
+
+// BuildManifestFromFiles - функция построения манифеста из списка .graphql файлов.
+func BuildManifestFromFiles(files []string) (*Manifest, error) {
+	sources := make([]*ast.Source, 0, len(files))
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		sources = append(sources, &ast.Source{Name: file, Input: string(raw)})
+	}
+
+	return BuildManifest(sources)
+}
+
+// BuildManifest - функция построения манифеста из документов GraphQL.
+// Каждая именованная операция попадает в манифест вместе со всеми фрагментами,
+// которые она использует (в том числе объявленными в других файлах).
+func BuildManifest(sources []*ast.Source) (*Manifest, error) {
+	var operations ast.OperationList
+	fragments := make(map[string]*ast.FragmentDefinition)
+	var fragmentOrder []string // fragmentOrder - порядок объявления фрагментов для стабильного вывода.
+
+	for _, source := range sources {
+		doc, err := parser.ParseQuery(source)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", source.Name, err)
+		}
+
+		for _, op := range doc.Operations {
+			if op.Name == "" {
+				return nil, fmt.Errorf("%s: anonymous operations cannot be persisted", source.Name)
+			}
+			if operations.ForName(op.Name) != nil {
+				return nil, fmt.Errorf("%s: duplicate operation name %s", source.Name, op.Name)
+			}
+			operations = append(operations, op)
+		}
+
+		for _, fragment := range doc.Fragments {
+			if _, ok := fragments[fragment.Name]; ok {
+				return nil, fmt.Errorf("%s: duplicate fragment name %s", source.Name, fragment.Name)
+			}
+			fragments[fragment.Name] = fragment
+			fragmentOrder = append(fragmentOrder, fragment.Name)
+		}
+	}
+
+	result := make([]*Operation, 0, len(operations))
+	for _, op := range operations {
+		// Сбор фрагментов, на которые операция ссылается прямо или через другие фрагменты.
+		used := make(map[string]bool)
+		if err := collectFragments(op.SelectionSet, fragments, used); err != nil {
+			return nil, fmt.Errorf("operation %s: %w", op.Name, err)
+		}
+
+		doc := &ast.QueryDocument{Operations: ast.OperationList{op}}
+		for _, name := range fragmentOrder {
+			if used[name] {
+				doc.Fragments = append(doc.Fragments, fragments[name])
+			}
+		}
+
+		var body bytes.Buffer
+		formatter.NewFormatter(&body, formatter.WithIndent("  ")).FormatQueryDocument(doc)
+		text := strings.TrimSpace(body.String())
+
+		result = append(result, &Operation{
+			ID:   QueryHash(text),
+			Name: op.Name,
+			Type: string(op.Operation),
+			Body: text,
+		})
+	}
+
+	return NewManifest(result)
+}
+
+// collectFragments - вспомогательная функция, рекурсивно собирающая имена используемых фрагментов.
+func collectFragments(set ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, used map[string]bool) error {
+	for _, selection := range set {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if err := collectFragments(sel.SelectionSet, fragments, used); err != nil {
+				return err
+			}
+		case *ast.InlineFragment:
+			if err := collectFragments(sel.SelectionSet, fragments, used); err != nil {
+				return err
+			}
+		case *ast.FragmentSpread:
+			if used[sel.Name] {
+				continue // Фрагмент уже учтен, повторный обход не нужен.
+			}
+			fragment, ok := fragments[sel.Name]
+			if !ok {
+				return fmt.Errorf("unknown fragment %s", sel.Name)
+			}
+			used[sel.Name] = true
+			if err := collectFragments(fragment.SelectionSet, fragments, used); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
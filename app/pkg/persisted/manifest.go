@@ -0,0 +1,206 @@
+package persisted
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// Форматы файла-манифеста, которые понимает сервер.
+const (
+	FormatApollo = "apollo" // FormatApollo - манифест в формате apollo-persisted-query-manifest.
+	FormatRelay  = "relay"  // FormatRelay - плоский JSON-объект вида {"<id>": "<текст запроса>"}.
+)
+
+// apolloManifestFormat - значение поля format в манифесте Apollo.
+const apolloManifestFormat = "apollo-persisted-query-manifest"
+
+// Operation - одна разрешенная операция из манифеста.
+type Operation struct {
+	ID   string `json:"id"`   // ID - идентификатор операции (sha256 от текста запроса).
+	Name string `json:"name"` // Name - имя операции.
+	Type string `json:"type"` // Type - тип операции: query, mutation или subscription.
+	Body string `json:"body"` // Body - полный текст запроса вместе с используемыми фрагментами.
+}
+
+// apolloManifest - структура файла манифеста в формате Apollo.
+type apolloManifest struct {
+	Format     string       `json:"format"`
+	Version    int          `json:"version"`
+	Operations []*Operation `json:"operations"`
+}
+
+// Manifest - набор разрешенных операций.
+// Операции индексируются и по идентификатору, и по хэшу текста запроса,
+// так как в формате Relay идентификатор не обязан совпадать с sha256.
+type Manifest struct {
+	byID   map[string]*Operation // byID - операции по идентификатору из манифеста.
+	byHash map[string]*Operation // byHash - операции по sha256 от текста запроса.
+	names  map[string]bool       // names - имена операций манифеста (см. HasOperation).
+}
+
+// NewManifest - функция-конструктор, создающая манифест из списка операций.
+// Возвращает ошибку, если идентификаторы операций повторяются.
+func NewManifest(operations []*Operation) (*Manifest, error) {
+	m := &Manifest{
+		byID:   make(map[string]*Operation, len(operations)),
+		byHash: make(map[string]*Operation, len(operations)),
+		names:  make(map[string]bool, len(operations)),
+	}
+
+	for _, op := range operations {
+		if op.ID == "" {
+			return nil, fmt.Errorf("operation %q has empty id", op.Name)
+		}
+		if _, ok := m.byID[op.ID]; ok {
+			return nil, fmt.Errorf("duplicate operation id %s", op.ID)
+		}
+		m.byID[op.ID] = op
+		m.byHash[QueryHash(op.Body)] = op
+		for _, name := range operationNames(op) {
+			m.names[name] = true
+		}
+	}
+
+	return m, nil
+}
+
+// LoadManifest - функция загрузки манифеста из JSON-файла.
+// Формат файла (Apollo или Relay) определяется автоматически.
+func LoadManifest(path string) (*Manifest, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest: %w", err)
+	}
+
+	manifest, err := ParseManifest(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing manifest %s: %w", path, err)
+	}
+
+	return manifest, nil
+}
+
+// ParseManifest - функция разбора содержимого манифеста.
+// Если в JSON есть поле format, файл считается манифестом Apollo, иначе - картой Relay.
+func ParseManifest(raw []byte) (*Manifest, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, fmt.Errorf("manifest must be a JSON object: %w", err)
+	}
+
+	if _, ok := probe["format"]; ok {
+		var apollo apolloManifest
+		if err := json.Unmarshal(raw, &apollo); err != nil {
+			return nil, err
+		}
+		if apollo.Format != apolloManifestFormat {
+			return nil, fmt.Errorf("unsupported manifest format %q", apollo.Format)
+		}
+		if apollo.Version != 1 {
+			return nil, fmt.Errorf("unsupported manifest version %d", apollo.Version)
+		}
+		return NewManifest(apollo.Operations)
+	}
+
+	// Формат Relay: ключ - идентификатор операции, значение - текст запроса.
+	var relay map[string]string
+	if err := json.Unmarshal(raw, &relay); err != nil {
+		return nil, fmt.Errorf("relay manifest must map ids to query strings: %w", err)
+	}
+
+	operations := make([]*Operation, 0, len(relay))
+	for id, body := range relay {
+		operations = append(operations, &Operation{ID: id, Body: body})
+	}
+
+	return NewManifest(operations)
+}
+
+// Lookup - метод поиска операции по идентификатору из манифеста или по sha256 текста запроса.
+func (m *Manifest) Lookup(hash string) (*Operation, bool) {
+	if op, ok := m.byID[hash]; ok {
+		return op, true
+	}
+	op, ok := m.byHash[hash]
+	return op, ok
+}
+
+// HasOperation - метод проверки, что в манифесте есть операция с указанным именем.
+func (m *Manifest) HasOperation(name string) bool {
+	return m.names[name]
+}
+
+// operationNames - вспомогательная функция имен операции манифеста: имя из манифеста Apollo или,
+// если его нет (формат Relay), имена операций из текста запроса; текст с ошибкой разбора имен не дает.
+func operationNames(op *Operation) []string {
+	if op.Name != "" {
+		return []string{op.Name}
+	}
+	doc, err := parser.ParseQuery(&ast.Source{Input: op.Body})
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, operation := range doc.Operations {
+		if operation.Name != "" {
+			names = append(names, operation.Name)
+		}
+	}
+	return names
+}
+
+// Len - метод, возвращающий количество операций в манифесте.
+func (m *Manifest) Len() int {
+	return len(m.byID)
+}
+
+// Operations - метод, возвращающий операции манифеста, отсортированные по имени и идентификатору.
+func (m *Manifest) Operations() []*Operation {
+	operations := make([]*Operation, 0, len(m.byID))
+	for _, op := range m.byID {
+		operations = append(operations, op)
+	}
+
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Name != operations[j].Name {
+			return operations[i].Name < operations[j].Name
+		}
+		return operations[i].ID < operations[j].ID
+	})
+
+	return operations
+}
+
+// Marshal - метод сериализации манифеста в указанный формат (FormatApollo или FormatRelay).
+func (m *Manifest) Marshal(format string) ([]byte, error) {
+	switch format {
+	case FormatApollo, "":
+		return json.MarshalIndent(apolloManifest{
+			Format:     apolloManifestFormat,
+			Version:    1,
+			Operations: m.Operations(),
+		}, "", "  ")
+	case FormatRelay:
+		relay := make(map[string]string, len(m.byID))
+		for id, op := range m.byID {
+			relay[id] = op.Body
+		}
+		return json.MarshalIndent(relay, "", "  ")
+	default:
+		return nil, fmt.Errorf("unknown manifest format %q", format)
+	}
+}
+
+// QueryHash - функция вычисления sha256 от текста запроса в шестнадцатеричном виде.
+// Совпадает с хэшем, который отправляют клиенты Apollo в extensions.persistedQuery.
+func QueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,128 @@
+package persisted
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestBuildManifest(t *testing.T) {
+	// TestBuildManifest проверяет построение манифеста с фрагментами из разных файлов.
+	sources := []*ast.Source{
+		{Name: "post.graphql", Input: `query GetPost($id: ID!) { post(id: $id) { ...PostFields } }`},
+		{Name: "fragments.graphql", Input: `fragment PostFields on Post { id title } fragment Unused on Post { author }`},
+	}
+
+	manifest, err := BuildManifest(sources)
+	if err != nil {
+		t.Fatalf("Failed to build manifest: %v", err)
+	}
+	if manifest.Len() != 1 {
+		t.Fatalf("Expected 1 operation, got %d", manifest.Len())
+	}
+
+	op := manifest.Operations()[0]
+	if op.Name != "GetPost" || op.Type != "query" {
+		t.Errorf("Unexpected operation: %+v", op)
+	}
+	if op.ID != QueryHash(op.Body) {
+		t.Errorf("Operation id must be sha256 of its body")
+	}
+	// В тело операции должен попасть только используемый фрагмент.
+	if !strings.Contains(op.Body, "fragment PostFields") || strings.Contains(op.Body, "Unused") {
+		t.Errorf("Unexpected operation body: %s", op.Body)
+	}
+}
+
+func TestBuildManifestErrors(t *testing.T) {
+	// TestBuildManifestErrors проверяет отказ от анонимных операций и неизвестных фрагментов.
+	cases := map[string]string{
+		"anonymous":        `{ posts { edges { cursor } } }`,
+		"unknown fragment": `query Q { posts { ...Missing } }`,
+	}
+
+	for name, input := range cases {
+		if _, err := BuildManifest([]*ast.Source{{Name: "q.graphql", Input: input}}); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	// TestManifestRoundTrip проверяет сериализацию и повторную загрузку в обоих форматах.
+	manifest, err := BuildManifest([]*ast.Source{{Name: "q.graphql", Input: `query A { posts { pageInfo { hasNextPage } } }`}})
+	if err != nil {
+		t.Fatalf("Failed to build manifest: %v", err)
+	}
+	id := manifest.Operations()[0].ID
+
+	for _, format := range []string{FormatApollo, FormatRelay} {
+		raw, err := manifest.Marshal(format)
+		if err != nil {
+			t.Fatalf("%s: failed to marshal: %v", format, err)
+		}
+
+		loaded, err := ParseManifest(raw)
+		if err != nil {
+			t.Fatalf("%s: failed to parse: %v", format, err)
+		}
+		if _, ok := loaded.Lookup(id); !ok {
+			t.Errorf("%s: operation %s not found after round trip", format, id)
+		}
+		// В формате Relay имени нет, и оно берется из текста запроса.
+		if !loaded.HasOperation("A") || loaded.HasOperation("B") {
+			t.Errorf("%s: expected only operation name A to be known", format)
+		}
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	// TestAllowlist проверяет пропуск операций из манифеста и отказ для остальных.
+	body := `query A { posts { pageInfo { hasNextPage } } }`
+	manifest, err := NewManifest([]*Operation{{ID: "relay-id", Name: "A", Type: "query", Body: body}})
+	if err != nil {
+		t.Fatalf("Failed to create manifest: %v", err)
+	}
+	allowlist := Allowlist{Manifest: manifest}
+	ctx := context.Background()
+
+	// Запрос по идентификатору из манифеста: текст запроса подставляется сервером.
+	params := &graphql.RawParams{Extensions: map[string]any{"persistedQuery": map[string]any{"sha256Hash": "relay-id"}}}
+	if err := allowlist.MutateOperationParameters(ctx, params); err != nil {
+		t.Fatalf("Expected known id to be allowed, got %v", err)
+	}
+	if params.Query != body || params.OperationName != "A" {
+		t.Errorf("Expected query from manifest, got %q (%q)", params.Query, params.OperationName)
+	}
+
+	// Запрос по sha256 текста тоже должен находиться.
+	params = &graphql.RawParams{Extensions: map[string]any{"persistedQuery": map[string]any{"sha256Hash": QueryHash(body)}}}
+	if err := allowlist.MutateOperationParameters(ctx, params); err != nil {
+		t.Errorf("Expected known hash to be allowed, got %v", err)
+	}
+
+	// Полный текст известного запроса без хэша допустим.
+	if err := allowlist.MutateOperationParameters(ctx, &graphql.RawParams{Query: body}); err != nil {
+		t.Errorf("Expected known query text to be allowed, got %v", err)
+	}
+
+	// Произвольный запрос отклоняется.
+	if err := allowlist.MutateOperationParameters(ctx, &graphql.RawParams{Query: `{ __typename }`}); err == nil {
+		t.Error("Expected unknown query to be rejected")
+	}
+
+	// Неизвестный хэш отклоняется.
+	params = &graphql.RawParams{Extensions: map[string]any{"persistedQuery": map[string]any{"sha256Hash": "unknown"}}}
+	if err := allowlist.MutateOperationParameters(ctx, params); err == nil {
+		t.Error("Expected unknown hash to be rejected")
+	}
+
+	// Известный хэш с подмененным текстом запроса отклоняется.
+	params = &graphql.RawParams{Query: `{ __typename }`, Extensions: map[string]any{"persistedQuery": map[string]any{"sha256Hash": "relay-id"}}}
+	if err := allowlist.MutateOperationParameters(ctx, params); err == nil {
+		t.Error("Expected mismatched query to be rejected")
+	}
+}
@@ -0,0 +1,77 @@
+// Package pubsub - рассылка событий подписчикам внутри процесса (для подписок GraphQL).
+package pubsub
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// BufferSize - число событий, которые подписчик может еще не забрать: если буфер заполнен,
+// новые события для этого подписчика отбрасываются, чтобы медленный клиент не задерживал остальных.
+const BufferSize = 16
+
+// Broker - рассылка событий типа T подписчикам темы (например, получателя уведомлений).
+// Брокер работает в пределах одного процесса: при нескольких экземплярах сервиса подписчик получает
+// только события, опубликованные его экземпляром.
+type Broker[T any] struct {
+	mu   sync.Mutex                     // mu защищает подписчиков и упорядочивает отправку с закрытием каналов.
+	subs map[string]map[chan T]struct{} // subs - каналы подписчиков по темам.
+}
+
+// New - функция-конструктор брокера без подписчиков.
+func New[T any]() *Broker[T] {
+	return &Broker[T]{subs: make(map[string]map[chan T]struct{})}
+}
+
+// Subscribe - метод подписки на события темы topic. Канал закрывается, когда ctx отменен
+// (например, клиент закрыл соединение).
+func (b *Broker[T]) Subscribe(ctx context.Context, topic string) <-chan T {
+	ch := make(chan T, BufferSize)
+
+	b.mu.Lock()
+	if b.subs[topic] == nil {
+		b.subs[topic] = make(map[chan T]struct{})
+	}
+	b.subs[topic][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs[topic], ch)
+		if len(b.subs[topic]) == 0 {
+			delete(b.subs, topic)
+		}
+		close(ch)
+	}()
+
+	return ch
+}
+
+// Publish - метод отправки события всем подписчикам темы topic. Не блокируется;
+// возвращает число подписчиков, получивших событие.
+func (b *Broker[T]) Publish(topic string, event T) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delivered := 0
+	for ch := range b.subs[topic] {
+		select {
+		case ch <- event:
+			delivered++
+		default:
+			slog.Warn("subscriber buffer is full, event dropped")
+		}
+	}
+	return delivered
+}
+
+// Subscribers - метод получения числа подписчиков темы topic.
+func (b *Broker[T]) Subscribers(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs[topic])
+}
@@ -0,0 +1,77 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	// TestPublishSubscribe проверяет, что событие получают только подписчики своей темы.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := New[string]()
+	alice := b.Subscribe(ctx, "alice")
+	alice2 := b.Subscribe(ctx, "alice")
+	bob := b.Subscribe(ctx, "bob")
+
+	if delivered := b.Publish("alice", "hello"); delivered != 2 {
+		t.Errorf("Expected 2 deliveries, got %d", delivered)
+	}
+	for _, ch := range []<-chan string{alice, alice2} {
+		if event := <-ch; event != "hello" {
+			t.Errorf("Expected hello, got %q", event)
+		}
+	}
+	select {
+	case event := <-bob:
+		t.Errorf("Expected no events for another topic, got %q", event)
+	default:
+	}
+
+	if delivered := b.Publish("carol", "nobody"); delivered != 0 {
+		t.Errorf("Expected no deliveries without subscribers, got %d", delivered)
+	}
+}
+
+func TestSubscriptionEndsWithContext(t *testing.T) {
+	// TestSubscriptionEndsWithContext проверяет, что после отмены контекста канал закрывается и подписчик удаляется.
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New[int]()
+	ch := b.Subscribe(ctx, "topic")
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("Expected the channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Channel was not closed after cancel")
+	}
+	if n := b.Subscribers("topic"); n != 0 {
+		t.Errorf("Expected no subscribers, got %d", n)
+	}
+	if delivered := b.Publish("topic", 1); delivered != 0 {
+		t.Errorf("Expected no deliveries after unsubscribe, got %d", delivered)
+	}
+}
+
+func TestSlowSubscriberDoesNotBlock(t *testing.T) {
+	// TestSlowSubscriberDoesNotBlock проверяет, что при заполненном буфере события отбрасываются, а Publish не блокируется.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := New[int]()
+	ch := b.Subscribe(ctx, "topic")
+	for i := range BufferSize {
+		b.Publish("topic", i)
+	}
+	if delivered := b.Publish("topic", BufferSize); delivered != 0 {
+		t.Errorf("Expected the event to be dropped, got %d deliveries", delivered)
+	}
+	if first := <-ch; first != 0 {
+		t.Errorf("Expected events in order, got %d first", first)
+	}
+}
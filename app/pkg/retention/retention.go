@@ -0,0 +1,57 @@
+// Package retention - окончательное удаление постов и комментариев после срока хранения.
+// Удаленные записи остаются в хранилище, чтобы модератор мог их восстановить; по истечении срока
+// фоновая задача удаляет их безвозвратно (см. data.Purger).
+package retention
+
+import (
+	"context"
+	"graphql-comment-system/app/pkg/data"
+	"log/slog"
+	"time"
+)
+
+// Purger - фоновая задача окончательного удаления записей с истекшим сроком хранения.
+type Purger struct {
+	purger    data.Purger   // purger - окончательное удаление в хранилище.
+	retention time.Duration // retention - срок, в течение которого удаленную запись можно восстановить.
+	interval  time.Duration // interval - период запуска.
+}
+
+// New - функция-конструктор фоновой задачи окончательного удаления.
+func New(purger data.Purger, retention, interval time.Duration) *Purger {
+	return &Purger{purger: purger, retention: retention, interval: interval}
+}
+
+// Run - метод периодического окончательного удаления: сразу при запуске и затем раз в interval.
+// Блокируется до отмены ctx; ошибки записываются в лог, и следующая попытка выполняется по расписанию.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.PurgeExpired(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "error purging deleted records", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired - метод окончательного удаления записей, удаленных раньше, чем now минус срок хранения.
+func (p *Purger) PurgeExpired(ctx context.Context, now time.Time) error {
+	deletedBefore := now.Add(-p.retention).UTC()
+	posts, comments, err := p.purger.Purge(ctx, deletedBefore)
+	if err != nil {
+		return err
+	}
+
+	if posts > 0 || comments > 0 {
+		slog.InfoContext(ctx, "deleted records purged",
+			slog.Int("posts", posts), slog.Int("comments", comments), slog.Time("deleted_before", deletedBefore))
+	}
+	return nil
+}
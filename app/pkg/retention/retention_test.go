@@ -0,0 +1,64 @@
+package retention
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingPurger - заглушка data.Purger, запоминающая границы сроков хранения.
+type recordingPurger struct {
+	mu     sync.Mutex
+	cutoff []time.Time
+	calls  chan struct{}
+}
+
+func (p *recordingPurger) Purge(ctx context.Context, deletedBefore time.Time) (int, int, error) {
+	p.mu.Lock()
+	p.cutoff = append(p.cutoff, deletedBefore)
+	p.mu.Unlock()
+	select {
+	case p.calls <- struct{}{}:
+	default:
+	}
+	return 1, 2, nil
+}
+
+func TestPurgeExpired(t *testing.T) {
+	// TestPurgeExpired проверяет границу срока хранения, передаваемую хранилищу.
+	store := &recordingPurger{calls: make(chan struct{}, 1)}
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	if err := New(store, 30*24*time.Hour, time.Hour).PurgeExpired(context.Background(), now); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	if len(store.cutoff) != 1 || !store.cutoff[0].Equal(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected cutoff 2024-03-01T09:00:00Z, got %v", store.cutoff)
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	// TestRunStopsOnCancel проверяет, что задача выполняется сразу при запуске и завершается при отмене контекста.
+	store := &recordingPurger{calls: make(chan struct{}, 1)}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		New(store, time.Hour, time.Hour).Run(ctx)
+	}()
+
+	select {
+	case <-store.calls:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a purge right after start")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Run to return after cancellation")
+	}
+}
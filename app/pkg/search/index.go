@@ -0,0 +1,105 @@
+package search
+
+import (
+	"math"
+	"sync"
+)
+
+// Field - поле документа для индексации: текст и вес его слов при ранжировании
+// (например, слова заголовка весят больше слов текста).
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Index - инвертированный индекс: для каждой основы слова хранит документы, в которых она встречается.
+// Безопасен для одновременного использования из нескольких горутин.
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[string]float64 // postings - вес основы в документе по основе и ID документа.
+	docs     map[string][]string           // docs - основы документа по ID, для удаления из postings.
+}
+
+// NewIndex - функция-конструктор пустого индекса.
+func NewIndex() *Index {
+	return &Index{postings: make(map[string]map[string]float64), docs: make(map[string][]string)}
+}
+
+// Add - метод индексации документа id. Документ с тем же ID заменяется.
+func (x *Index) Add(id string, fields ...Field) {
+	weights := make(map[string]float64)
+	for _, field := range fields {
+		for _, term := range Terms(field.Text) {
+			weights[term] += field.Weight
+		}
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.remove(id)
+	terms := make([]string, 0, len(weights))
+	for term, weight := range weights {
+		if x.postings[term] == nil {
+			x.postings[term] = make(map[string]float64)
+		}
+		x.postings[term][id] = weight
+		terms = append(terms, term)
+	}
+	x.docs[id] = terms
+}
+
+// Remove - метод удаления документа из индекса.
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(id)
+}
+
+// remove - вспомогательный метод удаления документа; вызывается под блокировкой.
+func (x *Index) remove(id string) {
+	for _, term := range x.docs[id] {
+		delete(x.postings[term], id)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	delete(x.docs, id)
+}
+
+// Match - метод поиска документов, содержащих все основы terms (см. QueryTerms).
+// Возвращает ранг каждого найденного документа: чем больше, тем документ релевантнее.
+// Ранг растет с весом слов в документе и с их редкостью среди всех документов (TF-IDF).
+func (x *Index) Match(terms []string) map[string]float64 {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	// Поиск начинается с самой редкой основы, чтобы перебирать как можно меньше документов.
+	rarest := terms[0]
+	for _, term := range terms[1:] {
+		if len(x.postings[term]) < len(x.postings[rarest]) {
+			rarest = term
+		}
+	}
+
+	total := float64(len(x.docs))
+	ranks := make(map[string]float64)
+candidates:
+	for id := range x.postings[rarest] {
+		var rank float64
+		for _, term := range terms {
+			weight, ok := x.postings[term][id]
+			if !ok {
+				continue candidates
+			}
+			idf := math.Log(1 + total/float64(len(x.postings[term])))
+			rank += (1 + math.Log(weight)) * idf
+		}
+		ranks[id] = rank
+	}
+	return ranks
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	// TestTerms проверяет стемминг русских и английских слов и пропуск стоп-слов.
+	for _, tc := range []struct {
+		text string
+		want []string
+	}{
+		{"Кошки и коты", []string{"кошк", "кот"}},
+		{"кошками, котами!", []string{"кошк", "кот"}},
+		{"Ёжик в тумане", []string{"ежик", "туман"}},
+		{"The cats are running", []string{"cat", "run"}},
+		{"Go 1.24 и PostgreSQL", []string{"go", "1", "24", "postgresql"}},
+	} {
+		if got := Terms(tc.text); fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("Terms(%q) = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+
+	if got := QueryTerms("кошка кошки и"); fmt.Sprint(got) != "[кошк]" {
+		t.Errorf("Expected unique query terms without stop words, got %v", got)
+	}
+}
+
+func TestIndexMatch(t *testing.T) {
+	// TestIndexMatch проверяет, что находятся только документы со всеми словами запроса,
+	// а слова заголовка весят больше слов текста.
+	x := NewIndex()
+	x.Add("title", Field{Text: "Кошки", Weight: 2}, Field{Text: "Рассказ о доме", Weight: 1})
+	x.Add("content", Field{Text: "Заметка", Weight: 2}, Field{Text: "У нас живет кошка в доме", Weight: 1})
+	x.Add("other", Field{Text: "Собаки", Weight: 2})
+
+	ranks := x.Match(QueryTerms("кошки"))
+	if len(ranks) != 2 || ranks["title"] <= ranks["content"] {
+		t.Errorf("Expected title match to rank higher, got %v", ranks)
+	}
+	if ranks := x.Match(QueryTerms("кошка дом")); len(ranks) != 2 {
+		t.Errorf("Expected both documents with all terms, got %v", ranks)
+	}
+	if ranks := x.Match(QueryTerms("кошка собака")); len(ranks) != 0 {
+		t.Errorf("Expected no document with all terms, got %v", ranks)
+	}
+
+	// Замена и удаление документа обновляют индекс.
+	x.Add("title", Field{Text: "Про собак", Weight: 2})
+	x.Remove("content")
+	if ranks := x.Match(QueryTerms("кошка")); len(ranks) != 0 {
+		t.Errorf("Expected replaced and removed documents not to match, got %v", ranks)
+	}
+	if ranks := x.Match(QueryTerms("собаки")); len(ranks) != 2 {
+		t.Errorf("Expected replaced document to match new text, got %v", ranks)
+	}
+	if ranks := x.Match(nil); ranks != nil {
+		t.Errorf("Expected no matches for an empty query, got %v", ranks)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	// TestSnippet проверяет подсветку найденных слов, экранирование HTML и обрезку длинного текста.
+	got := Snippet(QueryTerms("кошка"), "Наши <кошки> любят молоко.")
+	if got != "Наши &lt;<mark>кошки</mark>&gt; любят молоко." {
+		t.Errorf("Unexpected snippet %q", got)
+	}
+
+	long := "раз два три четыре пять шесть семь восемь девять десять кошка " +
+		"один два три четыре пять шесть семь восемь девять десять одиннадцать двенадцать тринадцать четырнадцать пятнадцать"
+	got = Snippet(QueryTerms("кошка"), long)
+	want := Ellipsis + "шесть семь восемь девять десять <mark>кошка</mark> один два три четыре пять шесть семь восемь девять десять одиннадцать двенадцать тринадцать четырнадцать" + Ellipsis
+	if got != want {
+		t.Errorf("Unexpected long snippet:\n got: %q\nwant: %q", got, want)
+	}
+
+	// Фрагмент берется из первого текста с найденным словом, а без совпадений - из начала первого текста.
+	if got := Snippet(QueryTerms("кошка"), "Текст поста", "Про кошек и кошку"); got != "Про кошек и <mark>кошку</mark>" {
+		t.Errorf("Expected snippet of the second text, got %q", got)
+	}
+	if got := Snippet(QueryTerms("кошка"), "Про собак", "Заголовок"); got != "Про собак" {
+		t.Errorf("Expected text start without match, got %q", got)
+	}
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// MarkStart и MarkEnd - теги, которыми в фрагменте выделяются найденные слова.
+// Остальной текст фрагмента экранируется как HTML.
+const (
+	MarkStart = "<mark>"
+	MarkEnd   = "</mark>"
+)
+
+// Ellipsis - знак пропуска в начале или конце фрагмента, если текст в него не поместился.
+const Ellipsis = "…"
+
+// Размер фрагмента в словах: до snippetContext слов перед первым найденным словом, всего не больше snippetWords.
+const (
+	snippetWords   = 20
+	snippetContext = 5
+)
+
+// Snippet - функция построения фрагмента первого из texts, в котором есть слово с основой из terms
+// (см. QueryTerms), например текста поста, а затем заголовка. Фрагмент строится вокруг первого найденного слова,
+// найденные слова выделяются MarkStart и MarkEnd. Если слов нет ни в одном тексте, фрагмент берется из начала первого.
+func Snippet(terms []string, texts ...string) string {
+	if len(texts) == 0 {
+		return ""
+	}
+	for _, text := range texts {
+		if result, found := fragment(text, terms); found {
+			return result
+		}
+	}
+	result, _ := fragment(texts[0], terms)
+	return result
+}
+
+// fragment - вспомогательная функция построения фрагмента одного текста.
+// Второе значение сообщает, найдено ли слово; если нет, фрагмент берется из начала текста.
+func fragment(text string, terms []string) (string, bool) {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return html.EscapeString(strings.TrimSpace(text)), false
+	}
+
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		wanted[term] = true
+	}
+
+	first, found := 0, false
+	for i, t := range tokens {
+		if t.term != "" && wanted[t.term] {
+			first, found = i, true
+			break
+		}
+	}
+
+	from := max(first-snippetContext, 0)
+	to := min(from+snippetWords, len(tokens))
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString(Ellipsis)
+	}
+	pos := tokens[from].start
+	for _, t := range tokens[from:to] {
+		b.WriteString(html.EscapeString(text[pos:t.start]))
+		word := html.EscapeString(text[t.start:t.end])
+		if t.term != "" && wanted[t.term] {
+			word = MarkStart + word + MarkEnd
+		}
+		b.WriteString(word)
+		pos = t.end
+	}
+	if to < len(tokens) {
+		b.WriteString(Ellipsis)
+	} else {
+		b.WriteString(html.EscapeString(strings.TrimRight(text[pos:], " \t\r\n")))
+	}
+
+	return b.String(), found
+}
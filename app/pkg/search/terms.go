@@ -0,0 +1,93 @@
+// Package search - полнотекстовый поиск для хранилищ без встроенного поиска (in-memory и SQLite):
+// разбиение текста на слова, стемминг русских и английских слов, инвертированный индекс
+// и фрагменты текста с подсветкой найденных слов.
+//
+// Разбор текста повторяет конфигурацию russian в PostgreSQL: кириллические слова приводятся
+// к основе русским стеммером Snowball, латинские - английским, стоп-слова не индексируются.
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kljensen/snowball/english"
+	"github.com/kljensen/snowball/russian"
+)
+
+// token - слово текста: границы в байтах и основа слова ("" для стоп-слов).
+type token struct {
+	start, end int
+	term       string
+}
+
+// tokenize - вспомогательная функция разбиения текста на слова: слово - непрерывная последовательность букв и цифр.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{start: start, end: i, term: stem(text[start:i])})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{start: start, end: len(text), term: stem(text[start:])})
+	}
+	return tokens
+}
+
+// stem - вспомогательная функция приведения слова к основе. Для стоп-слов возвращает пустую строку.
+func stem(word string) string {
+	word = strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+
+	switch {
+	case hasScript(word, unicode.Cyrillic):
+		if russian.IsStopWord(word) {
+			return ""
+		}
+		return russian.Stem(word, true)
+	case hasScript(word, unicode.Latin):
+		if english.IsStopWord(word) {
+			return ""
+		}
+		return english.Stem(word, true)
+	default:
+		return word // Числа и слова других алфавитов индексируются как есть.
+	}
+}
+
+// hasScript - вспомогательная функция проверки, есть ли в слове буквы алфавита script.
+func hasScript(word string, script *unicode.RangeTable) bool {
+	return strings.IndexFunc(word, func(r rune) bool { return unicode.Is(script, r) }) >= 0
+}
+
+// Terms - функция получения основ слов текста в порядке следования (с повторами, без стоп-слов).
+func Terms(text string) []string {
+	var terms []string
+	for _, t := range tokenize(text) {
+		if t.term != "" {
+			terms = append(terms, t.term)
+		}
+	}
+	return terms
+}
+
+// QueryTerms - функция получения различных основ слов поискового запроса.
+// Пустой результат означает, что в запросе нет значимых слов и искать нечего.
+func QueryTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range Terms(query) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
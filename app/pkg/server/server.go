@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config - структура настроек HTTP-сервера.
+type Config struct {
+	Addr              string        // Addr - адрес для прослушивания, например ":50051".
+	ReadTimeout       time.Duration // ReadTimeout - максимальное время чтения запроса вместе с телом.
+	ReadHeaderTimeout time.Duration // ReadHeaderTimeout - максимальное время чтения заголовков запроса.
+	WriteTimeout      time.Duration // WriteTimeout - максимальное время записи ответа.
+	IdleTimeout       time.Duration // IdleTimeout - время жизни простаивающего keep-alive соединения.
+	ShutdownTimeout   time.Duration // ShutdownTimeout - время на завершение активных запросов и подписок при остановке.
+	DrainDelay        time.Duration // DrainDelay - пауза после перевода в "не готов", чтобы балансировщик успел убрать экземпляр.
+}
+
+// DefaultConfig - функция, возвращающая настройки сервера по умолчанию.
+func DefaultConfig() Config {
+	return Config{
+		Addr:              ":50051",
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		ShutdownTimeout:   20 * time.Second,
+	}
+}
+
+// Server - HTTP-сервер с таймаутами и корректной остановкой.
+// При остановке сервер перестает принимать соединения, дожидается завершения обычных запросов,
+// закрывает активные WebSocket-подписки и ждет их завершения в пределах ShutdownTimeout.
+type Server struct {
+	config     Config
+	httpServer *http.Server
+	logger     *slog.Logger
+
+	streamsCtx    context.Context    // streamsCtx - контекст долгоживущих WebSocket-соединений.
+	cancelStreams context.CancelFunc // cancelStreams - отменяет streamsCtx, закрывая все подписки.
+
+	mu       sync.Mutex
+	closing  bool           // closing - сервер начал остановку, новые WebSocket-соединения не принимаются.
+	streams  sync.WaitGroup // streams - счетчик активных WebSocket-соединений.
+	onClose  []func()       // onClose - функции, вызываемые в начале остановки.
+	shutdown sync.Once
+}
+
+// New - функция-конструктор сервера.
+func New(config Config, handler http.Handler, logger *slog.Logger) *Server {
+	s := &Server{config: config, logger: logger}
+	s.streamsCtx, s.cancelStreams = context.WithCancel(context.Background())
+
+	s.httpServer = &http.Server{
+		Addr:              config.Addr,
+		Handler:           s.trackStreams(handler),
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+
+	return s
+}
+
+// OnShutdown - метод регистрации функции, вызываемой в самом начале остановки сервера
+// (например, чтобы перевести проверку готовности в состояние "не готов").
+func (s *Server) OnShutdown(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onClose = append(s.onClose, f)
+}
+
+// Run - метод запуска сервера на адресе из конфигурации.
+// Блокируется до отмены ctx (например, по SIGTERM) и возвращается после корректной остановки.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", s.config.Addr, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve - метод обслуживания соединений на переданном listener до отмены ctx.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		// Сервер остановился сам, без сигнала: это ошибка (например, listener закрыт извне).
+		return fmt.Errorf("server stopped unexpectedly: %w", err)
+	case <-ctx.Done():
+	}
+
+	return s.Shutdown()
+}
+
+// Shutdown - метод корректной остановки сервера.
+func (s *Server) Shutdown() error {
+	var err error
+	s.shutdown.Do(func() {
+		err = s.doShutdown()
+	})
+	return err
+}
+
+// doShutdown - вспомогательный метод, выполняющий остановку один раз.
+func (s *Server) doShutdown() error {
+	s.mu.Lock()
+	s.closing = true
+	hooks := s.onClose
+	s.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	// Пока идет пауза, сервер продолжает обслуживать запросы, а /readyz уже отвечает 503.
+	if s.config.DrainDelay > 0 {
+		s.logger.Info("draining before shutdown", slog.Duration("delay", s.config.DrainDelay))
+		time.Sleep(s.config.DrainDelay)
+	}
+
+	s.logger.Info("shutting down server", slog.Duration("timeout", s.config.ShutdownTimeout))
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+
+	// WebSocket-соединения перехвачены у http.Server, и Shutdown их не ждет,
+	// поэтому их контекст отменяется явно, а завершение отслеживается отдельно.
+	s.cancelStreams()
+	streamsDone := make(chan struct{})
+	go func() {
+		s.streams.Wait()
+		close(streamsDone)
+	}()
+
+	// Shutdown закрывает listener и простаивающие соединения и ждет завершения активных запросов.
+	err := s.httpServer.Shutdown(ctx)
+
+	select {
+	case <-streamsDone:
+	case <-ctx.Done():
+		err = errors.Join(err, fmt.Errorf("websocket connections did not close in time: %w", ctx.Err()))
+	}
+
+	if err != nil {
+		// Время на остановку истекло: оставшиеся соединения закрываются принудительно.
+		return errors.Join(err, s.httpServer.Close())
+	}
+
+	s.logger.Info("server stopped")
+	return nil
+}
+
+// trackStreams - middleware, отслеживающее WebSocket-соединения.
+// Контекст такого запроса дополнительно отменяется при остановке сервера, что закрывает подписки.
+func (s *Server) trackStreams(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		s.mu.Lock()
+		if s.closing {
+			s.mu.Unlock()
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		s.streams.Add(1)
+		s.mu.Unlock()
+		defer s.streams.Done()
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stop := context.AfterFunc(s.streamsCtx, cancel) // Отмена контекста запроса при остановке сервера.
+		defer stop()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// isWebSocketUpgrade - вспомогательная функция, определяющая запрос на установку WebSocket-соединения.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
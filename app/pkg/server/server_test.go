@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// startServer - вспомогательная функция запуска сервера на случайном порту.
+func startServer(t *testing.T, handler http.Handler) (*Server, string, context.CancelFunc, chan error) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.ShutdownTimeout = 2 * time.Second
+	srv := New(config, handler, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, listener) }()
+
+	return srv, "http://" + listener.Addr().String(), cancel, done
+}
+
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	// TestShutdownDrainsInFlightRequests проверяет, что начатый запрос завершается после сигнала остановки.
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		_, _ = io.WriteString(w, "done")
+	})
+
+	srv, url, cancel, done := startServer(t, handler)
+
+	var hookCalled bool
+	srv.OnShutdown(func() { hookCalled = true })
+
+	result := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			result <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		result <- string(body)
+	}()
+
+	<-started
+	cancel() // Имитация SIGTERM во время обработки запроса.
+
+	if body := <-result; body != "done" {
+		t.Errorf("Expected in-flight request to complete, got %q", body)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected clean shutdown, got %v", err)
+	}
+	if !hookCalled {
+		t.Error("Expected shutdown hook to be called")
+	}
+}
+
+func TestShutdownClosesWebSocketStreams(t *testing.T) {
+	// TestShutdownClosesWebSocketStreams проверяет, что контекст долгоживущего WebSocket-запроса
+	// отменяется при остановке и сервер дожидается его завершения.
+	streamClosed := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("Failed to hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		<-r.Context().Done() // Подписка живет, пока сервер не начнет остановку.
+		close(streamClosed)
+	})
+
+	_, url, cancel, done := startServer(t, handler)
+
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	go func() { _, _ = http.DefaultClient.Do(req) }()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-streamClosed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected websocket stream to be closed on shutdown")
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected clean shutdown, got %v", err)
+	}
+}
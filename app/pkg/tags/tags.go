@@ -0,0 +1,60 @@
+// Package tags - нормализация названий тегов постов.
+//
+// Тег хранится и сравнивается в нормализованном виде (slug): буквы в нижнем регистре, ё заменяется на е,
+// а любые последовательности пробелов и знаков между буквами и цифрами - одним дефисом.
+// Поэтому "Go Lang", " go_lang " и "#GO-LANG" - один и тот же тег go-lang.
+package tags
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ограничения тегов: длина нормализованного названия в символах и число тегов у одного поста.
+const (
+	MaxLength  = 50
+	MaxPerPost = 10
+)
+
+// Normalize - функция нормализации названия тега. Возвращает пустую строку, если в названии нет ни букв, ни цифр.
+func Normalize(name string) string {
+	var b strings.Builder
+	separator := false
+	for _, r := range strings.ToLower(name) {
+		if r == 'ё' {
+			r = 'е'
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			separator = b.Len() > 0
+			continue
+		}
+		if separator {
+			b.WriteByte('-')
+			separator = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// NormalizeAll - функция нормализации списка тегов поста: названия нормализуются, пустые и повторы отбрасываются,
+// результат упорядочен по алфавиту, как теги в ответах хранилищ. Для пустого списка возвращается пустой, а не nil.
+func NormalizeAll(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if tag := Normalize(name); tag != "" && !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Valid - функция проверки нормализованного названия тега: не пустое и не длиннее MaxLength символов.
+func Valid(tag string) bool {
+	return tag != "" && utf8.RuneCountInString(tag) <= MaxLength
+}
@@ -0,0 +1,34 @@
+package tags
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	// TestNormalize проверяет приведение регистра, замену разделителей дефисом и обрезку краев.
+	for name, want := range map[string]string{
+		"Go":             "go",
+		"  Go Lang  ":    "go-lang",
+		"go_lang":        "go-lang",
+		"#GO--LANG!":     "go-lang",
+		"Ёжики в Тумане": "ежики-в-тумане",
+		"PostgreSQL 16":  "postgresql-16",
+		" !? ":           "",
+	} {
+		if got := Normalize(name); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNormalizeAll(t *testing.T) {
+	// TestNormalizeAll проверяет удаление пустых названий и повторов и порядок по алфавиту.
+	got := NormalizeAll([]string{"Новости", "go", " GO ", "!!", "новости"})
+	if fmt.Sprint(got) != "[go новости]" {
+		t.Errorf("Unexpected normalized tags %v", got)
+	}
+	if got := NormalizeAll(nil); got == nil || len(got) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %#v", got)
+	}
+}
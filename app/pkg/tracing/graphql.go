@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer - расширение gqlgen, создающее спан на каждую GraphQL-операцию
+// и дочерние спаны на каждое поле, вычисляемое собственным resolver.
+// Поля, которые просто читаются из структуры модели, отдельных спанов не получают.
+type Tracer struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Tracer{}
+
+// ExtensionName - метод, возвращающий имя расширения.
+func (Tracer) ExtensionName() string {
+	return "OpenTelemetry"
+}
+
+// Validate - метод проверки конфигурации расширения.
+func (Tracer) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse - метод, оборачивающий выполнение операции в спан.
+// Resolvers полей получают контекст с этим спаном и становятся его потомками.
+func (Tracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+
+	opCtx := graphql.GetOperationContext(ctx)
+
+	name, opType := "anonymous", "unknown"
+	if opCtx.Operation != nil {
+		opType = string(opCtx.Operation.Operation)
+		if opCtx.Operation.Name != "" {
+			name = opCtx.Operation.Name
+		}
+	}
+
+	ctx, span := tracer().Start(ctx, "graphql."+opType+" "+name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("graphql.operation.name", name),
+			attribute.String("graphql.operation.type", opType),
+		),
+	)
+	defer span.End()
+
+	resp := next(ctx)
+	if resp != nil && len(resp.Errors) > 0 {
+		span.SetAttributes(attribute.Int("graphql.errors", len(resp.Errors)))
+		span.SetStatus(codes.Error, resp.Errors.Error())
+	}
+
+	return resp
+}
+
+// InterceptField - метод, создающий спан для поля с собственным resolver.
+func (Tracer) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	ctx, span := tracer().Start(ctx, fc.Object+"."+fc.Field.Name,
+		trace.WithAttributes(
+			attribute.String("graphql.field.path", fc.Path().String()),
+			attribute.String("graphql.field.type", fc.Field.Definition.Type.String()),
+		),
+	)
+	defer span.End()
+
+	res, err := next(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, fmt.Sprint(err))
+	}
+
+	return res, err
+}
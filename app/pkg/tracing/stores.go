@@ -0,0 +1,309 @@
+package tracing
+
+import (
+	"context"
+	"graphql-comment-system/app/graph/model"
+	"graphql-comment-system/app/pkg/data"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startStoreSpan - вспомогательная функция, открывающая спан для вызова метода хранилища.
+func startStoreSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endStoreSpan - вспомогательная функция, закрывающая спан и отмечающая ошибку.
+// Предназначена для вызова через defer с указателем на именованную ошибку метода.
+func endStoreSpan(span trace.Span, errp *error) {
+	if *errp != nil {
+		span.RecordError(*errp)
+		span.SetStatus(codes.Error, (*errp).Error())
+	}
+	span.End()
+}
+
+// postStore - декоратор data.PostStore, создающий спан на каждый вызов.
+type postStore struct {
+	next data.PostStore // next - оборачиваемое хранилище.
+}
+
+// TracePostStore - функция, оборачивающая хранилище постов трейсингом.
+func TracePostStore(store data.PostStore) data.PostStore {
+	return &postStore{next: store}
+}
+
+// AddPost - метод добавления поста со спаном.
+func (s *postStore) AddPost(ctx context.Context, post *model.Post) (err error) {
+	ctx, span := startStoreSpan(ctx, "PostStore.AddPost", attribute.String("post.id", post.ID))
+	defer endStoreSpan(span, &err)
+	return s.next.AddPost(ctx, post)
+}
+
+// GetPostByID - метод получения поста по ID со спаном.
+func (s *postStore) GetPostByID(ctx context.Context, id string) (_ *model.Post, err error) {
+	ctx, span := startStoreSpan(ctx, "PostStore.GetPostByID", attribute.String("post.id", id))
+	defer endStoreSpan(span, &err)
+	return s.next.GetPostByID(ctx, id)
+}
+
+// GetPosts - метод получения списка постов со спаном.
+func (s *postStore) GetPosts(ctx context.Context, first int32, after *string, filter model.PostFilter) (_ *model.PostConnection, err error) {
+	ctx, span := startStoreSpan(ctx, "PostStore.GetPosts", attribute.Int("page.first", int(first)))
+	defer endStoreSpan(span, &err)
+	return s.next.GetPosts(ctx, first, after, filter)
+}
+
+// CountPosts - метод подсчета постов со спаном.
+func (s *postStore) CountPosts(ctx context.Context, filter model.PostFilter) (_ int, err error) {
+	ctx, span := startStoreSpan(ctx, "PostStore.CountPosts")
+	defer endStoreSpan(span, &err)
+	return s.next.CountPosts(ctx, filter)
+}
+
+// UpdatePost - метод правки поста со спаном.
+func (s *postStore) UpdatePost(ctx context.Context, id string, update data.PostUpdate) (_ *model.Post, err error) {
+	ctx, span := startStoreSpan(ctx, "PostStore.UpdatePost", attribute.String("post.id", id))
+	defer endStoreSpan(span, &err)
+	return s.next.UpdatePost(ctx, id, update)
+}
+
+// GetPostRevisions - метод получения ревизий поста со спаном.
+func (s *postStore) GetPostRevisions(ctx context.Context, postID string, first int32, after *string) (_ *model.RevisionConnection, err error) {
+	ctx, span := startStoreSpan(ctx, "PostStore.GetPostRevisions",
+		attribute.String("post.id", postID), attribute.Int("page.first", int(first)))
+	defer endStoreSpan(span, &err)
+	return s.next.GetPostRevisions(ctx, postID, first, after)
+}
+
+// DeletePost - метод удаления поста со спаном.
+func (s *postStore) DeletePost(ctx context.Context, id, deletedBy string, deletedAt time.Time) (_ *model.Post, err error) {
+	ctx, span := startStoreSpan(ctx, "PostStore.DeletePost", attribute.String("post.id", id))
+	defer endStoreSpan(span, &err)
+	return s.next.DeletePost(ctx, id, deletedBy, deletedAt)
+}
+
+// RestorePost - метод восстановления поста со спаном.
+func (s *postStore) RestorePost(ctx context.Context, id string) (_ *model.Post, err error) {
+	ctx, span := startStoreSpan(ctx, "PostStore.RestorePost", attribute.String("post.id", id))
+	defer endStoreSpan(span, &err)
+	return s.next.RestorePost(ctx, id)
+}
+
+// GetTags - метод получения тегов со спаном.
+func (s *postStore) GetTags(ctx context.Context) (_ []*model.Tag, err error) {
+	ctx, span := startStoreSpan(ctx, "PostStore.GetTags")
+	defer endStoreSpan(span, &err)
+	return s.next.GetTags(ctx)
+}
+
+// commentStore - декоратор data.CommentStore, создающий спан на каждый вызов.
+type commentStore struct {
+	next data.CommentStore // next - оборачиваемое хранилище.
+}
+
+// TraceCommentStore - функция, оборачивающая хранилище комментариев трейсингом.
+func TraceCommentStore(store data.CommentStore) data.CommentStore {
+	return &commentStore{next: store}
+}
+
+// AddComment - метод добавления комментария со спаном.
+func (s *commentStore) AddComment(ctx context.Context, comment *model.Comment) (err error) {
+	ctx, span := startStoreSpan(ctx, "CommentStore.AddComment",
+		attribute.String("comment.id", comment.ID), attribute.String("post.id", comment.PostID))
+	defer endStoreSpan(span, &err)
+	return s.next.AddComment(ctx, comment)
+}
+
+// GetCommentByID - метод получения комментария по ID со спаном.
+func (s *commentStore) GetCommentByID(ctx context.Context, id string) (_ *model.Comment, err error) {
+	ctx, span := startStoreSpan(ctx, "CommentStore.GetCommentByID", attribute.String("comment.id", id))
+	defer endStoreSpan(span, &err)
+	return s.next.GetCommentByID(ctx, id)
+}
+
+// GetCommentsForPost - метод получения комментариев к посту со спаном.
+func (s *commentStore) GetCommentsForPost(ctx context.Context, postID string, first int32, after *string) (_ *model.CommentConnection, err error) {
+	ctx, span := startStoreSpan(ctx, "CommentStore.GetCommentsForPost",
+		attribute.String("post.id", postID), attribute.Int("page.first", int(first)))
+	defer endStoreSpan(span, &err)
+	return s.next.GetCommentsForPost(ctx, postID, first, after)
+}
+
+// GetRepliesForComment - метод получения ответов на комментарий со спаном.
+func (s *commentStore) GetRepliesForComment(ctx context.Context, commentID string, first int32, after *string) (_ *model.CommentConnection, err error) {
+	ctx, span := startStoreSpan(ctx, "CommentStore.GetRepliesForComment",
+		attribute.String("comment.id", commentID), attribute.Int("page.first", int(first)))
+	defer endStoreSpan(span, &err)
+	return s.next.GetRepliesForComment(ctx, commentID, first, after)
+}
+
+// CountCommentsForPost - метод подсчета комментариев к посту со спаном.
+func (s *commentStore) CountCommentsForPost(ctx context.Context, postID string) (_ int, err error) {
+	ctx, span := startStoreSpan(ctx, "CommentStore.CountCommentsForPost", attribute.String("post.id", postID))
+	defer endStoreSpan(span, &err)
+	return s.next.CountCommentsForPost(ctx, postID)
+}
+
+// CountRepliesForComment - метод подсчета ответов на комментарий со спаном.
+func (s *commentStore) CountRepliesForComment(ctx context.Context, commentID string) (_ int, err error) {
+	ctx, span := startStoreSpan(ctx, "CommentStore.CountRepliesForComment", attribute.String("comment.id", commentID))
+	defer endStoreSpan(span, &err)
+	return s.next.CountRepliesForComment(ctx, commentID)
+}
+
+// UpdateComment - метод правки комментария со спаном.
+func (s *commentStore) UpdateComment(ctx context.Context, id, content string, mentions []*model.Mention, editedAt time.Time) (_ *model.Comment, err error) {
+	ctx, span := startStoreSpan(ctx, "CommentStore.UpdateComment", attribute.String("comment.id", id))
+	defer endStoreSpan(span, &err)
+	return s.next.UpdateComment(ctx, id, content, mentions, editedAt)
+}
+
+// GetCommentRevisions - метод получения ревизий комментария со спаном.
+func (s *commentStore) GetCommentRevisions(ctx context.Context, commentID string, first int32, after *string) (_ *model.RevisionConnection, err error) {
+	ctx, span := startStoreSpan(ctx, "CommentStore.GetCommentRevisions",
+		attribute.String("comment.id", commentID), attribute.Int("page.first", int(first)))
+	defer endStoreSpan(span, &err)
+	return s.next.GetCommentRevisions(ctx, commentID, first, after)
+}
+
+// DeleteComment - метод удаления комментария со спаном.
+func (s *commentStore) DeleteComment(ctx context.Context, id, deletedBy string, deletedAt time.Time) (_ *model.Comment, err error) {
+	ctx, span := startStoreSpan(ctx, "CommentStore.DeleteComment", attribute.String("comment.id", id))
+	defer endStoreSpan(span, &err)
+	return s.next.DeleteComment(ctx, id, deletedBy, deletedAt)
+}
+
+// RestoreComment - метод восстановления комментария со спаном.
+func (s *commentStore) RestoreComment(ctx context.Context, id string) (_ *model.Comment, err error) {
+	ctx, span := startStoreSpan(ctx, "CommentStore.RestoreComment", attribute.String("comment.id", id))
+	defer endStoreSpan(span, &err)
+	return s.next.RestoreComment(ctx, id)
+}
+
+// notificationStore - декоратор data.NotificationStore, создающий спан на каждый вызов.
+// Имя получателя в атрибуты не попадает, так как это персональные данные.
+type notificationStore struct {
+	next data.NotificationStore // next - оборачиваемое хранилище.
+}
+
+// TraceNotificationStore - функция, оборачивающая хранилище уведомлений трейсингом.
+func TraceNotificationStore(store data.NotificationStore) data.NotificationStore {
+	return &notificationStore{next: store}
+}
+
+// AddNotification - метод добавления уведомления со спаном.
+func (s *notificationStore) AddNotification(ctx context.Context, notification *model.Notification) (err error) {
+	ctx, span := startStoreSpan(ctx, "NotificationStore.AddNotification",
+		attribute.String("notification.id", notification.ID), attribute.String("comment.id", notification.CommentID))
+	defer endStoreSpan(span, &err)
+	return s.next.AddNotification(ctx, notification)
+}
+
+// GetNotifications - метод получения уведомлений получателя со спаном.
+func (s *notificationStore) GetNotifications(ctx context.Context, recipient string, first int32, after *string, unreadOnly bool) (_ *model.NotificationConnection, err error) {
+	ctx, span := startStoreSpan(ctx, "NotificationStore.GetNotifications",
+		attribute.Int("page.first", int(first)), attribute.Bool("notification.unread_only", unreadOnly))
+	defer endStoreSpan(span, &err)
+	return s.next.GetNotifications(ctx, recipient, first, after, unreadOnly)
+}
+
+// MarkNotificationsRead - метод отметки уведомлений прочитанными со спаном; число отмеченных добавляется в атрибуты.
+func (s *notificationStore) MarkNotificationsRead(ctx context.Context, recipient string, ids []string, readAt time.Time) (marked int, err error) {
+	ctx, span := startStoreSpan(ctx, "NotificationStore.MarkNotificationsRead", attribute.Int("notification.ids", len(ids)))
+	defer endStoreSpan(span, &err)
+	marked, err = s.next.MarkNotificationsRead(ctx, recipient, ids, readAt)
+	span.SetAttributes(attribute.Int("notification.marked", marked))
+	return marked, err
+}
+
+// KnownRecipients - метод проверки имен получателей со спаном; сами имена в атрибуты не попадают, только их число.
+func (s *notificationStore) KnownRecipients(ctx context.Context, names []string) (_ []string, err error) {
+	ctx, span := startStoreSpan(ctx, "NotificationStore.KnownRecipients", attribute.Int("notification.names", len(names)))
+	defer endStoreSpan(span, &err)
+	return s.next.KnownRecipients(ctx, names)
+}
+
+// purger - декоратор data.Purger, создающий спан на каждое окончательное удаление.
+type purger struct {
+	next data.Purger // next - оборачиваемая реализация.
+}
+
+// TracePurger - функция, оборачивающая окончательное удаление трейсингом.
+func TracePurger(p data.Purger) data.Purger {
+	return &purger{next: p}
+}
+
+// Purge - метод окончательного удаления со спаном; число удаленных записей добавляется в атрибуты.
+func (p *purger) Purge(ctx context.Context, deletedBefore time.Time) (posts, comments int, err error) {
+	ctx, span := startStoreSpan(ctx, "Purger.Purge", attribute.String("purge.deleted_before", deletedBefore.UTC().Format(time.RFC3339Nano)))
+	defer endStoreSpan(span, &err)
+	posts, comments, err = p.next.Purge(ctx, deletedBefore)
+	span.SetAttributes(attribute.Int("purge.posts", posts), attribute.Int("purge.comments", comments))
+	return posts, comments, err
+}
+
+// unitOfWork - декоратор data.UnitOfWork, создающий спан на транзакцию;
+// спаны вызовов хранилищ внутри нее становятся дочерними.
+type unitOfWork struct {
+	next data.UnitOfWork // next - оборачиваемая единица работы.
+}
+
+// TraceUnitOfWork - функция, оборачивающая единицу работы трейсингом.
+func TraceUnitOfWork(uow data.UnitOfWork) data.UnitOfWork {
+	return &unitOfWork{next: uow}
+}
+
+// Do - метод выполнения единицы работы со спаном.
+func (u *unitOfWork) Do(ctx context.Context, fn func(tx data.Tx) error) (err error) {
+	ctx, span := startStoreSpan(ctx, "UnitOfWork.Do")
+	defer endStoreSpan(span, &err)
+	return u.next.Do(ctx, func(tx data.Tx) error {
+		return fn(tracedTx{
+			posts:         TracePostStore(tx.Posts()),
+			comments:      TraceCommentStore(tx.Comments()),
+			notifications: TraceNotificationStore(tx.Notifications()),
+		})
+	})
+}
+
+// tracedTx - хранилища транзакции, обернутые трейсингом.
+type tracedTx struct {
+	posts         data.PostStore
+	comments      data.CommentStore
+	notifications data.NotificationStore
+}
+
+// Posts - метод получения хранилища постов транзакции.
+func (t tracedTx) Posts() data.PostStore { return t.posts }
+
+// Comments - метод получения хранилища комментариев транзакции.
+func (t tracedTx) Comments() data.CommentStore { return t.comments }
+
+// Notifications - метод получения хранилища уведомлений транзакции.
+func (t tracedTx) Notifications() data.NotificationStore { return t.notifications }
+
+// searcher - декоратор data.Searcher, создающий спан на каждый поиск.
+type searcher struct {
+	next data.Searcher // next - оборачиваемая реализация.
+}
+
+// TraceSearcher - функция, оборачивающая полнотекстовый поиск трейсингом.
+func TraceSearcher(s data.Searcher) data.Searcher {
+	return &searcher{next: s}
+}
+
+// Search - метод поиска со спаном; число найденных на странице записей добавляется в атрибуты.
+// Текст запроса в атрибуты не попадает, так как может содержать персональные данные.
+func (s *searcher) Search(ctx context.Context, query string, types []model.SearchType, first int32, after *string) (result *model.SearchConnection, err error) {
+	ctx, span := startStoreSpan(ctx, "Searcher.Search", attribute.Int("search.first", int(first)))
+	defer endStoreSpan(span, &err)
+	result, err = s.next.Search(ctx, query, types, first, after)
+	if result != nil {
+		span.SetAttributes(attribute.Int("search.hits", len(result.Edges)))
+	}
+	return result, err
+}
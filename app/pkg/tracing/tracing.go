@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName - имя библиотеки инструментирования, под которым создаются спаны.
+const instrumentationName = "graphql-comment-system"
+
+// Типы экспортеров трейсов.
+const (
+	ExporterNone   = "none"   // ExporterNone - трейсинг выключен, но заголовки traceparent все равно пробрасываются.
+	ExporterOTLP   = "otlp"   // ExporterOTLP - отправка по OTLP/HTTP; адрес задается OTEL_EXPORTER_OTLP_ENDPOINT.
+	ExporterStdout = "stdout" // ExporterStdout - вывод спанов в stdout в виде JSON.
+	ExporterFile   = "file"   // ExporterFile - запись спанов в файл в виде JSON, удобно для тестов без коллектора.
+)
+
+// Config - структура конфигурации трейсинга.
+type Config struct {
+	Exporter    string  // Exporter - тип экспортера: none, otlp, stdout или file.
+	FilePath    string  // FilePath - путь к файлу для экспортера file.
+	ServiceName string  // ServiceName - имя сервиса в атрибуте service.name.
+	SampleRatio float64 // SampleRatio - доля трейсов, попадающих в выборку (от 0 до 1).
+}
+
+// ShutdownFunc - функция, отправляющая накопленные спаны и освобождающая ресурсы экспортера.
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup - функция настройки глобального TracerProvider и пропагатора W3C Trace Context.
+// Возвращает функцию завершения, которую нужно вызвать при остановке сервера.
+func Setup(ctx context.Context, config Config) (ShutdownFunc, error) {
+	// Пропагатор нужен всегда, чтобы входящий traceparent передавался дальше даже без экспорта.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+
+	switch config.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		otlp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error creating OTLP exporter: %w", err)
+		}
+		exporter = otlp
+	case ExporterStdout:
+		stdout, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("error creating stdout exporter: %w", err)
+		}
+		exporter = stdout
+	case ExporterFile:
+		if config.FilePath == "" {
+			return nil, errors.New("tracing file exporter requires a file path")
+		}
+		file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("error opening trace file: %w", err)
+		}
+		fileExporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error creating file exporter: %w", err)
+		}
+		exporter, closer = fileExporter, file
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q: expected none, otlp, stdout or file", config.Exporter)
+	}
+
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = instrumentationName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace resource: %w", err)
+	}
+
+	// Сэмплирование учитывает решение родительского спана, пришедшего в traceparent.
+	ratio := config.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+// Middleware - HTTP middleware, извлекающее контекст трейса из заголовков traceparent/tracestate.
+// Спаны GraphQL-операций, созданные дальше по цепочке, становятся дочерними для вызывающего сервиса.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// tracer - вспомогательная функция получения трейсера из глобального TracerProvider.
+// Трейсер запрашивается при каждом вызове, чтобы учитывать провайдер, установленный в Setup.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"graphql-comment-system/app/graph/model"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// stubCommentStore - заглушка хранилища комментариев для проверки декоратора.
+type stubCommentStore struct{}
+
+func (stubCommentStore) AddComment(ctx context.Context, comment *model.Comment) error { return nil }
+
+func (stubCommentStore) GetCommentByID(ctx context.Context, id string) (*model.Comment, error) {
+	return nil, errors.New("boom")
+}
+
+func (stubCommentStore) GetCommentsForPost(ctx context.Context, postID string, first int32, after *string) (*model.CommentConnection, error) {
+	return &model.CommentConnection{}, nil
+}
+
+func (stubCommentStore) GetRepliesForComment(ctx context.Context, commentID string, first int32, after *string) (*model.CommentConnection, error) {
+	return &model.CommentConnection{}, nil
+}
+
+func (stubCommentStore) CountCommentsForPost(ctx context.Context, postID string) (int, error) {
+	return 0, nil
+}
+
+func (stubCommentStore) CountRepliesForComment(ctx context.Context, commentID string) (int, error) {
+	return 0, nil
+}
+
+func (stubCommentStore) UpdateComment(ctx context.Context, id, content string, mentions []*model.Mention, editedAt time.Time) (*model.Comment, error) {
+	return &model.Comment{}, nil
+}
+
+func (stubCommentStore) GetCommentRevisions(ctx context.Context, commentID string, first int32, after *string) (*model.RevisionConnection, error) {
+	return &model.RevisionConnection{}, nil
+}
+
+func (stubCommentStore) DeleteComment(ctx context.Context, id, deletedBy string, deletedAt time.Time) (*model.Comment, error) {
+	return &model.Comment{}, nil
+}
+
+func (stubCommentStore) RestoreComment(ctx context.Context, id string) (*model.Comment, error) {
+	return &model.Comment{}, nil
+}
+
+// setupRecorder - вспомогательная функция, устанавливающая провайдер с записью спанов в память.
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestTraceCommentStore(t *testing.T) {
+	// TestTraceCommentStore проверяет создание спанов для методов хранилища и отметку ошибок.
+	recorder := setupRecorder(t)
+	store := TraceCommentStore(stubCommentStore{})
+	ctx := context.Background()
+
+	_, _ = store.GetCommentsForPost(ctx, "1", 10, nil)
+	_, _ = store.GetCommentByID(ctx, "42")
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "CommentStore.GetCommentsForPost" || spans[0].Status().Code == codes.Error {
+		t.Errorf("Unexpected first span: %s (%v)", spans[0].Name(), spans[0].Status())
+	}
+	if spans[1].Name() != "CommentStore.GetCommentByID" || spans[1].Status().Code != codes.Error {
+		t.Errorf("Expected failed GetCommentByID span, got %s (%v)", spans[1].Name(), spans[1].Status())
+	}
+}
+
+func TestMiddlewarePropagatesTraceparent(t *testing.T) {
+	// TestMiddlewarePropagatesTraceparent проверяет, что входящий traceparent становится родителем спанов запроса.
+	if _, err := Setup(context.Background(), Config{Exporter: ExporterNone}); err != nil {
+		t.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	var got trace.SpanContext
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = trace.SpanContextFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/query", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || !got.IsRemote() {
+		t.Errorf("Expected remote parent from traceparent, got %v", got)
+	}
+}
+
+func TestSetupFileExporter(t *testing.T) {
+	// TestSetupFileExporter проверяет запись спанов в файл без коллектора.
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterFile, FilePath: path})
+	if err != nil {
+		t.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	_, span := tracer().Start(context.Background(), "test-span")
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shut down tracing: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read trace file: %v", err)
+	}
+	if !strings.Contains(string(raw), `"Name":"test-span"`) {
+		t.Errorf("Expected exported span in file, got %s", raw)
+	}
+
+	// Неизвестный экспортер должен приводить к ошибке.
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("Expected error for unknown exporter")
+	}
+}
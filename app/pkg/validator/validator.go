@@ -2,9 +2,13 @@ package validator
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"graphql-comment-system/app/pkg/data"
+	"graphql-comment-system/app/pkg/markdown"
+	"graphql-comment-system/app/pkg/tags"
 	"strings"
+	"unicode/utf8"
 )
 
 // ValidationError - структура для представления ошибки валидации.
@@ -21,7 +25,7 @@ func (e *ValidationError) Error() string {
 }
 
 // ValidateCreatePostInput - функция для валидации входных данных при создании поста.
-// Проверяет обязательные поля: title, author, content на заполненность.
+// Проверяет обязательные поля: title, author, content на заполненность и разметку content (см. validateMarkup).
 func ValidateCreatePostInput(ctx context.Context, title, author, content string) []error {
 	var errors []error // errors - слайс для хранения ошибок валидации.
 
@@ -42,13 +46,19 @@ func ValidateCreatePostInput(ctx context.Context, title, author, content string)
 		// Если content состоит только из пробелов или пустое, добавляется ошибка валидации.
 		errors = append(errors, &ValidationError{Field: "content", Message: "content cannot be empty"})
 	}
+	// Проверка разметки поля content.
+	if err := validateMarkup(content); err != nil {
+		errors = append(errors, err)
+	}
 
 	return errors // Возвращает слайс накопленных ошибок валидации.
 }
 
 // ValidateCreateCommentInput - функция для валидации входных данных при создании комментария.
-// Выполняет несколько проверок: обязательные поля, максимальную длину контента,
+// Выполняет несколько проверок: обязательные поля, максимальную длину и разметку контента,
 // существование поста и родительского комментария (при наличии).
+// Ошибки валидации имеют тип *ValidationError; если же хранилище не смогло ответить,
+// возвращается единственная ошибка другого типа.
 func ValidateCreateCommentInput(postStore data.PostStore, commentStore data.CommentStore, ctx context.Context, author, content, postId string, parentId *string) []error {
 	var errors []error // errors - слайс для хранения ошибок валидации.
 
@@ -68,6 +78,10 @@ func ValidateCreateCommentInput(postStore data.PostStore, commentStore data.Comm
 		// Если длина content превышает 2000 символов, добавляется ошибка валидации.
 		errors = append(errors, &ValidationError{Field: "content", Message: "comment cannot be longer than 2000 characters"})
 	}
+	// Проверка разметки поля content.
+	if err := validateMarkup(content); err != nil {
+		errors = append(errors, err)
+	}
 
 	// Проверка поля postId на пустоту.
 	if len(strings.TrimSpace(postId)) == 0 {
@@ -75,11 +89,14 @@ func ValidateCreateCommentInput(postStore data.PostStore, commentStore data.Comm
 		errors = append(errors, &ValidationError{Field: "postId", Message: "postId cannot be empty"})
 	}
 
-	// Проверка существования поста с указанным postId в хранилище.
-	_, err := postStore.GetPostByID(ctx, postId)
-	if err != nil {
+	// Проверка существования поста с указанным postId в хранилище; удаленный пост считается отсутствующим.
+	post, err := postStore.GetPostByID(ctx, postId)
+	if stderrors.Is(err, data.ErrNotFound) || (err == nil && post.DeletedAt != nil) {
 		// Если пост с указанным postId не найден, добавляется ошибка валидации.
 		errors = append(errors, &ValidationError{Field: "postId", Message: "post with id " + postId + " not found"})
+	} else if err != nil {
+		// Другая ошибка хранилища (например, база недоступна) - не ошибка входных данных, проверка прерывается.
+		return []error{fmt.Errorf("error checking post %s: %w", postId, err)}
 	}
 
 	// Проверка поля parentId, если оно передано (не nil).
@@ -92,8 +109,11 @@ func ValidateCreateCommentInput(postStore data.PostStore, commentStore data.Comm
 
 		// Проверка существования родительского комментария с указанным parentId в хранилище.
 		comment, err := commentStore.GetCommentByID(ctx, *parentId)
-		if err != nil {
-			// Если родительский комментарий с указанным parentId не найден, добавляется ошибка валидации и функция завершает работу.
+		if err != nil && !stderrors.Is(err, data.ErrNotFound) {
+			return []error{fmt.Errorf("error checking parent comment %s: %w", *parentId, err)}
+		}
+		if err != nil || comment.DeletedAt != nil {
+			// Если родительский комментарий с указанным parentId не найден (или удален), добавляется ошибка валидации и функция завершает работу.
 			errors = append(errors, &ValidationError{Field: "parentId", Message: "parent comment with id " + *parentId + " not found"})
 			return errors // Возврат ошибок, так как дальнейшая проверка не имеет смысла без родительского комментария.
 		}
@@ -106,4 +126,105 @@ func ValidateCreateCommentInput(postStore data.PostStore, commentStore data.Comm
 	}
 
 	return errors // Возвращает слайс накопленных ошибок валидации.
-}
\ No newline at end of file
+}
+
+// ValidateUpdatePostInput - функция для валидации входных данных при правке поста.
+// Переданные (не nil) title и content не должны быть пустыми, разметка content проверяется так же, как при создании;
+// автор обязателен для проверки прав.
+func ValidateUpdatePostInput(ctx context.Context, author string, title, content *string) []error {
+	var errors []error // errors - слайс для хранения ошибок валидации.
+
+	if len(strings.TrimSpace(author)) == 0 {
+		errors = append(errors, &ValidationError{Field: "author", Message: "author cannot be empty"})
+	}
+	if title != nil && len(strings.TrimSpace(*title)) == 0 {
+		errors = append(errors, &ValidationError{Field: "title", Message: "title cannot be empty"})
+	}
+	if content != nil && len(strings.TrimSpace(*content)) == 0 {
+		errors = append(errors, &ValidationError{Field: "content", Message: "content cannot be empty"})
+	}
+	if content != nil {
+		if err := validateMarkup(*content); err != nil {
+			errors = append(errors, err)
+		}
+	}
+
+	return errors // Возвращает слайс накопленных ошибок валидации.
+}
+
+// ValidateUpdateCommentInput - функция для валидации входных данных при правке комментария.
+// К новому тексту применяются те же ограничения, что и при создании комментария.
+func ValidateUpdateCommentInput(ctx context.Context, author, content string) []error {
+	var errors []error // errors - слайс для хранения ошибок валидации.
+
+	if len(strings.TrimSpace(author)) == 0 {
+		errors = append(errors, &ValidationError{Field: "author", Message: "author cannot be empty"})
+	}
+	if len(strings.TrimSpace(content)) == 0 {
+		errors = append(errors, &ValidationError{Field: "content", Message: "content cannot be empty"})
+	}
+	if len(content) > 2000 {
+		errors = append(errors, &ValidationError{Field: "content", Message: "comment cannot be longer than 2000 characters"})
+	}
+	if err := validateMarkup(content); err != nil {
+		errors = append(errors, err)
+	}
+
+	return errors // Возвращает слайс накопленных ошибок валидации.
+}
+
+// ValidateDeleteInput - функция для валидации входных данных при удалении поста или комментария.
+// Автор обязателен для проверки прав.
+func ValidateDeleteInput(ctx context.Context, author string) []error {
+	var errors []error // errors - слайс для хранения ошибок валидации.
+
+	if len(strings.TrimSpace(author)) == 0 {
+		errors = append(errors, &ValidationError{Field: "author", Message: "author cannot be empty"})
+	}
+
+	return errors // Возвращает слайс накопленных ошибок валидации.
+}
+
+// ValidateSearchInput - функция для валидации поискового запроса.
+// Запрос не может быть пустым и длиннее 200 символов.
+func ValidateSearchInput(ctx context.Context, query string) []error {
+	var errors []error // errors - слайс для хранения ошибок валидации.
+
+	if len(strings.TrimSpace(query)) == 0 {
+		errors = append(errors, &ValidationError{Field: "query", Message: "query cannot be empty"})
+	}
+	if utf8.RuneCountInString(query) > 200 {
+		errors = append(errors, &ValidationError{Field: "query", Message: "query cannot be longer than 200 characters"})
+	}
+
+	return errors // Возвращает слайс накопленных ошибок валидации.
+}
+
+// ValidateTags - функция для валидации тегов поста.
+// Каждое название после нормализации (см. tags.Normalize) должно быть непустым и не длиннее tags.MaxLength
+// символов, а различных тегов у поста - не больше tags.MaxPerPost.
+func ValidateTags(ctx context.Context, names []string) []error {
+	var errors []error // errors - слайс для хранения ошибок валидации.
+
+	for _, name := range names {
+		if !tags.Valid(tags.Normalize(name)) {
+			errors = append(errors, &ValidationError{Field: "tags", Message: fmt.Sprintf("tag %q must contain letters or digits and be at most %d characters long", name, tags.MaxLength)})
+		}
+	}
+	if len(tags.NormalizeAll(names)) > tags.MaxPerPost {
+		errors = append(errors, &ValidationError{Field: "tags", Message: fmt.Sprintf("post cannot have more than %d tags", tags.MaxPerPost)})
+	}
+
+	return errors // Возвращает слайс накопленных ошибок валидации.
+}
+
+// validateMarkup - вспомогательная функция проверки разметки текста поста или комментария: текст не должен
+// содержать разметку, которую не поддерживает отрисовка contentHtml (см. markdown.Unsupported). Возвращает nil,
+// если разметка допустима.
+func validateMarkup(content string) error {
+	unsupported := markdown.Unsupported(content)
+	if len(unsupported) == 0 {
+		return nil
+	}
+	return &ValidationError{Field: "content", Message: "content contains unsupported markup: " + strings.Join(unsupported, ", ")}
+}